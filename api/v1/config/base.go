@@ -38,6 +38,7 @@ func (api *ConfigAPI) RegisterRoutes(router *gin.RouterGroup) {
 		// 维度主体配置
 		config.POST("/dimensions", api.CreateDimension)
 		config.PUT("/dimensions/:dim_id", api.UpdateDimension)
+		config.POST("/dimensions/:dim_id/namespace", api.MigrateDimensionNamespace)
 		config.GET("/dimensions/:dim_id", api.GetDimensionByID)
 		config.DELETE("/dimensions/:dim_id", api.DeleteDimension)
 
@@ -65,7 +66,16 @@ func (api *ConfigAPI) RegisterRoutes(router *gin.RouterGroup) {
 		// 数据表主体配置
 		config.GET("/tables/:table_id", api.GetTable)
 		config.POST("/tables", api.CreateTable)
+		config.POST("/tables/adopt", api.AdoptTable)
+		config.POST("/tables/import", api.ImportTable)
+		config.GET("/tables/:table_id/export", api.ExportTable)
+		config.POST("/tables/:table_id/clone", api.CloneTable)
+		config.POST("/tables/:table_id/namespace", api.MigrateTableNamespace)
 		config.PUT("/tables/:table_id", api.UpdateTable)
 		config.DELETE("/tables/:table_id", api.DeleteTable)
+
+		// 数据表回收站
+		config.GET("/tables/trash", api.ListTrashTables)
+		config.PUT("/tables/:table_id/restore", api.RestoreTable)
 	}
 }
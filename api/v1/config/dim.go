@@ -146,6 +146,36 @@ func (api *ConfigAPI) DeleteDimension(c *gin.Context) {
 	utils.Success(c, nil)
 }
 
+// @Summary      迁移维度到应用命名空间
+// @Description  将未按应用命名的旧维度表改名为app{应用ID}_{逻辑表名}，逻辑表名和维度ID不变；
+// @Description  已在应用命名空间中的维度不做处理，菜单维度不能迁移，返回迁移后的数据库表名
+// @Tags         ConfigDimension
+// @Accept       json
+// @Produce      json
+// @Security     Bearer
+// @Param        Authorization header string true "Bearer token"
+// @Param        App-ID header string true "应用ID"
+// @Param        dim_id path int true "配置ID"
+// @Success      200  {object}  Response
+// @Failure      400  {object}  Response
+// @Failure      500  {object}  Response
+// @Router       /config/dimensions/{dim_id}/namespace [post]
+func (api *ConfigAPI) MigrateDimensionNamespace(c *gin.Context) {
+	id := utils.ParseUint(c.Param("dim_id"))
+	if id == 0 {
+		utils.Error(c, http.StatusBadRequest, "invalid id")
+		return
+	}
+
+	physicalName, err := api.configService.MigrateDimensionNamespace(id)
+	if err != nil {
+		utils.ServerError(c, err)
+		return
+	}
+
+	utils.Success(c, gin.H{"physical_name": physicalName})
+}
+
 // @Summary      获取维度配置列表
 // @Description  获取维度配置列表
 // @Tags         ConfigDimension
@@ -1,10 +1,12 @@
 package config
 
 import (
+	"errors"
 	"strconv"
 
 	"github.com/gin-gonic/gin"
 	"github.com/iiwish/lingjian/internal/model"
+	"github.com/iiwish/lingjian/internal/service/config"
 	"github.com/iiwish/lingjian/pkg/utils"
 )
 
@@ -38,7 +40,8 @@ func (api *ConfigAPI) GetTable(c *gin.Context) {
 }
 
 // @Summary      创建数据表配置
-// @Description  创建新的数据表配置
+// @Description  创建新的数据表配置，table_name为应用内的逻辑表名，数据库中的表名为app{应用ID}_{table_name}；
+// @Description  逻辑表名不能使用sys_、app{数字}_前缀和_archive后缀
 // @Tags         ConfigTable
 // @Accept       json
 // @Produce      json
@@ -69,6 +72,94 @@ func (api *ConfigAPI) CreateTable(c *gin.Context) {
 	utils.Success(c, gin.H{"id": id})
 }
 
+// @Summary      纳管已有数据表
+// @Description  将数据库中已有的数据表纳入平台管理，读取字段和索引，创建数据表配置和系统菜单，并标记平台不支持的字段类型
+// @Tags         ConfigTable
+// @Accept       json
+// @Produce      json
+// @Security     Bearer
+// @Param        Authorization header string true "Bearer token"
+// @Param        App-ID header string true "应用ID"
+// @Param        request body model.AdoptTableReq true "纳管数据表请求参数"
+// @Success      200  {object}  model.AdoptTableResp
+// @Failure      400  {object}  Response
+// @Failure      500  {object}  Response
+// @Router       /config/tables/adopt [post]
+func (api *ConfigAPI) AdoptTable(c *gin.Context) {
+	var req model.AdoptTableReq
+	if err := c.ShouldBindJSON(&req); err != nil {
+		utils.ValidationError(c, err)
+		return
+	}
+	if err := req.Validate(); err != nil {
+		utils.ValidationError(c, err)
+		return
+	}
+
+	userID := c.GetUint("user_id")
+	appID := c.GetUint("app_id")
+	resp, err := api.configService.AdoptTable(&req, userID, appID)
+	if err != nil {
+		utils.ServerError(c, err)
+		return
+	}
+
+	utils.Success(c, resp)
+}
+
+// @Summary      克隆数据表
+// @Description  将数据表的字段、索引、功能配置和菜单位置克隆到目标应用，copy_data为true时按query条件复制记录，
+// @Description  附件字段的取值不复制，只复制用户数据权限内的记录和可以原样读取的字段，其他字段使用默认值；
+// @Description  源数据表或目标应用不属于当前应用时需有对应应用的访问权限；克隆记录在新数据表的操作记录中
+// @Tags         ConfigTable
+// @Accept       json
+// @Produce      json
+// @Security     Bearer
+// @Param        Authorization header string true "Bearer token"
+// @Param        App-ID header string true "应用ID"
+// @Param        table_id path int true "源数据表配置ID"
+// @Param        request body model.CloneTableReq true "克隆数据表请求参数"
+// @Success      200  {object}  model.CloneTableResp
+// @Failure      400  {object}  Response
+// @Failure      403  {object}  Response
+// @Failure      500  {object}  Response
+// @Router       /config/tables/{table_id}/clone [post]
+func (api *ConfigAPI) CloneTable(c *gin.Context) {
+	id := utils.ParseUint(c.Param("table_id"))
+	if id == 0 {
+		utils.Error(c, 400, "invalid id")
+		return
+	}
+
+	var req model.CloneTableReq
+	if err := c.ShouldBindJSON(&req); err != nil {
+		utils.ValidationError(c, err)
+		return
+	}
+	if err := req.Validate(); err != nil {
+		utils.ValidationError(c, err)
+		return
+	}
+
+	userID := c.GetUint("user_id")
+	appID := c.GetUint("app_id")
+	resp, err := api.configService.CloneTable(id, &req, userID, appID)
+	if err != nil {
+		var qerr *model.QueryError
+		switch {
+		case errors.As(err, &qerr):
+			utils.ValidationError(c, err)
+		case errors.Is(err, config.ErrAppAccessDenied):
+			utils.Error(c, 403, err.Error())
+		default:
+			utils.ServerError(c, err)
+		}
+		return
+	}
+
+	utils.Success(c, resp)
+}
+
 // @Summary      更新数据表配置
 // @Description  更新指定数据表的配置信息,包括基本信息、字段信息、索引信息和功能信息
 // @Tags         ConfigTable
@@ -109,7 +200,8 @@ func (api *ConfigAPI) UpdateTable(c *gin.Context) {
 }
 
 // @Summary      删除数据表配置
-// @Description  删除指定的数据表配置
+// @Description  将指定的数据表移入回收站，数据表改名为回收站表名并保留配置，保留期内可以恢复，
+// @Description  超过保留期（配置项table.trash_retention_days，默认30天）后由table_trash_purge任务彻底删除
 // @Tags         ConfigTable
 // @Accept       json
 // @Produce      json
@@ -128,10 +220,40 @@ func (api *ConfigAPI) DeleteTable(c *gin.Context) {
 		return
 	}
 
-	if err := api.configService.DeleteTable(uint(id)); err != nil {
+	if err := api.configService.DeleteTable(uint(id), c.GetUint("user_id")); err != nil {
 		utils.ServerError(c, err)
 		return
 	}
 
 	utils.Success(c, nil)
 }
+
+// @Summary      迁移数据表到应用命名空间
+// @Description  将未按应用命名的旧数据表改名为app{应用ID}_{逻辑表名}，归档表一起改名，逻辑表名和数据表ID不变；
+// @Description  已在应用命名空间中的数据表不做处理，返回迁移后的数据库表名
+// @Tags         ConfigTable
+// @Accept       json
+// @Produce      json
+// @Security     Bearer
+// @Param        Authorization header string true "Bearer token"
+// @Param        App-ID header string true "应用ID"
+// @Param        table_id path int true "配置ID"
+// @Success      200  {object}  Response
+// @Failure      400  {object}  Response
+// @Failure      500  {object}  Response
+// @Router       /config/tables/{table_id}/namespace [post]
+func (api *ConfigAPI) MigrateTableNamespace(c *gin.Context) {
+	id := utils.ParseUint(c.Param("table_id"))
+	if id == 0 {
+		utils.Error(c, 400, "invalid id")
+		return
+	}
+
+	physicalName, err := api.configService.MigrateTableNamespace(id)
+	if err != nil {
+		utils.ServerError(c, err)
+		return
+	}
+
+	utils.Success(c, gin.H{"physical_name": physicalName})
+}
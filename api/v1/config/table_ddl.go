@@ -0,0 +1,83 @@
+package config
+
+import (
+	"errors"
+
+	"github.com/gin-gonic/gin"
+	"github.com/iiwish/lingjian/internal/model"
+	"github.com/iiwish/lingjian/pkg/utils"
+)
+
+// @Summary      导出数据表定义
+// @Description  导出数据表定义，表名为逻辑表名。format为ddl时导出建表语句，可使用导入接口导入；
+// @Description  format为json时导出平台的数据表定义，含功能配置和字段扩展配置，可直接用于创建数据表
+// @Tags         ConfigTable
+// @Accept       json
+// @Produce      json
+// @Security     Bearer
+// @Param        Authorization header string true "Bearer token"
+// @Param        App-ID header string true "应用ID"
+// @Param        table_id path int true "配置ID"
+// @Param        format query string false "导出格式：ddl、json，默认为ddl"
+// @Success      200  {object}  model.ExportTableResp
+// @Failure      400  {object}  Response
+// @Failure      500  {object}  Response
+// @Router       /config/tables/{table_id}/export [get]
+func (api *ConfigAPI) ExportTable(c *gin.Context) {
+	id := utils.ParseUint(c.Param("table_id"))
+	if id == 0 {
+		utils.Error(c, 400, "invalid id")
+		return
+	}
+	format := c.DefaultQuery("format", model.TableExportDDL)
+	if format != model.TableExportDDL && format != model.TableExportJSON {
+		utils.Error(c, 400, "invalid format")
+		return
+	}
+
+	resp, err := api.configService.ExportTable(id, format)
+	if err != nil {
+		utils.ServerError(c, err)
+		return
+	}
+
+	utils.Success(c, resp)
+}
+
+// @Summary      从建表语句导入数据表
+// @Description  解析CREATE TABLE语句创建数据表，表名默认使用语句中的表名，去掉本应用的命名空间前缀；
+// @Description  外键、检查约束、生成列、表达式默认值、前缀索引、分区等不支持的子句忽略并在unsupported中说明；
+// @Description  字段使用原始字段类型，只有管理员可以导入；dry_run为true时只返回解析和校验结果，不创建数据表
+// @Tags         ConfigTable
+// @Accept       json
+// @Produce      json
+// @Security     Bearer
+// @Param        Authorization header string true "Bearer token"
+// @Param        App-ID header string true "应用ID"
+// @Param        request body model.ImportTableReq true "导入数据表请求参数"
+// @Success      200  {object}  model.ImportTableResp
+// @Failure      400  {object}  Response
+// @Failure      500  {object}  Response
+// @Router       /config/tables/import [post]
+func (api *ConfigAPI) ImportTable(c *gin.Context) {
+	var req model.ImportTableReq
+	if err := c.ShouldBindJSON(&req); err != nil {
+		utils.ValidationError(c, err)
+		return
+	}
+
+	userID := c.GetUint("user_id")
+	appID := c.GetUint("app_id")
+	resp, err := api.configService.ImportTable(&req, userID, appID)
+	if err != nil {
+		var derr *model.DDLError
+		if errors.As(err, &derr) {
+			utils.ValidationError(c, err)
+			return
+		}
+		utils.ServerError(c, err)
+		return
+	}
+
+	utils.Success(c, resp)
+}
@@ -0,0 +1,68 @@
+package config
+
+import (
+	"errors"
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+	"github.com/iiwish/lingjian/internal/service/config"
+	"github.com/iiwish/lingjian/pkg/utils"
+)
+
+// @Summary      获取回收站中的数据表
+// @Description  获取当前应用中已删除、尚未彻底删除的数据表，expires_at之后不能恢复
+// @Tags         ConfigTable
+// @Accept       json
+// @Produce      json
+// @Security     Bearer
+// @Param        Authorization header string true "Bearer token"
+// @Param        App-ID header string true "应用ID"
+// @Success      200  {object}  utils.Response{data=[]model.TrashTable}
+// @Failure      500  {object}  Response
+// @Router       /config/tables/trash [get]
+func (api *ConfigAPI) ListTrashTables(c *gin.Context) {
+	tables, err := api.configService.ListTrashTables(c.GetUint("app_id"))
+	if err != nil {
+		utils.ServerError(c, err)
+		return
+	}
+
+	utils.Success(c, tables)
+}
+
+// @Summary      恢复数据表
+// @Description  从回收站恢复数据表，数据表和归档表改回原表名；超过保留期或原表名已被占用时不能恢复
+// @Tags         ConfigTable
+// @Accept       json
+// @Produce      json
+// @Security     Bearer
+// @Param        Authorization header string true "Bearer token"
+// @Param        App-ID header string true "应用ID"
+// @Param        table_id path int true "配置ID"
+// @Success      200  {object}  nil
+// @Failure      400  {object}  Response
+// @Failure      404  {object}  Response
+// @Failure      500  {object}  Response
+// @Router       /config/tables/{table_id}/restore [put]
+func (api *ConfigAPI) RestoreTable(c *gin.Context) {
+	id := utils.ParseUint(c.Param("table_id"))
+	if id == 0 {
+		utils.Error(c, 400, "invalid id")
+		return
+	}
+
+	err := api.configService.RestoreTable(id, c.GetUint("user_id"), c.GetUint("app_id"))
+	if err != nil {
+		switch {
+		case errors.Is(err, config.ErrTrashTableNotFound):
+			utils.Error(c, http.StatusNotFound, err.Error())
+		case errors.Is(err, config.ErrTrashExpired):
+			utils.Error(c, http.StatusBadRequest, err.Error())
+		default:
+			utils.ServerError(c, err)
+		}
+		return
+	}
+
+	utils.Success(c, nil)
+}
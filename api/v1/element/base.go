@@ -25,6 +25,13 @@ func RegisterElementRoutes(router *gin.RouterGroup) {
 	elementAPI.RegisterRoutes(router)
 }
 
+// RegisterPublicRoutes 注册不需要认证的路由，附件下载通过地址中的签名校验
+func RegisterPublicRoutes(router *gin.RouterGroup) {
+	elementService := element.NewElementService(model.DB)
+	elementAPI := NewElementAPI(elementService)
+	router.GET("/attachments/:id/download", elementAPI.DownloadAttachment)
+}
+
 // RegisterRoutes 注册路由
 func (api *ElementAPI) RegisterRoutes(router *gin.RouterGroup) {
 	// 维度明细配置
@@ -45,8 +52,37 @@ func (api *ElementAPI) RegisterRoutes(router *gin.RouterGroup) {
 
 	// 数据表明细配置
 	router.POST("/table/:table_id/query", api.QueryTableItems)
+	router.GET("/table/:table_id/search", api.SearchTableItems)
+	router.POST("/table/:table_id/explain", api.ExplainTableItems)
+	router.GET("/table/:table_id/cache", api.GetTableCacheStats)
 	// router.GET("/table/:table_id", api.GetTableItems)
 	router.POST("/table/:table_id", api.CreateTableItems)
 	router.PUT("/table/:table_id", api.UpdateTableItems)
 	router.DELETE("/table/:table_id", api.DeleteTableItems)
+
+	// 数据表回收站
+	router.GET("/table/:table_id/recycle", api.GetRecycleItems)
+	router.PUT("/table/:table_id/recycle", api.RestoreTableItems)
+	router.DELETE("/table/:table_id/recycle", api.PurgeTableItems)
+
+	// 数据表按条件批量操作
+	router.POST("/table/:table_id/bulk/preview", api.PreviewBulkItems)
+	router.PUT("/table/:table_id/bulk", api.BulkUpdateTableItems)
+	router.DELETE("/table/:table_id/bulk", api.BulkDeleteTableItems)
+	router.GET("/table/:table_id/operations", api.GetTableOperations)
+
+	// 数据表附件
+	router.POST("/table/:table_id/attachments", api.UploadAttachment)
+	router.GET("/table/:table_id/attachments/:id", api.GetAttachment)
+
+	// 数据表视图
+	router.GET("/table/:table_id/views", api.ListTableViews)
+	router.GET("/table/:table_id/views/:view_id", api.GetTableView)
+	router.POST("/table/:table_id/views", api.CreateTableView)
+	router.PUT("/table/:table_id/views/:view_id", api.UpdateTableView)
+	router.DELETE("/table/:table_id/views/:view_id", api.DeleteTableView)
+
+	// 数据表归档
+	router.POST("/table/:table_id/archive", api.ArchiveTableItems)
+	router.GET("/table/:table_id/archive/logs", api.GetArchiveLogs)
 }
@@ -1,11 +1,13 @@
 package element
 
 import (
-	"fmt"
+	"errors"
 	"net/http"
+	"strings"
 
 	"github.com/gin-gonic/gin"
 	"github.com/iiwish/lingjian/internal/model"
+	"github.com/iiwish/lingjian/internal/service/element"
 	"github.com/iiwish/lingjian/pkg/utils"
 )
 
@@ -20,11 +22,12 @@ import (
 // @Param        table_id path int true "表ID"
 // @Param        page query int false "页码"
 // @Param        page_size query int false "每页数量"
+// @Param        expand_refs query bool false "是否展开引用字段"
 // @Param        query body model.QueryCondition false "查询条件"
-// @Success      200  {object}  utils.Response
+// @Success      200  {object}  utils.Response{data=model.TableQueryResp}
 // @Failure      400  {object}  utils.Response
 // @Failure      500  {object}  utils.Response
-// @Router       /table/{table_id}/query [post]
+// @Router       /table/{table_id} [get]
 func (api *ElementAPI) GetTableItems(c *gin.Context) {
 	// 获取表ID
 	tableID := utils.ParseUint(c.Param("table_id"))
@@ -33,25 +36,14 @@ func (api *ElementAPI) GetTableItems(c *gin.Context) {
 		return
 	}
 
-	// 获取分页参数
-	page := utils.ParseInt(c.Query("page"))
-	if page <= 0 {
-		page = 1
-	}
-
-	// 获取每页数量
-	pageSize := utils.ParseInt(c.Query("page_size"))
-	if pageSize <= 0 {
-		pageSize = 10
-	}
-	if pageSize > 1000 {
-		pageSize = 1000
-	}
-
 	// 获取查询条件
-	var query model.QueryCondition
-	if err := c.ShouldBindJSON(&query); err != nil {
-		query = model.QueryCondition{
+	req := model.TableQueryReq{
+		Page:       utils.ParseInt(c.Query("page")),
+		PageSize:   utils.ParseInt(c.Query("page_size")),
+		ExpandRefs: c.Query("expand_refs") == "true",
+	}
+	if err := c.ShouldBindJSON(&req.Query); err != nil {
+		req.Query = model.QueryCondition{
 			Root: model.ConditionGroup{
 				Logic:      model.LogicAnd,
 				Conditions: []interface{}{},
@@ -60,28 +52,91 @@ func (api *ElementAPI) GetTableItems(c *gin.Context) {
 			GroupBy: []string{},
 		} // 使用默认值
 	}
-	// 打印查询条件
-	fmt.Println(query)
+
+	api.queryTableItems(c, tableID, &req)
+}
+
+// @Summary      查询数据表记录QueryTableItems
+// @Description  查询指定数据表的记录列表，支持通过select、group_by、having进行聚合查询；
+// @Description  pagination为cursor时使用游标分页，返回的next_cursor用于获取下一页；count_mode可选exact、estimate、none；
+// @Description  数据表功能配置cache_ttl大于0时缓存查询结果，写入数据表后缓存失效，cached表示结果来自缓存；
+// @Description  缓存中不含引用字段展开的内容，expand_refs在读取结果后按引用表的当前数据展开；
+// @Description  传入view_id时按视图查询，视图的过滤条件与query同时生效，未指定的排序和每页数量使用视图的配置，结果中不包含视图隐藏的字段
+// @Description  include_archived为true且数据表配置了归档策略时，同时查询归档表中的记录
+// @Tags         Table
+// @Accept       json
+// @Produce      json
+// @Security     Bearer
+// @Param        Authorization header string true "Bearer Token"
+// @Param        App-ID header string true "应用ID"
+// @Param        table_id path int true "表ID"
+// @Param        page query int false "页码"
+// @Param        page_size query int false "每页数量"
+// @Param        query body model.TableQueryReq true "查询条件"
+// @Success      200  {object}  utils.Response{data=model.TableQueryResp}
+// @Failure      400  {object}  utils.Response
+// @Failure      500  {object}  utils.Response
+// @Router       /table/{table_id}/query [post]
+func (api *ElementAPI) QueryTableItems(c *gin.Context) {
+	// 获取表ID
+	tableID := utils.ParseUint(c.Param("table_id"))
+	if tableID == 0 {
+		utils.Error(c, http.StatusBadRequest, "invalid table_id")
+		return
+	}
+
+	// 获取查询条件
+	var req model.TableQueryReq
+	if err := c.ShouldBindJSON(&req); err != nil {
+		utils.Error(c, http.StatusBadRequest, "invalid query parameters")
+		return
+	}
+
+	// 请求体未指定分页参数时使用URL参数
+	if req.Page <= 0 {
+		req.Page = utils.ParseInt(c.Query("page"))
+	}
+	if req.PageSize <= 0 {
+		req.PageSize = utils.ParseInt(c.Query("page_size"))
+	}
+
+	api.queryTableItems(c, tableID, &req)
+}
+
+// queryTableItems 规范分页参数并查询数据表记录
+func (api *ElementAPI) queryTableItems(c *gin.Context, tableID uint, req *model.TableQueryReq) {
+	if req.Page <= 0 {
+		req.Page = 1
+	}
+	// 按视图查询且未指定每页数量时使用视图的配置
+	if req.PageSize <= 0 && req.ViewID == 0 {
+		req.PageSize = 10
+	}
+	if req.PageSize > 1000 {
+		req.PageSize = 1000
+	}
 
 	// 获取记录列表
-	items, total, err := api.elementService.GetTableItems(tableID, page, pageSize, &query)
+	resp, err := api.elementService.GetTableItems(tableID, req, c.GetUint("user_id"))
 	if err != nil {
+		var qerr *model.QueryError
+		if errors.As(err, &qerr) {
+			utils.Error(c, http.StatusBadRequest, err.Error())
+			return
+		}
+		if errors.Is(err, element.ErrViewNotFound) {
+			utils.Error(c, http.StatusNotFound, err.Error())
+			return
+		}
 		utils.Error(c, http.StatusInternalServerError, err.Error())
 		return
 	}
 
-	data := map[string]interface{}{
-		"total":    total,
-		"page":     page,
-		"pageSize": pageSize,
-		"items":    items,
-	}
-
-	utils.Success(c, data)
+	utils.Success(c, resp)
 }
 
-// @Summary      查询数据表记录QueryTableItems
-// @Description  查询指定数据表的记录列表
+// @Summary      快速搜索数据表记录
+// @Description  在数据表功能配置的search_cols字段中搜索关键字，有全文索引的字段使用全文检索，结果按相关度排序
 // @Tags         Table
 // @Accept       json
 // @Produce      json
@@ -89,14 +144,15 @@ func (api *ElementAPI) GetTableItems(c *gin.Context) {
 // @Param        Authorization header string true "Bearer Token"
 // @Param        App-ID header string true "应用ID"
 // @Param        table_id path int true "表ID"
+// @Param        q query string true "搜索关键字"
 // @Param        page query int false "页码"
 // @Param        page_size query int false "每页数量"
-// @Param        query body model.QueryCondition true "查询条件"
-// @Success      200  {object}  utils.Response
+// @Param        expand_refs query bool false "是否展开引用字段"
+// @Success      200  {object}  utils.Response{data=model.TableQueryResp}
 // @Failure      400  {object}  utils.Response
 // @Failure      500  {object}  utils.Response
-// @Router       /table/{table_id}/query [post]
-func (api *ElementAPI) QueryTableItems(c *gin.Context) {
+// @Router       /table/{table_id}/search [get]
+func (api *ElementAPI) SearchTableItems(c *gin.Context) {
 	// 获取表ID
 	tableID := utils.ParseUint(c.Param("table_id"))
 	if tableID == 0 {
@@ -104,49 +160,116 @@ func (api *ElementAPI) QueryTableItems(c *gin.Context) {
 		return
 	}
 
-	// 获取分页参数
-	page := utils.ParseInt(c.Query("page"))
-	if page <= 0 {
-		page = 1
+	req := &model.TableQueryReq{
+		Page:       utils.ParseInt(c.Query("page")),
+		PageSize:   utils.ParseInt(c.Query("page_size")),
+		ExpandRefs: c.Query("expand_refs") == "true",
+		Query:      model.QueryCondition{Root: model.ConditionGroup{Logic: model.LogicAnd}},
 	}
-
-	// 获取每页数量
-	pageSize := utils.ParseInt(c.Query("page_size"))
-	if pageSize <= 0 {
-		pageSize = 10
+	if req.Page <= 0 {
+		req.Page = 1
+	}
+	if req.PageSize <= 0 {
+		req.PageSize = 10
 	}
-	if pageSize > 1000 {
-		pageSize = 1000
+	if req.PageSize > 1000 {
+		req.PageSize = 1000
 	}
 
-	// 获取查询条件
-	var query struct {
-		Page     int                  `json:"page"`
-		PageSize int                  `json:"page_size"`
-		Query    model.QueryCondition `json:"query"`
+	resp, err := api.elementService.SearchTableItems(tableID, c.Query("q"), req, c.GetUint("user_id"))
+	if err != nil {
+		var qerr *model.QueryError
+		if errors.As(err, &qerr) || errors.Is(err, element.ErrSearchDisabled) {
+			utils.Error(c, http.StatusBadRequest, err.Error())
+			return
+		}
+		utils.Error(c, http.StatusInternalServerError, err.Error())
+		return
 	}
-	if err := c.ShouldBindJSON(&query); err != nil {
+
+	utils.Success(c, resp)
+}
+
+// @Summary      查看查询执行计划
+// @Description  请求参数与查询数据表记录相同，返回查询记录时生成的SQL、代入参数后的SQL和EXPLAIN FORMAT=JSON执行计划，
+// @Description  包含视图条件、归档表和分页，未指定页码和每页数量时为第一页；标记全表扫描并根据过滤和排序字段建议索引，仅管理员可用
+// @Tags         Table
+// @Accept       json
+// @Produce      json
+// @Security     Bearer
+// @Param        Authorization header string true "Bearer Token"
+// @Param        App-ID header string true "应用ID"
+// @Param        table_id path int true "表ID"
+// @Param        query body model.TableQueryReq true "查询条件"
+// @Success      200  {object}  utils.Response{data=model.TableExplainResp}
+// @Failure      400  {object}  utils.Response
+// @Failure      403  {object}  utils.Response
+// @Failure      404  {object}  utils.Response
+// @Failure      500  {object}  utils.Response
+// @Router       /table/{table_id}/explain [post]
+func (api *ElementAPI) ExplainTableItems(c *gin.Context) {
+	// 获取表ID
+	tableID := utils.ParseUint(c.Param("table_id"))
+	if tableID == 0 {
+		utils.Error(c, http.StatusBadRequest, "invalid table_id")
+		return
+	}
+
+	var req model.TableQueryReq
+	if err := c.ShouldBindJSON(&req); err != nil {
 		utils.Error(c, http.StatusBadRequest, "invalid query parameters")
 		return
 	}
-	// 打印查询条件
-	fmt.Println(query)
+	if req.PageSize > 1000 {
+		req.PageSize = 1000
+	}
 
-	// 获取记录列表
-	items, total, err := api.elementService.GetTableItems(tableID, query.Page, query.PageSize, &query.Query)
+	resp, err := api.elementService.ExplainTableItems(tableID, &req, c.GetUint("user_id"))
 	if err != nil {
-		utils.Error(c, http.StatusInternalServerError, err.Error())
+		var qerr *model.QueryError
+		switch {
+		case errors.Is(err, element.ErrAdminRequired):
+			utils.Error(c, http.StatusForbidden, err.Error())
+		case errors.As(err, &qerr):
+			utils.Error(c, http.StatusBadRequest, err.Error())
+		case errors.Is(err, element.ErrViewNotFound):
+			utils.Error(c, http.StatusNotFound, err.Error())
+		default:
+			utils.Error(c, http.StatusInternalServerError, err.Error())
+		}
+		return
+	}
+
+	utils.Success(c, resp)
+}
+
+// @Summary      获取查询结果缓存统计
+// @Description  返回数据表查询结果缓存的有效期、当前缓存代数和命中统计，数据表功能配置cache_ttl大于0且连接了Redis时启用缓存
+// @Tags         Table
+// @Produce      json
+// @Security     Bearer
+// @Param        Authorization header string true "Bearer Token"
+// @Param        App-ID header string true "应用ID"
+// @Param        table_id path int true "表ID"
+// @Success      200  {object}  utils.Response{data=model.TableCacheStats}
+// @Failure      400  {object}  utils.Response
+// @Failure      500  {object}  utils.Response
+// @Router       /table/{table_id}/cache [get]
+func (api *ElementAPI) GetTableCacheStats(c *gin.Context) {
+	// 获取表ID
+	tableID := utils.ParseUint(c.Param("table_id"))
+	if tableID == 0 {
+		utils.Error(c, http.StatusBadRequest, "invalid table_id")
 		return
 	}
 
-	data := map[string]interface{}{
-		"total":    total,
-		"page":     query.Page,
-		"pageSize": query.PageSize,
-		"items":    items,
+	stats, err := api.elementService.GetTableCacheStats(tableID)
+	if err != nil {
+		utils.Error(c, http.StatusInternalServerError, err.Error())
+		return
 	}
 
-	utils.Success(c, data)
+	utils.Success(c, stats)
 }
 
 // @Summary      创建数据表记录
@@ -158,9 +281,13 @@ func (api *ElementAPI) QueryTableItems(c *gin.Context) {
 // @Param        Authorization header string true "Bearer token"
 // @Param        App-ID header string true "应用ID"
 // @Param        table_id path int true "表ID"
+// @Param        mode query string false "写入方式：insert（默认）或upsert，upsert时主键或唯一键重复的记录更新已有记录"
+// @Param        batch_size query int false "每条INSERT语句写入的记录数，默认500，最大1000"
+// @Param        restore query bool false "upsert时与回收站中的记录重复是否恢复该记录，默认报错"
 // @Param        request body []map[string]interface{} true "创建数据表记录请求参数"
-// @Success      201  {object}  utils.Response
+// @Success      201  {object}  utils.Response{data=model.CreateTableItemsResp}
 // @Failure      400  {object}  utils.Response
+// @Failure      403  {object}  utils.Response{data=element.ColumnForbiddenError}
 // @Failure      500  {object}  utils.Response
 // @Router       /table/{table_id} [post]
 func (api *ElementAPI) CreateTableItems(c *gin.Context) {
@@ -178,14 +305,39 @@ func (api *ElementAPI) CreateTableItems(c *gin.Context) {
 		return
 	}
 
+	// 获取写入选项
+	opts := model.CreateTableItemsOptions{
+		Mode:      model.InsertMode(c.Query("mode")),
+		BatchSize: utils.ParseInt(c.Query("batch_size")),
+		Restore:   c.Query("restore") == "true",
+	}
+	if err := opts.Normalize(); err != nil {
+		utils.Error(c, http.StatusBadRequest, err.Error())
+		return
+	}
+
 	userID := c.GetUint("user_id")
-	err := api.elementService.CreateTableItems(tableItems, userID, tableID)
+	resp, err := api.elementService.CreateTableItems(tableItems, userID, tableID, opts)
 	if err != nil {
+		var verr *element.ValidationError
+		if errors.As(err, &verr) {
+			utils.ErrorWithData(c, http.StatusBadRequest, "数据校验失败", verr.Errors)
+			return
+		}
+		if errors.Is(err, element.ErrUpsertUnsupported) || errors.Is(err, element.ErrUpsertVersioned) {
+			utils.Error(c, http.StatusBadRequest, err.Error())
+			return
+		}
+		var ferr *element.ColumnForbiddenError
+		if errors.As(err, &ferr) {
+			utils.ErrorWithData(c, http.StatusForbidden, "没有字段写权限", ferr)
+			return
+		}
 		utils.Error(c, http.StatusInternalServerError, err.Error())
 		return
 	}
 
-	utils.Success(c, nil)
+	utils.Success(c, resp)
 }
 
 // @Summary      更新数据表记录
@@ -198,9 +350,13 @@ func (api *ElementAPI) CreateTableItems(c *gin.Context) {
 // @Param        App-ID header string true "应用ID"
 // @Param        table_id path int true "表ID"
 // @Param        id path int true "记录ID"
+// @Param        If-Match header string false "记录版本，启用乐观锁且只更新单条记录时可代替row_version字段"
 // @Param        request body model.UpdateTableItemsRequest true "更新数据表记录请求参数"
 // @Success      200  {object}  utils.Response
 // @Failure      400  {object}  utils.Response
+// @Failure      403  {object}  utils.Response{data=element.ColumnForbiddenError}
+// @Failure      404  {object}  utils.Response
+// @Failure      409  {object}  utils.Response{data=element.ConflictError}
 // @Failure      500  {object}  utils.Response
 // @Router       /table/{table_id} [put]
 func (api *ElementAPI) UpdateTableItems(c *gin.Context) {
@@ -218,16 +374,65 @@ func (api *ElementAPI) UpdateTableItems(c *gin.Context) {
 		return
 	}
 
+	// 启用乐观锁的数据表可以通过If-Match请求头提交单条记录的版本
+	if err := applyIfMatch(c, reqItems.Items); err != nil {
+		utils.Error(c, http.StatusBadRequest, err.Error())
+		return
+	}
+
 	userID := c.GetUint("user_id")
 	err := api.elementService.UpdateTableItems(reqItems, userID, uint(tableID))
 	if err != nil {
-		utils.Error(c, http.StatusInternalServerError, err.Error())
+		writeItemsError(c, err)
 		return
 	}
 
 	utils.Success(c, nil)
 }
 
+// applyIfMatch 将If-Match请求头中的记录版本写入记录，仅适用于单条记录
+func applyIfMatch(c *gin.Context, items []map[string]interface{}) error {
+	etag := c.GetHeader("If-Match")
+	if etag == "" {
+		return nil
+	}
+	if len(items) != 1 {
+		return errors.New("If-Match header only applies to a single item")
+	}
+	if _, ok := items[0][model.ColumnRowVersion]; !ok {
+		items[0][model.ColumnRowVersion] = strings.Trim(strings.TrimPrefix(etag, "W/"), `"`)
+	}
+	return nil
+}
+
+// writeItemsError 返回修改、删除记录的错误响应，版本冲突时返回记录当前值
+func writeItemsError(c *gin.Context, err error) {
+	var verr *element.ValidationError
+	if errors.As(err, &verr) {
+		utils.ErrorWithData(c, http.StatusBadRequest, "数据校验失败", verr.Errors)
+		return
+	}
+	var cerr *element.ConflictError
+	if errors.As(err, &cerr) {
+		utils.ErrorWithData(c, http.StatusConflict, "记录已被修改，请刷新后重试", cerr)
+		return
+	}
+	if errors.Is(err, element.ErrItemNotFound) {
+		utils.Error(c, http.StatusNotFound, err.Error())
+		return
+	}
+	if errors.Is(err, element.ErrInvalidKeyColumns) {
+		utils.Error(c, http.StatusBadRequest, err.Error())
+		return
+	}
+	var ferr *element.ColumnForbiddenError
+	if errors.As(err, &ferr) {
+		utils.ErrorWithData(c, http.StatusForbidden, "没有字段写权限", ferr)
+		return
+	}
+	utils.Error(c, http.StatusInternalServerError, err.Error())
+}
+
 // @Summary      批量删除数据表记录
 // @Description  批量删除指定的数据表记录
 // @Tags         Table
@@ -237,9 +442,12 @@ func (api *ElementAPI) UpdateTableItems(c *gin.Context) {
 // @Param        Authorization header string true "Bearer token"
 // @Param        App-ID header string true "应用ID"
 // @Param        table_id path int true "表ID"
+// @Param        If-Match header string false "记录版本，启用乐观锁且只删除单条记录时可代替row_version字段"
 // @Param        request body []map[string]interface{} true "记录删除请求参数"
 // @Success      204  {object}  nil
 // @Failure      400  {object}  utils.Response
+// @Failure      404  {object}  utils.Response
+// @Failure      409  {object}  utils.Response{data=element.ConflictError}
 // @Failure      500  {object}  utils.Response
 // @Router       /table/{table_id} [delete]
 func (api *ElementAPI) DeleteTableItems(c *gin.Context) {
@@ -257,10 +465,16 @@ func (api *ElementAPI) DeleteTableItems(c *gin.Context) {
 		return
 	}
 
+	// 启用乐观锁的数据表可以通过If-Match请求头提交单条记录的版本
+	if err := applyIfMatch(c, req); err != nil {
+		utils.Error(c, http.StatusBadRequest, err.Error())
+		return
+	}
+
 	userID := c.GetUint("user_id")
 	err := api.elementService.DeleteTableItems(userID, tableID, req)
 	if err != nil {
-		utils.Error(c, http.StatusInternalServerError, err.Error())
+		writeItemsError(c, err)
 		return
 	}
 
@@ -0,0 +1,103 @@
+package element
+
+import (
+	"errors"
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+	"github.com/iiwish/lingjian/internal/service/element"
+	"github.com/iiwish/lingjian/pkg/utils"
+)
+
+// @Summary      执行数据归档
+// @Description  按数据表func中的archive策略，将归档字段早于after_days天的记录分批移入归档表（表名加_archive后缀）。
+// @Description  每次执行记录一条归档记录，也可以通过table_archive定时任务执行
+// @Tags         Table
+// @Accept       json
+// @Produce      json
+// @Security     Bearer
+// @Param        Authorization header string true "Bearer token"
+// @Param        App-ID header string true "应用ID"
+// @Param        table_id path int true "表ID"
+// @Success      200  {object}  utils.Response{data=model.TableArchiveLog}
+// @Failure      400  {object}  utils.Response
+// @Failure      409  {object}  utils.Response
+// @Failure      500  {object}  utils.Response{data=model.TableArchiveLog}
+// @Router       /table/{table_id}/archive [post]
+func (api *ElementAPI) ArchiveTableItems(c *gin.Context) {
+	// 获取表ID
+	tableID := utils.ParseUint(c.Param("table_id"))
+	if tableID == 0 {
+		utils.Error(c, http.StatusBadRequest, "invalid table_id")
+		return
+	}
+
+	archiveLog, err := api.elementService.ArchiveTableItems(tableID, c.GetUint("user_id"))
+	if err != nil {
+		switch {
+		case errors.Is(err, element.ErrArchiveDisabled):
+			utils.Error(c, http.StatusBadRequest, err.Error())
+		case errors.Is(err, element.ErrArchiveRunning):
+			utils.Error(c, http.StatusConflict, err.Error())
+		case archiveLog != nil:
+			utils.ErrorWithData(c, http.StatusInternalServerError, err.Error(), archiveLog)
+		default:
+			utils.Error(c, http.StatusInternalServerError, err.Error())
+		}
+		return
+	}
+
+	utils.Success(c, archiveLog)
+}
+
+// @Summary      获取归档记录
+// @Description  获取数据表的归档执行记录，按执行时间倒序
+// @Tags         Table
+// @Accept       json
+// @Produce      json
+// @Security     Bearer
+// @Param        Authorization header string true "Bearer token"
+// @Param        App-ID header string true "应用ID"
+// @Param        table_id path int true "表ID"
+// @Param        page query int false "页码"
+// @Param        page_size query int false "每页数量"
+// @Success      200  {object}  utils.Response
+// @Failure      400  {object}  utils.Response
+// @Failure      500  {object}  utils.Response
+// @Router       /table/{table_id}/archive/logs [get]
+func (api *ElementAPI) GetArchiveLogs(c *gin.Context) {
+	// 获取表ID
+	tableID := utils.ParseUint(c.Param("table_id"))
+	if tableID == 0 {
+		utils.Error(c, http.StatusBadRequest, "invalid table_id")
+		return
+	}
+
+	// 获取分页参数
+	page := utils.ParseInt(c.Query("page"))
+	if page <= 0 {
+		page = 1
+	}
+	pageSize := utils.ParseInt(c.Query("page_size"))
+	if pageSize <= 0 {
+		pageSize = 10
+	}
+	if pageSize > 1000 {
+		pageSize = 1000
+	}
+
+	items, total, err := api.elementService.GetArchiveLogs(tableID, page, pageSize)
+	if err != nil {
+		utils.Error(c, http.StatusInternalServerError, err.Error())
+		return
+	}
+
+	data := map[string]interface{}{
+		"total":    total,
+		"page":     page,
+		"pageSize": pageSize,
+		"items":    items,
+	}
+
+	utils.Success(c, data)
+}
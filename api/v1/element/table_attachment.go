@@ -0,0 +1,196 @@
+package element
+
+import (
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"strconv"
+	"strings"
+
+	"github.com/gin-gonic/gin"
+	"github.com/iiwish/lingjian/internal/service/element"
+	"github.com/iiwish/lingjian/pkg/utils"
+)
+
+// @Summary      上传附件
+// @Description  上传文件到数据表的附件字段，按字段配置的max_size和mime_types检查文件，文件类型根据文件内容识别。
+// @Description  返回的附件ID需要写入记录的附件字段，长期未被引用的附件由attachment_cleanup任务清理
+// @Tags         Table
+// @Accept       multipart/form-data
+// @Produce      json
+// @Security     Bearer
+// @Param        Authorization header string true "Bearer Token"
+// @Param        App-ID header string true "应用ID"
+// @Param        table_id path int true "表ID"
+// @Param        field formData string true "附件字段名"
+// @Param        file formData file true "文件"
+// @Success      200  {object}  utils.Response{data=model.AttachmentResp}
+// @Failure      400  {object}  utils.Response
+// @Failure      403  {object}  utils.Response{data=element.ColumnForbiddenError}
+// @Failure      500  {object}  utils.Response
+// @Router       /table/{table_id}/attachments [post]
+func (api *ElementAPI) UploadAttachment(c *gin.Context) {
+	// 获取表ID
+	tableID := utils.ParseUint(c.Param("table_id"))
+	if tableID == 0 {
+		utils.Error(c, http.StatusBadRequest, "invalid table_id")
+		return
+	}
+
+	field := c.PostForm("field")
+	if field == "" {
+		utils.Error(c, http.StatusBadRequest, "field is required")
+		return
+	}
+	header, err := c.FormFile("file")
+	if err != nil {
+		utils.Error(c, http.StatusBadRequest, "file is required")
+		return
+	}
+	file, err := header.Open()
+	if err != nil {
+		utils.Error(c, http.StatusBadRequest, "invalid file")
+		return
+	}
+	defer file.Close()
+
+	resp, err := api.elementService.UploadAttachment(tableID, field, header.Filename, file, c.GetUint("user_id"))
+	if err != nil {
+		var aerr *element.AttachmentError
+		if errors.As(err, &aerr) {
+			utils.Error(c, http.StatusBadRequest, err.Error())
+			return
+		}
+		var ferr *element.ColumnForbiddenError
+		if errors.As(err, &ferr) {
+			utils.ErrorWithData(c, http.StatusForbidden, "没有字段写权限", ferr)
+			return
+		}
+		utils.Error(c, http.StatusInternalServerError, err.Error())
+		return
+	}
+
+	utils.Success(c, resp)
+}
+
+// @Summary      获取附件
+// @Description  获取附件信息和带签名的下载地址，下载地址在expires_at之前有效。附件需要被用户数据权限内未删除的记录引用
+// @Tags         Table
+// @Produce      json
+// @Security     Bearer
+// @Param        Authorization header string true "Bearer Token"
+// @Param        App-ID header string true "应用ID"
+// @Param        table_id path int true "表ID"
+// @Param        id path int true "附件ID"
+// @Success      200  {object}  utils.Response{data=model.AttachmentResp}
+// @Failure      400  {object}  utils.Response
+// @Failure      403  {object}  utils.Response{data=element.ColumnForbiddenError}
+// @Failure      404  {object}  utils.Response
+// @Failure      500  {object}  utils.Response
+// @Router       /table/{table_id}/attachments/{id} [get]
+func (api *ElementAPI) GetAttachment(c *gin.Context) {
+	// 获取表ID
+	tableID := utils.ParseUint(c.Param("table_id"))
+	if tableID == 0 {
+		utils.Error(c, http.StatusBadRequest, "invalid table_id")
+		return
+	}
+	id := utils.ParseUint(c.Param("id"))
+	if id == 0 {
+		utils.Error(c, http.StatusBadRequest, "invalid id")
+		return
+	}
+
+	resp, err := api.elementService.GetAttachment(tableID, id, c.GetUint("user_id"))
+	if err != nil {
+		if errors.Is(err, element.ErrAttachmentNotFound) {
+			utils.Error(c, http.StatusNotFound, err.Error())
+			return
+		}
+		var ferr *element.ColumnForbiddenError
+		if errors.As(err, &ferr) {
+			utils.ErrorWithData(c, http.StatusForbidden, "没有字段读权限", ferr)
+			return
+		}
+		utils.Error(c, http.StatusInternalServerError, err.Error())
+		return
+	}
+
+	utils.Success(c, resp)
+}
+
+// @Summary      下载附件
+// @Description  通过获取附件或上传附件时返回的带签名的下载地址下载附件，不需要认证
+// @Tags         Table
+// @Produce      octet-stream
+// @Param        id path int true "附件ID"
+// @Param        expires query int true "过期时间戳"
+// @Param        signature query string true "签名"
+// @Success      200  {file}    file
+// @Failure      400  {object}  utils.Response
+// @Failure      403  {object}  utils.Response
+// @Failure      404  {object}  utils.Response
+// @Failure      500  {object}  utils.Response
+// @Router       /attachments/{id}/download [get]
+func (api *ElementAPI) DownloadAttachment(c *gin.Context) {
+	id := utils.ParseUint(c.Param("id"))
+	if id == 0 {
+		utils.Error(c, http.StatusBadRequest, "invalid id")
+		return
+	}
+	expires, err := strconv.ParseInt(c.Query("expires"), 10, 64)
+	if err != nil {
+		utils.Error(c, http.StatusBadRequest, "invalid expires")
+		return
+	}
+
+	attachment, file, err := api.elementService.OpenAttachment(id, expires, c.Query("signature"))
+	if err != nil {
+		switch {
+		case errors.Is(err, element.ErrInvalidSignature):
+			utils.Error(c, http.StatusForbidden, err.Error())
+		case errors.Is(err, element.ErrAttachmentNotFound):
+			utils.Error(c, http.StatusNotFound, err.Error())
+		default:
+			utils.Error(c, http.StatusInternalServerError, err.Error())
+		}
+		return
+	}
+	defer file.Close()
+
+	c.Header("Content-Disposition", contentDisposition(attachment.FileName))
+	c.Header("Content-Length", strconv.FormatInt(attachment.Size, 10))
+	c.Header("Content-Type", attachment.MimeType)
+	c.Status(http.StatusOK)
+	io.Copy(c.Writer, file)
+}
+
+// contentDisposition 生成附件下载的Content-Disposition，按RFC 5987编码文件名，并提供ASCII文件名兼容旧客户端
+func contentDisposition(fileName string) string {
+	var fallback, encoded strings.Builder
+	for _, r := range fileName {
+		if r < 0x20 || r > 0x7e || r == '"' || r == '\\' {
+			fallback.WriteByte('_')
+		} else {
+			fallback.WriteRune(r)
+		}
+	}
+	for _, b := range []byte(fileName) {
+		if isAttrChar(b) {
+			encoded.WriteByte(b)
+		} else {
+			fmt.Fprintf(&encoded, "%%%02X", b)
+		}
+	}
+	return fmt.Sprintf(`attachment; filename="%s"; filename*=UTF-8''%s`, fallback.String(), encoded.String())
+}
+
+// isAttrChar 判断字节是否为RFC 5987中不需要编码的attr-char
+func isAttrChar(b byte) bool {
+	switch {
+	case 'a' <= b && b <= 'z', 'A' <= b && b <= 'Z', '0' <= b && b <= '9':
+		return true
+	}
+	return strings.IndexByte("!#$&+-.^_`|~", b) >= 0
+}
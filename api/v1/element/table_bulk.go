@@ -0,0 +1,195 @@
+package element
+
+import (
+	"errors"
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+	"github.com/iiwish/lingjian/internal/model"
+	"github.com/iiwish/lingjian/internal/service/element"
+	"github.com/iiwish/lingjian/pkg/utils"
+)
+
+// @Summary      预览按条件批量操作
+// @Description  返回匹配条件的记录数和批量操作上限，传入patch时同时校验修改的字段值
+// @Tags         Table
+// @Accept       json
+// @Produce      json
+// @Security     Bearer
+// @Param        Authorization header string true "Bearer token"
+// @Param        App-ID header string true "应用ID"
+// @Param        table_id path int true "表ID"
+// @Param        request body model.TableBulkReq true "批量操作请求参数"
+// @Success      200  {object}  utils.Response{data=model.TableBulkResp}
+// @Failure      400  {object}  utils.Response
+// @Failure      403  {object}  utils.Response{data=element.ColumnForbiddenError}
+// @Failure      500  {object}  utils.Response
+// @Router       /table/{table_id}/bulk/preview [post]
+func (api *ElementAPI) PreviewBulkItems(c *gin.Context) {
+	tableID, req, ok := bindBulkReq(c)
+	if !ok {
+		return
+	}
+
+	resp, err := api.elementService.PreviewBulkItems(tableID, req, c.GetUint("user_id"))
+	if err != nil {
+		writeBulkError(c, err)
+		return
+	}
+
+	utils.Success(c, resp)
+}
+
+// @Summary      按条件批量修改记录
+// @Description  将patch中的字段值写入全部匹配条件的记录，匹配的记录数超过上限时不执行
+// @Tags         Table
+// @Accept       json
+// @Produce      json
+// @Security     Bearer
+// @Param        Authorization header string true "Bearer token"
+// @Param        App-ID header string true "应用ID"
+// @Param        table_id path int true "表ID"
+// @Param        request body model.TableBulkReq true "批量修改请求参数"
+// @Success      200  {object}  utils.Response{data=model.TableBulkResp}
+// @Failure      400  {object}  utils.Response
+// @Failure      403  {object}  utils.Response{data=element.ColumnForbiddenError}
+// @Failure      500  {object}  utils.Response
+// @Router       /table/{table_id}/bulk [put]
+func (api *ElementAPI) BulkUpdateTableItems(c *gin.Context) {
+	tableID, req, ok := bindBulkReq(c)
+	if !ok {
+		return
+	}
+
+	userID := c.GetUint("user_id")
+	resp, err := api.elementService.BulkUpdateTableItems(tableID, req, userID)
+	if err != nil {
+		writeBulkError(c, err)
+		return
+	}
+
+	utils.Success(c, resp)
+}
+
+// @Summary      按条件批量删除记录
+// @Description  删除全部匹配条件的记录，启用软删除的数据表移入回收站，匹配的记录数超过上限时不执行
+// @Tags         Table
+// @Accept       json
+// @Produce      json
+// @Security     Bearer
+// @Param        Authorization header string true "Bearer token"
+// @Param        App-ID header string true "应用ID"
+// @Param        table_id path int true "表ID"
+// @Param        request body model.TableBulkReq true "批量删除请求参数"
+// @Success      200  {object}  utils.Response{data=model.TableBulkResp}
+// @Failure      400  {object}  utils.Response
+// @Failure      500  {object}  utils.Response
+// @Router       /table/{table_id}/bulk [delete]
+func (api *ElementAPI) BulkDeleteTableItems(c *gin.Context) {
+	tableID, req, ok := bindBulkReq(c)
+	if !ok {
+		return
+	}
+
+	userID := c.GetUint("user_id")
+	resp, err := api.elementService.BulkDeleteTableItems(tableID, req, userID)
+	if err != nil {
+		writeBulkError(c, err)
+		return
+	}
+
+	utils.Success(c, resp)
+}
+
+// @Summary      获取批量操作记录
+// @Description  获取数据表按条件批量修改、删除的操作记录，包含影响的记录主键
+// @Tags         Table
+// @Accept       json
+// @Produce      json
+// @Security     Bearer
+// @Param        Authorization header string true "Bearer token"
+// @Param        App-ID header string true "应用ID"
+// @Param        table_id path int true "表ID"
+// @Param        page query int false "页码"
+// @Param        page_size query int false "每页数量"
+// @Success      200  {object}  utils.Response
+// @Failure      400  {object}  utils.Response
+// @Failure      500  {object}  utils.Response
+// @Router       /table/{table_id}/operations [get]
+func (api *ElementAPI) GetTableOperations(c *gin.Context) {
+	// 获取表ID
+	tableID := utils.ParseUint(c.Param("table_id"))
+	if tableID == 0 {
+		utils.Error(c, http.StatusBadRequest, "invalid table_id")
+		return
+	}
+
+	// 获取分页参数
+	page := utils.ParseInt(c.Query("page"))
+	if page <= 0 {
+		page = 1
+	}
+	pageSize := utils.ParseInt(c.Query("page_size"))
+	if pageSize <= 0 {
+		pageSize = 10
+	}
+	if pageSize > 1000 {
+		pageSize = 1000
+	}
+
+	items, total, err := api.elementService.GetTableOperations(tableID, page, pageSize)
+	if err != nil {
+		utils.Error(c, http.StatusInternalServerError, err.Error())
+		return
+	}
+
+	data := map[string]interface{}{
+		"total":    total,
+		"page":     page,
+		"pageSize": pageSize,
+		"items":    items,
+	}
+
+	utils.Success(c, data)
+}
+
+// bindBulkReq 获取表ID并绑定批量操作请求参数
+func bindBulkReq(c *gin.Context) (uint, *model.TableBulkReq, bool) {
+	tableID := utils.ParseUint(c.Param("table_id"))
+	if tableID == 0 {
+		utils.Error(c, http.StatusBadRequest, "invalid table_id")
+		return 0, nil, false
+	}
+
+	var req model.TableBulkReq
+	if err := c.ShouldBindJSON(&req); err != nil {
+		utils.Error(c, http.StatusBadRequest, err.Error())
+		return 0, nil, false
+	}
+	return tableID, &req, true
+}
+
+// writeBulkError 返回批量操作的错误响应
+func writeBulkError(c *gin.Context, err error) {
+	var qerr *model.QueryError
+	if errors.As(err, &qerr) {
+		utils.Error(c, http.StatusBadRequest, err.Error())
+		return
+	}
+	var lerr *element.BulkLimitError
+	if errors.As(err, &lerr) {
+		utils.ErrorWithData(c, http.StatusBadRequest, err.Error(), lerr)
+		return
+	}
+	var verr *element.ValidationError
+	if errors.As(err, &verr) {
+		utils.ErrorWithData(c, http.StatusBadRequest, "数据校验失败", verr.Errors)
+		return
+	}
+	var ferr *element.ColumnForbiddenError
+	if errors.As(err, &ferr) {
+		utils.ErrorWithData(c, http.StatusForbidden, "没有字段写权限", ferr)
+		return
+	}
+	utils.Error(c, http.StatusInternalServerError, err.Error())
+}
@@ -0,0 +1,154 @@
+package element
+
+import (
+	"errors"
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+	"github.com/iiwish/lingjian/internal/service/element"
+	"github.com/iiwish/lingjian/pkg/utils"
+)
+
+// @Summary      获取回收站记录列表
+// @Description  获取启用软删除的数据表中已删除的记录
+// @Tags         Table
+// @Accept       json
+// @Produce      json
+// @Security     Bearer
+// @Param        Authorization header string true "Bearer token"
+// @Param        App-ID header string true "应用ID"
+// @Param        table_id path int true "表ID"
+// @Param        page query int false "页码"
+// @Param        page_size query int false "每页数量"
+// @Success      200  {object}  utils.Response
+// @Failure      400  {object}  utils.Response
+// @Failure      500  {object}  utils.Response
+// @Router       /table/{table_id}/recycle [get]
+func (api *ElementAPI) GetRecycleItems(c *gin.Context) {
+	// 获取表ID
+	tableID := utils.ParseUint(c.Param("table_id"))
+	if tableID == 0 {
+		utils.Error(c, http.StatusBadRequest, "invalid table_id")
+		return
+	}
+
+	// 获取分页参数
+	page := utils.ParseInt(c.Query("page"))
+	if page <= 0 {
+		page = 1
+	}
+
+	// 获取每页数量
+	pageSize := utils.ParseInt(c.Query("page_size"))
+	if pageSize <= 0 {
+		pageSize = 10
+	}
+	if pageSize > 1000 {
+		pageSize = 1000
+	}
+
+	items, total, err := api.elementService.GetRecycleItems(tableID, page, pageSize, c.GetUint("user_id"))
+	if err != nil {
+		if errors.Is(err, element.ErrSoftDeleteDisabled) {
+			utils.Error(c, http.StatusBadRequest, err.Error())
+			return
+		}
+		utils.Error(c, http.StatusInternalServerError, err.Error())
+		return
+	}
+
+	data := map[string]interface{}{
+		"total":    total,
+		"page":     page,
+		"pageSize": pageSize,
+		"items":    items,
+	}
+
+	utils.Success(c, data)
+}
+
+// @Summary      恢复回收站记录
+// @Description  将回收站中的记录恢复到数据表
+// @Tags         Table
+// @Accept       json
+// @Produce      json
+// @Security     Bearer
+// @Param        Authorization header string true "Bearer token"
+// @Param        App-ID header string true "应用ID"
+// @Param        table_id path int true "表ID"
+// @Param        request body []map[string]interface{} true "记录主键条件"
+// @Success      200  {object}  utils.Response
+// @Failure      400  {object}  utils.Response
+// @Failure      500  {object}  utils.Response
+// @Router       /table/{table_id}/recycle [put]
+func (api *ElementAPI) RestoreTableItems(c *gin.Context) {
+	// 获取表ID
+	tableID := utils.ParseUint(c.Param("table_id"))
+	if tableID == 0 {
+		utils.Error(c, http.StatusBadRequest, "invalid table_id")
+		return
+	}
+
+	// 绑定请求参数
+	var req []map[string]interface{}
+	if err := c.ShouldBindJSON(&req); err != nil {
+		utils.Error(c, http.StatusBadRequest, err.Error())
+		return
+	}
+
+	userID := c.GetUint("user_id")
+	err := api.elementService.RestoreTableItems(userID, tableID, req)
+	if err != nil {
+		if errors.Is(err, element.ErrSoftDeleteDisabled) {
+			utils.Error(c, http.StatusBadRequest, err.Error())
+			return
+		}
+		utils.Error(c, http.StatusInternalServerError, err.Error())
+		return
+	}
+
+	utils.Success(c, nil)
+}
+
+// @Summary      彻底删除回收站记录
+// @Description  从回收站中永久删除指定记录
+// @Tags         Table
+// @Accept       json
+// @Produce      json
+// @Security     Bearer
+// @Param        Authorization header string true "Bearer token"
+// @Param        App-ID header string true "应用ID"
+// @Param        table_id path int true "表ID"
+// @Param        request body []map[string]interface{} true "记录主键条件"
+// @Success      204  {object}  nil
+// @Failure      400  {object}  utils.Response
+// @Failure      500  {object}  utils.Response
+// @Router       /table/{table_id}/recycle [delete]
+func (api *ElementAPI) PurgeTableItems(c *gin.Context) {
+	// 获取表ID
+	tableID := utils.ParseUint(c.Param("table_id"))
+	if tableID == 0 {
+		utils.Error(c, http.StatusBadRequest, "invalid table_id")
+		return
+	}
+
+	// 绑定请求参数
+	var req []map[string]interface{}
+	if err := c.ShouldBindJSON(&req); err != nil {
+		utils.Error(c, http.StatusBadRequest, err.Error())
+		return
+	}
+
+	userID := c.GetUint("user_id")
+	err := api.elementService.PurgeTableItems(userID, tableID, req)
+	if err != nil {
+		if errors.Is(err, element.ErrSoftDeleteDisabled) {
+			utils.Error(c, http.StatusBadRequest, err.Error())
+			return
+		}
+		utils.Error(c, http.StatusInternalServerError, err.Error())
+		return
+	}
+
+	c.Status(http.StatusNoContent)
+}
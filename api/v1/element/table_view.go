@@ -0,0 +1,201 @@
+package element
+
+import (
+	"errors"
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+	"github.com/iiwish/lingjian/internal/model"
+	"github.com/iiwish/lingjian/internal/service/element"
+	"github.com/iiwish/lingjian/pkg/utils"
+)
+
+// tableViewError 根据视图操作的错误类型返回对应的状态码
+func tableViewError(c *gin.Context, err error) {
+	var qerr *model.QueryError
+	switch {
+	case errors.As(err, &qerr):
+		utils.Error(c, http.StatusBadRequest, err.Error())
+	case errors.Is(err, element.ErrViewNotFound):
+		utils.Error(c, http.StatusNotFound, err.Error())
+	case errors.Is(err, element.ErrViewForbidden):
+		utils.Error(c, http.StatusForbidden, err.Error())
+	case errors.Is(err, element.ErrViewNameExists):
+		utils.Error(c, http.StatusConflict, err.Error())
+	default:
+		utils.Error(c, http.StatusInternalServerError, err.Error())
+	}
+}
+
+// @Summary      获取视图列表
+// @Description  获取当前用户在数据表下可见的视图，包括自己创建的视图和共享给自己的视图
+// @Tags         Table
+// @Produce      json
+// @Security     Bearer
+// @Param        Authorization header string true "Bearer Token"
+// @Param        App-ID header string true "应用ID"
+// @Param        table_id path int true "表ID"
+// @Success      200  {object}  utils.Response{data=[]model.TableViewResp}
+// @Failure      400  {object}  utils.Response
+// @Failure      500  {object}  utils.Response
+// @Router       /table/{table_id}/views [get]
+func (api *ElementAPI) ListTableViews(c *gin.Context) {
+	// 获取表ID
+	tableID := utils.ParseUint(c.Param("table_id"))
+	if tableID == 0 {
+		utils.Error(c, http.StatusBadRequest, "invalid table_id")
+		return
+	}
+
+	views, err := api.elementService.ListTableViews(tableID, c.GetUint("user_id"))
+	if err != nil {
+		tableViewError(c, err)
+		return
+	}
+
+	utils.Success(c, views)
+}
+
+// @Summary      获取视图详情
+// @Description  获取视图的查询条件、排序、每页数量、隐藏字段和字段顺序
+// @Tags         Table
+// @Produce      json
+// @Security     Bearer
+// @Param        Authorization header string true "Bearer Token"
+// @Param        App-ID header string true "应用ID"
+// @Param        table_id path int true "表ID"
+// @Param        view_id path int true "视图ID"
+// @Success      200  {object}  utils.Response{data=model.TableViewResp}
+// @Failure      400  {object}  utils.Response
+// @Failure      404  {object}  utils.Response
+// @Failure      500  {object}  utils.Response
+// @Router       /table/{table_id}/views/{view_id} [get]
+func (api *ElementAPI) GetTableView(c *gin.Context) {
+	// 获取表ID和视图ID
+	tableID := utils.ParseUint(c.Param("table_id"))
+	viewID := utils.ParseUint(c.Param("view_id"))
+	if tableID == 0 || viewID == 0 {
+		utils.Error(c, http.StatusBadRequest, "invalid table_id or view_id")
+		return
+	}
+
+	view, err := api.elementService.GetTableView(tableID, viewID, c.GetUint("user_id"))
+	if err != nil {
+		tableViewError(c, err)
+		return
+	}
+
+	utils.Success(c, view)
+}
+
+// @Summary      创建视图
+// @Description  保存查询条件和列表展示配置为视图，shared为true时共享给roles中的角色，roles为空时共享给应用内所有用户
+// @Tags         Table
+// @Accept       json
+// @Produce      json
+// @Security     Bearer
+// @Param        Authorization header string true "Bearer Token"
+// @Param        App-ID header string true "应用ID"
+// @Param        table_id path int true "表ID"
+// @Param        view body model.TableViewReq true "视图配置"
+// @Success      200  {object}  utils.Response{data=model.TableViewResp}
+// @Failure      400  {object}  utils.Response
+// @Failure      409  {object}  utils.Response
+// @Failure      500  {object}  utils.Response
+// @Router       /table/{table_id}/views [post]
+func (api *ElementAPI) CreateTableView(c *gin.Context) {
+	// 获取表ID
+	tableID := utils.ParseUint(c.Param("table_id"))
+	if tableID == 0 {
+		utils.Error(c, http.StatusBadRequest, "invalid table_id")
+		return
+	}
+
+	var req model.TableViewReq
+	if err := c.ShouldBindJSON(&req); err != nil {
+		utils.Error(c, http.StatusBadRequest, "invalid view parameters")
+		return
+	}
+
+	view, err := api.elementService.CreateTableView(tableID, &req, c.GetUint("user_id"))
+	if err != nil {
+		tableViewError(c, err)
+		return
+	}
+
+	utils.Success(c, view)
+}
+
+// @Summary      更新视图
+// @Description  更新视图的名称、共享范围和配置，只有创建人和管理员可以修改
+// @Tags         Table
+// @Accept       json
+// @Produce      json
+// @Security     Bearer
+// @Param        Authorization header string true "Bearer Token"
+// @Param        App-ID header string true "应用ID"
+// @Param        table_id path int true "表ID"
+// @Param        view_id path int true "视图ID"
+// @Param        view body model.TableViewReq true "视图配置"
+// @Success      200  {object}  utils.Response{data=model.TableViewResp}
+// @Failure      400  {object}  utils.Response
+// @Failure      403  {object}  utils.Response
+// @Failure      404  {object}  utils.Response
+// @Failure      409  {object}  utils.Response
+// @Failure      500  {object}  utils.Response
+// @Router       /table/{table_id}/views/{view_id} [put]
+func (api *ElementAPI) UpdateTableView(c *gin.Context) {
+	// 获取表ID和视图ID
+	tableID := utils.ParseUint(c.Param("table_id"))
+	viewID := utils.ParseUint(c.Param("view_id"))
+	if tableID == 0 || viewID == 0 {
+		utils.Error(c, http.StatusBadRequest, "invalid table_id or view_id")
+		return
+	}
+
+	var req model.TableViewReq
+	if err := c.ShouldBindJSON(&req); err != nil {
+		utils.Error(c, http.StatusBadRequest, "invalid view parameters")
+		return
+	}
+
+	view, err := api.elementService.UpdateTableView(tableID, viewID, &req, c.GetUint("user_id"))
+	if err != nil {
+		tableViewError(c, err)
+		return
+	}
+
+	utils.Success(c, view)
+}
+
+// @Summary      删除视图
+// @Description  删除视图，只有创建人和管理员可以删除
+// @Tags         Table
+// @Produce      json
+// @Security     Bearer
+// @Param        Authorization header string true "Bearer Token"
+// @Param        App-ID header string true "应用ID"
+// @Param        table_id path int true "表ID"
+// @Param        view_id path int true "视图ID"
+// @Success      200  {object}  utils.Response
+// @Failure      400  {object}  utils.Response
+// @Failure      403  {object}  utils.Response
+// @Failure      404  {object}  utils.Response
+// @Failure      500  {object}  utils.Response
+// @Router       /table/{table_id}/views/{view_id} [delete]
+func (api *ElementAPI) DeleteTableView(c *gin.Context) {
+	// 获取表ID和视图ID
+	tableID := utils.ParseUint(c.Param("table_id"))
+	viewID := utils.ParseUint(c.Param("view_id"))
+	if tableID == 0 || viewID == 0 {
+		utils.Error(c, http.StatusBadRequest, "invalid table_id or view_id")
+		return
+	}
+
+	if err := api.elementService.DeleteTableView(tableID, viewID, c.GetUint("user_id")); err != nil {
+		tableViewError(c, err)
+		return
+	}
+
+	utils.Success(c, nil)
+}
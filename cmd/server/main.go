@@ -14,6 +14,7 @@ import (
 	"github.com/iiwish/lingjian/internal/model"
 	"github.com/iiwish/lingjian/pkg/queue"
 	"github.com/iiwish/lingjian/pkg/redis"
+	"github.com/iiwish/lingjian/pkg/storage"
 	"github.com/iiwish/lingjian/pkg/store"
 	"github.com/spf13/viper"
 	swaggerFiles "github.com/swaggo/files"
@@ -52,6 +53,11 @@ func init() {
 	// 初始化Redis连接
 	redis.InitRedis()
 
+	// 初始化文件存储
+	if err := storage.InitStorage(); err != nil {
+		log.Fatalf("Failed to initialize storage: %v", err)
+	}
+
 	// 初始化RabbitMQ连接
 	if err := queue.InitRabbitMQ(); err != nil {
 		log.Fatalf("Failed to initialize RabbitMQ: %v", err)
@@ -96,6 +102,9 @@ func main() {
 			// 注册用户相关路由
 			v1.RegisterUserRoutes(v1Group)
 
+			// 注册附件下载路由，通过签名校验，不需要认证
+			element.RegisterPublicRoutes(v1Group)
+
 			// 需要认证的路由
 			authorized := v1Group.Group("/")
 			authorized.Use(middleware.AuthMiddleware())
@@ -10,6 +10,7 @@ import (
 	"github.com/iiwish/lingjian/internal/model"
 	"github.com/iiwish/lingjian/internal/service"
 	"github.com/iiwish/lingjian/pkg/queue"
+	"github.com/iiwish/lingjian/pkg/storage"
 	"github.com/spf13/viper"
 )
 
@@ -28,6 +29,11 @@ func init() {
 		log.Fatalf("Failed to initialize database: %v", err)
 	}
 
+	// 初始化文件存储
+	if err := storage.InitStorage(); err != nil {
+		log.Fatalf("Failed to initialize storage: %v", err)
+	}
+
 	// 初始化RabbitMQ连接
 	if err := queue.InitRabbitMQ(); err != nil {
 		log.Fatalf("Failed to initialize RabbitMQ: %v", err)
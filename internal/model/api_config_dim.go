@@ -119,7 +119,8 @@ func (r *UpdateDimensionReq) Validate() error {
 
 type GetDimResp struct {
 	ID            uint             `json:"id"`
-	TableName     string           `json:"table_name"`
+	TableName     string           `json:"table_name"`    // 逻辑表名
+	PhysicalName  string           `json:"physical_name"` // 数据库中的维度表名
 	DisplayName   string           `json:"display_name"`
 	Description   string           `json:"description"`
 	DimensionType string           `json:"dimension_type"`
@@ -2,6 +2,12 @@ package model
 
 import (
 	"database/sql"
+	"encoding/json"
+	"fmt"
+	"regexp"
+	"strings"
+
+	"github.com/iiwish/lingjian/pkg/utils"
 )
 
 // 字段信息
@@ -14,6 +20,80 @@ type Field struct {
 	AutoIncrement bool   `json:"auto_increment,omitempty" db:"auto_increment"`
 	NotNull       bool   `json:"not_null,omitempty" db:"not_null"`
 	Default       string `json:"default,omitempty" db:"default"`
+	FieldOptions
+}
+
+// FieldOptions 字段扩展配置，保存在 sys_config_tables.field_options 中
+type FieldOptions struct {
+	Rules      []FieldRule      `json:"rules,omitempty" db:"-"`      // 校验规则
+	Formula    string           `json:"formula,omitempty" db:"-"`    // 计算字段公式
+	Computed   ComputedMode     `json:"computed,omitempty" db:"-"`   // 计算方式：stored写入时计算，virtual读取时计算
+	Policies   []ColumnPolicy   `json:"policies,omitempty" db:"-"`   // 按角色的字段访问策略
+	Reference  *FieldReference  `json:"reference,omitempty" db:"-"`  // 引用维度明细或其他数据表的记录
+	Type       FieldType        `json:"type,omitempty" db:"-"`       // 逻辑字段类型，设置后由服务端生成column_type
+	Length     int              `json:"length,omitempty" db:"-"`     // text/enum类型：最大长度
+	Precision  int              `json:"precision,omitempty" db:"-"`  // decimal/money类型：总位数
+	Scale      int              `json:"scale,omitempty" db:"-"`      // decimal类型：小数位数
+	Options    []string         `json:"options,omitempty" db:"-"`    // enum类型：可选值
+	Sequence   *FieldSequence   `json:"sequence,omitempty" db:"-"`   // sequence类型：编号格式
+	Attachment *FieldAttachment `json:"attachment,omitempty" db:"-"` // attachment类型：附件大小、数量和类型限制
+}
+
+// IsEmpty 判断字段扩展配置是否为空
+func (o *FieldOptions) IsEmpty() bool {
+	return len(o.Rules) == 0 && o.Formula == "" && o.Computed == "" && len(o.Policies) == 0 && o.Reference == nil &&
+		o.Type == "" && o.Length == 0 && o.Precision == 0 && o.Scale == 0 && len(o.Options) == 0 && o.Sequence == nil && o.Attachment == nil
+}
+
+// ParseFieldOptions 解析字段扩展配置，返回字段名到配置的映射
+func ParseFieldOptions(optionsStr string) (map[string]FieldOptions, error) {
+	options := make(map[string]FieldOptions)
+	if optionsStr == "" {
+		return options, nil
+	}
+	if err := json.Unmarshal([]byte(optionsStr), &options); err != nil {
+		return nil, fmt.Errorf("invalid field options: %v", err)
+	}
+	return options, nil
+}
+
+// ValidateFieldOptions 验证字段扩展配置，fieldNames为数据表中的全部字段
+func ValidateFieldOptions(options map[string]FieldOptions, fieldNames map[string]bool) error {
+	for name, opt := range options {
+		for _, rule := range opt.Rules {
+			if err := rule.Validate(fieldNames); err != nil {
+				return fmt.Errorf("field %s: %v", name, err)
+			}
+		}
+		if err := opt.validateComputed(name, fieldNames); err != nil {
+			return fmt.Errorf("field %s: %v", name, err)
+		}
+		if opt.Reference != nil {
+			if opt.Formula != "" {
+				return fmt.Errorf("field %s: computed field cannot be a reference", name)
+			}
+			if err := opt.Reference.Validate(); err != nil {
+				return fmt.Errorf("field %s: %v", name, err)
+			}
+		}
+		for i := range opt.Policies {
+			if err := opt.Policies[i].Validate(); err != nil {
+				return fmt.Errorf("field %s: %v", name, err)
+			}
+		}
+		if err := opt.validateSequence(); err != nil {
+			return fmt.Errorf("field %s: %v", name, err)
+		}
+		if err := opt.validateAttachment(); err != nil {
+			return fmt.Errorf("field %s: %v", name, err)
+		}
+	}
+
+	// 检查计算字段之间的循环引用
+	if _, err := SortComputedFields(options); err != nil {
+		return err
+	}
+	return nil
 }
 
 // FieldUpdateType 表示字段更新类型
@@ -47,23 +127,68 @@ type IndexUpdateReq struct {
 	Indexes []IndexUpdate `json:"indexes"`
 }
 
+// 索引类型
+const (
+	IndexTypeIndex    = "INDEX"    // 普通索引
+	IndexTypeUnique   = "UNIQUE"   // 唯一索引
+	IndexTypeFulltext = "FULLTEXT" // 全文索引
+)
+
+// IndexParserNgram 全文索引的ngram分词器，支持中文
+const IndexParserNgram = "ngram"
+
 // 索引信息
 type Index struct {
 	Name   string   `json:"name"`
-	Type   string   `json:"type"`
+	Type   string   `json:"type"`             // 索引类型：INDEX、UNIQUE、FULLTEXT，为空时为普通索引
+	Parser string   `json:"parser,omitempty"` // 全文索引分词器，仅支持ngram
 	Fields []string `json:"fields"`
 }
 
+// Validate 验证索引配置，并统一索引类型
+func (i *Index) Validate() error {
+	if !utils.IsValidIdentifier(i.Name) {
+		return fmt.Errorf("invalid index name: %s", i.Name)
+	}
+	if len(i.Fields) == 0 {
+		return fmt.Errorf("index %s has no fields", i.Name)
+	}
+
+	// 兼容从数据库读取的 BTREE、HASH 索引类型
+	switch strings.ToUpper(i.Type) {
+	case "", IndexTypeIndex, "BTREE", "HASH":
+		i.Type = IndexTypeIndex
+	case IndexTypeUnique:
+		i.Type = IndexTypeUnique
+	case IndexTypeFulltext:
+		i.Type = IndexTypeFulltext
+	default:
+		return fmt.Errorf("invalid index type %s of index %s", i.Type, i.Name)
+	}
+
+	if i.Parser != "" {
+		if i.Type != IndexTypeFulltext {
+			return fmt.Errorf("parser is only supported by fulltext index %s", i.Name)
+		}
+		if strings.ToLower(i.Parser) != IndexParserNgram {
+			return fmt.Errorf("unsupported parser %s of index %s", i.Parser, i.Name)
+		}
+		i.Parser = IndexParserNgram
+	}
+	return nil
+}
+
 // CreateTableReq 创建表请求
 type CreateTableReq struct {
-	ID          uint    `json:"id"`
-	TableName   string  `json:"table_name"`
-	DisplayName string  `json:"display_name"`
-	Description string  `json:"description"`
-	Func        string  `json:"func"`
-	Fields      []Field `json:"fields"`
-	Indexes     []Index `json:"indexes"`
-	ParentID    uint    `json:"parent_id"`
+	ID           uint    `json:"id"`
+	TableName    string  `json:"table_name"`              // 逻辑表名
+	PhysicalName string  `json:"physical_name,omitempty"` // 数据库中的表名，获取配置时返回，创建时忽略
+	DisplayName  string  `json:"display_name"`
+	Description  string  `json:"description"`
+	Func         string  `json:"func"`
+	Fields       []Field `json:"fields"`
+	Indexes      []Index `json:"indexes"`
+	ParentID     uint    `json:"parent_id"`
 }
 
 // UpdateTableReq 更新表请求
@@ -84,6 +209,266 @@ type TableUpdateReq struct {
 	Indexes     []IndexUpdate `json:"indexes"`      // 索引更新
 }
 
+// AdoptTableReq 纳管已有数据表请求
+type AdoptTableReq struct {
+	TableName   string `json:"table_name"`   // 数据库中已有的表名
+	DisplayName string `json:"display_name"` // 显示名称，默认为表名
+	Description string `json:"description"`  // 描述
+	Func        string `json:"func"`         // 功能配置
+	ParentID    uint   `json:"parent_id"`    // 系统菜单的父节点ID
+}
+
+// Validate 验证纳管请求，系统表不能纳管
+func (r *AdoptTableReq) Validate() error {
+	if !utils.IsValidIdentifier(r.TableName) {
+		return fmt.Errorf("invalid table name: %s", r.TableName)
+	}
+	if strings.HasPrefix(strings.ToLower(r.TableName), "sys_") {
+		return fmt.Errorf("system table %s cannot be adopted", r.TableName)
+	}
+	if strings.HasSuffix(strings.ToLower(r.TableName), ArchiveTableSuffix) {
+		return fmt.Errorf("archive table %s cannot be adopted", r.TableName)
+	}
+	if r.DisplayName == "" {
+		r.DisplayName = r.TableName
+	}
+	return nil
+}
+
+// CloneTableReq 克隆数据表请求
+type CloneTableReq struct {
+	TargetAppID uint            `json:"target_app_id"` // 目标应用ID，为0时克隆到当前应用
+	TableName   string          `json:"table_name"`    // 新数据表的表名
+	DisplayName string          `json:"display_name"`  // 显示名称，默认使用源数据表的显示名称
+	ParentID    uint            `json:"parent_id"`     // 系统菜单的父节点ID，为0时按源数据表的菜单位置放置
+	CopyData    bool            `json:"copy_data"`     // 是否复制记录
+	Query       *QueryCondition `json:"query"`         // 复制记录的过滤条件，为空时复制全部记录
+}
+
+// Validate 验证克隆请求
+func (r *CloneTableReq) Validate() error {
+	if !utils.IsValidIdentifier(r.TableName) {
+		return fmt.Errorf("invalid table name: %s", r.TableName)
+	}
+	if strings.HasPrefix(strings.ToLower(r.TableName), "sys_") {
+		return fmt.Errorf("table name %s is reserved for system tables", r.TableName)
+	}
+	if r.Query != nil && !r.CopyData {
+		return fmt.Errorf("query can only be used when copy_data is true")
+	}
+	return nil
+}
+
+// CloneTableResp 克隆数据表结果
+type CloneTableResp struct {
+	ID          uint  `json:"id"`           // 新数据表配置ID
+	OperationID uint  `json:"operation_id"` // 克隆操作记录ID
+	Copied      int64 `json:"copied"`       // 复制的记录数
+}
+
+// UnsupportedColumn 平台不支持读写的字段
+type UnsupportedColumn struct {
+	Name       string `json:"name"`        // 字段名
+	ColumnType string `json:"column_type"` // 字段类型
+	Reason     string `json:"reason"`      // 原因
+}
+
+// AdoptTableResp 纳管已有数据表结果
+type AdoptTableResp struct {
+	ID          uint                `json:"id"`                    // 数据表配置ID
+	Fields      []Field             `json:"fields"`                // 识别出的字段
+	Indexes     []Index             `json:"indexes"`               // 识别出的索引
+	Unsupported []UnsupportedColumn `json:"unsupported,omitempty"` // 不支持的字段类型
+	Warnings    []string            `json:"warnings,omitempty"`    // 其他提示，如缺少主键
+}
+
+// 软删除托管字段
+const (
+	ColumnDeletedAt = "deleted_at" // 删除时间，NULL表示未删除
+	ColumnDeletedBy = "deleted_by" // 删除人ID
+)
+
+// ColumnRowVersion 乐观锁托管字段，记录每次修改后递增
+const ColumnRowVersion = "row_version"
+
+// 按条件批量操作影响记录数的默认上限和最大上限
+const (
+	DefaultBulkLimit = 1000
+	MaxBulkLimit     = 100000
+)
+
+// MaxCacheTTL 查询结果缓存的最长有效期，单位秒
+const MaxCacheTTL = 86400
+
+// 数据归档每批移动记录数的默认值和最大值
+const (
+	DefaultArchiveBatchSize = 1000
+	MaxArchiveBatchSize     = 10000
+)
+
+// ArchiveTableSuffix 归档表名后缀，归档表与数据表结构相同，由平台管理
+const ArchiveTableSuffix = "_archive"
+
+// ArchiveTableName 获取数据表对应的归档表名
+func ArchiveTableName(tableName string) string {
+	return tableName + ArchiveTableSuffix
+}
+
+// DefaultRetentionDays 回收站默认保留天数
+const DefaultRetentionDays = 30
+
+// TrashTablePrefix 回收站表名前缀，删除的数据表改名为回收站表名，用户表名以字母开头不会与之冲突
+const TrashTablePrefix = "_trash_"
+
+// TrashTableName 获取数据表在回收站中的表名
+func TrashTableName(tableID uint) string {
+	return fmt.Sprintf("%s%d", TrashTablePrefix, tableID)
+}
+
+// fieldNameRegexp 字段名格式：字母或下划线开头，允许字母数字下划线，不超过MySQL字段名的最大长度64
+var fieldNameRegexp = regexp.MustCompile(`^[a-zA-Z_][a-zA-Z0-9_]{0,63}$`)
+
+// ValidateFieldName 校验字段名，字段名会拼接到建表和修改表结构的语句中
+func ValidateFieldName(name string) error {
+	if !fieldNameRegexp.MatchString(name) {
+		return fmt.Errorf("invalid field name: %s", name)
+	}
+	return nil
+}
+
+// MaxTableNameLength MySQL表名的最大长度
+const MaxTableNameLength = 64
+
+// reservedTablePrefixRegexp 逻辑表名不能使用的保留前缀：系统表的sys_和应用命名空间的app{应用ID}_
+var reservedTablePrefixRegexp = regexp.MustCompile(`(?i)^(sys_|app[0-9]+_)`)
+
+// appTablePrefixRegexp 匹配数据库表名中的应用命名空间前缀
+var appTablePrefixRegexp = regexp.MustCompile(`(?i)^app([0-9]+)_`)
+
+// PhysicalTableName 获取应用中逻辑表名对应的数据库表名，数据表和维度表按应用以app{应用ID}_为前缀，不同应用的同名表互不冲突
+func PhysicalTableName(appID uint, logicalName string) string {
+	return fmt.Sprintf("app%d_%s", appID, logicalName)
+}
+
+// LogicalTableName 获取数据库表名在应用中的逻辑表名，去掉应用的命名空间前缀，未使用命名空间的表返回原表名
+func LogicalTableName(appID uint, tableName string) string {
+	return strings.TrimPrefix(tableName, fmt.Sprintf("app%d_", appID))
+}
+
+// TableNamespaceApp 获取数据库表名所属应用命名空间的应用ID，不在应用命名空间中时返回0
+func TableNamespaceApp(tableName string) uint {
+	match := appTablePrefixRegexp.FindStringSubmatch(tableName)
+	if match == nil {
+		return 0
+	}
+	return utils.ParseUint(match[1])
+}
+
+// ValidateLogicalTableName 校验用户指定的逻辑表名，不能使用保留前缀和归档表后缀，
+// 加上应用前缀和归档表后缀后不能超过MySQL表名的最大长度
+func ValidateLogicalTableName(appID uint, name string) error {
+	if !utils.IsValidIdentifier(name) {
+		return fmt.Errorf("invalid table name: %s", name)
+	}
+	if reservedTablePrefixRegexp.MatchString(name) {
+		return fmt.Errorf("table name %s uses a reserved prefix", name)
+	}
+	if strings.HasSuffix(strings.ToLower(name), ArchiveTableSuffix) {
+		return fmt.Errorf("table name %s uses the reserved suffix %s", name, ArchiveTableSuffix)
+	}
+	if len(PhysicalTableName(appID, name))+len(ArchiveTableSuffix) > MaxTableNameLength {
+		return fmt.Errorf("table name %s is too long", name)
+	}
+	return nil
+}
+
+// TableFunc 数据表功能配置，对应 sys_config_tables.func
+type TableFunc struct {
+	SoftDelete    bool           `json:"soft_delete,omitempty"`    // 是否启用软删除
+	RetentionDays int            `json:"retention_days,omitempty"` // 回收站保留天数，0表示使用默认值
+	SearchCols    []string       `json:"search_cols,omitempty"`    // 快速搜索的字段
+	Versioned     bool           `json:"versioned,omitempty"`      // 是否启用乐观锁，修改和删除记录时需提供记录版本
+	BulkLimit     int            `json:"bulk_limit,omitempty"`     // 按条件批量修改、删除时最多影响的记录数，0表示使用默认值
+	DataScopes    []DataScope    `json:"data_scopes,omitempty"`    // 数据权限，按用户的维度权限过滤记录
+	CacheTTL      int            `json:"cache_ttl,omitempty"`      // 查询结果缓存有效期，单位秒，0表示不缓存
+	Archive       *ArchivePolicy `json:"archive,omitempty"`        // 数据归档策略，为空时不归档
+}
+
+// ArchivePolicy 数据归档策略，归档任务将时间字段早于期限的记录移入归档表
+type ArchivePolicy struct {
+	Column    string `json:"column"`               // 判断记录时间的日期字段，如created_at
+	AfterDays int    `json:"after_days"`           // 超过多少天的记录归档
+	BatchSize int    `json:"batch_size,omitempty"` // 每批移动的记录数，0表示使用默认值
+}
+
+// GetBatchSize 获取每批移动的记录数
+func (p *ArchivePolicy) GetBatchSize() int {
+	if p.BatchSize <= 0 {
+		return DefaultArchiveBatchSize
+	}
+	return p.BatchSize
+}
+
+// DataScope 数据权限配置，字段取值引用维度明细，用户只能访问取值在其有权限的维度节点及下级节点内的记录
+type DataScope struct {
+	Field  string `json:"field"`             // 引用维度的字段
+	DimID  uint   `json:"dim_id"`            // 维度ID
+	DimKey string `json:"dim_key,omitempty"` // 字段匹配的维度列，id或code，默认为id
+}
+
+// ParseTableFunc 解析数据表功能配置
+func ParseTableFunc(funcStr string) (*TableFunc, error) {
+	tableFunc := &TableFunc{}
+	if funcStr == "" {
+		return tableFunc, nil
+	}
+	if err := json.Unmarshal([]byte(funcStr), tableFunc); err != nil {
+		return nil, fmt.Errorf("invalid func config: %v", err)
+	}
+	if tableFunc.RetentionDays < 0 {
+		return nil, fmt.Errorf("invalid retention_days: %d", tableFunc.RetentionDays)
+	}
+	if tableFunc.BulkLimit < 0 || tableFunc.BulkLimit > MaxBulkLimit {
+		return nil, fmt.Errorf("invalid bulk_limit: %d", tableFunc.BulkLimit)
+	}
+	if tableFunc.CacheTTL < 0 || tableFunc.CacheTTL > MaxCacheTTL {
+		return nil, fmt.Errorf("invalid cache_ttl: %d", tableFunc.CacheTTL)
+	}
+	if archive := tableFunc.Archive; archive != nil {
+		if archive.Column == "" || archive.AfterDays <= 0 {
+			return nil, fmt.Errorf("archive policy requires column and positive after_days")
+		}
+		if archive.BatchSize < 0 || archive.BatchSize > MaxArchiveBatchSize {
+			return nil, fmt.Errorf("invalid archive batch_size: %d", archive.BatchSize)
+		}
+	}
+	for _, scope := range tableFunc.DataScopes {
+		if scope.Field == "" || scope.DimID == 0 {
+			return nil, fmt.Errorf("data scope requires field and dim_id")
+		}
+		if scope.DimKey != "" && scope.DimKey != "id" && scope.DimKey != "code" {
+			return nil, fmt.Errorf("invalid dim_key %s of data scope %s", scope.DimKey, scope.Field)
+		}
+	}
+	return tableFunc, nil
+}
+
+// GetRetentionDays 获取回收站保留天数
+func (f *TableFunc) GetRetentionDays() int {
+	if f.RetentionDays <= 0 {
+		return DefaultRetentionDays
+	}
+	return f.RetentionDays
+}
+
+// GetBulkLimit 获取按条件批量操作最多影响的记录数
+func (f *TableFunc) GetBulkLimit() int {
+	if f.BulkLimit <= 0 {
+		return DefaultBulkLimit
+	}
+	return f.BulkLimit
+}
+
 // MySQLField 表示从 MySQL 获取的字段信息
 // type MySQLField struct {
 // 	Field      string `db:"Field"`
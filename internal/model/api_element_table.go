@@ -1,7 +1,201 @@
 package model
 
+import "fmt"
+
 // UpdateTableItemsRequest 更新数据表记录请求参数
 type UpdateTableItemsRequest struct {
 	PrimaryKeyColumns []string                 `json:"primary_key_columns"` // 主键列名列表
 	Items             []map[string]interface{} `json:"items"`               // 要更新的数据表记录
 }
+
+// CountMode 总数统计方式
+type CountMode string
+
+const (
+	CountExact    CountMode = "exact"    // 精确统计
+	CountEstimate CountMode = "estimate" // 根据执行计划估算
+	CountNone     CountMode = "none"     // 不统计
+)
+
+// PaginationMode 分页方式
+type PaginationMode string
+
+const (
+	PaginationOffset PaginationMode = "offset" // 按页码分页
+	PaginationCursor PaginationMode = "cursor" // 按游标分页
+)
+
+// TableQueryReq 查询数据表记录请求参数
+type TableQueryReq struct {
+	Page            int            `json:"page"`                       // 页码，游标分页时忽略
+	PageSize        int            `json:"page_size"`                  // 每页数量
+	Pagination      PaginationMode `json:"pagination,omitempty"`       // 分页方式，默认offset，传入cursor时自动使用游标分页
+	Cursor          string         `json:"cursor,omitempty"`           // 上一页返回的next_cursor
+	CountMode       CountMode      `json:"count_mode,omitempty"`       // 总数统计方式，默认offset分页为exact，游标分页为none
+	Query           QueryCondition `json:"query"`                      // 查询条件
+	ExpandRefs      bool           `json:"expand_refs,omitempty"`      // 是否在记录的_refs中展开引用字段的名称、编码和维度路径
+	ViewID          uint           `json:"view_id,omitempty"`          // 按视图查询，视图的查询条件与query合并，未指定的排序和每页数量使用视图的配置
+	IncludeArchived bool           `json:"include_archived,omitempty"` // 是否同时查询归档表中的记录，数据表未配置归档策略时忽略
+}
+
+// IsCursor 判断是否使用游标分页
+func (r *TableQueryReq) IsCursor() bool {
+	return r.Pagination == PaginationCursor || r.Cursor != ""
+}
+
+// GetCountMode 获取总数统计方式
+func (r *TableQueryReq) GetCountMode() CountMode {
+	if r.CountMode != "" {
+		return r.CountMode
+	}
+	if r.IsCursor() {
+		return CountNone
+	}
+	return CountExact
+}
+
+// Validate 验证分页参数
+func (r *TableQueryReq) Validate() error {
+	switch r.Pagination {
+	case "", PaginationOffset, PaginationCursor:
+	default:
+		return &QueryError{Message: "invalid pagination " + string(r.Pagination)}
+	}
+	switch r.CountMode {
+	case "", CountExact, CountEstimate, CountNone:
+	default:
+		return &QueryError{Message: "invalid count_mode " + string(r.CountMode)}
+	}
+	if r.Pagination == PaginationOffset && r.Cursor != "" {
+		return &QueryError{Message: "cursor cannot be used with offset pagination"}
+	}
+	return nil
+}
+
+// TableQueryResp 查询数据表记录响应
+type TableQueryResp struct {
+	Items      []map[string]interface{} `json:"items"`                 // 记录列表
+	Total      int64                    `json:"total"`                 // 总数，count_mode为none时为-1
+	CountMode  CountMode                `json:"count_mode"`            // 总数统计方式
+	Page       int                      `json:"page,omitempty"`        // 页码，游标分页时为空
+	PageSize   int                      `json:"pageSize"`              // 每页数量
+	HasMore    bool                     `json:"has_more"`              // 是否还有下一页
+	NextCursor string                   `json:"next_cursor,omitempty"` // 下一页游标
+	Cached     bool                     `json:"cached,omitempty"`      // 是否来自查询结果缓存
+	View       *TableViewResp           `json:"view,omitempty"`        // 按视图查询时返回视图配置，用于确定字段显示顺序
+}
+
+// InsertMode 记录写入方式
+type InsertMode string
+
+const (
+	InsertModeInsert InsertMode = "insert" // 仅插入，主键或唯一键重复时报错
+	InsertModeUpsert InsertMode = "upsert" // 主键或唯一键重复时更新已有记录
+)
+
+// 批量写入的默认和最大批次大小
+const (
+	DefaultInsertBatchSize = 500
+	MaxInsertBatchSize     = 1000
+)
+
+// CreateTableItemsOptions 创建数据表记录选项
+type CreateTableItemsOptions struct {
+	Mode      InsertMode // 写入方式，默认insert
+	BatchSize int        // 每条INSERT语句写入的记录数，默认500
+	Restore   bool       // upsert模式下与已软删除的记录重复时恢复该记录，否则报错
+}
+
+// Normalize 校验并补全写入选项
+func (o *CreateTableItemsOptions) Normalize() error {
+	switch o.Mode {
+	case "":
+		o.Mode = InsertModeInsert
+	case InsertModeInsert, InsertModeUpsert:
+	default:
+		return fmt.Errorf("invalid mode %s", o.Mode)
+	}
+	if o.BatchSize <= 0 {
+		o.BatchSize = DefaultInsertBatchSize
+	}
+	if o.BatchSize > MaxInsertBatchSize {
+		o.BatchSize = MaxInsertBatchSize
+	}
+	return nil
+}
+
+// CreateTableItemsResp 创建数据表记录响应
+type CreateTableItemsResp struct {
+	Inserted int           `json:"inserted"`      // 新插入的记录数
+	Updated  int           `json:"updated"`       // upsert模式下更新的已有记录数
+	IDs      []interface{} `json:"ids,omitempty"` // 各记录的主键，按请求顺序排列，仅单字段主键的数据表返回
+}
+
+// 数据表操作类型
+const (
+	OperationBulkUpdate = "bulk_update" // 按条件批量修改
+	OperationBulkDelete = "bulk_delete" // 按条件批量删除
+	OperationClone      = "clone"       // 克隆数据表
+)
+
+// TableBulkReq 按条件批量修改、删除记录请求参数
+type TableBulkReq struct {
+	Query       QueryCondition         `json:"query"`                  // 过滤条件，仅使用root条件
+	Patch       map[string]interface{} `json:"patch,omitempty"`        // 批量修改的字段值，批量删除时忽略
+	MaxAffected int                    `json:"max_affected,omitempty"` // 最多影响的记录数，超出时不执行，不能超过数据表的bulk_limit
+}
+
+// Validate 验证批量操作的过滤条件，不允许无条件操作全部记录
+func (r *TableBulkReq) Validate() error {
+	if len(r.Query.Select) > 0 || len(r.Query.GroupBy) > 0 || len(r.Query.Having.Conditions) > 0 || len(r.Query.OrderBy) > 0 {
+		return &QueryError{Message: "bulk operations only support root conditions"}
+	}
+	if len(r.Query.Root.Conditions) == 0 {
+		return &QueryError{Message: "bulk operations require at least one condition"}
+	}
+	if r.MaxAffected < 0 {
+		return &QueryError{Message: "max_affected must not be negative"}
+	}
+	return nil
+}
+
+// TableBulkResp 按条件批量修改、删除记录响应
+type TableBulkResp struct {
+	Matched     int64 `json:"matched"`                // 匹配条件的记录数
+	Affected    int64 `json:"affected"`               // 实际影响的记录数，预览时为0
+	Limit       int   `json:"limit"`                  // 最多影响的记录数
+	OperationID uint  `json:"operation_id,omitempty"` // 操作记录ID
+}
+
+// TableExplainResp 查询执行计划响应
+type TableExplainResp struct {
+	SQL         string            `json:"sql"`         // 生成的SQL，参数以?占位
+	Args        []interface{}     `json:"args"`        // 绑定的参数
+	BoundSQL    string            `json:"bound_sql"`   // 代入参数后的SQL，仅用于查看，不能直接执行
+	Plan        interface{}       `json:"plan"`        // EXPLAIN FORMAT=JSON 返回的执行计划
+	FullScans   []FullScan        `json:"full_scans"`  // 执行计划中的全表扫描
+	Suggestions []IndexSuggestion `json:"suggestions"` // 根据过滤和排序字段建议添加的索引
+}
+
+// FullScan 执行计划中的全表扫描
+type FullScan struct {
+	Table string `json:"table"` // 表名或别名
+	Rows  int64  `json:"rows"`  // 预计扫描行数
+}
+
+// IndexSuggestion 索引建议
+type IndexSuggestion struct {
+	Columns []string `json:"columns"` // 索引字段，等值过滤字段在前，范围过滤或排序字段在后
+	Reason  string   `json:"reason"`  // 建议原因
+	SQL     string   `json:"sql"`     // 创建索引的SQL
+}
+
+// TableCacheStats 数据表查询结果缓存统计
+type TableCacheStats struct {
+	Enabled    bool    `json:"enabled"`    // 是否启用缓存，需配置cache_ttl且连接了Redis
+	TTL        int     `json:"ttl"`        // 缓存有效期，单位秒
+	Generation int64   `json:"generation"` // 缓存代数，写入数据表时递增，旧代数的缓存不再命中
+	Hits       int64   `json:"hits"`       // 命中次数
+	Misses     int64   `json:"misses"`     // 未命中次数
+	HitRate    float64 `json:"hit_rate"`   // 命中率
+}
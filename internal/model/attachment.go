@@ -0,0 +1,116 @@
+package model
+
+import (
+	"fmt"
+	"strings"
+	"time"
+)
+
+// RuleAttachment 附件校验，由附件字段产生，不能在rules中配置
+const RuleAttachment RuleType = "attachment"
+
+// 附件字段的默认限制
+const (
+	DefaultAttachmentSize  = 10 << 20  // 单个附件默认最大10MB
+	MaxAttachmentSize      = 100 << 20 // 单个附件最大100MB
+	DefaultAttachmentFiles = 10        // 每条记录默认最多10个附件
+	MaxAttachmentFiles     = 100       // 每条记录最多100个附件
+)
+
+// FieldAttachment 附件字段配置，字段保存附件ID的JSON数组
+type FieldAttachment struct {
+	MaxSize   int64    `json:"max_size,omitempty"`   // 单个附件的最大字节数，0表示使用默认值
+	MaxFiles  int      `json:"max_files,omitempty"`  // 每条记录最多的附件数，0表示使用默认值
+	MimeTypes []string `json:"mime_types,omitempty"` // 允许的文件类型，如 application/pdf、image/*，为空时不限制
+}
+
+// Validate 验证附件字段配置
+func (a *FieldAttachment) Validate() error {
+	if a.MaxSize < 0 || a.MaxSize > MaxAttachmentSize {
+		return fmt.Errorf("invalid attachment max_size: %d", a.MaxSize)
+	}
+	if a.MaxFiles < 0 || a.MaxFiles > MaxAttachmentFiles {
+		return fmt.Errorf("invalid attachment max_files: %d", a.MaxFiles)
+	}
+	for _, mime := range a.MimeTypes {
+		if !strings.Contains(mime, "/") {
+			return fmt.Errorf("invalid attachment mime type: %s", mime)
+		}
+	}
+	return nil
+}
+
+// GetMaxSize 获取单个附件的最大字节数
+func (a *FieldAttachment) GetMaxSize() int64 {
+	if a == nil || a.MaxSize == 0 {
+		return DefaultAttachmentSize
+	}
+	return a.MaxSize
+}
+
+// GetMaxFiles 获取每条记录最多的附件数
+func (a *FieldAttachment) GetMaxFiles() int {
+	if a == nil || a.MaxFiles == 0 {
+		return DefaultAttachmentFiles
+	}
+	return a.MaxFiles
+}
+
+// AllowsMimeType 判断是否允许上传该类型的文件，支持 image/* 形式的通配
+func (a *FieldAttachment) AllowsMimeType(mimeType string) bool {
+	if a == nil || len(a.MimeTypes) == 0 {
+		return true
+	}
+	mimeType = strings.ToLower(mimeType)
+	if i := strings.Index(mimeType, ";"); i >= 0 {
+		mimeType = strings.TrimSpace(mimeType[:i])
+	}
+	for _, allowed := range a.MimeTypes {
+		allowed = strings.ToLower(allowed)
+		if allowed == mimeType || (strings.HasSuffix(allowed, "/*") && strings.HasPrefix(mimeType, strings.TrimSuffix(allowed, "*"))) {
+			return true
+		}
+	}
+	return false
+}
+
+// validateAttachment 验证附件字段配置
+func (o *FieldOptions) validateAttachment() error {
+	if o.Type != TypeAttachment {
+		if o.Attachment != nil {
+			return fmt.Errorf("attachment config requires type attachment")
+		}
+		return nil
+	}
+	if o.Formula != "" || o.Reference != nil {
+		return fmt.Errorf("attachment field cannot be computed or a reference")
+	}
+	if o.Attachment != nil {
+		return o.Attachment.Validate()
+	}
+	return nil
+}
+
+// Attachment 附件表
+type Attachment struct {
+	ID         uint      `db:"id" json:"id"`
+	AppID      uint      `db:"app_id" json:"app_id"`
+	TableID    uint      `db:"table_id" json:"table_id"`
+	FieldName  string    `db:"field_name" json:"field_name"`
+	StorageKey string    `db:"storage_key" json:"-"`
+	FileName   string    `db:"file_name" json:"file_name"`
+	MimeType   string    `db:"mime_type" json:"mime_type"`
+	Size       int64     `db:"size" json:"size"`
+	CreatorID  uint      `db:"creator_id" json:"creator_id"`
+	CreatedAt  time.Time `db:"created_at" json:"created_at"`
+}
+
+// AttachmentResp 附件信息响应
+type AttachmentResp struct {
+	ID        uint   `json:"id"`         // 附件ID，写入附件字段
+	FileName  string `json:"file_name"`  // 原始文件名
+	MimeType  string `json:"mime_type"`  // 文件类型
+	Size      int64  `json:"size"`       // 文件大小
+	URL       string `json:"url"`        // 带签名的下载地址，无需登录
+	ExpiresAt int64  `json:"expires_at"` // 下载地址的过期时间，Unix时间戳
+}
@@ -0,0 +1,140 @@
+package model
+
+import (
+	"fmt"
+	"strings"
+)
+
+// ColumnAccess 字段访问级别
+type ColumnAccess string
+
+const (
+	AccessFull     ColumnAccess = "full"     // 可读写
+	AccessReadonly ColumnAccess = "readonly" // 只读，不能写入
+	AccessMasked   ColumnAccess = "masked"   // 脱敏显示，不能写入，不能用于查询条件
+	AccessHidden   ColumnAccess = "hidden"   // 不返回，不能写入，不能用于查询条件
+)
+
+// 脱敏方式
+const (
+	MaskDefault = ""        // 保留首尾字符
+	MaskPhone   = "phone"   // 手机号，保留前3位和后4位
+	MaskEmail   = "email"   // 邮箱，保留首字符和域名
+	MaskIDCard  = "id_card" // 证件号，保留前6位和后4位
+	MaskName    = "name"    // 姓名，保留首字符
+)
+
+// ColumnPolicy 字段访问策略
+type ColumnPolicy struct {
+	Roles  []string     `json:"roles,omitempty"` // 适用的角色编码，为空时适用于没有匹配其他策略的用户
+	Access ColumnAccess `json:"access"`          // 访问级别
+	Mask   string       `json:"mask,omitempty"`  // 脱敏方式，仅用于masked访问级别
+}
+
+// Validate 验证字段访问策略
+func (p *ColumnPolicy) Validate() error {
+	if p.Access.level() < 0 {
+		return fmt.Errorf("invalid access %s", p.Access)
+	}
+	if p.Mask != "" {
+		if p.Access != AccessMasked {
+			return fmt.Errorf("mask is only supported by masked access")
+		}
+		switch p.Mask {
+		case MaskPhone, MaskEmail, MaskIDCard, MaskName:
+		default:
+			return fmt.Errorf("invalid mask %s", p.Mask)
+		}
+	}
+	for _, role := range p.Roles {
+		if role == "" {
+			return fmt.Errorf("role code cannot be empty")
+		}
+	}
+	return nil
+}
+
+// level 访问级别的限制程度，数值越大限制越多，无效的访问级别返回-1
+func (a ColumnAccess) level() int {
+	switch a {
+	case AccessFull:
+		return 0
+	case AccessReadonly:
+		return 1
+	case AccessMasked:
+		return 2
+	case AccessHidden:
+		return 3
+	}
+	return -1
+}
+
+// Readable 字段取值是否可以原样返回和用于查询条件
+func (a ColumnAccess) Readable() bool {
+	return a == AccessFull || a == AccessReadonly
+}
+
+// Writable 字段是否可以写入
+func (a ColumnAccess) Writable() bool {
+	return a == AccessFull
+}
+
+// ResolveColumnPolicy 根据用户的角色计算生效的字段访问策略
+// 匹配多个角色策略时取限制最少的策略，没有匹配的角色策略时使用默认策略，都没有时可读写
+func ResolveColumnPolicy(policies []ColumnPolicy, roles map[string]bool) ColumnPolicy {
+	var matched, fallback *ColumnPolicy
+	for i := range policies {
+		p := &policies[i]
+		if len(p.Roles) == 0 {
+			fallback = p
+			continue
+		}
+		for _, role := range p.Roles {
+			if roles[role] {
+				if matched == nil || p.Access.level() < matched.Access.level() {
+					matched = p
+				}
+				break
+			}
+		}
+	}
+	if matched != nil {
+		return *matched
+	}
+	if fallback != nil {
+		return *fallback
+	}
+	return ColumnPolicy{Access: AccessFull}
+}
+
+// MaskValue 按脱敏方式处理字段取值，NULL保持不变
+func MaskValue(mask string, value interface{}) interface{} {
+	if value == nil {
+		return nil
+	}
+	s := []rune(fmt.Sprint(value))
+	switch mask {
+	case MaskPhone:
+		return maskRunes(s, 3, 4)
+	case MaskIDCard:
+		return maskRunes(s, 6, 4)
+	case MaskName:
+		return maskRunes(s, 1, 0)
+	case MaskEmail:
+		at := strings.LastIndex(string(s), "@")
+		if at <= 0 {
+			return maskRunes(s, 1, 0)
+		}
+		local := []rune(string(s)[:at])
+		return string(local[0]) + "***" + string(s)[at:]
+	}
+	return maskRunes(s, 1, 1)
+}
+
+// maskRunes 保留开头和结尾的字符，其余字符替换为*，字符数不足时全部替换
+func maskRunes(s []rune, head, tail int) string {
+	if len(s) <= head+tail {
+		return strings.Repeat("*", len(s))
+	}
+	return string(s[:head]) + strings.Repeat("*", len(s)-head-tail) + string(s[len(s)-tail:])
+}
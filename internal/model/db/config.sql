@@ -2,23 +2,31 @@
 CREATE TABLE IF NOT EXISTS sys_config_tables (
     id BIGINT UNSIGNED AUTO_INCREMENT PRIMARY KEY COMMENT '主键ID',
     app_id BIGINT UNSIGNED NOT NULL DEFAULT 0 COMMENT '应用ID',
-    table_name VARCHAR(64) NOT NULL DEFAULT '' COMMENT '表名',
+    table_name VARCHAR(64) NOT NULL DEFAULT '' COMMENT '数据库表名，按应用命名为app{应用ID}_{逻辑表名}',
+    logical_name VARCHAR(64) NOT NULL DEFAULT '' COMMENT '逻辑表名，用户看到的表名',
     display_name VARCHAR(64) NOT NULL DEFAULT '' COMMENT '显示名称',
     description VARCHAR(200) NOT NULL DEFAULT '' COMMENT '描述',
     func JSON COMMENT '表功能配置、筛选字段、搜索字段等',
+    field_options JSON COMMENT '字段扩展配置，如校验规则等',
     status TINYINT NOT NULL DEFAULT 1 COMMENT '状态 0:禁用 1:启用',
     created_at DATETIME NOT NULL DEFAULT '1901-01-01 00:00:00' COMMENT '创建时间',
     creator_id BIGINT UNSIGNED NOT NULL DEFAULT 0 COMMENT '创建人ID',
     updated_at DATETIME NOT NULL DEFAULT CURRENT_TIMESTAMP ON UPDATE CURRENT_TIMESTAMP COMMENT '更新时间',
     updater_id BIGINT UNSIGNED NOT NULL DEFAULT 0 COMMENT '更新人ID',
-    UNIQUE KEY uk_app_table (app_id, table_name) COMMENT '应用ID和表名唯一索引'
+    original_name VARCHAR(64) NOT NULL DEFAULT '' COMMENT '删除前的表名，数据表在回收站中时table_name为回收站表名',
+    deleted_at DATETIME NULL DEFAULT NULL COMMENT '删除时间，不为空表示数据表在回收站中',
+    deleter_id BIGINT UNSIGNED NOT NULL DEFAULT 0 COMMENT '删除人ID',
+    UNIQUE KEY uk_app_table (app_id, table_name) COMMENT '应用ID和表名唯一索引',
+    KEY idx_app_logical (app_id, logical_name) COMMENT '应用ID和逻辑表名索引',
+    KEY idx_deleted_at (deleted_at) COMMENT '删除时间索引'
 ) ENGINE=InnoDB DEFAULT CHARSET=utf8mb4 COMMENT='数据表配置' COLLATE=utf8mb4_general_ci;
 
 -- 维度配置
 CREATE TABLE IF NOT EXISTS sys_config_dimensions (
     id BIGINT UNSIGNED AUTO_INCREMENT PRIMARY KEY COMMENT '主键ID',
     app_id BIGINT UNSIGNED NOT NULL DEFAULT 0 COMMENT '应用ID',
-    table_name VARCHAR(64) NOT NULL DEFAULT '' COMMENT '维度表名',
+    table_name VARCHAR(64) NOT NULL DEFAULT '' COMMENT '数据库中的维度表名，按应用命名为app{应用ID}_{逻辑表名}',
+    logical_name VARCHAR(64) NOT NULL DEFAULT '' COMMENT '逻辑表名，用户看到的维度表名',
     display_name VARCHAR(64) NOT NULL DEFAULT '' COMMENT '显示名称',
     description VARCHAR(200) DEFAULT '' COMMENT '描述',
     dimension_type VARCHAR(20) NOT NULL DEFAULT '' COMMENT '维度类型：general, menu',
@@ -28,7 +36,8 @@ CREATE TABLE IF NOT EXISTS sys_config_dimensions (
     creator_id BIGINT UNSIGNED NOT NULL DEFAULT 0 COMMENT '创建人ID',
     updated_at DATETIME NOT NULL DEFAULT CURRENT_TIMESTAMP ON UPDATE CURRENT_TIMESTAMP COMMENT '更新时间',
     updater_id BIGINT UNSIGNED NOT NULL DEFAULT 0 COMMENT '更新人ID',
-    UNIQUE KEY uk_app_dimension (app_id, table_name) COMMENT '应用ID和维度表名唯一索引'
+    UNIQUE KEY uk_app_dimension (app_id, table_name) COMMENT '应用ID和维度表名唯一索引',
+    KEY idx_app_logical (app_id, logical_name) COMMENT '应用ID和逻辑表名索引'
 ) ENGINE=InnoDB DEFAULT CHARSET=utf8mb4 COMMENT='维度配置' COLLATE=utf8mb4_general_ci;
 
 -- 数据模型配置
@@ -86,3 +95,121 @@ CREATE TABLE IF NOT EXISTS sys_config_menus (
     updater_id BIGINT UNSIGNED NOT NULL DEFAULT 0 COMMENT '更新人ID',
     UNIQUE KEY uk_app_menu (app_id, node_id) COMMENT '应用ID和节点ID唯一索引'
 ) ENGINE=InnoDB DEFAULT CHARSET=utf8mb4 COMMENT='菜单配置' COLLATE=utf8mb4_general_ci;
+
+-- 数据表批量操作记录
+CREATE TABLE IF NOT EXISTS sys_table_operations (
+    id BIGINT UNSIGNED AUTO_INCREMENT PRIMARY KEY COMMENT '主键ID',
+    app_id BIGINT UNSIGNED NOT NULL DEFAULT 0 COMMENT '应用ID',
+    table_id BIGINT UNSIGNED NOT NULL DEFAULT 0 COMMENT '数据表ID',
+    operation VARCHAR(20) NOT NULL DEFAULT '' COMMENT '操作类型：bulk_update, bulk_delete, clone',
+    query_condition JSON COMMENT '过滤条件',
+    patch JSON COMMENT '更新的字段值，克隆时为源数据表和复制参数',
+    affected INT NOT NULL DEFAULT 0 COMMENT '影响的记录数',
+    affected_keys JSON COMMENT '影响的记录主键',
+    operator_id BIGINT UNSIGNED NOT NULL DEFAULT 0 COMMENT '操作人ID',
+    created_at DATETIME NOT NULL DEFAULT CURRENT_TIMESTAMP COMMENT '操作时间',
+    KEY idx_table_created (table_id, created_at) COMMENT '数据表ID和操作时间索引'
+) ENGINE=InnoDB DEFAULT CHARSET=utf8mb4 COMMENT='数据表批量操作记录' COLLATE=utf8mb4_general_ci;
+
+-- 数据表编号字段计数器
+CREATE TABLE IF NOT EXISTS sys_table_sequences (
+    table_id BIGINT UNSIGNED NOT NULL COMMENT '数据表ID',
+    field_name VARCHAR(64) NOT NULL COMMENT '编号字段名',
+    period VARCHAR(8) NOT NULL DEFAULT '' COMMENT '计数周期，如2026、202610，不重置时为空',
+    value BIGINT UNSIGNED NOT NULL DEFAULT 0 COMMENT '已分配的最大计数',
+    updated_at DATETIME NOT NULL DEFAULT CURRENT_TIMESTAMP ON UPDATE CURRENT_TIMESTAMP COMMENT '更新时间',
+    PRIMARY KEY (table_id, field_name, period)
+) ENGINE=InnoDB DEFAULT CHARSET=utf8mb4 COMMENT='数据表编号字段计数器' COLLATE=utf8mb4_general_ci;
+
+-- 数据表附件
+CREATE TABLE IF NOT EXISTS sys_attachments (
+    id BIGINT UNSIGNED AUTO_INCREMENT PRIMARY KEY COMMENT '主键ID',
+    app_id BIGINT UNSIGNED NOT NULL DEFAULT 0 COMMENT '应用ID',
+    table_id BIGINT UNSIGNED NOT NULL DEFAULT 0 COMMENT '数据表ID',
+    field_name VARCHAR(64) NOT NULL DEFAULT '' COMMENT '附件字段名',
+    storage_key VARCHAR(255) NOT NULL DEFAULT '' COMMENT '存储路径',
+    file_name VARCHAR(255) NOT NULL DEFAULT '' COMMENT '原始文件名',
+    mime_type VARCHAR(100) NOT NULL DEFAULT '' COMMENT '文件类型',
+    size BIGINT NOT NULL DEFAULT 0 COMMENT '文件大小',
+    creator_id BIGINT UNSIGNED NOT NULL DEFAULT 0 COMMENT '上传人ID',
+    created_at DATETIME NOT NULL DEFAULT CURRENT_TIMESTAMP COMMENT '上传时间',
+    KEY idx_table_field (table_id, field_name, created_at) COMMENT '数据表ID、字段名和上传时间索引'
+) ENGINE=InnoDB DEFAULT CHARSET=utf8mb4 COMMENT='数据表附件' COLLATE=utf8mb4_general_ci;
+
+-- 数据表视图
+CREATE TABLE IF NOT EXISTS sys_table_views (
+    id BIGINT UNSIGNED AUTO_INCREMENT PRIMARY KEY COMMENT '主键ID',
+    app_id BIGINT UNSIGNED NOT NULL DEFAULT 0 COMMENT '应用ID',
+    table_id BIGINT UNSIGNED NOT NULL DEFAULT 0 COMMENT '数据表ID',
+    name VARCHAR(64) NOT NULL DEFAULT '' COMMENT '视图名称',
+    shared TINYINT NOT NULL DEFAULT 0 COMMENT '是否共享 0:仅创建人可见 1:共享',
+    roles JSON COMMENT '共享的角色编码，为空时共享给应用内所有用户',
+    config JSON COMMENT '查询条件、排序、每页数量、隐藏字段和字段顺序',
+    created_at DATETIME NOT NULL DEFAULT '1901-01-01 00:00:00' COMMENT '创建时间',
+    creator_id BIGINT UNSIGNED NOT NULL DEFAULT 0 COMMENT '创建人ID',
+    updated_at DATETIME NOT NULL DEFAULT CURRENT_TIMESTAMP ON UPDATE CURRENT_TIMESTAMP COMMENT '更新时间',
+    updater_id BIGINT UNSIGNED NOT NULL DEFAULT 0 COMMENT '更新人ID',
+    UNIQUE KEY uk_table_creator_name (table_id, creator_id, name) COMMENT '数据表ID、创建人和视图名称唯一索引'
+) ENGINE=InnoDB DEFAULT CHARSET=utf8mb4 COMMENT='数据表视图' COLLATE=utf8mb4_general_ci;
+
+-- 数据表归档记录
+CREATE TABLE IF NOT EXISTS sys_table_archive_logs (
+    id BIGINT UNSIGNED AUTO_INCREMENT PRIMARY KEY COMMENT '主键ID',
+    table_id BIGINT UNSIGNED NOT NULL DEFAULT 0 COMMENT '数据表ID',
+    status TINYINT NOT NULL DEFAULT 0 COMMENT '执行状态：0失败/1成功/2执行中',
+    archived BIGINT NOT NULL DEFAULT 0 COMMENT '归档的记录数',
+    cutoff DATETIME NOT NULL DEFAULT CURRENT_TIMESTAMP COMMENT '归档截止时间，早于该时间的记录归档',
+    error TEXT COMMENT '错误信息',
+    operator_id BIGINT UNSIGNED NOT NULL DEFAULT 0 COMMENT '操作人ID，定时任务执行时为0',
+    start_time DATETIME NOT NULL DEFAULT CURRENT_TIMESTAMP COMMENT '开始时间',
+    end_time DATETIME NOT NULL DEFAULT CURRENT_TIMESTAMP COMMENT '结束时间',
+    KEY idx_table_start (table_id, start_time) COMMENT '数据表ID和开始时间索引'
+) ENGINE=InnoDB DEFAULT CHARSET=utf8mb4 COMMENT='数据表归档记录' COLLATE=utf8mb4_general_ci;
+
+-- 已有数据库升级：补充后续版本新增的列和索引，新增的表由上面的CREATE TABLE IF NOT EXISTS创建，可重复执行
+-- 升级后执行migrate_table_namespace.sql填充数据表和维度的逻辑表名
+DROP PROCEDURE IF EXISTS migrate_add_column;
+DROP PROCEDURE IF EXISTS migrate_add_index;
+
+DELIMITER $$
+-- migrate_add_column 列不存在时添加列
+CREATE PROCEDURE migrate_add_column(IN tbl VARCHAR(64), IN col VARCHAR(64), IN def TEXT)
+BEGIN
+    IF NOT EXISTS (
+        SELECT 1 FROM information_schema.columns
+        WHERE table_schema = DATABASE() AND table_name = tbl AND column_name = col
+    ) THEN
+        SET @ddl = CONCAT('ALTER TABLE `', tbl, '` ADD COLUMN `', col, '` ', def);
+        PREPARE stmt FROM @ddl;
+        EXECUTE stmt;
+        DEALLOCATE PREPARE stmt;
+    END IF;
+END$$
+
+-- migrate_add_index 索引不存在时添加索引
+CREATE PROCEDURE migrate_add_index(IN tbl VARCHAR(64), IN idx VARCHAR(64), IN def TEXT)
+BEGIN
+    IF NOT EXISTS (
+        SELECT 1 FROM information_schema.statistics
+        WHERE table_schema = DATABASE() AND table_name = tbl AND index_name = idx
+    ) THEN
+        SET @ddl = CONCAT('ALTER TABLE `', tbl, '` ADD KEY `', idx, '` ', def);
+        PREPARE stmt FROM @ddl;
+        EXECUTE stmt;
+        DEALLOCATE PREPARE stmt;
+    END IF;
+END$$
+DELIMITER ;
+
+CALL migrate_add_column('sys_config_tables', 'field_options', 'JSON COMMENT ''字段扩展配置，如校验规则等'' AFTER func');
+CALL migrate_add_column('sys_config_tables', 'original_name', 'VARCHAR(64) NOT NULL DEFAULT '''' COMMENT ''删除前的表名，数据表在回收站中时table_name为回收站表名'' AFTER updater_id');
+CALL migrate_add_column('sys_config_tables', 'deleted_at', 'DATETIME NULL DEFAULT NULL COMMENT ''删除时间，不为空表示数据表在回收站中'' AFTER original_name');
+CALL migrate_add_column('sys_config_tables', 'deleter_id', 'BIGINT UNSIGNED NOT NULL DEFAULT 0 COMMENT ''删除人ID'' AFTER deleted_at');
+CALL migrate_add_index('sys_config_tables', 'idx_deleted_at', '(deleted_at) COMMENT ''删除时间索引''');
+CALL migrate_add_column('sys_config_tables', 'logical_name', 'VARCHAR(64) NOT NULL DEFAULT '''' COMMENT ''逻辑表名，用户看到的表名'' AFTER table_name');
+CALL migrate_add_index('sys_config_tables', 'idx_app_logical', '(app_id, logical_name) COMMENT ''应用ID和逻辑表名索引''');
+CALL migrate_add_column('sys_config_dimensions', 'logical_name', 'VARCHAR(64) NOT NULL DEFAULT '''' COMMENT ''逻辑表名，用户看到的维度表名'' AFTER table_name');
+CALL migrate_add_index('sys_config_dimensions', 'idx_app_logical', '(app_id, logical_name) COMMENT ''应用ID和逻辑表名索引''');
+
+DROP PROCEDURE migrate_add_column;
+DROP PROCEDURE migrate_add_index;
@@ -0,0 +1,69 @@
+-- 数据表和维度按应用命名空间命名的迁移，可重复执行
+-- 新建的数据表和维度在数据库中命名为app{应用ID}_{逻辑表名}，用户看到的是逻辑表名
+-- 已有的数据表和维度保留原数据库表名，逻辑表名取去掉本应用前缀后的表名
+-- 数据表可以通过 POST /api/v1/config/tables/{table_id}/namespace 改名到应用命名空间
+-- 维度可以通过 POST /api/v1/config/dimensions/{dim_id}/namespace 改名到应用命名空间
+
+DROP PROCEDURE IF EXISTS migrate_add_column;
+DROP PROCEDURE IF EXISTS migrate_add_index;
+
+DELIMITER $$
+-- migrate_add_column 列不存在时添加列
+CREATE PROCEDURE migrate_add_column(IN tbl VARCHAR(64), IN col VARCHAR(64), IN def TEXT)
+BEGIN
+    IF NOT EXISTS (
+        SELECT 1 FROM information_schema.columns
+        WHERE table_schema = DATABASE() AND table_name = tbl AND column_name = col
+    ) THEN
+        SET @ddl = CONCAT('ALTER TABLE `', tbl, '` ADD COLUMN `', col, '` ', def);
+        PREPARE stmt FROM @ddl;
+        EXECUTE stmt;
+        DEALLOCATE PREPARE stmt;
+    END IF;
+END$$
+
+-- migrate_add_index 索引不存在时添加索引
+CREATE PROCEDURE migrate_add_index(IN tbl VARCHAR(64), IN idx VARCHAR(64), IN def TEXT)
+BEGIN
+    IF NOT EXISTS (
+        SELECT 1 FROM information_schema.statistics
+        WHERE table_schema = DATABASE() AND table_name = tbl AND index_name = idx
+    ) THEN
+        SET @ddl = CONCAT('ALTER TABLE `', tbl, '` ADD KEY `', idx, '` ', def);
+        PREPARE stmt FROM @ddl;
+        EXECUTE stmt;
+        DEALLOCATE PREPARE stmt;
+    END IF;
+END$$
+DELIMITER ;
+
+-- 填充逻辑表名时用到回收站的列，从没有回收站的版本升级时一起补充
+CALL migrate_add_column('sys_config_tables', 'original_name', 'VARCHAR(64) NOT NULL DEFAULT '''' COMMENT ''删除前的表名，数据表在回收站中时table_name为回收站表名'' AFTER updater_id');
+CALL migrate_add_column('sys_config_tables', 'deleted_at', 'DATETIME NULL DEFAULT NULL COMMENT ''删除时间，不为空表示数据表在回收站中'' AFTER original_name');
+CALL migrate_add_column('sys_config_tables', 'deleter_id', 'BIGINT UNSIGNED NOT NULL DEFAULT 0 COMMENT ''删除人ID'' AFTER deleted_at');
+CALL migrate_add_index('sys_config_tables', 'idx_deleted_at', '(deleted_at) COMMENT ''删除时间索引''');
+
+CALL migrate_add_column('sys_config_tables', 'logical_name', 'VARCHAR(64) NOT NULL DEFAULT '''' COMMENT ''逻辑表名，用户看到的表名'' AFTER table_name');
+CALL migrate_add_index('sys_config_tables', 'idx_app_logical', '(app_id, logical_name) COMMENT ''应用ID和逻辑表名索引''');
+CALL migrate_add_column('sys_config_dimensions', 'logical_name', 'VARCHAR(64) NOT NULL DEFAULT '''' COMMENT ''逻辑表名，用户看到的维度表名'' AFTER table_name');
+CALL migrate_add_index('sys_config_dimensions', 'idx_app_logical', '(app_id, logical_name) COMMENT ''应用ID和逻辑表名索引''');
+
+DROP PROCEDURE migrate_add_column;
+DROP PROCEDURE migrate_add_index;
+
+-- 回收站中的数据表使用删除前的表名
+UPDATE sys_config_tables
+SET logical_name = IF(
+    IF(deleted_at IS NULL, table_name, original_name) LIKE CONCAT('app', app_id, '\_%'),
+    SUBSTRING(IF(deleted_at IS NULL, table_name, original_name), LENGTH(CONCAT('app', app_id, '_')) + 1),
+    IF(deleted_at IS NULL, table_name, original_name)
+)
+WHERE logical_name = '';
+
+UPDATE sys_config_dimensions
+SET logical_name = IF(
+    table_name LIKE CONCAT('app', app_id, '\_%'),
+    SUBSTRING(table_name, LENGTH(CONCAT('app', app_id, '_')) + 1),
+    table_name
+)
+WHERE logical_name = '';
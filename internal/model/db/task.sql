@@ -3,7 +3,7 @@ CREATE TABLE IF NOT EXISTS sys_scheduled_tasks (
     id          BIGINT UNSIGNED NOT NULL AUTO_INCREMENT COMMENT '主键ID',
     app_id      BIGINT UNSIGNED NOT NULL DEFAULT 0 COMMENT '应用ID',
     name        VARCHAR(100) NOT NULL DEFAULT '' COMMENT '任务名称',
-    type        VARCHAR(20) NOT NULL DEFAULT '' COMMENT '任务类型：sql/http',
+    type        VARCHAR(20) NOT NULL DEFAULT '' COMMENT '任务类型：sql/http/table_purge/attachment_cleanup/table_archive/table_trash_purge',
     cron        VARCHAR(100) NOT NULL DEFAULT '' COMMENT 'cron表达式',
     content     TEXT NOT NULL COMMENT '任务内容（JSON格式）',
     timeout     INT NOT NULL DEFAULT 60 COMMENT '超时时间（秒）',
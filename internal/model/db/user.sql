@@ -39,13 +39,16 @@ CREATE TABLE IF NOT EXISTS sys_permissions (
     path VARCHAR(255) DEFAULT '' COMMENT '路径',
     method VARCHAR(10) DEFAULT '' COMMENT '方法',
     menu_id BIGINT UNSIGNED NOT NULL DEFAULT 0 COMMENT '菜单ID',
+    dim_id BIGINT UNSIGNED NOT NULL DEFAULT 0 COMMENT '维度ID',
+    item_id BIGINT UNSIGNED NOT NULL DEFAULT 0 COMMENT '维度明细ID 0:全部明细',
     status TINYINT NOT NULL DEFAULT 1 COMMENT '状态 0:禁用 1:启用',
     description VARCHAR(200) DEFAULT '' COMMENT '描述',
     created_at DATETIME NOT NULL DEFAULT '1901-01-01 00:00:00' COMMENT '创建时间',
     creator_id BIGINT UNSIGNED NOT NULL DEFAULT 0 COMMENT '创建人ID',
     updated_at DATETIME NOT NULL DEFAULT CURRENT_TIMESTAMP ON UPDATE CURRENT_TIMESTAMP COMMENT '更新时间',
     updater_id BIGINT UNSIGNED NOT NULL DEFAULT 0 COMMENT '更新人ID',
-    deleted_at DATETIME NULL DEFAULT '1901-01-01 00:00:00' COMMENT '删除时间'
+    deleted_at DATETIME NULL DEFAULT '1901-01-01 00:00:00' COMMENT '删除时间',
+    KEY idx_dim_item (dim_id, item_id) COMMENT '维度ID和维度明细ID索引'
 ) ENGINE=InnoDB DEFAULT CHARSET=utf8mb4 COLLATE=utf8mb4_general_ci COMMENT='权限表';
 
 -- 用户角色关联表
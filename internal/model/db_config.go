@@ -4,24 +4,39 @@ import "github.com/iiwish/lingjian/pkg/utils"
 
 // ConfigTable 数据表配置
 type ConfigTable struct {
+	ID           uint             `db:"id" json:"id"`
+	AppID        uint             `db:"app_id" json:"app_id"`
+	TableName    string           `db:"table_name" json:"table_name"`     // 数据库中的表名
+	LogicalName  string           `db:"logical_name" json:"logical_name"` // 逻辑表名，用户看到的表名
+	DisplayName  string           `db:"display_name" json:"display_name"`
+	Description  string           `db:"description" json:"description"`
+	Func         string           `db:"func" json:"func"`
+	FieldOptions string           `db:"field_options" json:"field_options"` // 字段扩展配置
+	Status       int              `db:"status" json:"status"`               // 0:禁用 1:启用
+	CreatedAt    utils.CustomTime `db:"created_at" json:"created_at"`
+	CreatorID    uint             `db:"creator_id" json:"creator_id"`
+	UpdatedAt    utils.CustomTime `db:"updated_at" json:"updated_at"`
+	UpdaterID    uint             `db:"updater_id" json:"updater_id"`
+}
+
+// TrashTable 回收站中的数据表
+type TrashTable struct {
 	ID          uint             `db:"id" json:"id"`
 	AppID       uint             `db:"app_id" json:"app_id"`
-	TableName   string           `db:"table_name" json:"table_name"`
+	TableName   string           `db:"logical_name" json:"table_name"` // 逻辑表名
+	TrashName   string           `db:"table_name" json:"trash_name"`   // 回收站中的表名
 	DisplayName string           `db:"display_name" json:"display_name"`
-	Description string           `db:"description" json:"description"`
-	Func        string           `db:"func" json:"func"`
-	Status      int              `db:"status" json:"status"` // 0:禁用 1:启用
-	CreatedAt   utils.CustomTime `db:"created_at" json:"created_at"`
-	CreatorID   uint             `db:"creator_id" json:"creator_id"`
-	UpdatedAt   utils.CustomTime `db:"updated_at" json:"updated_at"`
-	UpdaterID   uint             `db:"updater_id" json:"updater_id"`
+	DeletedAt   utils.CustomTime `db:"deleted_at" json:"deleted_at"`
+	DeleterID   uint             `db:"deleter_id" json:"deleter_id"`
+	ExpiresAt   utils.CustomTime `db:"expires_at" json:"expires_at"` // 超过该时间后不能恢复，由table_trash_purge任务彻底删除
 }
 
 // ConfigDimension 维度配置
 type ConfigDimension struct {
 	ID            uint             `db:"id" json:"id"`
 	AppID         uint             `db:"app_id" json:"app_id"`
-	TableName     string           `db:"table_name" json:"table_name"`
+	TableName     string           `db:"table_name" json:"table_name"`     // 数据库中的表名
+	LogicalName   string           `db:"logical_name" json:"logical_name"` // 逻辑表名，用户看到的表名
 	DisplayName   string           `db:"display_name" json:"display_name"`
 	Description   string           `db:"description" json:"description"`
 	DimensionType string           `db:"dimension_type" json:"dimension_type"`
@@ -85,3 +100,37 @@ type ConfigMenu struct {
 	UpdatedAt utils.CustomTime `db:"updated_at" json:"updated_at"`
 	UpdaterID uint             `db:"updater_id" json:"updater_id"`
 }
+
+// TableOperation 数据表批量操作记录
+type TableOperation struct {
+	ID             uint             `db:"id" json:"id"`
+	AppID          uint             `db:"app_id" json:"app_id"`
+	TableID        uint             `db:"table_id" json:"table_id"`
+	Operation      string           `db:"operation" json:"operation"`             // 操作类型：bulk_update, bulk_delete, clone
+	QueryCondition string           `db:"query_condition" json:"query_condition"` // 过滤条件
+	Patch          string           `db:"patch" json:"patch"`                     // 更新的字段值，克隆时为源数据表和复制参数
+	Affected       int              `db:"affected" json:"affected"`               // 影响的记录数
+	AffectedKeys   string           `db:"affected_keys" json:"affected_keys"`     // 影响的记录主键
+	OperatorID     uint             `db:"operator_id" json:"operator_id"`
+	CreatedAt      utils.CustomTime `db:"created_at" json:"created_at"`
+}
+
+// 数据表归档执行状态
+const (
+	ArchiveStatusFailed  = 0 // 失败
+	ArchiveStatusSuccess = 1 // 成功
+	ArchiveStatusRunning = 2 // 执行中
+)
+
+// TableArchiveLog 数据表归档记录
+type TableArchiveLog struct {
+	ID         uint             `db:"id" json:"id"`
+	TableID    uint             `db:"table_id" json:"table_id"`
+	Status     int              `db:"status" json:"status"`     // 0:失败 1:成功 2:执行中
+	Archived   int64            `db:"archived" json:"archived"` // 归档的记录数
+	Cutoff     utils.CustomTime `db:"cutoff" json:"cutoff"`     // 归档截止时间
+	Error      string           `db:"error" json:"error"`       // 错误信息
+	OperatorID uint             `db:"operator_id" json:"operator_id"`
+	StartTime  utils.CustomTime `db:"start_time" json:"start_time"`
+	EndTime    utils.CustomTime `db:"end_time" json:"end_time"`
+}
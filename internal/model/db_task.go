@@ -16,7 +16,7 @@ type ScheduledTask struct {
 	ID         uint             `db:"id" json:"id"`
 	AppID      uint             `db:"app_id" json:"app_id"`
 	Name       string           `db:"name" json:"name"`
-	Type       string           `db:"type" json:"type"`               // sql:SQL任务 http:HTTP任务
+	Type       string           `db:"type" json:"type"`               // sql:SQL任务 http:HTTP任务 table_purge:回收站清理
 	Cron       string           `db:"cron" json:"cron"`               // cron表达式
 	Content    string           `db:"content" json:"content"`         // 任务内容（SQL语句或HTTP配置）
 	Timeout    int              `db:"timeout" json:"timeout"`         // 超时时间（秒）
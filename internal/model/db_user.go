@@ -21,6 +21,9 @@ type User struct {
 	DeletedAt utils.CustomTime `db:"deleted_at" json:"deleted_at"`
 }
 
+// AdminRoleCodes 管理员角色编码，可以使用原始字段类型、查看查询执行计划等管理功能
+var AdminRoleCodes = []string{"super_admin", "app_admin"}
+
 // Role 角色表
 type Role struct {
 	ID          uint             `db:"id" json:"id"`
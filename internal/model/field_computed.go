@@ -0,0 +1,118 @@
+package model
+
+import (
+	"fmt"
+	"sort"
+
+	"github.com/iiwish/lingjian/pkg/formula"
+)
+
+// ComputedMode 计算字段的计算方式
+type ComputedMode string
+
+const (
+	ComputedStored  ComputedMode = "stored"  // 写入时计算并保存到数据表
+	ComputedVirtual ComputedMode = "virtual" // 读取时计算，不占用数据表字段
+)
+
+// IsComputed 判断是否为计算字段
+func (o *FieldOptions) IsComputed() bool {
+	return o.Computed != ""
+}
+
+// IsVirtual 判断是否为虚拟计算字段
+func (o *FieldOptions) IsVirtual() bool {
+	return o.Computed == ComputedVirtual
+}
+
+// validateComputed 验证计算字段配置，fieldNames为数据表中的全部字段（含虚拟字段）
+func (o *FieldOptions) validateComputed(name string, fieldNames map[string]bool) error {
+	if o.Computed == "" {
+		if o.Formula != "" {
+			return fmt.Errorf("formula requires computed mode")
+		}
+		return nil
+	}
+	if o.Computed != ComputedStored && o.Computed != ComputedVirtual {
+		return fmt.Errorf("invalid computed mode: %s", o.Computed)
+	}
+	if o.Formula == "" {
+		return fmt.Errorf("computed field requires a formula")
+	}
+	if o.Computed == ComputedVirtual && len(o.Rules) > 0 {
+		return fmt.Errorf("virtual field does not support rules")
+	}
+
+	expr, err := formula.Compile(o.Formula)
+	if err != nil {
+		return fmt.Errorf("invalid formula: %v", err)
+	}
+	for _, ref := range expr.Refs() {
+		if ref == name {
+			return fmt.Errorf("formula references itself")
+		}
+		if !fieldNames[ref] {
+			return fmt.Errorf("formula references unknown field: %s", ref)
+		}
+	}
+	return nil
+}
+
+// SortComputedFields 按依赖顺序返回计算字段，被引用的字段排在前面，存在循环引用时返回错误
+func SortComputedFields(options map[string]FieldOptions) ([]string, error) {
+	// 收集计算字段及其依赖的计算字段
+	deps := make(map[string][]string)
+	for name, opt := range options {
+		if !opt.IsComputed() {
+			continue
+		}
+		expr, err := formula.Compile(opt.Formula)
+		if err != nil {
+			return nil, fmt.Errorf("field %s: invalid formula: %v", name, err)
+		}
+		deps[name] = nil
+		for _, ref := range expr.Refs() {
+			if refOpt, ok := options[ref]; ok && refOpt.IsComputed() {
+				deps[name] = append(deps[name], ref)
+			}
+		}
+	}
+
+	names := make([]string, 0, len(deps))
+	for name := range deps {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+
+	// 深度优先拓扑排序
+	const (
+		visiting = 1
+		visited  = 2
+	)
+	state := make(map[string]int, len(deps))
+	order := make([]string, 0, len(deps))
+	var visit func(name string) error
+	visit = func(name string) error {
+		switch state[name] {
+		case visiting:
+			return fmt.Errorf("circular reference in computed field %s", name)
+		case visited:
+			return nil
+		}
+		state[name] = visiting
+		for _, dep := range deps[name] {
+			if err := visit(dep); err != nil {
+				return err
+			}
+		}
+		state[name] = visited
+		order = append(order, name)
+		return nil
+	}
+	for _, name := range names {
+		if err := visit(name); err != nil {
+			return nil, err
+		}
+	}
+	return order, nil
+}
@@ -0,0 +1,63 @@
+package model
+
+import (
+	"fmt"
+
+	"github.com/iiwish/lingjian/pkg/utils"
+)
+
+// 引用类型
+const (
+	RefDimension = "dimension" // 引用维度明细
+	RefTable     = "table"     // 引用数据表记录
+)
+
+// RuleReference 引用完整性校验，由字段的引用配置产生，不能在rules中配置
+const RuleReference RuleType = "reference"
+
+// FieldReference 引用字段配置，字段取值引用维度明细或其他数据表的记录
+type FieldReference struct {
+	Type    string `json:"type"`               // 引用类型：dimension或table
+	DimID   uint   `json:"dim_id,omitempty"`   // dimension引用：维度ID
+	TableID uint   `json:"table_id,omitempty"` // table引用：数据表ID
+	Key     string `json:"key,omitempty"`      // 字段取值匹配的列，维度为id或code，默认为id；数据表默认为主键
+	Label   string `json:"label,omitempty"`    // table引用：展开时显示的列
+}
+
+// Validate 验证引用字段配置
+func (r *FieldReference) Validate() error {
+	switch r.Type {
+	case RefDimension:
+		if r.DimID == 0 {
+			return fmt.Errorf("dimension reference requires dim_id")
+		}
+		if r.Key != "" && r.Key != "id" && r.Key != "code" {
+			return fmt.Errorf("invalid reference key: %s", r.Key)
+		}
+		if r.Label != "" {
+			return fmt.Errorf("label is only supported by table reference")
+		}
+	case RefTable:
+		if r.TableID == 0 {
+			return fmt.Errorf("table reference requires table_id")
+		}
+		if r.Key != "" && !utils.IsValidIdentifier(r.Key) {
+			return fmt.Errorf("invalid reference key: %s", r.Key)
+		}
+		if r.Label != "" && !utils.IsValidIdentifier(r.Label) {
+			return fmt.Errorf("invalid reference label: %s", r.Label)
+		}
+	default:
+		return fmt.Errorf("unknown reference type: %s", r.Type)
+	}
+	return nil
+}
+
+// RefItem 展开后的引用记录
+type RefItem struct {
+	Key   interface{} `json:"key"`            // 字段取值
+	Name  string      `json:"name,omitempty"` // 维度明细名称或数据表记录的显示列
+	Code  string      `json:"code,omitempty"` // 维度明细编码
+	Path  string      `json:"path,omitempty"` // 维度明细从根节点开始的名称路径，以/分隔
+	Found bool        `json:"found"`          // 引用的记录是否存在
+}
@@ -0,0 +1,96 @@
+package model
+
+import (
+	"fmt"
+	"regexp"
+)
+
+// RuleType 字段校验规则类型
+type RuleType string
+
+const (
+	RuleRequired  RuleType = "required"   // 必填
+	RuleMin       RuleType = "min"        // 最小值
+	RuleMax       RuleType = "max"        // 最大值
+	RuleMinLength RuleType = "min_length" // 最小长度
+	RuleMaxLength RuleType = "max_length" // 最大长度
+	RuleRegex     RuleType = "regex"      // 正则匹配
+	RuleEnum      RuleType = "enum"       // 枚举值
+	RuleDimension RuleType = "dimension"  // 必须存在于维度中
+	RuleCompare   RuleType = "compare"    // 与其他字段比较
+)
+
+// FieldRule 字段校验规则
+type FieldRule struct {
+	Type     RuleType    `json:"type"`               // 规则类型
+	Value    interface{} `json:"value,omitempty"`    // 规则参数：最小/最大值、长度、正则、枚举列表
+	DimID    uint        `json:"dim_id,omitempty"`   // dimension规则：维度ID
+	DimKey   string      `json:"dim_key,omitempty"`  // dimension规则：匹配的维度列，id或code，默认为id
+	Field    string      `json:"field,omitempty"`    // compare规则：比较的字段
+	Operator Operator    `json:"operator,omitempty"` // compare规则：比较运算符，支持eq/ne/gt/gte/lt/lte
+	Message  string      `json:"message,omitempty"`  // 自定义错误提示
+}
+
+// FieldError 字段校验错误
+type FieldError struct {
+	Row     int      `json:"row"`     // 行号，从0开始
+	Field   string   `json:"field"`   // 字段名
+	Rule    RuleType `json:"rule"`    // 未通过的规则
+	Message string   `json:"message"` // 错误提示
+}
+
+// Validate 验证校验规则定义，fieldNames为数据表中的全部字段
+func (r *FieldRule) Validate(fieldNames map[string]bool) error {
+	switch r.Type {
+	case RuleRequired:
+		return nil
+	case RuleMin, RuleMax:
+		if _, ok := r.Value.(float64); !ok {
+			return fmt.Errorf("rule %s requires a numeric value", r.Type)
+		}
+	case RuleMinLength, RuleMaxLength:
+		if v, ok := r.Value.(float64); !ok || v < 0 || v != float64(int(v)) {
+			return fmt.Errorf("rule %s requires a non-negative integer value", r.Type)
+		}
+	case RuleRegex:
+		pattern, ok := r.Value.(string)
+		if !ok || pattern == "" {
+			return fmt.Errorf("rule regex requires a pattern")
+		}
+		if _, err := regexp.Compile(pattern); err != nil {
+			return fmt.Errorf("invalid regex pattern %q: %v", pattern, err)
+		}
+	case RuleEnum:
+		values, ok := r.Value.([]interface{})
+		if !ok || len(values) == 0 {
+			return fmt.Errorf("rule enum requires a non-empty list")
+		}
+	case RuleDimension:
+		if r.DimID == 0 {
+			return fmt.Errorf("rule dimension requires dim_id")
+		}
+		if r.DimKey != "" && r.DimKey != "id" && r.DimKey != "code" {
+			return fmt.Errorf("invalid dim_key: %s", r.DimKey)
+		}
+	case RuleCompare:
+		if !fieldNames[r.Field] {
+			return fmt.Errorf("rule compare references unknown field: %s", r.Field)
+		}
+		switch r.Operator {
+		case OpEq, OpNe, OpGt, OpGte, OpLt, OpLte:
+		default:
+			return fmt.Errorf("invalid compare operator: %s", r.Operator)
+		}
+	default:
+		return fmt.Errorf("unknown rule type: %s", r.Type)
+	}
+	return nil
+}
+
+// GetDimKey 获取维度匹配列
+func (r *FieldRule) GetDimKey() string {
+	if r.DimKey == "" {
+		return "id"
+	}
+	return r.DimKey
+}
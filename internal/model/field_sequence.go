@@ -0,0 +1,148 @@
+package model
+
+import (
+	"fmt"
+	"regexp"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// SequenceReset 编号计数器的重置周期
+type SequenceReset string
+
+const (
+	ResetNever SequenceReset = ""      // 不重置
+	ResetYear  SequenceReset = "year"  // 每年重置
+	ResetMonth SequenceReset = "month" // 每月重置
+	ResetDay   SequenceReset = "day"   // 每天重置
+)
+
+// RuleSequence 编号字段由系统生成，不能在rules中配置
+const RuleSequence RuleType = "sequence"
+
+// 编号字段的长度限制
+const (
+	SequenceColumnLength = 64 // 编号字段的长度
+	DefaultSequenceWidth = 6  // 计数器默认补零位数
+	MaxSequenceWidth     = 18 // 计数器最大补零位数
+	maxSequenceDigits    = 19 // 计数器的最大位数
+)
+
+// sequenceTokenRegexp 编号格式中的占位符：{yyyy}、{yy}、{mm}、{dd}、{seq}或{seq:位数}
+var sequenceTokenRegexp = regexp.MustCompile(`\{(yyyy|yy|mm|dd|seq)(?::(\d+))?\}`)
+
+// FieldSequence 编号字段配置，新增记录时由服务端按格式生成编号，计数器只增不减，删除记录后编号不会复用
+type FieldSequence struct {
+	Pattern string        `json:"pattern"`         // 编号格式，如 PO-{yyyy}-{seq:6}
+	Reset   SequenceReset `json:"reset,omitempty"` // 计数器重置周期：year、month、day，为空时不重置
+}
+
+// Validate 验证编号字段配置，格式中必须有且只有一个计数器，重置周期需要格式中包含对应的日期
+func (s *FieldSequence) Validate() error {
+	tokens := make(map[string]int)
+	validWidth := true
+	length := 0 // 生成的编号的最大长度，计数器按最大位数计算
+	literal := sequenceTokenRegexp.ReplaceAllStringFunc(s.Pattern, func(token string) string {
+		m := sequenceTokenRegexp.FindStringSubmatch(token)
+		tokens[m[1]]++
+		if m[1] == "seq" {
+			if width := sequenceWidth(m[2]); width < 1 || width > MaxSequenceWidth {
+				validWidth = false
+			}
+			length += maxSequenceDigits
+		} else {
+			length += len(m[1])
+		}
+		return ""
+	})
+	if strings.ContainsAny(literal, "{}") {
+		return fmt.Errorf("invalid sequence pattern %q", s.Pattern)
+	}
+	if tokens["seq"] != 1 || !validWidth {
+		return fmt.Errorf("sequence pattern requires exactly one {seq} with width between 1 and %d", MaxSequenceWidth)
+	}
+	if length+len([]rune(literal)) > SequenceColumnLength {
+		return fmt.Errorf("sequence pattern is too long")
+	}
+
+	hasYear := tokens["yyyy"] > 0 || tokens["yy"] > 0
+	switch s.Reset {
+	case ResetNever:
+	case ResetYear:
+		if !hasYear {
+			return fmt.Errorf("yearly reset requires {yyyy} or {yy} in pattern")
+		}
+	case ResetMonth:
+		if !hasYear || tokens["mm"] == 0 {
+			return fmt.Errorf("monthly reset requires year and {mm} in pattern")
+		}
+	case ResetDay:
+		if !hasYear || tokens["mm"] == 0 || tokens["dd"] == 0 {
+			return fmt.Errorf("daily reset requires year, {mm} and {dd} in pattern")
+		}
+	default:
+		return fmt.Errorf("invalid sequence reset: %s", s.Reset)
+	}
+	return nil
+}
+
+// validateSequence 验证编号字段配置，编号字段不能同时是计算字段或引用字段
+func (o *FieldOptions) validateSequence() error {
+	if o.Type != TypeSequence {
+		if o.Sequence != nil {
+			return fmt.Errorf("sequence config requires type sequence")
+		}
+		return nil
+	}
+	if o.Sequence == nil {
+		return fmt.Errorf("type sequence requires sequence config")
+	}
+	if o.Formula != "" || o.Reference != nil {
+		return fmt.Errorf("sequence field cannot be computed or a reference")
+	}
+	return o.Sequence.Validate()
+}
+
+// Period 返回t所在的计数周期，同一周期内共用计数器，不重置时为空
+func (s *FieldSequence) Period(t time.Time) string {
+	switch s.Reset {
+	case ResetYear:
+		return t.Format("2006")
+	case ResetMonth:
+		return t.Format("200601")
+	case ResetDay:
+		return t.Format("20060102")
+	}
+	return ""
+}
+
+// Format 按编号格式生成编号
+func (s *FieldSequence) Format(t time.Time, value int64) string {
+	return sequenceTokenRegexp.ReplaceAllStringFunc(s.Pattern, func(token string) string {
+		m := sequenceTokenRegexp.FindStringSubmatch(token)
+		switch m[1] {
+		case "yyyy":
+			return t.Format("2006")
+		case "yy":
+			return t.Format("06")
+		case "mm":
+			return t.Format("01")
+		case "dd":
+			return t.Format("02")
+		}
+		return fmt.Sprintf("%0*d", sequenceWidth(m[2]), value)
+	})
+}
+
+// sequenceWidth 解析计数器的补零位数，未指定时使用默认位数
+func sequenceWidth(s string) int {
+	if s == "" {
+		return DefaultSequenceWidth
+	}
+	width, err := strconv.Atoi(s)
+	if err != nil {
+		return 0
+	}
+	return width
+}
@@ -0,0 +1,270 @@
+package model
+
+import (
+	"errors"
+	"fmt"
+	"regexp"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// FieldType 逻辑字段类型，由服务端映射为数据库字段类型
+type FieldType string
+
+const (
+	TypeText       FieldType = "text"       // 单行文本，VARCHAR(length)
+	TypeLongText   FieldType = "long_text"  // 长文本，TEXT
+	TypeInteger    FieldType = "integer"    // 整数，BIGINT
+	TypeDecimal    FieldType = "decimal"    // 小数，DECIMAL(precision, scale)
+	TypeMoney      FieldType = "money"      // 金额，DECIMAL(precision, 2)
+	TypeDate       FieldType = "date"       // 日期，DATE
+	TypeDateTime   FieldType = "datetime"   // 日期时间，DATETIME
+	TypeBoolean    FieldType = "boolean"    // 布尔，TINYINT(1)
+	TypeEnum       FieldType = "enum"       // 枚举，VARCHAR，取值限定为options
+	TypeJSON       FieldType = "json"       // JSON
+	TypeEmail      FieldType = "email"      // 邮箱，VARCHAR(255)
+	TypePhone      FieldType = "phone"      // 电话，VARCHAR(20)
+	TypeURL        FieldType = "url"        // 网址，VARCHAR(1024)
+	TypeSequence   FieldType = "sequence"   // 编号，VARCHAR(64)，新增记录时按sequence配置生成
+	TypeAttachment FieldType = "attachment" // 附件，JSON，保存附件ID数组
+)
+
+// 逻辑字段类型的默认长度和精度
+const (
+	DefaultTextLength     = 255
+	MaxTextLength         = 4000
+	DefaultEnumLength     = 50
+	DefaultDecimalPrecise = 18
+	DefaultDecimalScale   = 4
+	MaxDecimalPrecise     = 65
+	MaxDecimalScale       = 30
+)
+
+// 逻辑字段类型的取值格式
+const (
+	patternInteger  = `^-?\d+$`
+	patternDecimal  = `^-?\d+(\.\d+)?$`
+	patternDate     = `^\d{4}-\d{2}-\d{2}$`
+	patternDateTime = `^\d{4}-\d{2}-\d{2}[ T]\d{2}:\d{2}(:\d{2})?$`
+	patternEmail    = `^[^@\s]+@[^@\s]+\.[^@\s]+$`
+	patternPhone    = `^\+?[0-9][0-9\- ]{4,18}[0-9]$`
+	patternURL      = `^https?://[^\s]+$`
+)
+
+// ErrRawColumnType 非管理员只能使用逻辑字段类型
+var ErrRawColumnType = errors.New("raw column_type is only available for administrators, use type instead")
+
+// rawColumnTypeRegexp 原始字段类型的格式，避免拼接到DDL中的内容包含其他语句
+var rawColumnTypeRegexp = regexp.MustCompile(`(?i)^[a-z]+(\s*\(\s*('[^';]*'|\d+)(\s*,\s*('[^';]*'|\d+))*\s*\))?(\s+(unsigned|zerofill|binary|character set \w+|collate \w+))*$`)
+
+// 各逻辑字段类型的取值格式
+var fieldTypePatterns = map[FieldType]string{
+	TypeInteger:  patternInteger,
+	TypeDecimal:  patternDecimal,
+	TypeMoney:    patternDecimal,
+	TypeDate:     patternDate,
+	TypeDateTime: patternDateTime,
+	TypeEmail:    patternEmail,
+	TypePhone:    patternPhone,
+	TypeURL:      patternURL,
+}
+
+// ResolveColumnType 根据逻辑字段类型生成数据库字段类型并校验默认值，allowRaw为true时允许直接使用原始字段类型
+// 虚拟计算字段没有数据表字段，不做处理
+func (f *Field) ResolveColumnType(allowRaw bool) error {
+	if f.IsVirtual() {
+		return nil
+	}
+	if f.Type == "" {
+		if !allowRaw {
+			return ErrRawColumnType
+		}
+		if !rawColumnTypeRegexp.MatchString(strings.TrimSpace(f.ColumnType)) {
+			return fmt.Errorf("invalid column_type %q of field %s", f.ColumnType, f.Name)
+		}
+		return nil
+	}
+
+	columnType, err := f.logicalColumnType()
+	if err != nil {
+		return fmt.Errorf("field %s: %v", f.Name, err)
+	}
+	f.ColumnType = columnType
+	if err := f.normalizeDefault(); err != nil {
+		return fmt.Errorf("field %s: %v", f.Name, err)
+	}
+	return nil
+}
+
+// logicalColumnType 将逻辑字段类型映射为数据库字段类型
+func (f *Field) logicalColumnType() (string, error) {
+	switch f.Type {
+	case TypeText, TypeEnum:
+		length := f.Length
+		if length == 0 {
+			length = DefaultTextLength
+			if f.Type == TypeEnum {
+				length = DefaultEnumLength
+			}
+		}
+		if length < 1 || length > MaxTextLength {
+			return "", fmt.Errorf("length must be between 1 and %d", MaxTextLength)
+		}
+		if f.Type == TypeEnum {
+			if len(f.Options) == 0 {
+				return "", fmt.Errorf("enum type requires options")
+			}
+			for _, option := range f.Options {
+				if option == "" || len([]rune(option)) > length {
+					return "", fmt.Errorf("invalid enum option %q", option)
+				}
+			}
+		}
+		return fmt.Sprintf("VARCHAR(%d)", length), nil
+	case TypeLongText:
+		return "TEXT", nil
+	case TypeInteger:
+		return "BIGINT", nil
+	case TypeDecimal, TypeMoney:
+		precision, scale := f.Precision, f.Scale
+		if precision == 0 {
+			precision = DefaultDecimalPrecise
+		}
+		if f.Type == TypeMoney {
+			scale = 2
+		} else if scale == 0 {
+			scale = DefaultDecimalScale
+		}
+		if precision < 1 || precision > MaxDecimalPrecise || scale < 0 || scale > MaxDecimalScale || scale > precision {
+			return "", fmt.Errorf("invalid precision %d and scale %d", precision, scale)
+		}
+		return fmt.Sprintf("DECIMAL(%d,%d)", precision, scale), nil
+	case TypeDate:
+		return "DATE", nil
+	case TypeDateTime:
+		return "DATETIME", nil
+	case TypeBoolean:
+		return "TINYINT(1)", nil
+	case TypeJSON:
+		return "JSON", nil
+	case TypeEmail:
+		return "VARCHAR(255)", nil
+	case TypePhone:
+		return "VARCHAR(20)", nil
+	case TypeURL:
+		return "VARCHAR(1024)", nil
+	case TypeSequence:
+		return fmt.Sprintf("VARCHAR(%d)", SequenceColumnLength), nil
+	case TypeAttachment:
+		return "JSON", nil
+	}
+	return "", fmt.Errorf("unknown field type: %s", f.Type)
+}
+
+// normalizeDefault 校验默认值是否符合逻辑字段类型，布尔类型默认值统一为0或1
+func (f *Field) normalizeDefault() error {
+	if f.Type == TypeBoolean {
+		switch strings.ToLower(f.Default) {
+		case "", "0", "false":
+			f.Default = "0"
+		case "1", "true":
+			f.Default = "1"
+		default:
+			return fmt.Errorf("invalid boolean default %q", f.Default)
+		}
+		return nil
+	}
+	if f.Default == "" {
+		return nil
+	}
+	if strings.ContainsAny(f.Default, `'\`) {
+		return fmt.Errorf("default value cannot contain quotes or backslashes")
+	}
+
+	switch f.Type {
+	case TypeLongText, TypeJSON, TypeSequence, TypeAttachment:
+		return fmt.Errorf("type %s does not support default value", f.Type)
+	case TypeText:
+		if len([]rune(f.Default)) > f.Length && f.Length > 0 {
+			return fmt.Errorf("default value is too long")
+		}
+	case TypeEnum:
+		for _, option := range f.Options {
+			if option == f.Default {
+				return nil
+			}
+		}
+		return fmt.Errorf("default value %q is not an option", f.Default)
+	case TypeDate:
+		if _, err := time.Parse("2006-01-02", f.Default); err != nil {
+			return fmt.Errorf("invalid date default %q", f.Default)
+		}
+	case TypeDateTime:
+		if _, err := time.Parse("2006-01-02 15:04:05", f.Default); err != nil {
+			return fmt.Errorf("invalid datetime default %q", f.Default)
+		}
+	case TypeInteger:
+		if _, err := strconv.ParseInt(f.Default, 10, 64); err != nil {
+			return fmt.Errorf("invalid integer default %q", f.Default)
+		}
+	case TypeDecimal, TypeMoney:
+		if _, err := strconv.ParseFloat(f.Default, 64); err != nil {
+			return fmt.Errorf("invalid decimal default %q", f.Default)
+		}
+	default:
+		if pattern, ok := fieldTypePatterns[f.Type]; ok && !regexp.MustCompile(pattern).MatchString(f.Default) {
+			return fmt.Errorf("invalid %s default %q", f.Type, f.Default)
+		}
+	}
+	return nil
+}
+
+// NormalizeValue 按逻辑字段类型转换写入的取值，布尔类型的true/false转换为1/0，其他取值原样返回
+// 写入前调用，取值需已通过TypeRules校验
+func (o *FieldOptions) NormalizeValue(value interface{}) interface{} {
+	if o.Type != TypeBoolean {
+		return value
+	}
+	switch v := value.(type) {
+	case bool:
+		if v {
+			return 1
+		}
+		return 0
+	case string:
+		switch strings.ToLower(strings.TrimSpace(v)) {
+		case "true", "1":
+			return 1
+		case "false", "0":
+			return 0
+		}
+	}
+	return value
+}
+
+// TypeRules 逻辑字段类型对应的隐含校验规则，写入记录时与字段配置的校验规则一起校验
+func (o *FieldOptions) TypeRules() []FieldRule {
+	switch o.Type {
+	case "":
+		return nil
+	case TypeBoolean:
+		return []FieldRule{{Type: RuleEnum, Value: []interface{}{"0", "1", "true", "false"}, Message: "必须是布尔值"}}
+	case TypeEnum:
+		options := make([]interface{}, len(o.Options))
+		for i, option := range o.Options {
+			options[i] = option
+		}
+		return []FieldRule{{Type: RuleEnum, Value: options}}
+	case TypeText:
+		length := o.Length
+		if length == 0 {
+			length = DefaultTextLength
+		}
+		return []FieldRule{{Type: RuleMaxLength, Value: float64(length)}}
+	}
+	if pattern, ok := fieldTypePatterns[o.Type]; ok {
+		return []FieldRule{{Type: RuleRegex, Value: pattern}}
+	}
+	return nil
+}
@@ -0,0 +1,249 @@
+package model
+
+import (
+	"strings"
+
+	"github.com/iiwish/lingjian/pkg/utils"
+)
+
+// AggregateFunc 聚合函数
+type AggregateFunc string
+
+const (
+	AggCount         AggregateFunc = "count"          // 计数
+	AggCountDistinct AggregateFunc = "count_distinct" // 去重计数
+	AggSum           AggregateFunc = "sum"            // 求和
+	AggAvg           AggregateFunc = "avg"            // 平均值
+	AggMin           AggregateFunc = "min"            // 最小值
+	AggMax           AggregateFunc = "max"            // 最大值
+)
+
+// DateBucket 日期分桶粒度
+type DateBucket string
+
+const (
+	BucketDay     DateBucket = "day"     // 按天，格式 2006-01-02
+	BucketMonth   DateBucket = "month"   // 按月，格式 2006-01
+	BucketQuarter DateBucket = "quarter" // 按季度，格式 2006-Q1
+	BucketYear    DateBucket = "year"    // 按年
+)
+
+// SelectField 查询字段
+type SelectField struct {
+	Field  string        `json:"field"`            // 字段名，count时可以为空
+	Func   AggregateFunc `json:"func,omitempty"`   // 聚合函数
+	Bucket DateBucket    `json:"bucket,omitempty"` // 日期分桶，仅用于非聚合字段
+	Alias  string        `json:"alias,omitempty"`  // 别名，为空时自动生成
+}
+
+// IsAggregate 判断是否为聚合字段
+func (f *SelectField) IsAggregate() bool {
+	return f.Func != ""
+}
+
+// GetAlias 获取字段别名
+func (f *SelectField) GetAlias() string {
+	if f.Alias != "" {
+		return f.Alias
+	}
+	switch {
+	case f.Func == AggCount && f.Field == "":
+		return "count"
+	case f.Func != "":
+		return string(f.Func) + "_" + f.Field
+	}
+	return f.Field
+}
+
+// expression 生成字段的SQL表达式
+func (f *SelectField) expression() string {
+	field := QuoteIdentifier(f.Field)
+	switch f.Func {
+	case AggCount:
+		if f.Field == "" {
+			return "COUNT(*)"
+		}
+		return "COUNT(" + field + ")"
+	case AggCountDistinct:
+		return "COUNT(DISTINCT " + field + ")"
+	case AggSum:
+		return "SUM(" + field + ")"
+	case AggAvg:
+		return "AVG(" + field + ")"
+	case AggMin:
+		return "MIN(" + field + ")"
+	case AggMax:
+		return "MAX(" + field + ")"
+	}
+
+	switch f.Bucket {
+	case BucketDay:
+		return "DATE_FORMAT(" + field + ", '%Y-%m-%d')"
+	case BucketMonth:
+		return "DATE_FORMAT(" + field + ", '%Y-%m')"
+	case BucketQuarter:
+		return "CONCAT(YEAR(" + field + "), '-Q', QUARTER(" + field + "))"
+	case BucketYear:
+		return "YEAR(" + field + ")"
+	}
+	return field
+}
+
+// validate 验证查询字段
+func (f *SelectField) validate() error {
+	switch f.Func {
+	case "":
+		if f.Field == "" {
+			return &QueryError{Message: "select field is required"}
+		}
+	case AggCount:
+	case AggCountDistinct, AggSum, AggAvg, AggMin, AggMax:
+		if f.Field == "" {
+			return &QueryError{Message: "aggregate " + string(f.Func) + " requires a field"}
+		}
+	default:
+		return &QueryError{Field: f.Field, Message: "unknown aggregate function " + string(f.Func)}
+	}
+	switch f.Bucket {
+	case "":
+	case BucketDay, BucketMonth, BucketQuarter, BucketYear:
+		if f.Func != "" {
+			return &QueryError{Field: f.Field, Message: "bucket cannot be used with aggregate function"}
+		}
+	default:
+		return &QueryError{Field: f.Field, Message: "unknown date bucket " + string(f.Bucket)}
+	}
+
+	if f.Alias != "" && !utils.IsValidIdentifier(f.Alias) {
+		return &QueryError{Field: f.Alias, Message: "invalid alias"}
+	}
+	return nil
+}
+
+// IsAggregate 判断是否为聚合查询
+func (q *QueryCondition) IsAggregate() bool {
+	if len(q.GroupBy) > 0 {
+		return true
+	}
+	for i := range q.Select {
+		if q.Select[i].IsAggregate() {
+			return true
+		}
+	}
+	return false
+}
+
+// ValidateSelect 验证查询字段、分组及分组过滤条件
+func (q *QueryCondition) ValidateSelect() error {
+	aliases := make(map[string]bool, len(q.Select))
+	for i := range q.Select {
+		if err := q.Select[i].validate(); err != nil {
+			return err
+		}
+		alias := q.Select[i].GetAlias()
+		if aliases[alias] {
+			return &QueryError{Field: alias, Message: "duplicate alias"}
+		}
+		aliases[alias] = true
+	}
+
+	if len(q.Having.Conditions) > 0 {
+		if !q.IsAggregate() {
+			return &QueryError{Message: "having requires group by or aggregate fields"}
+		}
+		// HAVING 只能引用查询字段别名或分组字段
+		allowed := make(map[string]bool, len(aliases)+len(q.GroupBy))
+		for alias := range aliases {
+			allowed[alias] = true
+		}
+		for _, field := range q.GroupBy {
+			allowed[field] = true
+		}
+		if len(q.Select) == 0 {
+			allowed["count"] = true
+		}
+		for _, field := range conditionFields(&q.Having) {
+			if !allowed[field] {
+				return &QueryError{Field: field, Message: "having field must be a select alias or group by field"}
+			}
+		}
+	}
+	return nil
+}
+
+// conditionFields 收集条件组中引用的全部字段
+func conditionFields(group *ConditionGroup) []string {
+	var fields []string
+	for _, item := range group.Conditions {
+		switch v := item.(type) {
+		case Condition:
+			fields = append(fields, v.Field)
+		case ConditionGroup:
+			fields = append(fields, conditionFields(&v)...)
+		case map[string]interface{}:
+			if field, ok := v["field"].(string); ok {
+				fields = append(fields, field)
+			}
+		}
+	}
+	return fields
+}
+
+// buildSelect 构建查询字段列表
+func (q *QueryCondition) buildSelect() string {
+	if len(q.Select) == 0 {
+		if len(q.GroupBy) == 0 {
+			return "*"
+		}
+		// 仅指定分组时返回分组字段及记录数
+		columns := make([]string, 0, len(q.GroupBy)+1)
+		for _, field := range q.GroupBy {
+			columns = append(columns, QuoteIdentifier(field))
+		}
+		return strings.Join(append(columns, "COUNT(*) AS `count`"), ",")
+	}
+
+	columns := make([]string, 0, len(q.Select))
+	for i := range q.Select {
+		f := &q.Select[i]
+		expr := f.expression()
+		if alias := QuoteIdentifier(f.GetAlias()); alias != expr {
+			expr += " AS " + alias
+		}
+		columns = append(columns, expr)
+	}
+	return strings.Join(columns, ",")
+}
+
+// buildGroupBy 构建分组表达式，未指定分组时按非聚合查询字段分组
+func (q *QueryCondition) buildGroupBy() []string {
+	exprs := make(map[string]string, len(q.Select))
+	var plain []string
+	hasAggregate := false
+	for i := range q.Select {
+		f := &q.Select[i]
+		if f.IsAggregate() {
+			hasAggregate = true
+			continue
+		}
+		exprs[f.GetAlias()] = f.expression()
+		plain = append(plain, f.expression())
+	}
+
+	if len(q.GroupBy) == 0 {
+		if hasAggregate {
+			return plain
+		}
+		return nil
+	}
+
+	groupBy := make([]string, 0, len(q.GroupBy))
+	for _, field := range q.GroupBy {
+		if expr, ok := exprs[field]; ok {
+			groupBy = append(groupBy, expr)
+		} else {
+			groupBy = append(groupBy, QuoteIdentifier(field))
+		}
+	}
+	return groupBy
+}
@@ -1,6 +1,7 @@
 package model
 
 import (
+	"fmt"
 	"strings"
 )
 
@@ -20,6 +21,7 @@ const (
 	OpNotIn      Operator = "not_in"      // NOT IN查询
 	OpBetween    Operator = "between"     // 区间查询
 	OpNotBetween Operator = "not_between" // 不在区间
+	OpMatch      Operator = "match"       // 全文检索，字段需要全文索引
 )
 
 // LogicOperator 逻辑运算符
@@ -45,9 +47,43 @@ type ConditionGroup struct {
 
 // QueryCondition 查询条件组合
 type QueryCondition struct {
-	Root    ConditionGroup `json:"root"`     // 根条件组
-	OrderBy []OrderBy      `json:"order_by"` // 排序
-	GroupBy []string       `json:"group_by"` // 分组
+	Root    ConditionGroup `json:"root"`             // 根条件组
+	Select  []SelectField  `json:"select,omitempty"` // 查询字段，为空时查询全部字段
+	OrderBy []OrderBy      `json:"order_by"`         // 排序
+	GroupBy []string       `json:"group_by"`         // 分组，可以是字段名或查询字段别名
+	Having  ConditionGroup `json:"having,omitempty"` // 分组过滤条件，字段为查询字段别名
+
+	scopes    []scope // 服务端附加的过滤条件，不接受客户端传入
+	relevance []scope // 服务端附加的相关度表达式
+}
+
+// scope 服务端附加的过滤条件
+type scope struct {
+	clause string
+	args   []interface{}
+}
+
+// QueryError 查询条件错误
+type QueryError struct {
+	Field   string // 出错的字段
+	Message string // 错误信息
+}
+
+func (e *QueryError) Error() string {
+	if e.Field == "" {
+		return "invalid query: " + e.Message
+	}
+	return fmt.Sprintf("invalid query: field %s: %s", e.Field, e.Message)
+}
+
+// AddScope 追加服务端过滤条件，与客户端条件以AND连接
+func (q *QueryCondition) AddScope(clause string, args ...interface{}) {
+	q.scopes = append(q.scopes, scope{clause: clause, args: args})
+}
+
+// AddRelevance 追加相关度表达式，未指定排序时按相关度降序排列
+func (q *QueryCondition) AddRelevance(expr string, args ...interface{}) {
+	q.relevance = append(q.relevance, scope{clause: expr, args: args})
 }
 
 // OrderBy 排序
@@ -56,17 +92,28 @@ type OrderBy struct {
 	Desc  bool   `json:"desc"`  // 是否降序
 }
 
-// BuildQuery 构建查询SQL
-func (q *QueryCondition) BuildQuery(tableName string) (string, []interface{}) {
-	query := "SELECT * FROM " + tableName
-	where, args := buildWhereClause(&q.Root)
+// QuoteIdentifier 使用反引号包裹字段名或表名
+func QuoteIdentifier(name string) string {
+	return "`" + strings.ReplaceAll(name, "`", "``") + "`"
+}
+
+// BuildQuery 构建查询SQL，source为已转义的表名或子查询
+func (q *QueryCondition) BuildQuery(source string) (string, []interface{}) {
+	query := "SELECT " + q.buildSelect() + " FROM " + source
+	where, args := q.BuildWhere()
 	if where != "" {
 		query += " WHERE " + where
 	}
 
 	// 添加GROUP BY
-	if len(q.GroupBy) > 0 {
-		query += " GROUP BY " + strings.Join(q.GroupBy, ",")
+	if groupBy := q.buildGroupBy(); len(groupBy) > 0 {
+		query += " GROUP BY " + strings.Join(groupBy, ",")
+	}
+
+	// 添加HAVING
+	if having, havingArgs := buildWhereClause(&q.Having); having != "" {
+		query += " HAVING " + having
+		args = append(args, havingArgs...)
 	}
 
 	// 添加ORDER BY
@@ -74,17 +121,109 @@ func (q *QueryCondition) BuildQuery(tableName string) (string, []interface{}) {
 		var orders []string
 		for _, order := range q.OrderBy {
 			if order.Desc {
-				orders = append(orders, order.Field+" DESC")
+				orders = append(orders, QuoteIdentifier(order.Field)+" DESC")
 			} else {
-				orders = append(orders, order.Field+" ASC")
+				orders = append(orders, QuoteIdentifier(order.Field)+" ASC")
 			}
 		}
 		query += " ORDER BY " + strings.Join(orders, ",")
+	} else if relevance, relevanceArgs := q.buildRelevance(); relevance != "" {
+		// 未指定排序时，全文检索按相关度排序
+		query += " ORDER BY " + relevance + " DESC"
+		args = append(args, relevanceArgs...)
 	}
 
 	return query, args
 }
 
+// buildRelevance 合并全文检索条件与服务端附加的相关度表达式，聚合查询不按相关度排序
+func (q *QueryCondition) buildRelevance() (string, []interface{}) {
+	if q.IsAggregate() {
+		return "", nil
+	}
+	var exprs []string
+	var args []interface{}
+	for _, c := range matchConditions(&q.Root) {
+		exprs = append(exprs, MatchClause(c.Field))
+		args = append(args, c.Value)
+	}
+	for _, r := range q.relevance {
+		exprs = append(exprs, r.clause)
+		args = append(args, r.args...)
+	}
+	if len(exprs) == 0 {
+		return "", nil
+	}
+	if len(exprs) == 1 {
+		return exprs[0], args
+	}
+	return "(" + strings.Join(exprs, " + ") + ")", args
+}
+
+// matchConditions 获取条件组中的全文检索条件
+func matchConditions(group *ConditionGroup) []Condition {
+	var result []Condition
+	for _, item := range group.Conditions {
+		switch v := item.(type) {
+		case Condition:
+			if v.Operator == OpMatch {
+				result = append(result, v)
+			}
+		case ConditionGroup:
+			result = append(result, matchConditions(&v)...)
+		}
+	}
+	return result
+}
+
+// AndConditions 返回必须同时满足的条件，展开嵌套的AND条件组，忽略OR条件组
+func (q *QueryCondition) AndConditions() []Condition {
+	return andConditions(&q.Root)
+}
+
+func andConditions(group *ConditionGroup) []Condition {
+	if group.Logic == LogicOr && len(group.Conditions) > 1 {
+		return nil
+	}
+	var result []Condition
+	for _, item := range group.Conditions {
+		switch v := item.(type) {
+		case Condition:
+			result = append(result, v)
+		case ConditionGroup:
+			result = append(result, andConditions(&v)...)
+		}
+	}
+	return result
+}
+
+// MatchClause 构建自然语言模式的全文检索条件，fields需与全文索引的字段一致
+func MatchClause(fields ...string) string {
+	quoted := make([]string, len(fields))
+	for i, field := range fields {
+		quoted[i] = QuoteIdentifier(field)
+	}
+	return "MATCH (" + strings.Join(quoted, ",") + ") AGAINST (?)"
+}
+
+// BuildWhere 构建WHERE条件，合并客户端条件与服务端附加条件
+func (q *QueryCondition) BuildWhere() (string, []interface{}) {
+	where, args := buildWhereClause(&q.Root)
+	if len(q.scopes) == 0 {
+		return where, args
+	}
+
+	var clauses []string
+	if where != "" {
+		clauses = append(clauses, "("+where+")")
+	}
+	for _, s := range q.scopes {
+		clauses = append(clauses, s.clause)
+		args = append(args, s.args...)
+	}
+	return strings.Join(clauses, " AND "), args
+}
+
 func buildWhereClause(group *ConditionGroup) (string, []interface{}) {
 	var conditions []string
 	var args []interface{}
@@ -97,77 +236,104 @@ func buildWhereClause(group *ConditionGroup) (string, []interface{}) {
 			args = append(args, arg...)
 		case ConditionGroup:
 			condition, arg := buildWhereClause(&v)
-			conditions = append(conditions, "("+condition+")")
-			args = append(args, arg...)
+			if condition != "" {
+				conditions = append(conditions, "("+condition+")")
+				args = append(args, arg...)
+			}
 		case map[string]interface{}:
-			// 将map转换为Condition结构体
-			c := Condition{
-				Field:    v["field"].(string),
-				Operator: Operator(v["operator"].(string)),
-				Value:    v["value"],
+			// 将map转换为Condition或ConditionGroup，格式错误的条件在Validate中拒绝
+			parsed, err := parseConditionItem(v)
+			if err != nil {
+				continue
+			}
+			wrapper := ConditionGroup{Conditions: []interface{}{parsed}}
+			condition, arg := buildWhereClause(&wrapper)
+			if condition != "" {
+				conditions = append(conditions, condition)
+				args = append(args, arg...)
 			}
-			condition, arg := buildCondition(c)
-			conditions = append(conditions, condition)
-			args = append(args, arg...)
 		}
 	}
 
-	return strings.Join(conditions, " "+string(group.Logic)+" "), args
+	logic := group.Logic
+	if logic == "" {
+		logic = LogicAnd
+	}
+	return strings.Join(conditions, " "+string(logic)+" "), args
 }
 
 func buildCondition(condition Condition) (string, []interface{}) {
 	var query string
 	var args []interface{}
+	field := QuoteIdentifier(condition.Field)
 
 	switch condition.Operator {
 	case OpEq:
-		query = condition.Field + " = ?"
+		if condition.Value == nil {
+			return field + " IS NULL", nil
+		}
+		query = field + " = ?"
 		args = append(args, condition.Value)
 	case OpNe:
-		query = condition.Field + " != ?"
+		if condition.Value == nil {
+			return field + " IS NOT NULL", nil
+		}
+		query = field + " != ?"
 		args = append(args, condition.Value)
 	case OpGt:
-		query = condition.Field + " > ?"
+		query = field + " > ?"
 		args = append(args, condition.Value)
 	case OpGte:
-		query = condition.Field + " >= ?"
+		query = field + " >= ?"
 		args = append(args, condition.Value)
 	case OpLt:
-		query = condition.Field + " < ?"
+		query = field + " < ?"
 		args = append(args, condition.Value)
 	case OpLte:
-		query = condition.Field + " <= ?"
+		query = field + " <= ?"
 		args = append(args, condition.Value)
 	case OpLike:
-		query = condition.Field + " LIKE ?"
-		args = append(args, "%"+condition.Value.(string)+"%")
+		query = field + " LIKE ?"
+		args = append(args, "%"+fmt.Sprint(condition.Value)+"%")
 	case OpNotLike:
-		query = condition.Field + " NOT LIKE ?"
-		args = append(args, "%"+condition.Value.(string)+"%")
-	case OpIn:
-		values := condition.Value.([]interface{})
-		placeholders := make([]string, len(values))
-		for i := range values {
-			placeholders[i] = "?"
-			args = append(args, values[i])
+		query = field + " NOT LIKE ?"
+		args = append(args, "%"+fmt.Sprint(condition.Value)+"%")
+	case OpIn, OpNotIn:
+		values, _ := condition.Value.([]interface{})
+		if len(values) == 0 {
+			// 空列表：IN 恒为假，NOT IN 恒为真
+			if condition.Operator == OpIn {
+				return "1 = 0", nil
+			}
+			return "1 = 1", nil
 		}
-		query = condition.Field + " IN (" + strings.Join(placeholders, ",") + ")"
-	case OpNotIn:
-		values := condition.Value.([]interface{})
 		placeholders := make([]string, len(values))
 		for i := range values {
 			placeholders[i] = "?"
 			args = append(args, values[i])
 		}
-		query = condition.Field + " NOT IN (" + strings.Join(placeholders, ",") + ")"
-	case OpBetween:
-		values := condition.Value.([]interface{})
-		query = condition.Field + " BETWEEN ? AND ?"
-		args = append(args, values[0], values[1])
-	case OpNotBetween:
-		values := condition.Value.([]interface{})
-		query = condition.Field + " NOT BETWEEN ? AND ?"
+		if condition.Operator == OpIn {
+			query = field + " IN (" + strings.Join(placeholders, ",") + ")"
+		} else {
+			query = field + " NOT IN (" + strings.Join(placeholders, ",") + ")"
+		}
+	case OpBetween, OpNotBetween:
+		values, _ := condition.Value.([]interface{})
+		if len(values) != 2 {
+			return "1 = 0", nil
+		}
+		if condition.Operator == OpBetween {
+			query = field + " BETWEEN ? AND ?"
+		} else {
+			query = field + " NOT BETWEEN ? AND ?"
+		}
 		args = append(args, values[0], values[1])
+	case OpMatch:
+		query = MatchClause(condition.Field)
+		args = append(args, condition.Value)
+	default:
+		// 未知操作符不产生任何匹配，Validate 中会拒绝
+		return "1 = 0", nil
 	}
 
 	return query, args
@@ -0,0 +1,404 @@
+package model
+
+import (
+	"strconv"
+	"strings"
+	"time"
+)
+
+// maxConditionDepth 条件组最大嵌套层数
+const maxConditionDepth = 16
+
+// maxInValues IN查询最多允许的取值个数
+const maxInValues = 1000
+
+// ColumnKind 字段取值类别
+type ColumnKind int
+
+const (
+	KindString ColumnKind = iota // 字符串
+	KindNumber                   // 数值
+	KindDate                     // 日期时间
+)
+
+// ColumnInfo 数据表字段信息
+type ColumnInfo struct {
+	Name          string `db:"name" json:"name"`                     // 字段名
+	DataType      string `db:"data_type" json:"data_type"`           // 数据类型，如 int、varchar
+	ColumnType    string `db:"column_type" json:"column_type"`       // 完整类型，如 int unsigned、varchar(50)
+	Nullable      bool   `db:"nullable" json:"nullable"`             // 是否允许为NULL
+	PrimaryKey    bool   `db:"primary_key" json:"primary_key"`       // 是否为主键
+	AutoIncrement bool   `db:"auto_increment" json:"auto_increment"` // 是否自增
+}
+
+// Kind 获取字段取值类别
+func (c *ColumnInfo) Kind() ColumnKind {
+	switch strings.ToLower(c.DataType) {
+	case "tinyint", "smallint", "mediumint", "int", "integer", "bigint",
+		"decimal", "numeric", "float", "double", "real", "bit", "year":
+		return KindNumber
+	case "date", "datetime", "timestamp":
+		return KindDate
+	}
+	return KindString
+}
+
+// TableSchema 数据表结构
+type TableSchema struct {
+	Columns         map[string]ColumnInfo // 字段名 -> 字段信息
+	PrimaryKey      []string              // 主键字段
+	FulltextIndexes [][]string            // 全文索引的字段列表
+	UniqueKeys      [][]string            // 唯一键的字段列表，包含主键
+	Indexes         [][]string            // 普通索引的字段列表
+}
+
+// NewTableSchema 根据字段列表创建数据表结构
+func NewTableSchema(columns []ColumnInfo) *TableSchema {
+	schema := &TableSchema{Columns: make(map[string]ColumnInfo, len(columns))}
+	for _, col := range columns {
+		schema.Columns[col.Name] = col
+		if col.PrimaryKey {
+			schema.PrimaryKey = append(schema.PrimaryKey, col.Name)
+		}
+	}
+	return schema
+}
+
+// Column 获取字段信息
+func (s *TableSchema) Column(name string) (*ColumnInfo, bool) {
+	col, ok := s.Columns[name]
+	if !ok {
+		return nil, false
+	}
+	return &col, true
+}
+
+// AutoIncrementKey 获取自增主键字段，主键不是单个自增字段时返回空
+func (s *TableSchema) AutoIncrementKey() string {
+	if len(s.PrimaryKey) != 1 {
+		return ""
+	}
+	if col := s.Columns[s.PrimaryKey[0]]; col.AutoIncrement {
+		return col.Name
+	}
+	return ""
+}
+
+// HasFulltextIndex 判断是否存在字段完全一致的全文索引
+func (s *TableSchema) HasFulltextIndex(fields ...string) bool {
+	for _, index := range s.FulltextIndexes {
+		if len(index) != len(fields) {
+			continue
+		}
+		matched := true
+		for _, field := range fields {
+			found := false
+			for _, col := range index {
+				if col == field {
+					found = true
+					break
+				}
+			}
+			if !found {
+				matched = false
+				break
+			}
+		}
+		if matched {
+			return true
+		}
+	}
+	return false
+}
+
+// Validate 根据数据表结构校验查询条件，将条件统一转换为 Condition/ConditionGroup 并按字段类型转换取值
+func (q *QueryCondition) Validate(schema *TableSchema) error {
+	// 校验WHERE条件
+	err := normalizeGroup(&q.Root, 0, func(field string) (*ColumnInfo, error) {
+		col, ok := schema.Column(field)
+		if !ok {
+			return nil, &QueryError{Field: field, Message: "unknown field"}
+		}
+		return col, nil
+	})
+	if err != nil {
+		return err
+	}
+	for _, c := range matchConditions(&q.Root) {
+		if !schema.HasFulltextIndex(c.Field) {
+			return &QueryError{Field: c.Field, Message: "match requires a fulltext index on the field"}
+		}
+	}
+
+	// HAVING 条件引用别名，字段在 ValidateSelect 中校验
+	if err := normalizeGroup(&q.Having, 0, func(string) (*ColumnInfo, error) { return nil, nil }); err != nil {
+		return err
+	}
+	if err := q.ValidateSelect(); err != nil {
+		return err
+	}
+
+	// 校验查询字段
+	aliases := make(map[string]bool, len(q.Select))
+	for i := range q.Select {
+		f := &q.Select[i]
+		aliases[f.GetAlias()] = true
+		if f.Field == "" {
+			continue
+		}
+		col, ok := schema.Column(f.Field)
+		if !ok {
+			return &QueryError{Field: f.Field, Message: "unknown field"}
+		}
+		if (f.Func == AggSum || f.Func == AggAvg) && col.Kind() != KindNumber {
+			return &QueryError{Field: f.Field, Message: "aggregate " + string(f.Func) + " requires a numeric field"}
+		}
+		if f.Bucket != "" && col.Kind() != KindDate {
+			return &QueryError{Field: f.Field, Message: "date bucket requires a date field"}
+		}
+	}
+
+	// 校验分组字段
+	for _, field := range q.GroupBy {
+		if _, ok := schema.Column(field); !ok && !aliases[field] {
+			return &QueryError{Field: field, Message: "unknown group by field"}
+		}
+	}
+
+	// 校验排序字段
+	if len(q.Select) == 0 && len(q.GroupBy) > 0 {
+		aliases["count"] = true
+	}
+	for _, order := range q.OrderBy {
+		if _, ok := schema.Column(order.Field); !ok && !aliases[order.Field] {
+			return &QueryError{Field: order.Field, Message: "unknown order by field"}
+		}
+	}
+	return nil
+}
+
+// normalizeGroup 校验条件组，resolve 返回字段信息，返回nil表示不按类型转换
+func normalizeGroup(group *ConditionGroup, depth int, resolve func(field string) (*ColumnInfo, error)) error {
+	if depth > maxConditionDepth {
+		return &QueryError{Message: "conditions are nested too deeply"}
+	}
+
+	switch LogicOperator(strings.ToUpper(string(group.Logic))) {
+	case "", LogicAnd:
+		group.Logic = LogicAnd
+	case LogicOr:
+		group.Logic = LogicOr
+	default:
+		return &QueryError{Message: "invalid logic operator " + string(group.Logic)}
+	}
+
+	for i, item := range group.Conditions {
+		if m, ok := item.(map[string]interface{}); ok {
+			parsed, err := parseConditionItem(m)
+			if err != nil {
+				return err
+			}
+			item = parsed
+		}
+
+		switch v := item.(type) {
+		case Condition:
+			if err := normalizeCondition(&v, resolve); err != nil {
+				return err
+			}
+			group.Conditions[i] = v
+		case ConditionGroup:
+			if err := normalizeGroup(&v, depth+1, resolve); err != nil {
+				return err
+			}
+			group.Conditions[i] = v
+		default:
+			return &QueryError{Message: "invalid condition"}
+		}
+	}
+	return nil
+}
+
+// parseConditionItem 将JSON解析得到的map转换为 Condition 或 ConditionGroup
+func parseConditionItem(m map[string]interface{}) (interface{}, error) {
+	if raw, ok := m["conditions"]; ok {
+		items, ok := raw.([]interface{})
+		if !ok && raw != nil {
+			return nil, &QueryError{Message: "conditions must be an array"}
+		}
+		group := ConditionGroup{Conditions: items}
+		if logic, ok := m["logic"]; ok && logic != nil {
+			s, ok := logic.(string)
+			if !ok {
+				return nil, &QueryError{Message: "logic must be a string"}
+			}
+			group.Logic = LogicOperator(s)
+		}
+		return group, nil
+	}
+
+	field, ok := m["field"].(string)
+	if !ok || field == "" {
+		return nil, &QueryError{Message: "condition field must be a non-empty string"}
+	}
+	operator, ok := m["operator"].(string)
+	if !ok {
+		return nil, &QueryError{Field: field, Message: "condition operator must be a string"}
+	}
+	return Condition{Field: field, Operator: Operator(operator), Value: m["value"]}, nil
+}
+
+// normalizeCondition 校验单个条件并转换取值
+func normalizeCondition(c *Condition, resolve func(field string) (*ColumnInfo, error)) error {
+	if c.Field == "" {
+		return &QueryError{Message: "condition field is required"}
+	}
+	col, err := resolve(c.Field)
+	if err != nil {
+		return err
+	}
+
+	switch c.Operator {
+	case OpEq, OpNe:
+		if c.Value == nil {
+			return nil
+		}
+		c.Value, err = coerceValue(c.Field, col, c.Value)
+	case OpGt, OpGte, OpLt, OpLte:
+		if c.Value == nil {
+			return &QueryError{Field: c.Field, Message: "value is required for operator " + string(c.Operator)}
+		}
+		c.Value, err = coerceValue(c.Field, col, c.Value)
+	case OpLike, OpNotLike:
+		if !isScalar(c.Value) || c.Value == nil {
+			return &QueryError{Field: c.Field, Message: "like requires a scalar value"}
+		}
+		c.Value = scalarString(c.Value)
+	case OpIn, OpNotIn:
+		values, ok := c.Value.([]interface{})
+		if !ok {
+			return &QueryError{Field: c.Field, Message: "value must be an array for operator " + string(c.Operator)}
+		}
+		if len(values) > maxInValues {
+			return &QueryError{Field: c.Field, Message: "too many values"}
+		}
+		for i := range values {
+			if values[i], err = coerceValue(c.Field, col, values[i]); err != nil {
+				return err
+			}
+		}
+	case OpBetween, OpNotBetween:
+		values, ok := c.Value.([]interface{})
+		if !ok || len(values) != 2 {
+			return &QueryError{Field: c.Field, Message: "value must be an array of two elements for operator " + string(c.Operator)}
+		}
+		for i := range values {
+			if values[i] == nil {
+				return &QueryError{Field: c.Field, Message: "between bounds cannot be null"}
+			}
+			if values[i], err = coerceValue(c.Field, col, values[i]); err != nil {
+				return err
+			}
+		}
+	case OpMatch:
+		if col == nil || col.Kind() != KindString {
+			return &QueryError{Field: c.Field, Message: "match requires a text field"}
+		}
+		s, ok := c.Value.(string)
+		if !ok || strings.TrimSpace(s) == "" {
+			return &QueryError{Field: c.Field, Message: "match requires a non-empty string value"}
+		}
+		c.Value = strings.TrimSpace(s)
+	default:
+		return &QueryError{Field: c.Field, Message: "unknown operator " + string(c.Operator)}
+	}
+	return err
+}
+
+// isScalar 判断是否为标量取值
+func isScalar(v interface{}) bool {
+	switch v.(type) {
+	case nil, string, float64, float32, int, int64, uint, uint64, bool:
+		return true
+	}
+	return false
+}
+
+// scalarString 将标量取值转换为字符串
+func scalarString(v interface{}) string {
+	switch val := v.(type) {
+	case string:
+		return val
+	case float64:
+		return strconv.FormatFloat(val, 'f', -1, 64)
+	case float32:
+		return strconv.FormatFloat(float64(val), 'f', -1, 32)
+	case int:
+		return strconv.Itoa(val)
+	case int64:
+		return strconv.FormatInt(val, 10)
+	case uint:
+		return strconv.FormatUint(uint64(val), 10)
+	case uint64:
+		return strconv.FormatUint(val, 10)
+	case bool:
+		if val {
+			return "1"
+		}
+		return "0"
+	}
+	return ""
+}
+
+// queryDateLayouts 查询条件支持的日期格式
+var queryDateLayouts = []string{
+	"2006-01-02 15:04:05",
+	"2006-01-02",
+	time.RFC3339,
+	"2006-01-02T15:04:05",
+	"2006-01-02 15:04",
+}
+
+// coerceValue 按字段类型转换查询取值，col为nil时只检查是否为标量
+func coerceValue(field string, col *ColumnInfo, v interface{}) (interface{}, error) {
+	if !isScalar(v) {
+		return nil, &QueryError{Field: field, Message: "value must be a scalar"}
+	}
+	if col == nil || v == nil {
+		return v, nil
+	}
+
+	switch col.Kind() {
+	case KindNumber:
+		switch val := v.(type) {
+		case bool:
+			if val {
+				return 1, nil
+			}
+			return 0, nil
+		case string:
+			s := strings.TrimSpace(val)
+			if _, err := strconv.ParseFloat(s, 64); err != nil {
+				return nil, &QueryError{Field: field, Message: "value must be a number"}
+			}
+			return s, nil
+		}
+		return v, nil
+	case KindDate:
+		s, ok := v.(string)
+		if !ok {
+			return nil, &QueryError{Field: field, Message: "value must be a date string"}
+		}
+		s = strings.TrimSpace(s)
+		for _, layout := range queryDateLayouts {
+			if t, err := time.ParseInLocation(layout, s, time.Local); err == nil {
+				if strings.ToLower(col.DataType) == "date" {
+					return t.Format("2006-01-02"), nil
+				}
+				return t.Format("2006-01-02 15:04:05"), nil
+			}
+		}
+		return nil, &QueryError{Field: field, Message: "invalid date value " + s}
+	}
+	return scalarString(v), nil
+}
@@ -0,0 +1,87 @@
+package model
+
+import (
+	"encoding/json"
+	"errors"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func testTableSchema() *TableSchema {
+	schema := NewTableSchema([]ColumnInfo{
+		{Name: "id", DataType: "bigint", ColumnType: "bigint", PrimaryKey: true, AutoIncrement: true},
+		{Name: "title", DataType: "varchar", ColumnType: "varchar(100)"},
+		{Name: "amount", DataType: "decimal", ColumnType: "decimal(10,2)"},
+		{Name: "birthday", DataType: "date", ColumnType: "date"},
+		{Name: "created_at", DataType: "datetime", ColumnType: "datetime"},
+	})
+	schema.FulltextIndexes = [][]string{{"title"}}
+	return schema
+}
+
+func parseTestCondition(t *testing.T, body string) *QueryCondition {
+	var q QueryCondition
+	if err := json.Unmarshal([]byte(body), &q); err != nil {
+		t.Fatal(err)
+	}
+	return &q
+}
+
+func TestQueryConditionValidate(t *testing.T) {
+	q := parseTestCondition(t, `{
+		"root": {"logic": "or", "conditions": [
+			{"field": "amount", "operator": "gte", "value": " 10.5"},
+			{"field": "birthday", "operator": "between", "value": ["2024-01-01 08:00:00", "2024-12-31"]},
+			{"field": "title", "operator": "match", "value": " 数据 "},
+			{"conditions": [{"field": "id", "operator": "in", "value": [1, "2", true]}]}
+		]},
+		"order_by": [{"field": "amount", "desc": true}]
+	}`)
+	assert.NoError(t, q.Validate(testTableSchema()))
+
+	assert.Equal(t, LogicOr, q.Root.Logic)
+	assert.Equal(t, Condition{Field: "amount", Operator: OpGte, Value: "10.5"}, q.Root.Conditions[0])
+	assert.Equal(t, []interface{}{"2024-01-01", "2024-12-31"}, q.Root.Conditions[1].(Condition).Value)
+	assert.Equal(t, "数据", q.Root.Conditions[2].(Condition).Value)
+	group := q.Root.Conditions[3].(ConditionGroup)
+	assert.Equal(t, LogicAnd, group.Logic)
+	assert.Equal(t, []interface{}{float64(1), "2", 1}, group.Conditions[0].(Condition).Value)
+}
+
+func TestQueryConditionValidateErrors(t *testing.T) {
+	cases := []struct {
+		name  string
+		body  string
+		field string
+	}{
+		{"未知字段", `{"root": {"conditions": [{"field": "unknown", "operator": "eq", "value": 1}]}}`, "unknown"},
+		{"未知操作符", `{"root": {"conditions": [{"field": "id", "operator": "regexp", "value": 1}]}}`, "id"},
+		{"数值字段取值", `{"root": {"conditions": [{"field": "amount", "operator": "eq", "value": "abc"}]}}`, "amount"},
+		{"日期字段取值", `{"root": {"conditions": [{"field": "created_at", "operator": "lt", "value": "昨天"}]}}`, "created_at"},
+		{"IN取值不是数组", `{"root": {"conditions": [{"field": "id", "operator": "in", "value": 1}]}}`, "id"},
+		{"区间边界为NULL", `{"root": {"conditions": [{"field": "id", "operator": "between", "value": [1, null]}]}}`, "id"},
+		{"全文检索无索引", `{"root": {"conditions": [{"field": "amount", "operator": "match", "value": "1"}]}}`, "amount"},
+		{"未知排序字段", `{"root": {}, "order_by": [{"field": "unknown"}]}`, "unknown"},
+		{"逻辑运算符", `{"root": {"logic": "xor"}}`, ""},
+	}
+
+	for _, c := range cases {
+		t.Run(c.name, func(t *testing.T) {
+			err := parseTestCondition(t, c.body).Validate(testTableSchema())
+			var qerr *QueryError
+			if assert.True(t, errors.As(err, &qerr), "expected QueryError, got %v", err) {
+				assert.Equal(t, c.field, qerr.Field)
+			}
+		})
+	}
+}
+
+func TestQueryConditionValidateDepth(t *testing.T) {
+	nested := ConditionGroup{}
+	for i := 0; i <= maxConditionDepth+1; i++ {
+		nested = ConditionGroup{Conditions: []interface{}{nested}}
+	}
+	q := &QueryCondition{Root: nested}
+	assert.Error(t, q.Validate(testTableSchema()))
+}
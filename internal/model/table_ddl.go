@@ -0,0 +1,716 @@
+package model
+
+import (
+	"fmt"
+	"strings"
+)
+
+// 数据表定义的导出格式
+const (
+	TableExportDDL  = "ddl"  // CREATE TABLE语句
+	TableExportJSON = "json" // 平台的数据表定义，可直接用于创建数据表
+)
+
+// ExportTableResp 导出数据表定义结果
+type ExportTableResp struct {
+	Format string          `json:"format"`          // 导出格式：ddl、json
+	DDL    string          `json:"ddl,omitempty"`   // CREATE TABLE语句，表名为逻辑表名
+	Table  *CreateTableReq `json:"table,omitempty"` // 平台的数据表定义
+}
+
+// ImportTableReq 从CREATE TABLE语句导入数据表请求
+type ImportTableReq struct {
+	DDL         string `json:"ddl" binding:"required"` // CREATE TABLE语句
+	TableName   string `json:"table_name"`             // 逻辑表名，为空时使用语句中的表名
+	DisplayName string `json:"display_name"`           // 显示名称，为空时使用表名
+	Description string `json:"description"`            // 描述，为空时使用表注释
+	Func        string `json:"func"`                   // 功能配置，启用的托管字段和索引从语句中去掉
+	ParentID    uint   `json:"parent_id"`              // 系统菜单的父节点ID
+	DryRun      bool   `json:"dry_run"`                // 只解析和校验，不创建数据表
+}
+
+// ImportTableResp 从CREATE TABLE语句导入数据表结果
+type ImportTableResp struct {
+	ID          uint                `json:"id,omitempty"`          // 数据表配置ID，dry_run时为0
+	Table       *CreateTableReq     `json:"table"`                 // 解析出的数据表定义
+	Unsupported []UnsupportedClause `json:"unsupported,omitempty"` // 不支持、导入时忽略的子句
+}
+
+// UnsupportedClause 平台不支持、导入时忽略的DDL子句
+type UnsupportedClause struct {
+	Clause string `json:"clause"` // 子句内容
+	Reason string `json:"reason"` // 原因
+}
+
+// DDLError CREATE TABLE语句无法解析
+type DDLError struct {
+	Message string
+}
+
+func (e *DDLError) Error() string {
+	return "invalid ddl: " + e.Message
+}
+
+// ddlTokenKind DDL词法单元类型
+type ddlTokenKind int
+
+const (
+	ddlWord   ddlTokenKind = iota // 关键字或未加引号的标识符
+	ddlIdent                      // 反引号标识符
+	ddlString                     // 字符串
+	ddlNumber                     // 数值
+	ddlSymbol                     // 符号
+)
+
+// ddlToken DDL词法单元，text为去掉引号和转义后的内容，raw为原文
+type ddlToken struct {
+	kind ddlTokenKind
+	text string
+	raw  string
+}
+
+// tokenizeDDL 将DDL拆分为词法单元，忽略注释
+func tokenizeDDL(ddl string) ([]ddlToken, error) {
+	var tokens []ddlToken
+	for i := 0; i < len(ddl); {
+		c := ddl[i]
+		switch {
+		case c == ' ' || c == '\t' || c == '\n' || c == '\r':
+			i++
+		case c == '#' || (c == '-' && strings.HasPrefix(ddl[i:], "--") && (i+2 == len(ddl) || strings.ContainsRune(" \t\r\n", rune(ddl[i+2])))):
+			end := strings.IndexByte(ddl[i:], '\n')
+			if end < 0 {
+				i = len(ddl)
+			} else {
+				i += end + 1
+			}
+		case strings.HasPrefix(ddl[i:], "/*!"):
+			// 版本注释中的内容会被MySQL执行，按语句内容解析
+			i += 3
+			for i < len(ddl) && isDDLDigit(ddl[i]) {
+				i++
+			}
+		case strings.HasPrefix(ddl[i:], "*/"):
+			i += 2
+		case c == '/' && strings.HasPrefix(ddl[i:], "/*"):
+			end := strings.Index(ddl[i+2:], "*/")
+			if end < 0 {
+				return nil, &DDLError{Message: "unterminated comment"}
+			}
+			i += end + 4
+		case c == '`' || c == '\'' || c == '"':
+			var text strings.Builder
+			j := i + 1
+			for ; j < len(ddl); j++ {
+				if ddl[j] == c {
+					// 连续两个引号表示引号本身
+					if j+1 < len(ddl) && ddl[j+1] == c {
+						text.WriteByte(c)
+						j++
+						continue
+					}
+					break
+				}
+				if ddl[j] == '\\' && c != '`' && j+1 < len(ddl) {
+					j++
+					text.WriteByte(unescapeDDLChar(ddl[j]))
+					continue
+				}
+				text.WriteByte(ddl[j])
+			}
+			if j >= len(ddl) {
+				return nil, &DDLError{Message: "unterminated quoted string"}
+			}
+			kind := ddlString
+			if c == '`' {
+				kind = ddlIdent
+			}
+			tokens = append(tokens, ddlToken{kind: kind, text: text.String(), raw: ddl[i : j+1]})
+			i = j + 1
+		case isDDLDigit(c) || (c == '.' && i+1 < len(ddl) && isDDLDigit(ddl[i+1])):
+			j := i
+			for j < len(ddl) && (isDDLDigit(ddl[j]) || ddl[j] == '.') {
+				j++
+			}
+			tokens = append(tokens, ddlToken{kind: ddlNumber, text: ddl[i:j], raw: ddl[i:j]})
+			i = j
+		case isDDLWordChar(c):
+			j := i
+			for j < len(ddl) && (isDDLWordChar(ddl[j]) || isDDLDigit(ddl[j])) {
+				j++
+			}
+			tokens = append(tokens, ddlToken{kind: ddlWord, text: ddl[i:j], raw: ddl[i:j]})
+			i = j
+		default:
+			tokens = append(tokens, ddlToken{kind: ddlSymbol, text: string(c), raw: string(c)})
+			i++
+		}
+	}
+	return tokens, nil
+}
+
+// unescapeDDLChar 转换字符串中反斜杠转义的字符
+func unescapeDDLChar(c byte) byte {
+	switch c {
+	case 'n':
+		return '\n'
+	case 't':
+		return '\t'
+	case 'r':
+		return '\r'
+	case '0':
+		return 0
+	}
+	return c
+}
+
+func isDDLDigit(c byte) bool {
+	return c >= '0' && c <= '9'
+}
+
+func isDDLWordChar(c byte) bool {
+	return c == '_' || c == '$' || (c >= 'a' && c <= 'z') || (c >= 'A' && c <= 'Z') || c >= 0x80
+}
+
+// joinDDLTokens 将词法单元还原为子句文本
+func joinDDLTokens(tokens []ddlToken) string {
+	var b strings.Builder
+	for i, token := range tokens {
+		if i > 0 && token.raw != "," && token.raw != ")" && tokens[i-1].raw != "(" {
+			b.WriteByte(' ')
+		}
+		b.WriteString(token.raw)
+	}
+	return b.String()
+}
+
+// ddlParser 在词法单元序列上解析，读到末尾时返回空的词法单元
+type ddlParser struct {
+	tokens []ddlToken
+	pos    int
+}
+
+func (p *ddlParser) done() bool {
+	return p.pos >= len(p.tokens)
+}
+
+func (p *ddlParser) peek() ddlToken {
+	if p.done() {
+		return ddlToken{kind: ddlSymbol}
+	}
+	return p.tokens[p.pos]
+}
+
+func (p *ddlParser) next() ddlToken {
+	token := p.peek()
+	p.pos++
+	return token
+}
+
+// isWord 判断当前词法单元是否为指定关键字之一，关键字不区分大小写
+func (p *ddlParser) isWord(keywords ...string) bool {
+	token := p.peek()
+	if token.kind != ddlWord {
+		return false
+	}
+	for _, keyword := range keywords {
+		if strings.EqualFold(token.text, keyword) {
+			return true
+		}
+	}
+	return false
+}
+
+// acceptWord 当前词法单元为指定关键字时读取并返回true
+func (p *ddlParser) acceptWord(keyword string) bool {
+	if p.isWord(keyword) {
+		p.pos++
+		return true
+	}
+	return false
+}
+
+func (p *ddlParser) isSymbol(symbol string) bool {
+	token := p.peek()
+	return token.kind == ddlSymbol && token.text == symbol
+}
+
+func (p *ddlParser) acceptSymbol(symbol string) bool {
+	if p.isSymbol(symbol) {
+		p.pos++
+		return true
+	}
+	return false
+}
+
+// name 读取标识符
+func (p *ddlParser) name() (string, bool) {
+	token := p.peek()
+	if token.kind != ddlWord && token.kind != ddlIdent {
+		return "", false
+	}
+	p.pos++
+	return token.text, true
+}
+
+// group 读取从当前左括号到匹配的右括号之间的词法单元，不含两端括号
+func (p *ddlParser) group() ([]ddlToken, error) {
+	if !p.acceptSymbol("(") {
+		return nil, &DDLError{Message: fmt.Sprintf("expected ( near %q", p.peek().raw)}
+	}
+	start, depth := p.pos, 1
+	for ; !p.done(); p.pos++ {
+		switch {
+		case p.isSymbol("("):
+			depth++
+		case p.isSymbol(")"):
+			depth--
+		}
+		if depth == 0 {
+			inner := p.tokens[start:p.pos]
+			p.pos++
+			return inner, nil
+		}
+	}
+	return nil, &DDLError{Message: "unbalanced parentheses"}
+}
+
+// splitDDLTokens 按最外层的逗号拆分词法单元
+func splitDDLTokens(tokens []ddlToken) [][]ddlToken {
+	var parts [][]ddlToken
+	start, depth := 0, 0
+	for i, token := range tokens {
+		if token.kind != ddlSymbol {
+			continue
+		}
+		switch token.text {
+		case "(":
+			depth++
+		case ")":
+			depth--
+		case ",":
+			if depth == 0 {
+				parts = append(parts, tokens[start:i])
+				start = i + 1
+			}
+		}
+	}
+	return append(parts, tokens[start:])
+}
+
+// ddlTable 解析CREATE TABLE语句的中间结果
+type ddlTable struct {
+	req         *CreateTableReq
+	primaryKey  []string
+	indexNames  map[string]bool
+	unsupported []UnsupportedClause
+}
+
+func (t *ddlTable) unsupport(tokens []ddlToken, reason string) {
+	t.unsupported = append(t.unsupported, UnsupportedClause{Clause: joinDDLTokens(tokens), Reason: reason})
+}
+
+// unsupportColumn 记录字段定义中不支持的属性，子句前加上字段名
+func (t *ddlTable) unsupportColumn(name string, tokens []ddlToken, reason string) {
+	t.unsupported = append(t.unsupported, UnsupportedClause{Clause: QuoteIdentifier(name) + " " + joinDDLTokens(tokens), Reason: reason})
+}
+
+// addIndex 添加索引，未命名的索引按MySQL的规则以第一个字段命名
+func (t *ddlTable) addIndex(index Index) {
+	if index.Name == "" {
+		index.Name = index.Fields[0]
+		for i := 2; t.indexNames[strings.ToLower(index.Name)]; i++ {
+			index.Name = fmt.Sprintf("%s_%d", index.Fields[0], i)
+		}
+	}
+	t.indexNames[strings.ToLower(index.Name)] = true
+	t.req.Indexes = append(t.req.Indexes, index)
+}
+
+// ParseCreateTable 解析CREATE TABLE语句为创建数据表请求，表名为语句中的表名
+// 平台不支持的子句不会导致解析失败，在返回的列表中说明；数据表使用数据库默认的存储引擎和字符集
+func ParseCreateTable(ddl string) (*CreateTableReq, []UnsupportedClause, error) {
+	tokens, err := tokenizeDDL(ddl)
+	if err != nil {
+		return nil, nil, err
+	}
+	for len(tokens) > 0 && tokens[len(tokens)-1].raw == ";" {
+		tokens = tokens[:len(tokens)-1]
+	}
+	p := &ddlParser{tokens: tokens}
+	t := &ddlTable{req: &CreateTableReq{}, indexNames: make(map[string]bool)}
+
+	if !p.acceptWord("CREATE") {
+		return nil, nil, &DDLError{Message: "statement must start with CREATE TABLE"}
+	}
+	if p.isWord("TEMPORARY") {
+		t.unsupport([]ddlToken{p.next()}, "临时表按普通数据表创建")
+	}
+	if !p.acceptWord("TABLE") {
+		return nil, nil, &DDLError{Message: "statement must start with CREATE TABLE"}
+	}
+	if p.acceptWord("IF") && !(p.acceptWord("NOT") && p.acceptWord("EXISTS")) {
+		return nil, nil, &DDLError{Message: "expected IF NOT EXISTS"}
+	}
+	name, ok := p.name()
+	if !ok {
+		return nil, nil, &DDLError{Message: "missing table name"}
+	}
+	// 去掉库名
+	if p.acceptSymbol(".") {
+		if name, ok = p.name(); !ok {
+			return nil, nil, &DDLError{Message: "missing table name"}
+		}
+	}
+	t.req.TableName = name
+	if p.isWord("LIKE", "AS", "SELECT") {
+		return nil, nil, &DDLError{Message: "CREATE TABLE ... LIKE and CREATE TABLE ... SELECT are not supported"}
+	}
+
+	body, err := p.group()
+	if err != nil {
+		return nil, nil, err
+	}
+	for _, definition := range splitDDLTokens(body) {
+		if len(definition) == 0 {
+			return nil, nil, &DDLError{Message: "empty column or index definition"}
+		}
+		if err := t.parseDefinition(definition); err != nil {
+			return nil, nil, err
+		}
+	}
+	if len(t.req.Fields) == 0 {
+		return nil, nil, &DDLError{Message: "table has no columns"}
+	}
+	// 字段名会拼接到建表语句中，加引号的字段名也只能使用字母、数字和下划线
+	for _, field := range t.req.Fields {
+		if err := ValidateFieldName(field.Name); err != nil {
+			return nil, nil, &DDLError{Message: err.Error()}
+		}
+	}
+	if err := t.parseTableOptions(p); err != nil {
+		return nil, nil, err
+	}
+
+	// 表级主键定义可以出现在字段定义之前
+	for _, name := range t.primaryKey {
+		field := t.field(name)
+		if field == nil {
+			return nil, nil, &DDLError{Message: fmt.Sprintf("primary key column %s does not exist", name)}
+		}
+		field.PrimaryKey = true
+	}
+	t.dropDanglingIndexes()
+	return t.req, t.unsupported, nil
+}
+
+// field 按字段名查找字段，不区分大小写
+func (t *ddlTable) field(name string) *Field {
+	for i := range t.req.Fields {
+		if strings.EqualFold(t.req.Fields[i].Name, name) {
+			return &t.req.Fields[i]
+		}
+	}
+	return nil
+}
+
+// dropDanglingIndexes 去掉引用了忽略字段的索引
+func (t *ddlTable) dropDanglingIndexes() {
+	indexes := t.req.Indexes[:0]
+	for _, index := range t.req.Indexes {
+		valid := true
+		for _, name := range index.Fields {
+			valid = valid && t.field(name) != nil
+		}
+		if valid {
+			indexes = append(indexes, index)
+			continue
+		}
+		t.unsupported = append(t.unsupported, UnsupportedClause{
+			Clause: fmt.Sprintf("INDEX %s (%s)", index.Name, strings.Join(index.Fields, ", ")),
+			Reason: "索引引用的字段未导入",
+		})
+	}
+	t.req.Indexes = indexes
+}
+
+// parseDefinition 解析括号中的一个字段、索引或约束定义
+func (t *ddlTable) parseDefinition(tokens []ddlToken) error {
+	p := &ddlParser{tokens: tokens}
+	if p.acceptWord("CONSTRAINT") {
+		if !p.isWord("PRIMARY", "UNIQUE", "FOREIGN", "CHECK") {
+			p.name()
+		}
+	}
+	switch {
+	case p.isWord("PRIMARY"):
+		p.next()
+		if !p.acceptWord("KEY") {
+			return &DDLError{Message: "expected PRIMARY KEY"}
+		}
+		index, err := t.parseIndex(p, tokens, false)
+		if err != nil || index == nil {
+			return err
+		}
+		t.primaryKey = index.Fields
+	case p.isWord("UNIQUE", "KEY", "INDEX", "FULLTEXT"):
+		indexType := IndexTypeIndex
+		if p.isWord("UNIQUE") {
+			indexType = IndexTypeUnique
+		} else if p.isWord("FULLTEXT") {
+			indexType = IndexTypeFulltext
+		}
+		if indexType != IndexTypeIndex {
+			p.next()
+			if !p.acceptWord("KEY") {
+				p.acceptWord("INDEX")
+			}
+		} else {
+			p.next()
+		}
+		index, err := t.parseIndex(p, tokens, true)
+		if err != nil || index == nil {
+			return err
+		}
+		index.Type = indexType
+		t.addIndex(*index)
+	case p.isWord("SPATIAL"):
+		t.unsupport(tokens, "空间索引不支持")
+	case p.isWord("FOREIGN"):
+		t.unsupport(tokens, "外键约束不支持，可使用引用字段")
+	case p.isWord("CHECK"):
+		t.unsupport(tokens, "检查约束不支持，可使用字段校验规则")
+	default:
+		return t.parseColumn(p, tokens)
+	}
+	return nil
+}
+
+// parseIndex 解析索引名、索引字段和索引选项，不支持的索引返回nil
+func (t *ddlTable) parseIndex(p *ddlParser, tokens []ddlToken, named bool) (*Index, error) {
+	index := &Index{}
+	if named && !p.isWord("USING") && !p.isSymbol("(") {
+		index.Name, _ = p.name()
+	}
+	if p.acceptWord("USING") {
+		p.next()
+	}
+	parts, err := p.group()
+	if err != nil {
+		return nil, err
+	}
+	for _, part := range splitDDLTokens(parts) {
+		pp := &ddlParser{tokens: part}
+		name, ok := pp.name()
+		if !ok {
+			t.unsupport(tokens, "函数索引不支持")
+			return nil, nil
+		}
+		if pp.isSymbol("(") {
+			t.unsupport(tokens, "前缀索引不支持")
+			return nil, nil
+		}
+		if pp.isWord("DESC") {
+			t.unsupport(part, "降序索引按升序创建")
+		}
+		index.Fields = append(index.Fields, name)
+	}
+
+	// 索引选项
+	for !p.done() {
+		switch {
+		case p.acceptWord("USING"), p.acceptWord("KEY_BLOCK_SIZE"):
+			p.acceptSymbol("=")
+			p.next()
+		case p.acceptWord("VISIBLE"):
+		case p.isWord("WITH"):
+			start := p.pos
+			p.next()
+			p.acceptWord("PARSER")
+			parser := p.next()
+			if strings.EqualFold(parser.text, IndexParserNgram) {
+				index.Parser = IndexParserNgram
+			} else {
+				t.unsupport(p.tokens[start:p.pos], "全文索引只支持ngram分词器")
+			}
+		default:
+			t.unsupport(p.tokens[p.pos:], "索引选项不支持")
+			p.pos = len(p.tokens)
+		}
+	}
+	return index, nil
+}
+
+// columnTypeAttrs 字段类型中可以出现的属性
+var columnTypeAttrs = []string{"UNSIGNED", "ZEROFILL", "BINARY"}
+
+// parseColumn 解析字段定义，生成列的字段不导入
+func (t *ddlTable) parseColumn(p *ddlParser, tokens []ddlToken) error {
+	name, ok := p.name()
+	if !ok {
+		return &DDLError{Message: fmt.Sprintf("invalid column definition: %s", joinDDLTokens(tokens))}
+	}
+	dataType := p.next()
+	if dataType.kind != ddlWord {
+		return &DDLError{Message: fmt.Sprintf("column %s has no data type", name)}
+	}
+	columnType := strings.ToLower(dataType.text)
+	if p.isSymbol("(") {
+		args, err := p.group()
+		if err != nil {
+			return err
+		}
+		values := make([]string, 0, len(args))
+		for _, arg := range splitDDLTokens(args) {
+			if len(arg) != 1 {
+				return &DDLError{Message: fmt.Sprintf("invalid data type of column %s", name)}
+			}
+			values = append(values, arg[0].raw)
+		}
+		columnType += "(" + strings.Join(values, ",") + ")"
+	}
+
+	field := Field{Name: name, Sort: len(t.req.Fields) + 1}
+	for !p.done() {
+		start := p.pos
+		switch {
+		case p.isWord(columnTypeAttrs...):
+			columnType += " " + strings.ToLower(p.next().text)
+		case p.isWord("CHARACTER", "CHARSET"):
+			if p.next(); p.isWord("SET") {
+				p.next()
+			}
+			columnType += " character set " + p.next().text
+		case p.acceptWord("COLLATE"):
+			columnType += " collate " + p.next().text
+		case p.acceptWord("NOT"):
+			if !p.acceptWord("NULL") {
+				return &DDLError{Message: fmt.Sprintf("expected NOT NULL of column %s", name)}
+			}
+			field.NotNull = true
+		case p.acceptWord("NULL"):
+		case p.acceptWord("DEFAULT"):
+			if err := t.parseDefault(p, &field, start); err != nil {
+				return err
+			}
+		case p.acceptWord("AUTO_INCREMENT"):
+			field.AutoIncrement = true
+		case p.isWord("PRIMARY", "KEY"):
+			p.acceptWord("PRIMARY")
+			p.acceptWord("KEY")
+			field.PrimaryKey = true
+		case p.acceptWord("UNIQUE"):
+			p.acceptWord("KEY")
+			t.addIndex(Index{Type: IndexTypeUnique, Fields: []string{name}})
+		case p.acceptWord("COMMENT"):
+			field.Comment = p.next().text
+		case p.acceptWord("VISIBLE"):
+		case p.isWord("GENERATED", "AS"):
+			t.unsupport(tokens, "生成列不支持，可使用计算字段")
+			return nil
+		case p.isWord("ON"):
+			p.next()
+			p.acceptWord("UPDATE")
+			p.next()
+			if p.isSymbol("(") {
+				p.group()
+			}
+			t.unsupportColumn(name, p.tokens[start:p.pos], "自动更新的字段取值不支持")
+		default:
+			reason := "字段属性不支持"
+			if p.isWord("REFERENCES") {
+				reason = "外键约束不支持，可使用引用字段"
+			} else if p.isWord("CHECK", "CONSTRAINT") {
+				reason = "检查约束不支持，可使用字段校验规则"
+			}
+			t.unsupportColumn(name, p.tokens[p.pos:], reason)
+			p.pos = len(p.tokens)
+		}
+	}
+	field.ColumnType = columnType
+	t.req.Fields = append(t.req.Fields, field)
+	return nil
+}
+
+// parseDefault 解析字段默认值，表达式默认值不支持
+func (t *ddlTable) parseDefault(p *ddlParser, field *Field, start int) error {
+	switch value := p.peek(); {
+	case value.kind == ddlString:
+		p.next()
+		field.Default = value.text
+	case value.kind == ddlNumber:
+		p.next()
+		field.Default = value.text
+	case value.raw == "-" || value.raw == "+":
+		p.next()
+		if number := p.next(); number.kind == ddlNumber {
+			field.Default = strings.TrimPrefix(value.raw, "+") + number.text
+		} else {
+			return &DDLError{Message: fmt.Sprintf("invalid default value of column %s", field.Name)}
+		}
+	case p.acceptWord("NULL"):
+	case p.acceptWord("TRUE"):
+		field.Default = "1"
+	case p.acceptWord("FALSE"):
+		field.Default = "0"
+	case p.isSymbol("("):
+		if _, err := p.group(); err != nil {
+			return err
+		}
+		t.unsupportColumn(field.Name, p.tokens[start:p.pos], "表达式默认值不支持")
+	case value.kind == ddlWord:
+		p.next()
+		if p.isSymbol("(") {
+			if _, err := p.group(); err != nil {
+				return err
+			}
+		} else if p.peek().kind == ddlString {
+			// 带字符集或进制前缀的字面量，如 _utf8mb4'a'、b'0'
+			p.next()
+		}
+		t.unsupportColumn(field.Name, p.tokens[start:p.pos], "表达式默认值不支持")
+	default:
+		return &DDLError{Message: fmt.Sprintf("invalid default value of column %s", field.Name)}
+	}
+	return nil
+}
+
+// parseTableOptions 解析右括号之后的表选项，表注释作为描述
+func (t *ddlTable) parseTableOptions(p *ddlParser) error {
+	for !p.done() {
+		if p.acceptSymbol(",") {
+			continue
+		}
+		start := p.pos
+		if p.isWord("PARTITION") {
+			t.unsupport(p.tokens[start:], "分区表不支持")
+			return nil
+		}
+		p.acceptWord("DEFAULT")
+		option := p.next()
+		if option.kind != ddlWord {
+			return &DDLError{Message: fmt.Sprintf("invalid table option near %q", option.raw)}
+		}
+		optionName := strings.ToUpper(option.text)
+		if optionName == "CHARACTER" {
+			p.acceptWord("SET")
+			optionName = "CHARSET"
+		}
+		p.acceptSymbol("=")
+		value := p.next()
+
+		switch optionName {
+		case "COMMENT":
+			t.req.Description = value.text
+		case "ENGINE":
+			if !strings.EqualFold(value.text, "InnoDB") {
+				t.unsupport(p.tokens[start:p.pos], "数据表使用InnoDB存储引擎")
+			}
+		case "AUTO_INCREMENT", "CHARSET", "COLLATE", "ROW_FORMAT":
+			// 自增起始值随数据确定，字符集和行格式使用数据库默认值
+		default:
+			t.unsupport(p.tokens[start:p.pos], "表选项不支持")
+		}
+	}
+	return nil
+}
@@ -0,0 +1,77 @@
+package model
+
+import (
+	"errors"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestParseCreateTable(t *testing.T) {
+	ddl := "CREATE TABLE IF NOT EXISTS `shop`.`t_order` (\n" +
+		"  `id` bigint unsigned NOT NULL AUTO_INCREMENT COMMENT '编号',\n" +
+		"  `code` varchar(32) CHARACTER SET utf8mb4 NOT NULL DEFAULT '' COMMENT 'it''s \\'code\\'',\n" +
+		"  `amount` decimal(10,2) DEFAULT -1.5,\n" +
+		"  `enabled` tinyint(1) DEFAULT TRUE,\n" +
+		"  `updated_at` datetime DEFAULT CURRENT_TIMESTAMP ON UPDATE CURRENT_TIMESTAMP,\n" +
+		"  `total` decimal(12,2) GENERATED ALWAYS AS (`amount` * 2) VIRTUAL,\n" +
+		"  PRIMARY KEY (`id`),\n" +
+		"  UNIQUE KEY `uk_code` (`code`),\n" +
+		"  KEY `idx_total` (`total`),\n" +
+		"  CONSTRAINT `fk_user` FOREIGN KEY (`code`) REFERENCES `users` (`code`)\n" +
+		") ENGINE=InnoDB AUTO_INCREMENT=10 DEFAULT CHARSET=utf8mb4 COMMENT='订单';"
+
+	table, unsupported, err := ParseCreateTable(ddl)
+	if !assert.NoError(t, err) {
+		return
+	}
+
+	assert.Equal(t, "t_order", table.TableName)
+	assert.Equal(t, "订单", table.Description)
+	if assert.Len(t, table.Fields, 5) {
+		id := table.Fields[0]
+		assert.Equal(t, "bigint unsigned", id.ColumnType)
+		assert.True(t, id.PrimaryKey && id.AutoIncrement && id.NotNull)
+		assert.Equal(t, "编号", id.Comment)
+
+		code := table.Fields[1]
+		assert.Equal(t, "varchar(32) character set utf8mb4", code.ColumnType)
+		assert.Equal(t, "it's 'code'", code.Comment)
+		assert.Equal(t, "", code.Default)
+
+		assert.Equal(t, "decimal(10,2)", table.Fields[2].ColumnType)
+		assert.Equal(t, "-1.5", table.Fields[2].Default)
+		assert.Equal(t, "1", table.Fields[3].Default)
+		assert.Equal(t, "updated_at", table.Fields[4].Name)
+	}
+	if assert.Len(t, table.Indexes, 1) {
+		assert.Equal(t, Index{Name: "uk_code", Type: IndexTypeUnique, Fields: []string{"code"}}, table.Indexes[0])
+	}
+
+	// 表达式默认值、自动更新、生成列、外键，以及引用了生成列的索引
+	assert.Len(t, unsupported, 5)
+}
+
+func TestParseCreateTableErrors(t *testing.T) {
+	cases := []struct {
+		name string
+		ddl  string
+	}{
+		{"不是建表语句", "DROP TABLE t"},
+		{"复制表结构", "CREATE TABLE t LIKE s"},
+		{"没有字段", "CREATE TABLE t ()"},
+		{"字符串未结束", "CREATE TABLE t (a varchar(10) DEFAULT 'x)"},
+		{"括号不匹配", "CREATE TABLE t (a int"},
+		{"主键字段不存在", "CREATE TABLE t (a int, PRIMARY KEY (b))"},
+		{"字段名不合法", "CREATE TABLE t (`a b` int)"},
+		{"字段名含引号", "CREATE TABLE t (`a``; DROP TABLE t; --` int)"},
+	}
+
+	for _, c := range cases {
+		t.Run(c.name, func(t *testing.T) {
+			_, _, err := ParseCreateTable(c.ddl)
+			var derr *DDLError
+			assert.True(t, errors.As(err, &derr), "expected DDLError, got %v", err)
+		})
+	}
+}
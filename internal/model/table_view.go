@@ -0,0 +1,70 @@
+package model
+
+import (
+	"fmt"
+
+	"github.com/iiwish/lingjian/pkg/utils"
+)
+
+// MaxViewPageSize 视图保存的每页数量上限，与查询接口一致
+const MaxViewPageSize = 1000
+
+// TableView 数据表视图，保存用户常用的查询条件和列表展示配置
+type TableView struct {
+	ID        uint             `db:"id" json:"id"`
+	AppID     uint             `db:"app_id" json:"app_id"`
+	TableID   uint             `db:"table_id" json:"table_id"`
+	Name      string           `db:"name" json:"name"`
+	Shared    bool             `db:"shared" json:"shared"` // 是否共享，未共享的视图仅创建人可见
+	Roles     string           `db:"roles" json:"roles"`   // 共享的角色编码，为空时共享给应用内所有用户
+	Config    string           `db:"config" json:"config"` // 视图配置，TableViewConfig的JSON
+	CreatedAt utils.CustomTime `db:"created_at" json:"created_at"`
+	CreatorID uint             `db:"creator_id" json:"creator_id"`
+	UpdatedAt utils.CustomTime `db:"updated_at" json:"updated_at"`
+	UpdaterID uint             `db:"updater_id" json:"updater_id"`
+}
+
+// TableViewConfig 视图保存的查询条件和列表展示配置
+type TableViewConfig struct {
+	Query         QueryCondition `json:"query"`                    // 查询条件
+	Sort          []OrderBy      `json:"sort,omitempty"`           // 排序，为空时使用查询条件中的order_by
+	PageSize      int            `json:"page_size,omitempty"`      // 每页数量，0表示使用默认值
+	HiddenColumns []string       `json:"hidden_columns,omitempty"` // 隐藏的字段，不出现在查询结果中
+	ColumnOrder   []string       `json:"column_order,omitempty"`   // 字段显示顺序
+}
+
+// TableViewReq 创建或更新视图请求参数
+type TableViewReq struct {
+	Name   string   `json:"name" binding:"required"` // 视图名称，同一用户在同一数据表下唯一
+	Shared bool     `json:"shared"`                  // 是否共享
+	Roles  []string `json:"roles,omitempty"`         // 共享的角色编码，为空时共享给应用内所有用户
+	TableViewConfig
+}
+
+// Validate 校验视图名称和配置中与表结构无关的部分
+func (r *TableViewReq) Validate() error {
+	if r.Name == "" || len([]rune(r.Name)) > 64 {
+		return &QueryError{Message: "view name must be 1 to 64 characters"}
+	}
+	if !r.Shared && len(r.Roles) > 0 {
+		return &QueryError{Message: "roles can only be set for shared views"}
+	}
+	if r.PageSize < 0 || r.PageSize > MaxViewPageSize {
+		return &QueryError{Message: fmt.Sprintf("page_size must be between 0 and %d", MaxViewPageSize)}
+	}
+	return nil
+}
+
+// TableViewResp 视图详情
+type TableViewResp struct {
+	ID       uint     `json:"id"`
+	TableID  uint     `json:"table_id"`
+	Name     string   `json:"name"`
+	Shared   bool     `json:"shared"`
+	Roles    []string `json:"roles"`
+	Editable bool     `json:"editable"` // 当前用户是否可以修改和删除视图，创建人和管理员可以修改
+	TableViewConfig
+	CreatorID uint             `json:"creator_id"`
+	CreatedAt utils.CustomTime `json:"created_at"`
+	UpdatedAt utils.CustomTime `json:"updated_at"`
+}
@@ -111,9 +111,9 @@ func (s *AppService) CreateApp(app *model.App, user_id uint) (*model.App, error)
 		return nil, err
 	}
 
-	// 创建系统菜单
+	// 创建系统菜单，维度表名加上应用前缀后为app{应用ID}_menu_system
 	sysMenuReq := &model.CreateMenuReq{
-		TableName:   "app" + strconv.FormatInt(appID, 10) + "_menu_system",
+		TableName:   "menu_system",
 		MenuName:    "系统",
 		Description: "系统菜单",
 		ParentID:    0,
@@ -35,8 +35,11 @@ func NewDimensionService(db *sqlx.DB) *DimensionService {
 	}
 }
 
-// CreateDimension 创建维度配置
+// CreateDimension 创建维度配置，请求中的表名为逻辑表名，数据库中的维度表名加上应用的命名空间前缀
 func (s *DimensionService) CreateDimension(req *model.CreateDimReq, creatorID uint, appID uint) (uint, error) {
+	if err := model.ValidateLogicalTableName(appID, req.TableName); err != nil {
+		return 0, err
+	}
 	dimType := "general"
 	if req.DimensionType != "" {
 		dimType = req.DimensionType
@@ -44,7 +47,8 @@ func (s *DimensionService) CreateDimension(req *model.CreateDimReq, creatorID ui
 	// 维度配置
 	dimDB := model.ConfigDimension{
 		AppID:         appID,
-		TableName:     req.TableName,
+		TableName:     model.PhysicalTableName(appID, req.TableName),
+		LogicalName:   req.TableName,
 		DisplayName:   req.DisplayName,
 		Description:   req.Description,
 		Status:        1,
@@ -62,22 +66,17 @@ func (s *DimensionService) CreateDimension(req *model.CreateDimReq, creatorID ui
 	defer tx.Rollback()
 
 	// 检查表名是否已存在
-	var count int
-	err = tx.Get(&count, "SELECT COUNT(*) FROM information_schema.tables WHERE table_schema = DATABASE() AND table_name = ?", req.TableName)
-	if err != nil {
-		return 0, fmt.Errorf("check table name failed: %v", err)
-	}
-	if count > 0 {
-		return 0, fmt.Errorf("table name already exists")
+	if err := checkTableName(tx, appID, req.TableName); err != nil {
+		return 0, err
 	}
 
 	// 插入维度配置
 	result, err := tx.NamedExec(`
 	INSERT INTO sys_config_dimensions (
-		app_id, table_name, display_name, description, dimension_type, status, 
+		app_id, table_name, logical_name, display_name, description, dimension_type, status, 
 		created_at, creator_id, updated_at, updater_id, custom_columns
 	) VALUES (
-		:app_id, :table_name, :display_name, :description, :dimension_type, :status, 
+		:app_id, :table_name, :logical_name, :display_name, :description, :dimension_type, :status, 
 		NOW(), :creator_id, NOW(), :creator_id, :custom_columns
 	)
 	`, dimDB)
@@ -105,7 +104,7 @@ func (s *DimensionService) CreateDimension(req *model.CreateDimReq, creatorID ui
 			level INT NOT NULL DEFAULT 0 COMMENT '层级',
 			sort INT NOT NULL DEFAULT 0 COMMENT '排序',
 			status TINYINT NOT NULL DEFAULT 1 COMMENT '状态',
-	`, req.TableName))
+	`, dimDB.TableName))
 
 	// 添加自定义列
 	for _, col := range req.CustomColumns {
@@ -154,7 +153,7 @@ func (s *DimensionService) CreateDimension(req *model.CreateDimReq, creatorID ui
 	// 添加权限
 	permission := &model.Permission{
 		Name:        req.DisplayName,
-		Code:        req.TableName,
+		Code:        dimDB.TableName,
 		Type:        "dim",
 		Path:        "",
 		Method:      "",
@@ -217,20 +216,20 @@ func (s *DimensionService) UpdateDimension(req *model.UpdateDimensionReq, update
 		return fmt.Errorf("get old dimension failed: %v", err)
 	}
 
-	// 对比数据表名是否有变化
-	if oldDim.TableName != req.TableName {
-		// 检查新表名是否已存在
-		var count int
-		err = tx.Get(&count, "SELECT COUNT(*) FROM information_schema.tables WHERE table_schema = DATABASE() AND table_name = ?", req.TableName)
-		if err != nil {
-			return fmt.Errorf("check table name failed: %v", err)
+	// 对比逻辑表名是否有变化，数据库中的维度表名改为应用命名空间中的新表名
+	tableName := oldDim.TableName
+	if oldDim.LogicalName != req.TableName {
+		// 检查新表名是否可用
+		if err := model.ValidateLogicalTableName(oldDim.AppID, req.TableName); err != nil {
+			return err
 		}
-		if count > 0 {
-			return fmt.Errorf("table name already exists")
+		if err := checkTableName(tx, oldDim.AppID, req.TableName); err != nil {
+			return err
 		}
 
 		// 修改数据表名
-		_, err = tx.Exec("RENAME TABLE " + oldDim.TableName + " TO " + req.TableName)
+		tableName = model.PhysicalTableName(oldDim.AppID, req.TableName)
+		_, err = tx.Exec("RENAME TABLE " + model.QuoteIdentifier(oldDim.TableName) + " TO " + model.QuoteIdentifier(tableName))
 		if err != nil {
 			return fmt.Errorf("rename table failed: %v", err)
 		}
@@ -250,7 +249,7 @@ func (s *DimensionService) UpdateDimension(req *model.UpdateDimensionReq, update
 			'level', 'sort', 'status', 'created_at', 'creator_id', 
 			'updated_at', 'updater_id'
 		)
-	`, req.TableName)
+	`, tableName)
 	if err != nil {
 		return fmt.Errorf("get columns failed: %v", err)
 	}
@@ -270,7 +269,7 @@ func (s *DimensionService) UpdateDimension(req *model.UpdateDimensionReq, update
 	// 删除不再需要的列
 	for colName := range currentColumns {
 		if _, exists := newColumns[colName]; !exists {
-			_, err = tx.Exec(fmt.Sprintf("ALTER TABLE %s DROP COLUMN %s", req.TableName, colName))
+			_, err = tx.Exec(fmt.Sprintf("ALTER TABLE %s DROP COLUMN %s", model.QuoteIdentifier(tableName), model.QuoteIdentifier(colName)))
 			if err != nil {
 				return fmt.Errorf("drop column failed: %v", err)
 			}
@@ -281,9 +280,9 @@ func (s *DimensionService) UpdateDimension(req *model.UpdateDimensionReq, update
 	for colName, col := range newColumns {
 		if !currentColumns[colName] {
 			colDef := fmt.Sprintf("ADD COLUMN %s VARCHAR(%d) NOT NULL DEFAULT '' COMMENT '%s'",
-				col.Name, col.Length, col.Comment)
+				model.QuoteIdentifier(col.Name), col.Length, col.Comment)
 
-			_, err = tx.Exec(fmt.Sprintf("ALTER TABLE %s %s", req.TableName, colDef))
+			_, err = tx.Exec(fmt.Sprintf("ALTER TABLE %s %s", model.QuoteIdentifier(tableName), colDef))
 			if err != nil {
 				return fmt.Errorf("add column failed: %v", err)
 			}
@@ -294,13 +293,14 @@ func (s *DimensionService) UpdateDimension(req *model.UpdateDimensionReq, update
 	_, err = tx.Exec(`
 		UPDATE sys_config_dimensions SET 
 			table_name = ?,
+			logical_name = ?,
 			display_name = ?, 
 			description = ?, 
 			custom_columns = ?,
 			updated_at = NOW(), 
 			updater_id = ?
 		WHERE id = ?
-	`, req.TableName, req.DisplayName, req.Description, toJSONString(req.CustomColumns), updaterID, req.ID)
+	`, tableName, req.TableName, req.DisplayName, req.Description, toJSONString(req.CustomColumns), updaterID, req.ID)
 	if err != nil {
 		return fmt.Errorf("update sys_config_dimensions failed: %v", err)
 	}
@@ -324,7 +324,8 @@ func (s *DimensionService) GetDimension(id uint) (*model.GetDimResp, error) {
 	result := model.GetDimResp{
 		ID:            dimension.ID,
 		AppID:         dimension.AppID,
-		TableName:     dimension.TableName,
+		TableName:     dimension.LogicalName,
+		PhysicalName:  dimension.TableName,
 		DisplayName:   dimension.DisplayName,
 		Description:   dimension.Description,
 		DimensionType: dimension.DimensionType,
@@ -365,7 +366,7 @@ func (s *DimensionService) DeleteDimension(id uint) error {
 	}
 
 	// 删除数据表
-	_, err = tx.Exec("DROP TABLE " + tableName)
+	_, err = tx.Exec("DROP TABLE " + model.QuoteIdentifier(tableName))
 	if err != nil {
 		return fmt.Errorf("drop table failed: %v", err)
 	}
@@ -435,7 +436,8 @@ func (s *DimensionService) GetDimensions(userID uint, appID uint, dimType string
 		result := model.GetDimResp{
 			ID:            dimension.ID,
 			AppID:         dimension.AppID,
-			TableName:     dimension.TableName,
+			TableName:     dimension.LogicalName,
+			PhysicalName:  dimension.TableName,
 			DisplayName:   dimension.DisplayName,
 			Description:   dimension.Description,
 			DimensionType: dimension.DimensionType,
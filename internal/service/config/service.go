@@ -34,12 +34,44 @@ func (s *ConfigService) CreateTable(table *model.CreateTableReq, creatorID uint,
 	return s.tableService.CreateTable(table, creatorID, appID)
 }
 
+func (s *ConfigService) AdoptTable(req *model.AdoptTableReq, creatorID uint, appID uint) (*model.AdoptTableResp, error) {
+	return s.tableService.AdoptTable(req, creatorID, appID)
+}
+
+func (s *ConfigService) CloneTable(tableID uint, req *model.CloneTableReq, creatorID uint, appID uint) (*model.CloneTableResp, error) {
+	return s.tableService.CloneTable(tableID, req, creatorID, appID)
+}
+
 func (s *ConfigService) UpdateTable(tableID uint, req *model.TableUpdateReq, updaterID uint, appID uint) error {
 	return s.tableService.UpdateTable(tableID, req, updaterID, appID)
 }
 
-func (s *ConfigService) DeleteTable(id uint) error {
-	return s.tableService.DeleteTable(id)
+func (s *ConfigService) DeleteTable(id uint, operatorID uint) error {
+	return s.tableService.DeleteTable(id, operatorID)
+}
+
+func (s *ConfigService) ListTrashTables(appID uint) ([]model.TrashTable, error) {
+	return s.tableService.ListTrashTables(appID)
+}
+
+func (s *ConfigService) RestoreTable(id uint, operatorID uint, appID uint) error {
+	return s.tableService.RestoreTable(id, operatorID, appID)
+}
+
+func (s *ConfigService) PurgeTrashTables(appID uint, retentionDays int) (int, error) {
+	return s.tableService.PurgeTrashTables(appID, retentionDays)
+}
+
+func (s *ConfigService) MigrateTableNamespace(tableID uint) (string, error) {
+	return s.tableService.MigrateTableNamespace(tableID)
+}
+
+func (s *ConfigService) ExportTable(id uint, format string) (*model.ExportTableResp, error) {
+	return s.tableService.ExportTable(id, format)
+}
+
+func (s *ConfigService) ImportTable(req *model.ImportTableReq, creatorID uint, appID uint) (*model.ImportTableResp, error) {
+	return s.tableService.ImportTable(req, creatorID, appID)
 }
 
 // 维度配置相关方法
@@ -59,6 +91,10 @@ func (s *ConfigService) DeleteDimension(id uint) error {
 	return s.dimensionService.DeleteDimension(id)
 }
 
+func (s *ConfigService) MigrateDimensionNamespace(dimID uint) (string, error) {
+	return s.dimensionService.MigrateDimensionNamespace(dimID)
+}
+
 func (s *ConfigService) GetDimensions(userID uint, appID uint, dimType string) ([]model.GetDimResp, error) {
 	return s.dimensionService.GetDimensions(userID, appID, dimType)
 }
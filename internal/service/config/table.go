@@ -1,11 +1,15 @@
 package config
 
 import (
+	"database/sql"
 	"fmt"
+	"regexp"
+	"sort"
 	"strings"
 
 	"github.com/iiwish/lingjian/internal/model"
 	"github.com/iiwish/lingjian/internal/service/element"
+	"github.com/iiwish/lingjian/pkg/utils"
 	"github.com/jmoiron/sqlx"
 )
 
@@ -35,11 +39,209 @@ func isNumericType(columnType string) bool {
 	return false
 }
 
+// softDeleteColumns 软删除托管字段定义
+var softDeleteColumns = []string{
+	model.ColumnDeletedAt + " DATETIME NULL DEFAULT NULL COMMENT '删除时间'",
+	model.ColumnDeletedBy + " BIGINT UNSIGNED NOT NULL DEFAULT 0 COMMENT '删除人ID'",
+}
+
+// softDeleteIndex 软删除托管索引定义
+const softDeleteIndex = "INDEX idx_deleted_at (" + model.ColumnDeletedAt + ")"
+
+// rowVersionColumn 乐观锁托管字段定义
+const rowVersionColumn = model.ColumnRowVersion + " BIGINT UNSIGNED NOT NULL DEFAULT 1 COMMENT '记录版本'"
+
+// checkManagedFields 检查字段是否与托管字段冲突
+func checkManagedFields(tableFunc *model.TableFunc, fieldNames []string) error {
+	for _, name := range fieldNames {
+		if tableFunc.SoftDelete && (name == model.ColumnDeletedAt || name == model.ColumnDeletedBy) {
+			return fmt.Errorf("column %s is managed by soft delete", name)
+		}
+		if tableFunc.Versioned && name == model.ColumnRowVersion {
+			return fmt.Errorf("column %s is managed by row versioning", name)
+		}
+	}
+	return nil
+}
+
+// withoutManagedColumns 去掉由功能配置生成的托管字段和索引，以及由字段primary_key表示的主键索引
+func withoutManagedColumns(tableFunc *model.TableFunc, fields []model.Field, indexes []model.Index) ([]model.Field, []model.Index) {
+	var resultFields []model.Field
+	for _, field := range fields {
+		if (tableFunc.SoftDelete && (field.Name == model.ColumnDeletedAt || field.Name == model.ColumnDeletedBy)) ||
+			(tableFunc.Versioned && field.Name == model.ColumnRowVersion) {
+			continue
+		}
+		resultFields = append(resultFields, field)
+	}
+	var resultIndexes []model.Index
+	for _, index := range indexes {
+		if index.Name == "PRIMARY" || (tableFunc.SoftDelete && index.Name == "idx_deleted_at") {
+			continue
+		}
+		resultIndexes = append(resultIndexes, index)
+	}
+	return resultFields, resultIndexes
+}
+
+// ensureSoftDeleteColumns 确保数据表存在软删除托管字段
+func ensureSoftDeleteColumns(tx *sqlx.Tx, tableName string) error {
+	var count int
+	err := tx.Get(&count, "SELECT COUNT(*) FROM information_schema.columns WHERE table_schema = DATABASE() AND table_name = ? AND column_name = ?", tableName, model.ColumnDeletedAt)
+	if err != nil {
+		return fmt.Errorf("check soft delete columns failed: %v", err)
+	}
+	if count > 0 {
+		return nil
+	}
+
+	alterSQL := "ALTER TABLE " + tableName
+	for _, col := range softDeleteColumns {
+		alterSQL += " ADD COLUMN " + col + ","
+	}
+	alterSQL += " ADD " + softDeleteIndex
+	_, err = tx.Exec(alterSQL)
+	if err != nil {
+		return fmt.Errorf("add soft delete columns failed: %v", err)
+	}
+	return nil
+}
+
+// ensureRowVersionColumn 确保数据表存在乐观锁托管字段
+func ensureRowVersionColumn(tx *sqlx.Tx, tableName string) error {
+	var count int
+	err := tx.Get(&count, "SELECT COUNT(*) FROM information_schema.columns WHERE table_schema = DATABASE() AND table_name = ? AND column_name = ?", tableName, model.ColumnRowVersion)
+	if err != nil {
+		return fmt.Errorf("check row version column failed: %v", err)
+	}
+	if count > 0 {
+		return nil
+	}
+
+	_, err = tx.Exec("ALTER TABLE " + tableName + " ADD COLUMN " + rowVersionColumn)
+	if err != nil {
+		return fmt.Errorf("add row version column failed: %v", err)
+	}
+	return nil
+}
+
+// toNameSet 将字段名列表转换为集合
+func toNameSet(names []string) map[string]bool {
+	set := make(map[string]bool, len(names))
+	for _, name := range names {
+		set[name] = true
+	}
+	return set
+}
+
+// getColumnNames 获取数据表的全部字段名
+func getColumnNames(q sqlx.Queryer, tableName string) ([]string, error) {
+	var names []string
+	err := sqlx.Select(q, &names, "SELECT COLUMN_NAME FROM information_schema.columns WHERE table_schema = DATABASE() AND table_name = ? ORDER BY ORDINAL_POSITION", tableName)
+	if err != nil {
+		return nil, fmt.Errorf("get columns failed: %v", err)
+	}
+	return names, nil
+}
+
+// loadFieldOptions 读取数据表的字段扩展配置
+func loadFieldOptions(tx *sqlx.Tx, tableID uint) (map[string]model.FieldOptions, error) {
+	var optionsStr string
+	err := tx.Get(&optionsStr, "SELECT IFNULL(field_options, '') FROM sys_config_tables WHERE id = ?", tableID)
+	if err != nil {
+		return nil, fmt.Errorf("get field options failed: %v", err)
+	}
+	return model.ParseFieldOptions(optionsStr)
+}
+
+// isVirtualField 判断字段是否为虚拟计算字段
+func isVirtualField(options map[string]model.FieldOptions, name string) bool {
+	opt, ok := options[name]
+	return ok && opt.IsVirtual()
+}
+
+// virtualFieldNames 获取全部虚拟计算字段名
+func virtualFieldNames(options map[string]model.FieldOptions) []string {
+	var names []string
+	for name, opt := range options {
+		if opt.IsVirtual() {
+			names = append(names, name)
+		}
+	}
+	sort.Strings(names)
+	return names
+}
+
+// updateFieldOptions 根据字段更新同步字段扩展配置
+func updateFieldOptions(tx *sqlx.Tx, appID uint, tableID uint, tableName string, options map[string]model.FieldOptions, updates []model.FieldUpdate) error {
+	for _, update := range updates {
+		switch update.UpdateType {
+		case model.UpdateTypeDrop:
+			delete(options, update.OldFieldName)
+		case model.UpdateTypeAdd, model.UpdateTypeModify:
+			if update.OldFieldName != "" && update.OldFieldName != update.Field.Name {
+				delete(options, update.OldFieldName)
+			}
+			if update.Field.FieldOptions.IsEmpty() {
+				delete(options, update.Field.Name)
+			} else {
+				options[update.Field.Name] = update.Field.FieldOptions
+			}
+		}
+	}
+
+	// 字段变更后重新校验，确保规则和公式引用的字段仍然存在
+	columnNames, err := getColumnNames(tx, tableName)
+	if err != nil {
+		return err
+	}
+	fieldNames := toNameSet(columnNames)
+	for _, name := range virtualFieldNames(options) {
+		if fieldNames[name] {
+			return fmt.Errorf("virtual field %s conflicts with existing column", name)
+		}
+		fieldNames[name] = true
+	}
+	if err := model.ValidateFieldOptions(options, fieldNames); err != nil {
+		return err
+	}
+	if err := checkFieldReferences(tx, appID, options); err != nil {
+		return err
+	}
+
+	_, err = tx.Exec("UPDATE sys_config_tables SET field_options = ? WHERE id = ?", toJSONString(options), tableID)
+	if err != nil {
+		return fmt.Errorf("update field options failed: %v", err)
+	}
+	return nil
+}
+
 // CreateTable 创建数据表配置
 func (s *TableService) CreateTable(tableinfo *model.CreateTableReq, creatorID uint, appID uint) (uint, error) {
+	fields := make([]*model.Field, 0, len(tableinfo.Fields))
+	for i := range tableinfo.Fields {
+		fields = append(fields, &tableinfo.Fields[i])
+	}
+	if err := resolveColumnTypes(s.db, creatorID, fields); err != nil {
+		return 0, err
+	}
+	return s.createTable(tableinfo, creatorID, appID)
+}
+
+// createTable 创建数据表、数据表配置和系统菜单，字段的数据库字段类型需已确定
+// 请求中的表名为逻辑表名，数据库中的表名加上应用的命名空间前缀
+func (s *TableService) createTable(tableinfo *model.CreateTableReq, creatorID uint, appID uint) (uint, error) {
+	if err := model.ValidateLogicalTableName(appID, tableinfo.TableName); err != nil {
+		return 0, err
+	}
+	if err := checkTableName(s.db, appID, tableinfo.TableName); err != nil {
+		return 0, err
+	}
+
 	var table model.ConfigTable
 	table.AppID = appID
-	table.TableName = tableinfo.TableName
+	table.TableName = model.PhysicalTableName(appID, tableinfo.TableName)
+	table.LogicalName = tableinfo.TableName
 	table.DisplayName = tableinfo.DisplayName
 	table.Description = tableinfo.Description
 	table.Status = 1
@@ -52,6 +254,56 @@ func (s *TableService) CreateTable(tableinfo *model.CreateTableReq, creatorID ui
 		table.Func = tableinfo.Func
 	}
 
+	// 解析功能配置
+	tableFunc, err := model.ParseTableFunc(table.Func)
+	if err != nil {
+		return 0, err
+	}
+	fieldNames := make([]string, 0, len(tableinfo.Fields))
+	for _, field := range tableinfo.Fields {
+		if err := model.ValidateFieldName(field.Name); err != nil {
+			return 0, err
+		}
+		fieldNames = append(fieldNames, field.Name)
+	}
+	if err := checkManagedFields(tableFunc, fieldNames); err != nil {
+		return 0, err
+	}
+	for i := range tableinfo.Indexes {
+		if err := tableinfo.Indexes[i].Validate(); err != nil {
+			return 0, err
+		}
+	}
+
+	// 收集字段扩展配置
+	fieldOptions := make(map[string]model.FieldOptions)
+	for _, field := range tableinfo.Fields {
+		if !field.FieldOptions.IsEmpty() {
+			fieldOptions[field.Name] = field.FieldOptions
+		}
+	}
+	if err := model.ValidateFieldOptions(fieldOptions, toNameSet(fieldNames)); err != nil {
+		return 0, err
+	}
+	table.FieldOptions = toJSONString(fieldOptions)
+
+	// 快速搜索字段、数据权限字段必须是数据表字段
+	columnNames := make(map[string]bool, len(tableinfo.Fields))
+	for _, field := range tableinfo.Fields {
+		if !field.FieldOptions.IsVirtual() {
+			columnNames[field.Name] = true
+		}
+	}
+	if err := checkFuncColumns(tableFunc, columnNames); err != nil {
+		return 0, err
+	}
+	if err := checkDataScopeDims(s.db, appID, tableFunc); err != nil {
+		return 0, err
+	}
+	if err := checkFieldReferences(s.db, appID, fieldOptions); err != nil {
+		return 0, err
+	}
+
 	// 开启事务
 	tx, err := s.db.Beginx()
 	if err != nil {
@@ -62,9 +314,9 @@ func (s *TableService) CreateTable(tableinfo *model.CreateTableReq, creatorID ui
 	// 插入数据表配置
 	result, err := tx.NamedExec(`
         INSERT INTO sys_config_tables (
-            app_id, table_name, display_name, description, func, status, created_at, creator_id, updated_at, updater_id
+            app_id, table_name, logical_name, display_name, description, func, field_options, status, created_at, creator_id, updated_at, updater_id
         ) VALUES (
-            :app_id, :table_name, :display_name, :description, :func, :status, NOW(), :creator_id, NOW(), :creator_id
+            :app_id, :table_name, :logical_name, :display_name, :description, :func, :field_options, :status, NOW(), :creator_id, NOW(), :creator_id
         )
     `, table)
 	if err != nil {
@@ -80,28 +332,24 @@ func (s *TableService) CreateTable(tableinfo *model.CreateTableReq, creatorID ui
 	// 构建创建数据表的SQL语句
 	createTableSQL := fmt.Sprintf(`
         CREATE TABLE %s (
-    `, table.TableName)
+    `, model.QuoteIdentifier(table.TableName))
 
-	firstField := true
+	// 多个主键字段时在字段之后定义联合主键
+	var primaryKeys []string
 	for _, field := range tableinfo.Fields {
-		fieldSQL := fmt.Sprintf("%s %s", field.Name, field.ColumnType)
-		if field.NotNull {
-			fieldSQL += " NOT NULL"
-		}
-		if field.AutoIncrement {
-			fieldSQL += " AUTO_INCREMENT"
-		}
-		if field.Default != "" {
-			if isNumericType(field.ColumnType) {
-				fieldSQL += fmt.Sprintf(" DEFAULT %s", field.Default) // 数值类型不加引号
-			} else {
-				fieldSQL += fmt.Sprintf(" DEFAULT '%s'", field.Default) // 非数值类型加引号
-			}
+		if field.PrimaryKey && !field.FieldOptions.IsVirtual() {
+			primaryKeys = append(primaryKeys, model.QuoteIdentifier(field.Name))
 		}
-		if field.Comment != "" {
-			fieldSQL += fmt.Sprintf(" COMMENT '%s'", field.Comment)
+	}
+
+	firstField := true
+	for _, field := range tableinfo.Fields {
+		// 虚拟计算字段在读取时计算，不创建数据表字段
+		if field.FieldOptions.IsVirtual() {
+			continue
 		}
-		if field.PrimaryKey {
+		fieldSQL := buildFieldSQL(field)
+		if field.PrimaryKey && len(primaryKeys) == 1 {
 			fieldSQL += " PRIMARY KEY"
 		}
 		if firstField {
@@ -112,9 +360,28 @@ func (s *TableService) CreateTable(tableinfo *model.CreateTableReq, creatorID ui
 		}
 	}
 
+	if len(primaryKeys) > 1 {
+		createTableSQL += ", PRIMARY KEY (" + strings.Join(primaryKeys, ", ") + ")"
+	}
+
+	// 添加软删除托管字段
+	if tableFunc.SoftDelete {
+		for _, col := range softDeleteColumns {
+			createTableSQL += ", " + col
+		}
+	}
+
+	// 添加乐观锁托管字段
+	if tableFunc.Versioned {
+		createTableSQL += ", " + rowVersionColumn
+	}
+
 	for _, index := range tableinfo.Indexes {
-		indexSQL := fmt.Sprintf("INDEX %s (%s)", index.Name, strings.Join(index.Fields, ", "))
-		createTableSQL += ", " + indexSQL
+		createTableSQL += ", " + buildIndexSQL(index)
+	}
+
+	if tableFunc.SoftDelete {
+		createTableSQL += ", " + softDeleteIndex
 	}
 
 	createTableSQL = strings.TrimSuffix(createTableSQL, ", ") + ")"
@@ -132,23 +399,31 @@ func (s *TableService) CreateTable(tableinfo *model.CreateTableReq, creatorID ui
 
 	fmt.Printf("table id: %d\n", id)
 	// 创建对应的系统菜单的menu
+	if err := s.createTableMenu(&table, uint(id), tableinfo.ParentID, creatorID); err != nil {
+		return 0, err
+	}
+
+	return uint(id), nil
+}
+
+// createTableMenu 创建数据表对应的系统菜单
+func (s *TableService) createTableMenu(table *model.ConfigTable, tableID uint, parentID uint, creatorID uint) error {
 	menu := &model.CreateMenuItemReq{
-		ParentID:    tableinfo.ParentID,
+		ParentID:    parentID,
 		MenuName:    table.DisplayName,
-		MenuCode:    table.TableName,
+		MenuCode:    table.LogicalName,
 		Description: table.Description,
 		MenuType:    2, // 表示table类型
 		Status:      1,
 		IconPath:    "table",
-		SourceID:    uint(id),
+		SourceID:    tableID,
 	}
 
-	err = s.menuService.CreateSysMenu(table.AppID, creatorID, menu)
+	err := s.menuService.CreateSysMenu(table.AppID, creatorID, menu)
 	if err != nil {
-		return 0, fmt.Errorf("create menu failed: %v", err)
+		return fmt.Errorf("create menu failed: %v", err)
 	}
-
-	return uint(id), nil
+	return nil
 }
 
 // UpdateTable 统一的更新数据表配置方法
@@ -160,33 +435,68 @@ func (s *TableService) UpdateTable(tableID uint, req *model.TableUpdateReq, upda
 	}
 	defer tx.Rollback()
 
-	// 获取原数据表名称
-	var oldTableName string
-	err = tx.Get(&oldTableName, "SELECT table_name FROM sys_config_tables WHERE id = ?", tableID)
+	// 获取原数据表名称，修改逻辑表名时数据库表名改为应用命名空间中的新表名
+	var current model.ConfigTable
+	err = tx.Get(&current, "SELECT app_id, table_name, logical_name FROM sys_config_tables WHERE id = ? AND deleted_at IS NULL", tableID)
 	if err != nil {
 		return fmt.Errorf("get table name failed: %v", err)
 	}
+	oldTableName, newTableName := current.TableName, current.TableName
+	renamed := req.TableName != "" && req.TableName != current.LogicalName
+	if renamed {
+		newTableName = model.PhysicalTableName(current.AppID, req.TableName)
+	}
+	// DDL 会隐式提交，无论成功与否都清除表结构缓存和查询结果缓存
+	defer element.InvalidateTableSchema(oldTableName, newTableName, model.ArchiveTableName(oldTableName), model.ArchiveTableName(newTableName))
+	defer element.InvalidateTableCache(tableID)
+
+	// 解析功能配置
+	tableFunc, err := model.ParseTableFunc(req.Func)
+	if err != nil {
+		return err
+	}
+	var fieldNames []string
+	var fields []*model.Field
+	for i, update := range req.Fields {
+		if update.UpdateType == model.UpdateTypeDrop {
+			fieldNames = append(fieldNames, update.OldFieldName)
+		} else {
+			fieldNames = append(fieldNames, update.Field.Name)
+			fields = append(fields, &req.Fields[i].Field)
+		}
+	}
+	if err := resolveColumnTypes(tx, updaterID, fields); err != nil {
+		return err
+	}
+	if err := checkManagedFields(tableFunc, fieldNames); err != nil {
+		return err
+	}
+	for i := range req.Indexes {
+		if req.Indexes[i].UpdateType == model.UpdateTypeDrop {
+			continue
+		}
+		if err := req.Indexes[i].Index.Validate(); err != nil {
+			return err
+		}
+	}
 
 	// 1. 更新基本信息
-	if req.TableName != "" && req.TableName != oldTableName {
-		// 检查新表名是否已存在
-		var count int
-		err = tx.Get(&count, "SELECT COUNT(*) FROM information_schema.tables WHERE table_schema = DATABASE() AND table_name = ?", req.TableName)
-		if err != nil {
-			return fmt.Errorf("check table name failed: %v", err)
+	if renamed {
+		// 检查新表名是否可用
+		if err := model.ValidateLogicalTableName(current.AppID, req.TableName); err != nil {
+			return err
 		}
-		if count > 0 {
-			return fmt.Errorf("table name already exists")
+		if err := checkTableName(tx, current.AppID, req.TableName); err != nil {
+			return err
 		}
 
-		// 修改数据表名称
-		_, err = tx.Exec("RENAME TABLE " + oldTableName + " TO " + req.TableName)
-		if err != nil {
-			return fmt.Errorf("rename table failed: %v", err)
+		// 修改数据表名称，归档表一起改名
+		if err := renameTables(tx, oldTableName, newTableName); err != nil {
+			return err
 		}
 
 		// 更新数据表配置
-		_, err = tx.Exec("UPDATE sys_config_tables SET table_name = ? WHERE id = ?", req.TableName, tableID)
+		_, err = tx.Exec("UPDATE sys_config_tables SET table_name = ?, logical_name = ? WHERE id = ?", newTableName, req.TableName, tableID)
 		if err != nil {
 			return fmt.Errorf("update sys_config_tables failed: %v", err)
 		}
@@ -213,14 +523,35 @@ func (s *TableService) UpdateTable(tableID uint, req *model.TableUpdateReq, upda
 
 	// 2. 更新字段信息
 	if len(req.Fields) > 0 {
-		tableName := req.TableName
-		if tableName == "" {
-			tableName = oldTableName
+		tableName := newTableName
+
+		// 读取原字段扩展配置，用于识别虚拟计算字段
+		fieldOptions, err := loadFieldOptions(tx, tableID)
+		if err != nil {
+			return err
 		}
 
 		for _, update := range req.Fields {
+			oldName := update.OldFieldName
+			if oldName == "" {
+				oldName = update.Field.Name
+			}
+			if err := model.ValidateFieldName(oldName); err != nil {
+				return err
+			}
+			if update.UpdateType != model.UpdateTypeDrop {
+				if err := model.ValidateFieldName(update.Field.Name); err != nil {
+					return err
+				}
+			}
+			oldVirtual := isVirtualField(fieldOptions, oldName)
+			newVirtual := update.Field.FieldOptions.IsVirtual()
+
 			switch update.UpdateType {
 			case model.UpdateTypeAdd:
+				if newVirtual {
+					continue
+				}
 				// 添加字段
 				fieldSQL := buildFieldSQL(update.Field)
 				_, err = tx.Exec("ALTER TABLE " + tableName + " ADD COLUMN " + fieldSQL)
@@ -228,53 +559,89 @@ func (s *TableService) UpdateTable(tableID uint, req *model.TableUpdateReq, upda
 					return fmt.Errorf("add column failed: %v", err)
 				}
 			case model.UpdateTypeDrop:
+				if oldVirtual {
+					continue
+				}
 				// 删除字段
-				_, err = tx.Exec("ALTER TABLE " + tableName + " DROP COLUMN " + update.OldFieldName)
+				_, err = tx.Exec("ALTER TABLE " + tableName + " DROP COLUMN " + model.QuoteIdentifier(update.OldFieldName))
 				if err != nil {
 					return fmt.Errorf("drop column failed: %v", err)
 				}
 			case model.UpdateTypeModify:
-				// 修改字段
-				fieldSQL := buildFieldSQL(update.Field)
-				_, err = tx.Exec("ALTER TABLE " + tableName + " MODIFY COLUMN " + fieldSQL)
-				if err != nil {
-					return fmt.Errorf("modify column failed: %v", err)
+				switch {
+				case oldVirtual && newVirtual:
+					continue
+				case oldVirtual:
+					// 虚拟字段改为实际字段
+					_, err = tx.Exec("ALTER TABLE " + tableName + " ADD COLUMN " + buildFieldSQL(update.Field))
+					if err != nil {
+						return fmt.Errorf("add column failed: %v", err)
+					}
+				case newVirtual:
+					// 实际字段改为虚拟字段
+					_, err = tx.Exec("ALTER TABLE " + tableName + " DROP COLUMN " + model.QuoteIdentifier(oldName))
+					if err != nil {
+						return fmt.Errorf("drop column failed: %v", err)
+					}
+				default:
+					// 修改字段
+					fieldSQL := buildFieldSQL(update.Field)
+					_, err = tx.Exec("ALTER TABLE " + tableName + " MODIFY COLUMN " + fieldSQL)
+					if err != nil {
+						return fmt.Errorf("modify column failed: %v", err)
+					}
 				}
 			}
 		}
+
+		// 同步字段扩展配置
+		if err := updateFieldOptions(tx, current.AppID, tableID, tableName, fieldOptions, req.Fields); err != nil {
+			return err
+		}
+	}
+
+	// 启用软删除时补充托管字段
+	if tableFunc.SoftDelete {
+		tableName := newTableName
+		if err := ensureSoftDeleteColumns(tx, tableName); err != nil {
+			return err
+		}
+	}
+
+	// 启用乐观锁时补充托管字段
+	if tableFunc.Versioned {
+		tableName := newTableName
+		if err := ensureRowVersionColumn(tx, tableName); err != nil {
+			return err
+		}
 	}
 
 	// 3. 更新索引信息
 	if len(req.Indexes) > 0 {
-		tableName := req.TableName
-		if tableName == "" {
-			tableName = oldTableName
-		}
+		tableName := newTableName
 
 		for _, update := range req.Indexes {
 			switch update.UpdateType {
 			case model.UpdateTypeAdd:
 				// 添加索引
-				indexSQL := fmt.Sprintf("CREATE INDEX %s ON %s (%s)", update.Index.Name, tableName, strings.Join(update.Index.Fields, ", "))
-				_, err = tx.Exec(indexSQL)
+				_, err = tx.Exec("ALTER TABLE " + tableName + " ADD " + buildIndexSQL(update.Index))
 				if err != nil {
 					return fmt.Errorf("add index failed: %v", err)
 				}
 			case model.UpdateTypeDrop:
 				// 删除索引
-				_, err = tx.Exec("DROP INDEX " + update.OldIndexName + " ON " + tableName)
+				_, err = tx.Exec("DROP INDEX " + model.QuoteIdentifier(update.OldIndexName) + " ON " + tableName)
 				if err != nil {
 					return fmt.Errorf("drop index failed: %v", err)
 				}
 			case model.UpdateTypeModify:
 				// 修改索引
-				_, err = tx.Exec("DROP INDEX " + update.OldIndexName + " ON " + tableName)
+				_, err = tx.Exec("DROP INDEX " + model.QuoteIdentifier(update.OldIndexName) + " ON " + tableName)
 				if err != nil {
 					return fmt.Errorf("drop index failed: %v", err)
 				}
 
-				indexSQL := fmt.Sprintf("CREATE INDEX %s ON %s (%s)", update.Index.Name, tableName, strings.Join(update.Index.Fields, ", "))
-				_, err = tx.Exec(indexSQL)
+				_, err = tx.Exec("ALTER TABLE " + tableName + " ADD " + buildIndexSQL(update.Index))
 				if err != nil {
 					return fmt.Errorf("add index failed: %v", err)
 				}
@@ -282,6 +649,21 @@ func (s *TableService) UpdateTable(tableID uint, req *model.TableUpdateReq, upda
 		}
 	}
 
+	// 检查快速搜索字段、数据权限字段和归档字段
+	if len(tableFunc.SearchCols) > 0 || len(tableFunc.DataScopes) > 0 || tableFunc.Archive != nil {
+		tableName := newTableName
+		columnNames, err := getColumnNames(tx, tableName)
+		if err != nil {
+			return err
+		}
+		if err := checkFuncColumns(tableFunc, toNameSet(columnNames)); err != nil {
+			return err
+		}
+		if err := checkDataScopeDims(tx, current.AppID, tableFunc); err != nil {
+			return err
+		}
+	}
+
 	// 提交事务
 	if err := tx.Commit(); err != nil {
 		return fmt.Errorf("commit transaction failed: %v", err)
@@ -295,10 +677,11 @@ func (s *TableService) GetTable(id uint) (*model.CreateTableReq, error) {
 	var table model.ConfigTable
 	query := `
         SELECT 
-            id, app_id, table_name, display_name, description, 
-            IFNULL(func, "") AS func, status, created_at, creator_id, updated_at, updater_id 
+            id, app_id, table_name, logical_name, display_name, description, 
+            IFNULL(func, "") AS func, IFNULL(field_options, "") AS field_options,
+            status, created_at, creator_id, updated_at, updater_id 
         FROM sys_config_tables 
-        WHERE id = ?
+        WHERE id = ? AND deleted_at IS NULL
     `
 	err := s.db.Get(&table, query, id)
 	if err != nil {
@@ -306,14 +689,52 @@ func (s *TableService) GetTable(id uint) (*model.CreateTableReq, error) {
 	}
 
 	var tableInfo model.CreateTableReq
-	tableInfo.TableName = table.TableName
+	tableInfo.TableName = table.LogicalName
+	tableInfo.PhysicalName = table.TableName
 	tableInfo.DisplayName = table.DisplayName
 	tableInfo.Description = table.Description
 	tableInfo.Func = table.Func
 
 	// 根据数据表名称获取字段信息
+	fields, err := loadTableFields(s.db, table.TableName)
+	if err != nil {
+		return nil, err
+	}
+
+	// 合并字段扩展配置
+	fieldOptions, err := model.ParseFieldOptions(table.FieldOptions)
+	if err != nil {
+		return nil, err
+	}
+	for i := range fields {
+		if opt, ok := fieldOptions[fields[i].Name]; ok {
+			fields[i].FieldOptions = opt
+		}
+	}
+	// 虚拟计算字段没有数据表字段，排在实际字段之后
+	for _, name := range virtualFieldNames(fieldOptions) {
+		fields = append(fields, model.Field{
+			Name:         name,
+			Sort:         len(fields) + 1,
+			FieldOptions: fieldOptions[name],
+		})
+	}
+
+	tableInfo.Fields = append(tableInfo.Fields, fields...)
+
+	// 根据数据表名称获取索引信息
+	tableInfo.Indexes, err = loadTableIndexes(s.db, table.TableName)
+	if err != nil {
+		return nil, err
+	}
+
+	return &tableInfo, nil
+}
+
+// loadTableFields 从information_schema读取数据表的字段信息
+func loadTableFields(q sqlx.Queryer, tableName string) ([]model.Field, error) {
 	var fields []model.Field
-	query = "SELECT " +
+	query := "SELECT " +
 		"`COLUMN_NAME` AS `name`, " +
 		"IFNULL(`COLUMN_COMMENT`, '') AS `comment`, " +
 		"`COLUMN_TYPE` AS `column_type`, " +
@@ -323,43 +744,73 @@ func (s *TableService) GetTable(id uint) (*model.CreateTableReq, error) {
 		"(`IS_NULLABLE` = 'NO') AS `not_null`, " +
 		"IFNULL(`COLUMN_DEFAULT`, '') AS `default`" +
 		"FROM `information_schema`.`columns` " +
-		"WHERE `TABLE_SCHEMA` = DATABASE() AND `TABLE_NAME` = ?"
-	err = s.db.Select(&fields, query, table.TableName)
+		"WHERE `TABLE_SCHEMA` = DATABASE() AND `TABLE_NAME` = ? " +
+		"ORDER BY ORDINAL_POSITION"
+	err := sqlx.Select(q, &fields, query, tableName)
 	if err != nil {
 		return nil, fmt.Errorf("get fields failed: %v", err)
 	}
+	return fields, nil
+}
 
-	tableInfo.Fields = append(tableInfo.Fields, fields...)
-
-	// 根据数据表名称获取索引信息
+// loadTableIndexes 通过SHOW INDEX读取数据表的索引信息
+func loadTableIndexes(q sqlx.Queryer, tableName string) ([]model.Index, error) {
 	var indexes []model.MySQLIndex
-	err = s.db.Select(&indexes, "SHOW INDEX FROM "+table.TableName)
+	err := sqlx.Select(q, &indexes, "SHOW INDEX FROM "+model.QuoteIdentifier(tableName))
 	if err != nil {
 		return nil, fmt.Errorf("get indexes failed: %v", err)
 	}
 
-	indexMap := make(map[string]*model.Index)
+	var result []model.Index
+	indexMap := make(map[string]int)
 	for _, index := range indexes {
-		if idx, ok := indexMap[index.KeyName]; ok {
-			idx.Fields = append(idx.Fields, index.ColumnName)
-		} else {
-			indexMap[index.KeyName] = &model.Index{
-				Name:   index.KeyName,
-				Type:   index.IndexType,
-				Fields: []string{index.ColumnName},
-			}
+		if i, ok := indexMap[index.KeyName]; ok {
+			result[i].Fields = append(result[i].Fields, index.ColumnName)
+			continue
+		}
+		indexType := model.IndexTypeIndex
+		if index.IndexType == model.IndexTypeFulltext {
+			indexType = model.IndexTypeFulltext
+		} else if index.NonUnique == 0 {
+			indexType = model.IndexTypeUnique
 		}
+		indexMap[index.KeyName] = len(result)
+		result = append(result, model.Index{
+			Name:   index.KeyName,
+			Type:   indexType,
+			Fields: []string{index.ColumnName},
+		})
+	}
+	return result, nil
+}
+
+// dropTable 彻底删除数据表及其附件、归档表、配置、视图、编号计数器、操作记录、归档日志和菜单，用于清理回收站和克隆失败的补偿
+// DROP语句会隐式提交事务，因此先删除附件和数据表，最后在事务中删除配置，中途失败时配置仍在，可以重新删除
+func (s *TableService) dropTable(id uint) error {
+	var table struct {
+		TableName string `db:"table_name"`
+		AppID     uint   `db:"app_id"`
 	}
+	err := s.db.Get(&table, "SELECT table_name, app_id FROM sys_config_tables WHERE id = ?", id)
+	if err != nil {
+		return fmt.Errorf("get table name failed: %v", err)
+	}
+	archiveName := model.ArchiveTableName(table.TableName)
+	defer element.InvalidateTableSchema(table.TableName, archiveName)
 
-	for _, idx := range indexMap {
-		tableInfo.Indexes = append(tableInfo.Indexes, *idx)
+	// 删除附件文件和附件记录
+	if err := element.DeleteTableAttachments(s.db, id); err != nil {
+		return err
 	}
 
-	return &tableInfo, nil
-}
+	// 先删除归档表再删除数据表
+	if _, err := s.db.Exec("DROP TABLE IF EXISTS " + model.QuoteIdentifier(archiveName)); err != nil {
+		return fmt.Errorf("drop archive table failed: %v", err)
+	}
+	if _, err := s.db.Exec("DROP TABLE IF EXISTS " + model.QuoteIdentifier(table.TableName)); err != nil {
+		return fmt.Errorf("drop table failed: %v", err)
+	}
 
-// DeleteTable 删除数据表配置
-func (s *TableService) DeleteTable(id uint) error {
 	// 开启事务
 	tx, err := s.db.Beginx()
 	if err != nil {
@@ -367,23 +818,18 @@ func (s *TableService) DeleteTable(id uint) error {
 	}
 	defer tx.Rollback()
 
-	// 获取数据表名称
-	var tableName string
-	err = tx.Get(&tableName, "SELECT table_name FROM sys_config_tables WHERE id = ?", id)
-	if err != nil {
-		return fmt.Errorf("get table name failed: %v", err)
+	for _, name := range []string{"sys_table_views", "sys_table_sequences", "sys_table_operations", "sys_table_archive_logs"} {
+		if _, err := tx.Exec("DELETE FROM "+name+" WHERE table_id = ?", id); err != nil {
+			return fmt.Errorf("delete %s failed: %v", name, err)
+		}
 	}
-
-	// 直接删除数据表
-	_, err = tx.Exec("DELETE FROM sys_config_tables WHERE id = ?", id)
+	_, err = tx.Exec(fmt.Sprintf("DELETE FROM %s WHERE menu_type = ? AND source_id = ?", systemMenuTable(table.AppID)),
+		tableMenuType, utils.Uint2String(id))
 	if err != nil {
-		return fmt.Errorf("delete table failed: %v", err)
+		return fmt.Errorf("delete table menu failed: %v", err)
 	}
-
-	// 删除数据表
-	_, err = tx.Exec("DROP TABLE IF EXISTS " + tableName)
-	if err != nil {
-		return fmt.Errorf("drop table failed: %v", err)
+	if _, err := tx.Exec("DELETE FROM sys_config_tables WHERE id = ?", id); err != nil {
+		return fmt.Errorf("delete table failed: %v", err)
 	}
 
 	// 提交事务
@@ -394,9 +840,112 @@ func (s *TableService) DeleteTable(id uint) error {
 	return nil
 }
 
-// buildFieldSQL 构建字段的 SQL 语句
+// checkFuncColumns 检查功能配置引用的快速搜索字段、数据权限字段和归档字段是否为数据表字段
+func checkFuncColumns(tableFunc *model.TableFunc, columnNames map[string]bool) error {
+	for _, col := range tableFunc.SearchCols {
+		if !columnNames[col] {
+			return fmt.Errorf("search column %s is not a table column", col)
+		}
+	}
+	for _, scope := range tableFunc.DataScopes {
+		if !columnNames[scope.Field] {
+			return fmt.Errorf("data scope column %s is not a table column", scope.Field)
+		}
+	}
+	if tableFunc.Archive != nil && !columnNames[tableFunc.Archive.Column] {
+		return fmt.Errorf("archive column %s is not a table column", tableFunc.Archive.Column)
+	}
+	return nil
+}
+
+// checkDataScopeDims 检查数据权限引用的维度是否存在且属于数据表所在应用
+func checkDataScopeDims(q sqlx.Queryer, appID uint, tableFunc *model.TableFunc) error {
+	for _, scope := range tableFunc.DataScopes {
+		var count int
+		err := sqlx.Get(q, &count, "SELECT COUNT(*) FROM sys_config_dimensions WHERE id = ? AND app_id = ?", scope.DimID, appID)
+		if err != nil {
+			return fmt.Errorf("check data scope dimension failed: %v", err)
+		}
+		if count == 0 {
+			return fmt.Errorf("dimension %d of data scope %s does not exist", scope.DimID, scope.Field)
+		}
+	}
+	return nil
+}
+
+// checkFieldReferences 检查引用字段引用的维度、数据表以及匹配列和显示列是否存在，维度和数据表需属于数据表所在应用
+func checkFieldReferences(q sqlx.Queryer, appID uint, options map[string]model.FieldOptions) error {
+	for name, opt := range options {
+		ref := opt.Reference
+		if ref == nil {
+			continue
+		}
+		if ref.Type == model.RefDimension {
+			var count int
+			err := sqlx.Get(q, &count, "SELECT COUNT(*) FROM sys_config_dimensions WHERE id = ? AND app_id = ?", ref.DimID, appID)
+			if err != nil {
+				return fmt.Errorf("check reference dimension failed: %v", err)
+			}
+			if count == 0 {
+				return fmt.Errorf("dimension %d referenced by field %s does not exist", ref.DimID, name)
+			}
+			continue
+		}
+
+		var tableName string
+		err := sqlx.Get(q, &tableName, "SELECT table_name FROM sys_config_tables WHERE id = ? AND app_id = ? AND deleted_at IS NULL", ref.TableID, appID)
+		if err == sql.ErrNoRows {
+			return fmt.Errorf("table %d referenced by field %s does not exist", ref.TableID, name)
+		}
+		if err != nil {
+			return fmt.Errorf("check reference table failed: %v", err)
+		}
+		columnNames, err := getColumnNames(q, tableName)
+		if err != nil {
+			return err
+		}
+		columns := toNameSet(columnNames)
+		for _, col := range []string{ref.Key, ref.Label} {
+			if col != "" && !columns[col] {
+				return fmt.Errorf("column %s referenced by field %s does not exist in table %s", col, name, tableName)
+			}
+		}
+	}
+	return nil
+}
+
+// buildIndexSQL 构建索引定义的 SQL 语句，索引需先通过 Validate 校验
+func buildIndexSQL(index model.Index) string {
+	fields := make([]string, len(index.Fields))
+	for i, field := range index.Fields {
+		fields[i] = model.QuoteIdentifier(field)
+	}
+
+	indexSQL := fmt.Sprintf("INDEX %s (%s)", model.QuoteIdentifier(index.Name), strings.Join(fields, ", "))
+	switch index.Type {
+	case model.IndexTypeUnique:
+		indexSQL = "UNIQUE " + indexSQL
+	case model.IndexTypeFulltext:
+		indexSQL = "FULLTEXT " + indexSQL
+		if index.Parser != "" {
+			indexSQL += " WITH PARSER " + index.Parser
+		}
+	}
+	return indexSQL
+}
+
+// numericDefaultRegexp 可不加引号拼接到DDL中的数字默认值
+var numericDefaultRegexp = regexp.MustCompile(`^-?\d+(\.\d+)?$`)
+
+// quoteLiteral 将字符串转义为SQL字符串字面量
+func quoteLiteral(value string) string {
+	return "'" + strings.NewReplacer(`\`, `\\`, "'", "''").Replace(value) + "'"
+}
+
+// buildFieldSQL 构建字段的 SQL 语句，字段名需先通过 ValidateFieldName 校验
+// 数值类型的数字默认值不加引号，其余默认值和注释按字符串字面量转义
 func buildFieldSQL(field model.Field) string {
-	fieldSQL := fmt.Sprintf("%s %s", field.Name, field.ColumnType)
+	fieldSQL := fmt.Sprintf("%s %s", model.QuoteIdentifier(field.Name), field.ColumnType)
 	if field.NotNull {
 		fieldSQL += " NOT NULL"
 	}
@@ -404,10 +953,14 @@ func buildFieldSQL(field model.Field) string {
 		fieldSQL += " AUTO_INCREMENT"
 	}
 	if field.Default != "" {
-		fieldSQL += fmt.Sprintf(" DEFAULT '%s'", field.Default)
+		if isNumericType(field.ColumnType) && numericDefaultRegexp.MatchString(field.Default) {
+			fieldSQL += " DEFAULT " + field.Default
+		} else {
+			fieldSQL += " DEFAULT " + quoteLiteral(field.Default)
+		}
 	}
 	if field.Comment != "" {
-		fieldSQL += fmt.Sprintf(" COMMENT '%s'", field.Comment)
+		fieldSQL += " COMMENT " + quoteLiteral(field.Comment)
 	}
 	return fieldSQL
 }
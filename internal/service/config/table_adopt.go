@@ -0,0 +1,190 @@
+package config
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/iiwish/lingjian/internal/model"
+	"github.com/iiwish/lingjian/internal/service/element"
+)
+
+// unsupportedTypes 平台不支持读写的字段类型及原因
+var unsupportedTypes = map[string]string{
+	"bit":                "位类型读取结果为字节，无法正确显示和写入",
+	"binary":             "二进制类型无法正确显示和写入",
+	"varbinary":          "二进制类型无法正确显示和写入",
+	"tinyblob":           "二进制类型无法正确显示和写入",
+	"blob":               "二进制类型无法正确显示和写入",
+	"mediumblob":         "二进制类型无法正确显示和写入",
+	"longblob":           "二进制类型无法正确显示和写入",
+	"geometry":           "空间类型无法正确显示和写入",
+	"point":              "空间类型无法正确显示和写入",
+	"linestring":         "空间类型无法正确显示和写入",
+	"polygon":            "空间类型无法正确显示和写入",
+	"multipoint":         "空间类型无法正确显示和写入",
+	"multilinestring":    "空间类型无法正确显示和写入",
+	"multipolygon":       "空间类型无法正确显示和写入",
+	"geometrycollection": "空间类型无法正确显示和写入",
+}
+
+// baseColumnType 获取字段的基础类型，如 varchar(50) 返回 varchar
+func baseColumnType(columnType string) string {
+	columnType = strings.ToLower(strings.TrimSpace(columnType))
+	if i := strings.IndexAny(columnType, "( "); i >= 0 {
+		columnType = columnType[:i]
+	}
+	return columnType
+}
+
+// findUnsupportedColumns 查找平台不支持读写的字段
+func findUnsupportedColumns(fields []model.Field) []model.UnsupportedColumn {
+	var unsupported []model.UnsupportedColumn
+	for _, field := range fields {
+		if reason, ok := unsupportedTypes[baseColumnType(field.ColumnType)]; ok {
+			unsupported = append(unsupported, model.UnsupportedColumn{
+				Name:       field.Name,
+				ColumnType: field.ColumnType,
+				Reason:     reason,
+			})
+		}
+	}
+	return unsupported
+}
+
+// AdoptTable 将数据库中已有的数据表纳入平台管理，创建数据表配置和系统菜单
+// 不修改已有字段，启用软删除或乐观锁时补充对应的托管字段
+func (s *TableService) AdoptTable(req *model.AdoptTableReq, creatorID uint, appID uint) (*model.AdoptTableResp, error) {
+	if err := req.Validate(); err != nil {
+		return nil, err
+	}
+
+	// 检查数据表是否存在以及是否已被纳管
+	var count int
+	err := s.db.Get(&count, "SELECT COUNT(*) FROM information_schema.tables WHERE table_schema = DATABASE() AND table_name = ? AND table_type = 'BASE TABLE'", req.TableName)
+	if err != nil {
+		return nil, fmt.Errorf("check table failed: %v", err)
+	}
+	if count == 0 {
+		return nil, fmt.Errorf("table %s does not exist", req.TableName)
+	}
+	err = s.db.Get(&count, "SELECT COUNT(*) FROM sys_config_tables WHERE table_name = ?", req.TableName)
+	if err != nil {
+		return nil, fmt.Errorf("check table config failed: %v", err)
+	}
+	if count > 0 {
+		return nil, fmt.Errorf("table %s is already managed", req.TableName)
+	}
+
+	// 其他应用命名空间中的表不能纳管，逻辑表名去掉本应用的命名空间前缀
+	if ns := model.TableNamespaceApp(req.TableName); ns != 0 && ns != appID {
+		return nil, fmt.Errorf("table %s belongs to app %d", req.TableName, ns)
+	}
+	logicalName := model.LogicalTableName(appID, req.TableName)
+	if err := checkLogicalName(s.db, appID, logicalName); err != nil {
+		return nil, err
+	}
+
+	// 读取表结构
+	fields, err := loadTableFields(s.db, req.TableName)
+	if err != nil {
+		return nil, err
+	}
+	indexes, err := loadTableIndexes(s.db, req.TableName)
+	if err != nil {
+		return nil, err
+	}
+
+	resp := &model.AdoptTableResp{
+		Fields:      fields,
+		Indexes:     indexes,
+		Unsupported: findUnsupportedColumns(fields),
+	}
+	hasPrimaryKey := false
+	fieldNames := make([]string, 0, len(fields))
+	for _, field := range fields {
+		fieldNames = append(fieldNames, field.Name)
+		hasPrimaryKey = hasPrimaryKey || field.PrimaryKey
+	}
+	if !hasPrimaryKey {
+		resp.Warnings = append(resp.Warnings, "数据表没有主键，不支持修改、删除和批量操作")
+	}
+
+	// 校验功能配置
+	table := model.ConfigTable{
+		AppID:        appID,
+		TableName:    req.TableName,
+		LogicalName:  logicalName,
+		DisplayName:  req.DisplayName,
+		Description:  req.Description,
+		Func:         req.Func,
+		FieldOptions: "{}",
+		Status:       1,
+		CreatorID:    creatorID,
+		UpdaterID:    creatorID,
+	}
+	if table.Func == "" {
+		table.Func = "{}"
+	}
+	tableFunc, err := model.ParseTableFunc(table.Func)
+	if err != nil {
+		return nil, err
+	}
+	if err := checkManagedFields(tableFunc, fieldNames); err != nil {
+		return nil, err
+	}
+	if err := checkFuncColumns(tableFunc, toNameSet(fieldNames)); err != nil {
+		return nil, err
+	}
+	if err := checkDataScopeDims(s.db, appID, tableFunc); err != nil {
+		return nil, err
+	}
+
+	// 开启事务
+	tx, err := s.db.Beginx()
+	if err != nil {
+		return nil, fmt.Errorf("begin transaction failed: %v", err)
+	}
+	defer tx.Rollback()
+
+	// 插入数据表配置
+	result, err := tx.NamedExec(`
+        INSERT INTO sys_config_tables (
+            app_id, table_name, logical_name, display_name, description, func, field_options, status, created_at, creator_id, updated_at, updater_id
+        ) VALUES (
+            :app_id, :table_name, :logical_name, :display_name, :description, :func, :field_options, :status, NOW(), :creator_id, NOW(), :creator_id
+        )
+    `, table)
+	if err != nil {
+		return nil, fmt.Errorf("insert sys_config_tables failed: %v", err)
+	}
+	id, err := result.LastInsertId()
+	if err != nil {
+		return nil, fmt.Errorf("get last insert id failed: %v", err)
+	}
+	resp.ID = uint(id)
+
+	// 补充托管字段
+	if tableFunc.SoftDelete {
+		if err := ensureSoftDeleteColumns(tx, table.TableName); err != nil {
+			return nil, err
+		}
+	}
+	if tableFunc.Versioned {
+		if err := ensureRowVersionColumn(tx, table.TableName); err != nil {
+			return nil, err
+		}
+	}
+
+	// 提交事务
+	if err := tx.Commit(); err != nil {
+		return nil, fmt.Errorf("commit transaction failed: %v", err)
+	}
+	element.InvalidateTableSchema(table.TableName)
+
+	// 创建对应的系统菜单
+	if err := s.createTableMenu(&table, resp.ID, req.ParentID, creatorID); err != nil {
+		return nil, err
+	}
+
+	return resp, nil
+}
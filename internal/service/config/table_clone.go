@@ -0,0 +1,230 @@
+package config
+
+import (
+	"database/sql"
+	"encoding/json"
+	"errors"
+	"fmt"
+
+	"github.com/iiwish/lingjian/internal/model"
+	"github.com/iiwish/lingjian/internal/service/element"
+	"github.com/iiwish/lingjian/pkg/utils"
+	"github.com/jmoiron/sqlx"
+)
+
+// ErrAppAccessDenied 用户没有目标应用的访问权限
+var ErrAppAccessDenied = errors.New("no permission to access target app")
+
+// tableMenuType 系统菜单中数据表菜单的类型
+const tableMenuType = "2"
+
+// systemMenuTable 获取应用的系统菜单维度表名
+func systemMenuTable(appID uint) string {
+	if appID == 1 {
+		return "sys_menu_system"
+	}
+	return "app" + utils.Uint2String(appID) + "_menu_system"
+}
+
+// canAccessApp 判断用户是否可以访问应用，管理员可以访问全部应用，其他用户需拥有应用中维度的权限
+func canAccessApp(q sqlx.Queryer, userID uint, appID uint) (bool, error) {
+	admin, err := isAdmin(q, userID)
+	if err != nil || admin {
+		return admin, err
+	}
+	var count int
+	err = sqlx.Get(q, &count, `
+		SELECT COUNT(*) FROM sys_config_dimensions m
+		INNER JOIN sys_permissions p ON m.id = p.dim_id
+		INNER JOIN sys_role_permissions rp ON p.id = rp.permission_id
+		INNER JOIN sys_user_roles ur ON rp.role_id = ur.role_id
+		WHERE ur.user_id = ?
+		AND m.app_id = ?
+		AND m.status = 1
+		AND p.status = 1
+	`, userID, appID)
+	if err != nil {
+		return false, fmt.Errorf("check app permission failed: %v", err)
+	}
+	return count > 0, nil
+}
+
+// cloneMenuParent 按源数据表的菜单位置确定新数据表的菜单父节点
+// 同一应用内使用相同的父节点，跨应用时按父节点编码在目标应用中查找，找不到时放在根节点下
+func (s *TableService) cloneMenuParent(srcAppID uint, srcTableID uint, targetAppID uint) (uint, error) {
+	srcMenu := systemMenuTable(srcAppID)
+	var parentID uint
+	err := s.db.Get(&parentID, fmt.Sprintf("SELECT parent_id FROM %s WHERE menu_type = ? AND source_id = ? LIMIT 1", srcMenu),
+		tableMenuType, utils.Uint2String(srcTableID))
+	if err == sql.ErrNoRows {
+		return 0, nil
+	}
+	if err != nil {
+		return 0, fmt.Errorf("get table menu failed: %v", err)
+	}
+	if parentID == 0 {
+		return 0, nil
+	}
+	if srcAppID == targetAppID {
+		return parentID, nil
+	}
+
+	var parentCode string
+	err = s.db.Get(&parentCode, fmt.Sprintf("SELECT code FROM %s WHERE id = ?", srcMenu), parentID)
+	if err == sql.ErrNoRows {
+		return 0, nil
+	}
+	if err != nil {
+		return 0, fmt.Errorf("get parent menu failed: %v", err)
+	}
+	var targetParentID uint
+	err = s.db.Get(&targetParentID, fmt.Sprintf("SELECT id FROM %s WHERE code = ? ORDER BY id LIMIT 1", systemMenuTable(targetAppID)), parentCode)
+	if err == sql.ErrNoRows {
+		return 0, nil
+	}
+	if err != nil {
+		return 0, fmt.Errorf("get target parent menu failed: %v", err)
+	}
+	return targetParentID, nil
+}
+
+// CloneTable 将数据表的字段、索引、功能配置和菜单位置克隆到目标应用，可按查询条件复制记录，跨应用克隆需有源应用和目标应用的访问权限
+// 建表语句会隐式提交事务，复制记录失败时删除已创建的数据表和菜单，克隆成功后记录为新数据表的一条操作记录
+func (s *TableService) CloneTable(tableID uint, req *model.CloneTableReq, creatorID uint, appID uint) (*model.CloneTableResp, error) {
+	if err := req.Validate(); err != nil {
+		return nil, err
+	}
+	targetAppID := req.TargetAppID
+	if targetAppID == 0 {
+		targetAppID = appID
+	}
+	var count int
+	if err := s.db.Get(&count, "SELECT COUNT(*) FROM sys_apps WHERE id = ?", targetAppID); err != nil {
+		return nil, fmt.Errorf("check target app failed: %v", err)
+	}
+	if count == 0 {
+		return nil, fmt.Errorf("app %d does not exist", targetAppID)
+	}
+	if targetAppID != appID {
+		allowed, err := canAccessApp(s.db, creatorID, targetAppID)
+		if err != nil {
+			return nil, err
+		}
+		if !allowed {
+			return nil, ErrAppAccessDenied
+		}
+	}
+
+	var srcAppID uint
+	if err := s.db.Get(&srcAppID, "SELECT app_id FROM sys_config_tables WHERE id = ? AND deleted_at IS NULL", tableID); err != nil {
+		return nil, fmt.Errorf("get table failed: %v", err)
+	}
+	// 源数据表不属于当前应用时，需要有源应用的访问权限
+	if srcAppID != appID {
+		allowed, err := canAccessApp(s.db, creatorID, srcAppID)
+		if err != nil {
+			return nil, err
+		}
+		if !allowed {
+			return nil, ErrAppAccessDenied
+		}
+	}
+	source, err := s.GetTable(tableID)
+	if err != nil {
+		return nil, err
+	}
+	tableFunc, err := model.ParseTableFunc(source.Func)
+	if err != nil {
+		return nil, err
+	}
+
+	clone := &model.CreateTableReq{
+		TableName:   req.TableName,
+		DisplayName: req.DisplayName,
+		Description: source.Description,
+		Func:        source.Func,
+		ParentID:    req.ParentID,
+	}
+	if clone.DisplayName == "" {
+		clone.DisplayName = source.DisplayName
+	}
+
+	// 托管字段和索引由功能配置重新生成，附件字段的附件属于源数据表，不复制取值
+	fields, indexes := withoutManagedColumns(tableFunc, source.Fields, source.Indexes)
+	var columns []string
+	for _, field := range fields {
+		// 字段类型读取自已有数据表，原始字段类型不需要管理员权限
+		if err := field.ResolveColumnType(true); err != nil {
+			return nil, err
+		}
+		clone.Fields = append(clone.Fields, field)
+		if !field.IsVirtual() && field.Type != model.TypeAttachment {
+			columns = append(columns, field.Name)
+		}
+	}
+	clone.Indexes = indexes
+
+	if clone.ParentID == 0 {
+		if clone.ParentID, err = s.cloneMenuParent(srcAppID, tableID, targetAppID); err != nil {
+			return nil, err
+		}
+	}
+	newID, err := s.createTable(clone, creatorID, targetAppID)
+	if err != nil {
+		return nil, err
+	}
+
+	resp := &model.CloneTableResp{ID: newID}
+	if req.CopyData {
+		resp.Copied, err = s.copyTableData(tableID, newID, model.PhysicalTableName(targetAppID, req.TableName), columns, req.Query, creatorID)
+		if err != nil {
+			s.dropTable(newID)
+			return nil, err
+		}
+	}
+
+	// 记录克隆操作
+	condition := []byte("null")
+	if req.Query != nil {
+		if condition, err = json.Marshal(req.Query); err != nil {
+			return nil, fmt.Errorf("marshal query condition failed: %v", err)
+		}
+	}
+	detail, err := json.Marshal(map[string]interface{}{
+		"source_table_id": tableID,
+		"source_app_id":   srcAppID,
+		"copy_data":       req.CopyData,
+	})
+	if err != nil {
+		return nil, fmt.Errorf("marshal clone detail failed: %v", err)
+	}
+	result, err := s.db.Exec(`
+		INSERT INTO sys_table_operations (app_id, table_id, operation, query_condition, patch, affected, operator_id, created_at)
+		VALUES (?, ?, ?, ?, ?, ?, ?, NOW())
+	`, targetAppID, newID, model.OperationClone, string(condition), string(detail), resp.Copied, creatorID)
+	if err != nil {
+		return nil, fmt.Errorf("save table operation failed: %v", err)
+	}
+	operationID, err := result.LastInsertId()
+	if err != nil {
+		return nil, fmt.Errorf("get last insert id failed: %v", err)
+	}
+	resp.OperationID = uint(operationID)
+	return resp, nil
+}
+
+// copyTableData 按用户的字段访问策略和数据权限复制源数据表的记录，同时复制编号字段计数器以免新数据表生成重复的编号
+func (s *TableService) copyTableData(srcTableID uint, newID uint, tableName string, columns []string, query *model.QueryCondition, userID uint) (int64, error) {
+	copied, err := element.CopyTableItems(s.db, srcTableID, tableName, columns, query, userID)
+	if err != nil {
+		return 0, err
+	}
+	_, err = s.db.Exec(`
+		INSERT INTO sys_table_sequences (table_id, field_name, period, value)
+		SELECT ?, field_name, period, value FROM sys_table_sequences WHERE table_id = ?
+	`, newID, srcTableID)
+	if err != nil {
+		return 0, fmt.Errorf("copy table sequences failed: %v", err)
+	}
+	return copied, nil
+}
@@ -0,0 +1,118 @@
+package config
+
+import (
+	"fmt"
+	"regexp"
+	"strings"
+
+	"github.com/iiwish/lingjian/internal/model"
+	"github.com/jmoiron/sqlx"
+)
+
+// autoIncrementOptionRegexp 建表语句中的自增起始值，与环境中的数据有关，导出时去掉
+var autoIncrementOptionRegexp = regexp.MustCompile(` AUTO_INCREMENT=\d+`)
+
+// showCreateTable 获取数据表的建表语句，表名替换为exportName
+func showCreateTable(q sqlx.Queryer, tableName string, exportName string) (string, error) {
+	var name, ddl string
+	err := q.QueryRowx("SHOW CREATE TABLE "+model.QuoteIdentifier(tableName)).Scan(&name, &ddl)
+	if err != nil {
+		return "", fmt.Errorf("show create table failed: %v", err)
+	}
+	ddl = strings.Replace(ddl, "CREATE TABLE "+model.QuoteIdentifier(tableName), "CREATE TABLE "+model.QuoteIdentifier(exportName), 1)
+	return autoIncrementOptionRegexp.ReplaceAllString(ddl, ""), nil
+}
+
+// ExportTable 导出数据表定义，两种格式的表名都为逻辑表名，可在其他环境或应用中导入
+// ddl格式为数据库中的建表语句；json格式为平台的数据表定义，含功能配置和字段扩展配置，不含托管字段和索引，可直接用于创建数据表
+func (s *TableService) ExportTable(id uint, format string) (*model.ExportTableResp, error) {
+	table, err := s.GetTable(id)
+	if err != nil {
+		return nil, err
+	}
+
+	resp := &model.ExportTableResp{Format: format}
+	switch format {
+	case model.TableExportDDL:
+		if resp.DDL, err = showCreateTable(s.db, table.PhysicalName, table.TableName); err != nil {
+			return nil, err
+		}
+	case model.TableExportJSON:
+		tableFunc, err := model.ParseTableFunc(table.Func)
+		if err != nil {
+			return nil, err
+		}
+		table.Fields, table.Indexes = withoutManagedColumns(tableFunc, table.Fields, table.Indexes)
+		table.PhysicalName = ""
+		resp.Table = table
+	default:
+		return nil, fmt.Errorf("unsupported export format: %s", format)
+	}
+	return resp, nil
+}
+
+// ImportTable 解析CREATE TABLE语句并创建数据表，不支持的子句和字段类型在结果中说明
+// 字段使用语句中的原始字段类型，只有管理员可以导入；dry_run时只解析和校验，不创建数据表
+func (s *TableService) ImportTable(req *model.ImportTableReq, creatorID uint, appID uint) (*model.ImportTableResp, error) {
+	table, unsupported, err := model.ParseCreateTable(req.DDL)
+	if err != nil {
+		return nil, err
+	}
+
+	// 从本应用导出的建表语句可能带有命名空间前缀
+	table.TableName = model.LogicalTableName(appID, table.TableName)
+	if req.TableName != "" {
+		table.TableName = req.TableName
+	}
+	table.DisplayName = req.DisplayName
+	if table.DisplayName == "" {
+		table.DisplayName = table.TableName
+	}
+	if req.Description != "" {
+		table.Description = req.Description
+	}
+	table.Func = req.Func
+	table.ParentID = req.ParentID
+
+	// 启用的托管字段和索引由功能配置生成
+	tableFunc, err := model.ParseTableFunc(req.Func)
+	if err != nil {
+		return nil, err
+	}
+	table.Fields, table.Indexes = withoutManagedColumns(tableFunc, table.Fields, table.Indexes)
+	for _, column := range findUnsupportedColumns(table.Fields) {
+		unsupported = append(unsupported, model.UnsupportedClause{
+			Clause: model.QuoteIdentifier(column.Name) + " " + column.ColumnType,
+			Reason: column.Reason,
+		})
+	}
+
+	fields := make([]*model.Field, 0, len(table.Fields))
+	for i := range table.Fields {
+		fields = append(fields, &table.Fields[i])
+	}
+	if err := resolveColumnTypes(s.db, creatorID, fields); err != nil {
+		return nil, err
+	}
+
+	resp := &model.ImportTableResp{Table: table, Unsupported: unsupported}
+	if req.DryRun {
+		if err := model.ValidateLogicalTableName(appID, table.TableName); err != nil {
+			return nil, err
+		}
+		if err := checkTableName(s.db, appID, table.TableName); err != nil {
+			return nil, err
+		}
+		for i := range table.Indexes {
+			if err := table.Indexes[i].Validate(); err != nil {
+				return nil, err
+			}
+		}
+		return resp, nil
+	}
+
+	if resp.ID, err = s.createTable(table, creatorID, appID); err != nil {
+		return nil, err
+	}
+	return resp, nil
+}
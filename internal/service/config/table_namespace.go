@@ -0,0 +1,118 @@
+package config
+
+import (
+	"fmt"
+
+	"github.com/iiwish/lingjian/internal/model"
+	"github.com/iiwish/lingjian/internal/service/element"
+	"github.com/jmoiron/sqlx"
+)
+
+// checkLogicalName 检查逻辑表名是否已被应用中未删除的数据表或维度使用
+func checkLogicalName(q sqlx.Queryer, appID uint, logicalName string) error {
+	var count int
+	err := sqlx.Get(q, &count, `
+		SELECT (SELECT COUNT(*) FROM sys_config_tables WHERE app_id = ? AND logical_name = ? AND deleted_at IS NULL)
+			+ (SELECT COUNT(*) FROM sys_config_dimensions WHERE app_id = ? AND logical_name = ?)
+	`, appID, logicalName, appID, logicalName)
+	if err != nil {
+		return fmt.Errorf("check table name failed: %v", err)
+	}
+	if count > 0 {
+		return fmt.Errorf("table name %s already exists", logicalName)
+	}
+	return nil
+}
+
+// checkPhysicalName 检查数据库中是否已存在指定的表或其归档表
+func checkPhysicalName(q sqlx.Queryer, tableName string) error {
+	for _, name := range []string{tableName, model.ArchiveTableName(tableName)} {
+		exists, err := tableExists(q, name)
+		if err != nil {
+			return err
+		}
+		if exists {
+			return fmt.Errorf("table %s already exists", name)
+		}
+	}
+	return nil
+}
+
+// checkTableName 检查逻辑表名在应用中是否可用，逻辑表名和对应的数据库表名都不能已被使用
+func checkTableName(q sqlx.Queryer, appID uint, logicalName string) error {
+	if err := checkLogicalName(q, appID, logicalName); err != nil {
+		return err
+	}
+	return checkPhysicalName(q, model.PhysicalTableName(appID, logicalName))
+}
+
+// MigrateTableNamespace 将未使用应用命名空间的旧数据表改名到应用的命名空间，逻辑表名不变
+// 数据表和归档表一起改名，已在命名空间中的数据表不做处理，返回迁移后的数据库表名
+func (s *TableService) MigrateTableNamespace(tableID uint) (string, error) {
+	var table model.ConfigTable
+	err := s.db.Get(&table, "SELECT id, app_id, table_name, logical_name FROM sys_config_tables WHERE id = ? AND deleted_at IS NULL", tableID)
+	if err != nil {
+		return "", fmt.Errorf("get table failed: %v", err)
+	}
+	physicalName := model.PhysicalTableName(table.AppID, table.LogicalName)
+	if table.TableName == physicalName {
+		return physicalName, nil
+	}
+	if err := model.ValidateLogicalTableName(table.AppID, table.LogicalName); err != nil {
+		return "", fmt.Errorf("%v, rename the table before migrating", err)
+	}
+	if err := checkPhysicalName(s.db, physicalName); err != nil {
+		return "", err
+	}
+
+	defer element.InvalidateTableSchema(table.TableName, model.ArchiveTableName(table.TableName), physicalName, model.ArchiveTableName(physicalName))
+	defer element.InvalidateTableCache(tableID)
+
+	// 改名语句会隐式提交事务，先改名再更新配置，更新失败时改回原表名
+	if err := renameTables(s.db, table.TableName, physicalName); err != nil {
+		return "", err
+	}
+	_, err = s.db.Exec("UPDATE sys_config_tables SET table_name = ? WHERE id = ?", physicalName, tableID)
+	if err != nil {
+		renameTables(s.db, physicalName, table.TableName)
+		return "", fmt.Errorf("update sys_config_tables failed: %v", err)
+	}
+	return physicalName, nil
+}
+
+// MigrateDimensionNamespace 将未使用应用命名空间的旧维度表改名到应用的命名空间，逻辑表名不变
+// 菜单维度按数据库表名查找，不做迁移；已在命名空间中的维度不做处理，返回迁移后的数据库表名
+func (s *DimensionService) MigrateDimensionNamespace(dimID uint) (string, error) {
+	var dim model.ConfigDimension
+	err := s.db.Get(&dim, "SELECT id, app_id, table_name, logical_name, dimension_type FROM sys_config_dimensions WHERE id = ?", dimID)
+	if err != nil {
+		return "", fmt.Errorf("get dimension failed: %v", err)
+	}
+	if dim.DimensionType == "menu" {
+		return "", fmt.Errorf("menu dimension %s can not be migrated", dim.TableName)
+	}
+	physicalName := model.PhysicalTableName(dim.AppID, dim.LogicalName)
+	if dim.TableName == physicalName {
+		return physicalName, nil
+	}
+	if err := model.ValidateLogicalTableName(dim.AppID, dim.LogicalName); err != nil {
+		return "", fmt.Errorf("%v, rename the dimension before migrating", err)
+	}
+	if err := checkPhysicalName(s.db, physicalName); err != nil {
+		return "", err
+	}
+
+	defer element.InvalidateTableSchema(dim.TableName, physicalName)
+
+	// 改名语句会隐式提交事务，先改名再更新配置，更新失败时改回原表名
+	_, err = s.db.Exec("RENAME TABLE " + model.QuoteIdentifier(dim.TableName) + " TO " + model.QuoteIdentifier(physicalName))
+	if err != nil {
+		return "", fmt.Errorf("rename table failed: %v", err)
+	}
+	_, err = s.db.Exec("UPDATE sys_config_dimensions SET table_name = ? WHERE id = ?", physicalName, dimID)
+	if err != nil {
+		s.db.Exec("RENAME TABLE " + model.QuoteIdentifier(physicalName) + " TO " + model.QuoteIdentifier(dim.TableName))
+		return "", fmt.Errorf("update sys_config_dimensions failed: %v", err)
+	}
+	return physicalName, nil
+}
@@ -0,0 +1,186 @@
+package config
+
+import (
+	"database/sql"
+	"errors"
+	"fmt"
+	"strings"
+
+	"github.com/iiwish/lingjian/internal/model"
+	"github.com/iiwish/lingjian/internal/service/element"
+	"github.com/jmoiron/sqlx"
+	"github.com/spf13/viper"
+)
+
+var (
+	// ErrTrashTableNotFound 数据表不在回收站中
+	ErrTrashTableNotFound = errors.New("table is not in the trash")
+	// ErrTrashExpired 数据表超过回收站保留期，不能恢复
+	ErrTrashExpired = errors.New("table has exceeded the trash retention period")
+)
+
+// trashRetentionDays 获取删除的数据表在回收站中保留的天数
+func trashRetentionDays() int {
+	if days := viper.GetInt("table.trash_retention_days"); days > 0 {
+		return days
+	}
+	return model.DefaultRetentionDays
+}
+
+// tableExists 检查数据库中是否存在指定的表
+func tableExists(q sqlx.Queryer, tableName string) (bool, error) {
+	var count int
+	err := sqlx.Get(q, &count, "SELECT COUNT(*) FROM information_schema.tables WHERE table_schema = DATABASE() AND table_name = ?", tableName)
+	if err != nil {
+		return false, fmt.Errorf("check table exists failed: %v", err)
+	}
+	return count > 0, nil
+}
+
+// renameTables 将数据表和归档表一起改名，不存在的表跳过，多个表在一条语句中改名保证原子性
+func renameTables(q sqlx.Ext, from string, to string) error {
+	pairs := [][2]string{{from, to}, {model.ArchiveTableName(from), model.ArchiveTableName(to)}}
+	renames := make([]string, 0, len(pairs))
+	for _, pair := range pairs {
+		exists, err := tableExists(q, pair[0])
+		if err != nil {
+			return err
+		}
+		if exists {
+			renames = append(renames, model.QuoteIdentifier(pair[0])+" TO "+model.QuoteIdentifier(pair[1]))
+		}
+	}
+	if len(renames) == 0 {
+		return nil
+	}
+	if _, err := q.Exec("RENAME TABLE " + strings.Join(renames, ", ")); err != nil {
+		return fmt.Errorf("rename table failed: %v", err)
+	}
+	return nil
+}
+
+// DeleteTable 将数据表移入回收站，数据表和归档表改名为回收站表名，配置保留并标记为已删除
+// 改名语句会隐式提交事务，因此先改名再更新配置，更新失败时改回原表名
+func (s *TableService) DeleteTable(id uint, operatorID uint) error {
+	var tableName string
+	err := s.db.Get(&tableName, "SELECT table_name FROM sys_config_tables WHERE id = ? AND deleted_at IS NULL", id)
+	if err != nil {
+		return fmt.Errorf("get table name failed: %v", err)
+	}
+	trashName := model.TrashTableName(id)
+	defer element.InvalidateTableSchema(tableName, model.ArchiveTableName(tableName), trashName, model.ArchiveTableName(trashName))
+	defer element.InvalidateTableCache(id)
+
+	if err := renameTables(s.db, tableName, trashName); err != nil {
+		return err
+	}
+	result, err := s.db.Exec(`
+		UPDATE sys_config_tables SET table_name = ?, original_name = ?, deleted_at = NOW(), deleter_id = ?
+		WHERE id = ? AND deleted_at IS NULL
+	`, trashName, tableName, operatorID, id)
+	if err == nil {
+		var affected int64
+		if affected, err = result.RowsAffected(); err == nil && affected == 0 {
+			err = errors.New("table has been deleted")
+		}
+	}
+	if err != nil {
+		renameTables(s.db, trashName, tableName)
+		return fmt.Errorf("move table to trash failed: %v", err)
+	}
+	return nil
+}
+
+// ListTrashTables 获取应用回收站中的数据表，按删除时间倒序
+func (s *TableService) ListTrashTables(appID uint) ([]model.TrashTable, error) {
+	tables := []model.TrashTable{}
+	err := s.db.Select(&tables, `
+		SELECT id, app_id, logical_name, table_name, display_name, deleted_at, deleter_id,
+			DATE_ADD(deleted_at, INTERVAL ? DAY) AS expires_at
+		FROM sys_config_tables
+		WHERE app_id = ? AND deleted_at IS NOT NULL
+		ORDER BY deleted_at DESC
+	`, trashRetentionDays(), appID)
+	if err != nil {
+		return nil, fmt.Errorf("list trash tables failed: %v", err)
+	}
+	return tables, nil
+}
+
+// RestoreTable 从回收站恢复数据表，超过保留期或原表名已被占用时不能恢复
+func (s *TableService) RestoreTable(id uint, operatorID uint, appID uint) error {
+	var table struct {
+		TrashName    string `db:"table_name"`
+		OriginalName string `db:"original_name"`
+		LogicalName  string `db:"logical_name"`
+		Expired      bool   `db:"expired"`
+	}
+	err := s.db.Get(&table, `
+		SELECT table_name, original_name, logical_name, deleted_at < DATE_SUB(NOW(), INTERVAL ? DAY) AS expired
+		FROM sys_config_tables
+		WHERE id = ? AND app_id = ? AND deleted_at IS NOT NULL
+	`, trashRetentionDays(), id, appID)
+	if err == sql.ErrNoRows {
+		return ErrTrashTableNotFound
+	}
+	if err != nil {
+		return fmt.Errorf("get trash table failed: %v", err)
+	}
+	if table.Expired {
+		return ErrTrashExpired
+	}
+
+	// 删除后可能创建了同名的数据表
+	if err := checkLogicalName(s.db, appID, table.LogicalName); err != nil {
+		return err
+	}
+	if err := checkPhysicalName(s.db, table.OriginalName); err != nil {
+		return err
+	}
+
+	defer element.InvalidateTableSchema(table.OriginalName, model.ArchiveTableName(table.OriginalName), table.TrashName, model.ArchiveTableName(table.TrashName))
+	defer element.InvalidateTableCache(id)
+
+	if err := renameTables(s.db, table.TrashName, table.OriginalName); err != nil {
+		return err
+	}
+	_, err = s.db.Exec(`
+		UPDATE sys_config_tables SET table_name = original_name, original_name = '', deleted_at = NULL, deleter_id = 0, updater_id = ?
+		WHERE id = ?
+	`, operatorID, id)
+	if err != nil {
+		renameTables(s.db, table.OriginalName, table.TrashName)
+		return fmt.Errorf("restore table failed: %v", err)
+	}
+	return nil
+}
+
+// PurgeTrashTables 彻底删除应用回收站中超过保留期的数据表
+// 保留天数取配置的保留天数和retentionDays中较大的一个，避免清理仍可恢复的数据表
+// 单个数据表删除失败不影响其他数据表，返回删除的数据表数量和遇到的第一个错误
+func (s *TableService) PurgeTrashTables(appID uint, retentionDays int) (int, error) {
+	if configured := trashRetentionDays(); retentionDays < configured {
+		retentionDays = configured
+	}
+	var ids []uint
+	err := s.db.Select(&ids, `
+		SELECT id FROM sys_config_tables
+		WHERE app_id = ? AND deleted_at IS NOT NULL AND deleted_at < DATE_SUB(NOW(), INTERVAL ? DAY)
+	`, appID, retentionDays)
+	if err != nil {
+		return 0, fmt.Errorf("list expired trash tables failed: %v", err)
+	}
+
+	var purged int
+	var firstErr error
+	for _, id := range ids {
+		if err := s.dropTable(id); err != nil {
+			if firstErr == nil {
+				firstErr = err
+			}
+			continue
+		}
+		purged++
+	}
+	return purged, firstErr
+}
@@ -0,0 +1,52 @@
+package config
+
+import (
+	"fmt"
+
+	"github.com/iiwish/lingjian/internal/model"
+	"github.com/jmoiron/sqlx"
+)
+
+// canUseRawColumnType 判断用户是否可以直接使用原始字段类型，只有管理员可以使用
+func canUseRawColumnType(q sqlx.Queryer, userID uint) (bool, error) {
+	return isAdmin(q, userID)
+}
+
+// isAdmin 判断用户是否拥有启用的管理员角色
+func isAdmin(q sqlx.Queryer, userID uint) (bool, error) {
+	query, args, err := sqlx.In(`
+		SELECT COUNT(*) FROM sys_roles r
+		INNER JOIN sys_user_roles ur ON r.id = ur.role_id
+		WHERE ur.user_id = ?
+		AND r.code IN (?)
+		AND r.status = 1
+	`, userID, model.AdminRoleCodes)
+	if err != nil {
+		return false, fmt.Errorf("prepare role query failed: %v", err)
+	}
+	var count int
+	if err := sqlx.Get(q, &count, query, args...); err != nil {
+		return false, fmt.Errorf("get user roles failed: %v", err)
+	}
+	return count > 0, nil
+}
+
+// resolveColumnTypes 将字段的逻辑类型转换为数据库字段类型，未设置逻辑类型的字段只有管理员可以使用
+func resolveColumnTypes(q sqlx.Queryer, userID uint, fields []*model.Field) error {
+	allowRaw := false
+	for _, field := range fields {
+		if field.Type == "" && !field.IsVirtual() {
+			var err error
+			if allowRaw, err = canUseRawColumnType(q, userID); err != nil {
+				return err
+			}
+			break
+		}
+	}
+	for _, field := range fields {
+		if err := field.ResolveColumnType(allowRaw); err != nil {
+			return err
+		}
+	}
+	return nil
+}
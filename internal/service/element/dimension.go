@@ -169,6 +169,7 @@ func (s *DimensionService) CreateDimensionItem(reqItem *model.CreateDimensionIte
 	if err := tx.Commit(); err != nil {
 		return 0, fmt.Errorf("commit transaction failed: %v", err)
 	}
+	InvalidateDimensionCache(dim_id)
 
 	return uint(id), nil
 }
@@ -265,6 +266,7 @@ func (s *DimensionService) UpdateDimensionItem(reqItem *model.UpdateDimensionIte
 	if err := tx.Commit(); err != nil {
 		return fmt.Errorf("commit transaction failed: %v", err)
 	}
+	InvalidateDimensionCache(dim_id)
 
 	return nil
 }
@@ -272,18 +274,9 @@ func (s *DimensionService) UpdateDimensionItem(reqItem *model.UpdateDimensionIte
 // TreeDimensionItems 获取维度明细配置树形结构
 func (s *DimensionService) TreeDimensionItems(userID uint, dim_id uint, item_id uint, query_type string, query_level uint) ([]model.TreeDimensionItem, error) {
 	// 首先检查用户是否有该维度的权限
-	permissionQuery := `
-		SELECT DISTINCT p.item_id FROM sys_permissions p
-		INNER JOIN sys_role_permissions rp ON p.id = rp.permission_id
-		INNER JOIN sys_user_roles ur ON rp.role_id = ur.role_id
-		WHERE ur.user_id = ? 
-		AND p.dim_id = ?
-		AND p.status = 1
-	`
-	var permItemIDs []uint
-	err := s.db.Select(&permItemIDs, permissionQuery, userID, dim_id)
+	permItemIDs, err := permittedItemIDs(s.db, userID, dim_id)
 	if err != nil {
-		return nil, fmt.Errorf("get user permissions failed: %v", err)
+		return nil, err
 	}
 
 	// 如果没有该维度的权限记录,直接返回空值
@@ -613,6 +606,7 @@ func (s *DimensionService) DeleteDimensionItems(operatorID uint, dim_id uint, it
 	if err := tx.Commit(); err != nil {
 		return fmt.Errorf("commit transaction failed: %v", err)
 	}
+	InvalidateDimensionCache(dim_id)
 
 	return nil
 }
@@ -757,6 +751,7 @@ func (s *DimensionService) UpdateDimensionItemSort(updaterID uint, dim_id uint,
 	if err := tx.Commit(); err != nil {
 		return fmt.Errorf("commit transaction failed: %v", err)
 	}
+	InvalidateDimensionCache(dim_id)
 
 	return nil
 }
@@ -1,6 +1,8 @@
 package element
 
 import (
+	"io"
+
 	"github.com/iiwish/lingjian/internal/model"
 	"github.com/jmoiron/sqlx"
 )
@@ -24,12 +26,16 @@ func NewElementService(db *sqlx.DB) *ElementService {
 }
 
 // Table
-func (s *ElementService) GetTableItems(tableID uint, page int, pageSize int, query *model.QueryCondition) ([]map[string]interface{}, int, error) {
-	return s.tableService.GetTableItems(tableID, page, pageSize, query)
+func (s *ElementService) GetTableItems(tableID uint, req *model.TableQueryReq, userID uint) (*model.TableQueryResp, error) {
+	return s.tableService.GetTableItems(tableID, req, userID)
+}
+
+func (s *ElementService) SearchTableItems(tableID uint, keyword string, req *model.TableQueryReq, userID uint) (*model.TableQueryResp, error) {
+	return s.tableService.SearchTableItems(tableID, keyword, req, userID)
 }
 
-func (s *ElementService) CreateTableItems(tableItems []map[string]interface{}, creatorID uint, tableID uint) error {
-	return s.tableService.CreateTableItems(tableItems, creatorID, tableID)
+func (s *ElementService) CreateTableItems(tableItems []map[string]interface{}, creatorID uint, tableID uint, opts model.CreateTableItemsOptions) (*model.CreateTableItemsResp, error) {
+	return s.tableService.CreateTableItems(tableItems, creatorID, tableID, opts)
 }
 
 func (s *ElementService) UpdateTableItems(reqItems model.UpdateTableItemsRequest, updaterID uint, tableID uint) error {
@@ -40,6 +46,82 @@ func (s *ElementService) DeleteTableItems(operatorID uint, tableID uint, reqItem
 	return s.tableService.DeleteTableItems(operatorID, tableID, reqItems)
 }
 
+func (s *ElementService) GetRecycleItems(tableID uint, page int, pageSize int, userID uint) ([]map[string]interface{}, int, error) {
+	return s.tableService.GetRecycleItems(tableID, page, pageSize, userID)
+}
+
+func (s *ElementService) RestoreTableItems(operatorID uint, tableID uint, reqItems []map[string]interface{}) error {
+	return s.tableService.RestoreTableItems(operatorID, tableID, reqItems)
+}
+
+func (s *ElementService) PurgeTableItems(operatorID uint, tableID uint, reqItems []map[string]interface{}) error {
+	return s.tableService.PurgeTableItems(operatorID, tableID, reqItems)
+}
+
+func (s *ElementService) PreviewBulkItems(tableID uint, req *model.TableBulkReq, userID uint) (*model.TableBulkResp, error) {
+	return s.tableService.PreviewBulkItems(tableID, req, userID)
+}
+
+func (s *ElementService) BulkUpdateTableItems(tableID uint, req *model.TableBulkReq, operatorID uint) (*model.TableBulkResp, error) {
+	return s.tableService.BulkUpdateTableItems(tableID, req, operatorID)
+}
+
+func (s *ElementService) BulkDeleteTableItems(tableID uint, req *model.TableBulkReq, operatorID uint) (*model.TableBulkResp, error) {
+	return s.tableService.BulkDeleteTableItems(tableID, req, operatorID)
+}
+
+func (s *ElementService) GetTableOperations(tableID uint, page int, pageSize int) ([]model.TableOperation, int, error) {
+	return s.tableService.GetTableOperations(tableID, page, pageSize)
+}
+
+func (s *ElementService) GetTableCacheStats(tableID uint) (*model.TableCacheStats, error) {
+	return s.tableService.GetTableCacheStats(tableID)
+}
+
+func (s *ElementService) ExplainTableItems(tableID uint, req *model.TableQueryReq, userID uint) (*model.TableExplainResp, error) {
+	return s.tableService.ExplainTableItems(tableID, req, userID)
+}
+
+func (s *ElementService) UploadAttachment(tableID uint, field string, fileName string, r io.Reader, creatorID uint) (*model.AttachmentResp, error) {
+	return s.tableService.UploadAttachment(tableID, field, fileName, r, creatorID)
+}
+
+func (s *ElementService) GetAttachment(tableID uint, id uint, userID uint) (*model.AttachmentResp, error) {
+	return s.tableService.GetAttachment(tableID, id, userID)
+}
+
+func (s *ElementService) OpenAttachment(id uint, expires int64, signature string) (*model.Attachment, io.ReadCloser, error) {
+	return s.tableService.OpenAttachment(id, expires, signature)
+}
+
+func (s *ElementService) ListTableViews(tableID uint, userID uint) ([]*model.TableViewResp, error) {
+	return s.tableService.ListTableViews(tableID, userID)
+}
+
+func (s *ElementService) GetTableView(tableID uint, viewID uint, userID uint) (*model.TableViewResp, error) {
+	return s.tableService.GetTableView(tableID, viewID, userID)
+}
+
+func (s *ElementService) CreateTableView(tableID uint, req *model.TableViewReq, userID uint) (*model.TableViewResp, error) {
+	return s.tableService.CreateTableView(tableID, req, userID)
+}
+
+func (s *ElementService) UpdateTableView(tableID uint, viewID uint, req *model.TableViewReq, userID uint) (*model.TableViewResp, error) {
+	return s.tableService.UpdateTableView(tableID, viewID, req, userID)
+}
+
+func (s *ElementService) DeleteTableView(tableID uint, viewID uint, userID uint) error {
+	return s.tableService.DeleteTableView(tableID, viewID, userID)
+}
+
+func (s *ElementService) ArchiveTableItems(tableID uint, operatorID uint) (*model.TableArchiveLog, error) {
+	return s.tableService.ArchiveTableItems(tableID, operatorID)
+}
+
+func (s *ElementService) GetArchiveLogs(tableID uint, page int, pageSize int) ([]model.TableArchiveLog, int, error) {
+	return s.tableService.GetArchiveLogs(tableID, page, pageSize)
+}
+
 // Dimension
 func (s *ElementService) CreateDimensionItem(item *model.CreateDimensionItemReq, creatorID uint, dimID uint) (uint, error) {
 	return s.dimensionService.CreateDimensionItem(item, creatorID, dimID)
@@ -1,7 +1,10 @@
 package element
 
 import (
+	"errors"
 	"fmt"
+	"sort"
+	"strconv"
 	"strings"
 	"time"
 
@@ -20,47 +23,261 @@ func NewTableService(db *sqlx.DB) *TableService {
 	return &TableService{db: db}
 }
 
+// ErrSoftDeleteDisabled 数据表未启用软删除
+var ErrSoftDeleteDisabled = errors.New("table does not enable soft delete")
+
+// ErrInvalidKeyColumns 定位记录的字段不是数据表的主键或唯一键
+var ErrInvalidKeyColumns = errors.New("primary_key_columns must be the primary key or a unique key of the table")
+
+// tableMeta 数据表运行时配置
+type tableMeta struct {
+	ID           uint
+	AppID        uint
+	TableName    string
+	Func         *model.TableFunc
+	FieldOptions map[string]model.FieldOptions
+}
+
+// getTableMeta 从配置表读取数据表配置
+func getTableMeta(q sqlx.Queryer, tableID uint) (*tableMeta, error) {
+	var table model.ConfigTable
+	err := sqlx.Get(q, &table, `
+		SELECT id, app_id, table_name, IFNULL(func, "") AS func, IFNULL(field_options, "") AS field_options
+		FROM sys_config_tables
+		WHERE id = ? AND deleted_at IS NULL
+	`, tableID)
+	if err != nil {
+		return nil, fmt.Errorf("get table config failed: %v", err)
+	}
+
+	tableFunc, err := model.ParseTableFunc(table.Func)
+	if err != nil {
+		return nil, err
+	}
+
+	fieldOptions, err := model.ParseFieldOptions(table.FieldOptions)
+	if err != nil {
+		return nil, err
+	}
+
+	return &tableMeta{
+		ID:           table.ID,
+		AppID:        table.AppID,
+		TableName:    table.TableName,
+		Func:         tableFunc,
+		FieldOptions: fieldOptions,
+	}, nil
+}
+
+// buildKeyWhere 根据键值对构建WHERE条件
+func buildKeyWhere(condition map[string]interface{}) (string, []interface{}) {
+	cols := make([]string, 0, len(condition))
+	for col := range condition {
+		cols = append(cols, col)
+	}
+	sort.Strings(cols)
+
+	whereClauses := make([]string, 0, len(cols))
+	args := make([]interface{}, 0, len(cols))
+	for _, col := range cols {
+		whereClauses = append(whereClauses, model.QuoteIdentifier(col)+" = ?")
+		args = append(args, condition[col])
+	}
+	return strings.Join(whereClauses, " AND "), args
+}
+
+// checkKeyColumns 检查定位记录的字段是数据表的主键或唯一键，且每条记录都提交了键值
+func checkKeyColumns(schema *model.TableSchema, keyColumns []string, items []map[string]interface{}) error {
+	matched := false
+	for _, key := range schema.UniqueKeys {
+		if sameColumns(key, keyColumns) {
+			matched = true
+			break
+		}
+	}
+	if !matched {
+		return ErrInvalidKeyColumns
+	}
+
+	var errs []model.FieldError
+	for row, item := range items {
+		for _, col := range keyColumns {
+			if item[col] == nil {
+				errs = append(errs, model.FieldError{Row: row, Field: col, Message: "缺少键值"})
+			}
+		}
+	}
+	if len(errs) > 0 {
+		return &ValidationError{Errors: errs}
+	}
+	return nil
+}
+
+// sameColumns 判断两组字段是否相同，不区分顺序
+func sameColumns(a, b []string) bool {
+	if len(a) != len(b) {
+		return false
+	}
+	set := make(map[string]bool, len(a))
+	for _, col := range a {
+		set[col] = true
+	}
+	for _, col := range b {
+		if !set[col] {
+			return false
+		}
+	}
+	return true
+}
+
+// checkManagedWrites 检查记录中是否写入了系统维护的软删除和记录版本字段
+func checkManagedWrites(items []map[string]interface{}) error {
+	var errs []model.FieldError
+	for row, item := range items {
+		for _, col := range []string{model.ColumnDeletedAt, model.ColumnDeletedBy, model.ColumnRowVersion} {
+			if _, ok := item[col]; ok {
+				errs = append(errs, model.FieldError{Row: row, Field: col, Message: "由系统维护，不能修改"})
+			}
+		}
+	}
+	if len(errs) > 0 {
+		return &ValidationError{Errors: errs}
+	}
+	return nil
+}
+
 // GetTableItems 获取数据表记录列表
-func (s *TableService) GetTableItems(tableID uint, page int, pageSize int, query *model.QueryCondition) ([]map[string]interface{}, int, error) {
+func (s *TableService) GetTableItems(tableID uint, req *model.TableQueryReq, userID uint) (*model.TableQueryResp, error) {
+	if err := req.Validate(); err != nil {
+		return nil, err
+	}
+
 	// 从配置表读取表配置
-	var tableName string
-	err := s.db.Get(&tableName, "SELECT table_name FROM sys_config_tables WHERE id = ?", tableID)
+	meta, err := getTableMeta(s.db, tableID)
 	if err != nil {
-		return nil, 0, fmt.Errorf("get table name failed: %v", err)
+		return nil, err
 	}
 
-	// 构建基础查询SQL
-	baseQuery, args := query.BuildQuery(tableName)
+	// 按视图查询时合并视图的查询条件
+	var view *model.TableViewResp
+	if req.ViewID > 0 {
+		if view, err = s.applyTableView(tableID, req, userID); err != nil {
+			return nil, err
+		}
+	}
+
+	policies, err := s.prepareQuery(meta, &req.Query, userID)
+	if err != nil {
+		return nil, err
+	}
+	var resp *model.TableQueryResp
+	if cacheEnabled(meta) {
+		resp, err = s.cachedQueryItems(meta, req, policies)
+	} else {
+		resp, err = s.queryItems(meta, req, policies)
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	// 展开引用字段，引用的记录按用户在目标表的权限读取，不写入查询结果缓存
+	if req.ExpandRefs && !req.Query.IsAggregate() {
+		if err := expandReferences(s.db, meta, policies, userID, resp.Items); err != nil {
+			return nil, err
+		}
+	}
+	if view == nil {
+		return resp, nil
+	}
+	hideColumns(resp.Items, view.HiddenColumns)
+	resp.View = view
+	return resp, nil
+}
+
+// prepareQuery 校验查询条件并附加软删除和数据权限的过滤条件，返回用户生效的字段访问策略
+func (s *TableService) prepareQuery(meta *tableMeta, query *model.QueryCondition, userID uint) (columnPolicies, error) {
+	// 根据表结构校验查询条件，用户不可读的字段不能用于查询条件
+	schema, err := getTableSchema(s.db, meta.TableName)
+	if err != nil {
+		return nil, err
+	}
+	policies, err := userColumnPolicies(s.db, meta, userID)
+	if err != nil {
+		return nil, err
+	}
+	if err := query.Validate(policies.restrict(schema)); err != nil {
+		return nil, err
+	}
+
+	// 软删除的记录不出现在查询结果中
+	if meta.Func.SoftDelete {
+		query.AddScope(model.ColumnDeletedAt + " IS NULL")
+	}
+
+	// 只返回用户数据权限内的记录
+	if err := applyDataScope(s.db, meta, userID, query); err != nil {
+		return nil, err
+	}
+	return policies, nil
+}
+
+// queryItems 分页查询数据表记录，支持页码分页和游标分页，返回的记录按用户的字段访问策略处理
+func (s *TableService) queryItems(meta *tableMeta, req *model.TableQueryReq, policies columnPolicies) (*model.TableQueryResp, error) {
+	computed, err := meta.computedFields()
+	if err != nil {
+		return nil, err
+	}
+
+	query := &req.Query
+	resp := &model.TableQueryResp{
+		Total:     -1,
+		CountMode: req.GetCountMode(),
+		PageSize:  req.PageSize,
+	}
+
+	source, keys, err := s.querySourceKeys(meta, req)
+	if err != nil {
+		return nil, err
+	}
+	baseQuery, args := query.BuildQuery(source)
 
 	// 查询总数
-	var total int
-	countQuery := fmt.Sprintf("SELECT COUNT(*) FROM (%s) AS t", baseQuery)
-	err = s.db.Get(&total, countQuery, args...)
+	switch resp.CountMode {
+	case model.CountExact:
+		resp.Total, err = s.countItems(baseQuery, args)
+	case model.CountEstimate:
+		if query.IsAggregate() {
+			resp.Total, err = s.countItems(baseQuery, args)
+		} else {
+			resp.Total, err = s.estimateItems(baseQuery, args)
+		}
+	}
 	if err != nil {
-		return nil, 0, fmt.Errorf("count table items failed: %v", err)
+		return nil, err
 	}
 
-	// 添加分页
-	offset := (page - 1) * pageSize
-	baseQuery += " LIMIT ? OFFSET ?"
-	args = append(args, pageSize, offset)
+	if !req.IsCursor() {
+		resp.Page = req.Page
+	}
+	baseQuery, args, err = pageQuery(req, source, keys)
+	if err != nil {
+		return nil, err
+	}
 
-	// 打印SQL
-	fmt.Println(baseQuery, args)
 	// 查询记录
 	rows, err := s.db.Queryx(baseQuery, args...)
 	if err != nil {
-		return nil, 0, fmt.Errorf("list table items failed: %v", err)
+		return nil, fmt.Errorf("list table items failed: %v", err)
 	}
 	defer rows.Close()
 
 	// 解析结果
-	var results []map[string]interface{}
+	results := make([]map[string]interface{}, 0, req.PageSize+1)
 	for rows.Next() {
 		row := make(map[string]interface{})
 		err = rows.MapScan(row)
 		if err != nil {
-			return nil, 0, fmt.Errorf("list table items failed: %v", err)
+			return nil, fmt.Errorf("list table items failed: %v", err)
 		}
 
 		// 将时间字段转换为指定格式
@@ -75,80 +292,202 @@ func (s *TableService) GetTableItems(tableID uint, page int, pageSize int, query
 
 	// 将结果中的字节数组转换为字符串
 	results = utils.ConvertBytesToString(results).([]map[string]interface{})
-	return results, total, nil
+
+	if len(results) > req.PageSize {
+		resp.HasMore = true
+		results = results[:req.PageSize]
+	}
+	if req.IsCursor() && resp.HasMore {
+		resp.NextCursor, err = encodeCursor(keys, results[len(results)-1])
+		if err != nil {
+			return nil, fmt.Errorf("encode cursor failed: %v", err)
+		}
+	}
+
+	// 计算虚拟字段，聚合查询的结果不是原始记录，不计算虚拟字段
+	if !query.IsAggregate() {
+		applyVirtualFields(computed, results)
+	}
+	policies.apply(results)
+	resp.Items = results
+	return resp, nil
+}
+
+// querySourceKeys 获取查询记录的数据源，游标分页时按排序字段加主键排序并返回排序键
+// 包含归档记录时数据源合并数据表和归档表
+func (s *TableService) querySourceKeys(meta *tableMeta, req *model.TableQueryReq) (string, []sortKey, error) {
+	var keys []sortKey
+	if req.IsCursor() {
+		schema, err := getTableSchema(s.db, meta.TableName)
+		if err != nil {
+			return "", nil, err
+		}
+		keys, err = cursorSortKeys(&req.Query, schema)
+		if err != nil {
+			return "", nil, err
+		}
+		req.Query.OrderBy = sortKeyOrderBy(keys)
+	}
+
+	source, err := s.querySource(meta, req.IncludeArchived)
+	if err != nil {
+		return "", nil, err
+	}
+	return source, keys, nil
+}
+
+// pageQuery 构建查询一页记录的SQL，多查询一条用于判断是否还有下一页
+func pageQuery(req *model.TableQueryReq, source string, keys []sortKey) (string, []interface{}, error) {
+	query := &req.Query
+	if !req.IsCursor() {
+		baseQuery, args := query.BuildQuery(source)
+		offset := (req.Page - 1) * req.PageSize
+		return baseQuery + " LIMIT ? OFFSET ?", append(args, req.PageSize+1, offset), nil
+	}
+
+	if req.Cursor != "" {
+		values, err := decodeCursor(req.Cursor, keys)
+		if err != nil {
+			return "", nil, err
+		}
+		query.AddScope(keysetClause(keys, values))
+	}
+	baseQuery, args := query.BuildQuery(source)
+	return baseQuery + " LIMIT ?", append(args, req.PageSize+1), nil
+}
+
+// countItems 精确统计记录总数
+func (s *TableService) countItems(baseQuery string, args []interface{}) (int64, error) {
+	var total int64
+	countQuery := fmt.Sprintf("SELECT COUNT(*) FROM (%s) AS t", baseQuery)
+	if err := s.db.Get(&total, countQuery, args...); err != nil {
+		return 0, fmt.Errorf("count table items failed: %v", err)
+	}
+	return total, nil
 }
 
-// BatchCreateTableItems 批量创建数据表记录
-func (s *TableService) CreateTableItems(tableItems []map[string]interface{}, creatorID uint, tableID uint) error {
+// estimateItems 根据执行计划估算记录总数
+func (s *TableService) estimateItems(baseQuery string, args []interface{}) (int64, error) {
+	rows, err := s.db.Queryx("EXPLAIN "+baseQuery, args...)
+	if err != nil {
+		return 0, fmt.Errorf("estimate table items failed: %v", err)
+	}
+	defer rows.Close()
+
+	if !rows.Next() {
+		return 0, nil
+	}
+	plan := make(map[string]interface{})
+	if err := rows.MapScan(plan); err != nil {
+		return 0, fmt.Errorf("estimate table items failed: %v", err)
+	}
+	plan = utils.ConvertBytesToString(plan).(map[string]interface{})
+
+	// 预计扫描行数乘以过滤比例
+	estimate, _ := strconv.ParseFloat(fmt.Sprint(plan["rows"]), 64)
+	if filtered, err := strconv.ParseFloat(fmt.Sprint(plan["filtered"]), 64); err == nil {
+		estimate = estimate * filtered / 100
+	}
+	return int64(estimate), nil
+}
+
+// CreateTableItems 批量创建数据表记录，upsert模式下主键或唯一键重复的记录更新已有记录
+func (s *TableService) CreateTableItems(tableItems []map[string]interface{}, creatorID uint, tableID uint, opts model.CreateTableItemsOptions) (*model.CreateTableItemsResp, error) {
+	if err := opts.Normalize(); err != nil {
+		return nil, err
+	}
+
 	// 开启事务
 	tx, err := s.db.Beginx()
 	if err != nil {
-		return fmt.Errorf("begin transaction failed: %v", err)
+		return nil, fmt.Errorf("begin transaction failed: %v", err)
 	}
 	defer tx.Rollback()
 
 	// 从配置表读取表配置
-	var tableName string
-	err = tx.Get(&tableName, "SELECT table_name FROM sys_config_tables WHERE id = ?", tableID)
+	meta, err := getTableMeta(tx, tableID)
 	if err != nil {
-		return fmt.Errorf("get table name failed: %v", err)
+		return nil, err
 	}
 
-	// 构建批量插入SQL
-	for _, item := range tableItems {
-		columns := make([]string, 0)
-		values := make([]string, 0)
-		args := make([]interface{}, 0)
+	// 检查字段写权限
+	policies, err := userColumnPolicies(tx, meta, creatorID)
+	if err != nil {
+		return nil, err
+	}
+	if err := policies.checkWritable(tableItems, nil); err != nil {
+		return nil, err
+	}
+	if opts.Mode == model.InsertModeUpsert && meta.Func.Versioned {
+		return nil, ErrUpsertVersioned
+	}
 
-		// 添加基础字段
-		if val, ok := item["creator_id"]; ok {
-			columns = append(columns, "creator_id")
-			values = append(values, "?")
-			args = append(args, val)
-		}
-		if val, ok := item["updater_id"]; ok {
-			columns = append(columns, "updater_id")
-			values = append(values, "?")
-			args = append(args, val)
+	// 写入的数据权限字段取值需在用户数据权限内，upsert只能更新用户数据权限内的记录
+	scope, err := userDataScope(tx, meta, creatorID)
+	if err != nil {
+		return nil, err
+	}
+
+	// 计算字段，记录版本和编号由系统维护
+	computed, err := meta.computedFields()
+	if err != nil {
+		return nil, err
+	}
+	sequences := meta.sequenceFields()
+	for _, item := range tableItems {
+		if meta.Func.Versioned {
+			delete(item, model.ColumnRowVersion)
 		}
-		if val, ok := item["created_at"]; ok {
-			columns = append(columns, "created_at")
-			values = append(values, "?")
-			args = append(args, val)
+		for _, field := range sequences {
+			delete(item, field)
 		}
-		if val, ok := item["updated_at"]; ok {
-			columns = append(columns, "updated_at")
-			values = append(values, "?")
-			args = append(args, val)
+		if err := computeStoredFields(computed, item, nil); err != nil {
+			return nil, err
 		}
+	}
 
-		// 添加动态字段
-		for k, v := range item {
-			if k != "creator_id" && k != "updater_id" && k != "created_at" && k != "updated_at" {
-				columns = append(columns, k)
-				values = append(values, "?")
-				args = append(args, v)
-			}
-		}
+	// 校验数据
+	schema, err := getTableSchema(tx, meta.TableName)
+	if err != nil {
+		return nil, err
+	}
+	if err := checkItemColumns(schema, tableItems); err != nil {
+		return nil, err
+	}
+	if err := validateItems(tx, meta, tableItems, false, nil); err != nil {
+		return nil, err
+	}
+	if err := checkScopeValues(tx, scope, tableItems, false); err != nil {
+		return nil, err
+	}
+	normalizeItems(meta, tableItems)
+	if opts.Mode == model.InsertModeUpsert && len(schema.UniqueKeys) == 0 {
+		return nil, ErrUpsertUnsupported
+	}
 
-		query := fmt.Sprintf("INSERT INTO %s (%s) VALUES (%s)",
-			tableName,
-			strings.Join(columns, ","),
-			strings.Join(values, ","))
+	if err := assignSequences(tx, meta, tableItems); err != nil {
+		return nil, err
+	}
 
-		// 执行插入
-		_, err := tx.Exec(query, args...)
-		if err != nil {
-			return fmt.Errorf("insert table item failed: %v", err)
+	resp := &model.CreateTableItemsResp{}
+	if len(schema.PrimaryKey) == 1 {
+		resp.IDs = make([]interface{}, len(tableItems))
+	}
+
+	// 按字段组合分批执行多行INSERT
+	for _, batch := range splitInsertBatches(tableItems, opts.BatchSize) {
+		if err := insertItems(tx, meta, schema, scope, tableItems, batch, opts, resp); err != nil {
+			return nil, err
 		}
 	}
 
 	// 提交事务
 	if err := tx.Commit(); err != nil {
-		return fmt.Errorf("commit transaction failed: %v", err)
+		return nil, fmt.Errorf("commit transaction failed: %v", err)
 	}
+	InvalidateTableCache(tableID)
 
-	return nil
+	return resp, nil
 }
 
 // UpdateTableItems 更新数据表记录
@@ -161,67 +500,168 @@ func (s *TableService) UpdateTableItems(req model.UpdateTableItemsRequest, updat
 	defer tx.Rollback()
 
 	// 从配置表读取表配置
-	var tableName string
-	err = tx.Get(&tableName, "SELECT table_name FROM sys_config_tables WHERE id = ?", tableID)
+	meta, err := getTableMeta(tx, tableID)
+	if err != nil {
+		return err
+	}
+	tableName := meta.TableName
+
+	// 按主键或唯一键定位记录，只能修改数据表字段
+	schema, err := getTableSchema(tx, tableName)
+	if err != nil {
+		return err
+	}
+	if err := checkKeyColumns(schema, req.PrimaryKeyColumns, req.Items); err != nil {
+		return err
+	}
+	if err := checkItemColumns(schema, req.Items); err != nil {
+		return err
+	}
+
+	// 只能修改用户数据权限内的记录和有写权限的字段
+	scope, err := userDataScope(tx, meta, updaterID)
 	if err != nil {
-		return fmt.Errorf("get table name failed: %v", err)
+		return err
+	}
+	policies, err := userColumnPolicies(tx, meta, updaterID)
+	if err != nil {
+		return err
+	}
+	if err := policies.checkWritable(req.Items, req.PrimaryKeyColumns); err != nil {
+		return err
+	}
+	if err := checkSequenceWrites(meta, req.Items, req.PrimaryKeyColumns); err != nil {
+		return err
+	}
+
+	// 乐观锁：取出客户端提交的记录版本，其余系统维护的字段不能修改
+	var versions []uint64
+	if meta.Func.Versioned {
+		versions, err = takeRowVersions(req.Items)
+		if err != nil {
+			return err
+		}
+	}
+	if err := checkManagedWrites(req.Items); err != nil {
+		return err
+	}
+
+	// 计算字段，存储的计算字段和字段比较规则需要结合记录当前值
+	computed, err := meta.computedFields()
+	if err != nil {
+		return err
+	}
+	var currents []map[string]interface{}
+	if hasStoredFields(computed) || meta.hasCompareRules() {
+		currents = make([]map[string]interface{}, len(req.Items))
+	}
+	for i, item := range req.Items {
+		var current map[string]interface{}
+		if currents != nil {
+			current, err = loadCurrentItem(tx, tableName, req.PrimaryKeyColumns, item)
+			if err != nil {
+				return err
+			}
+			currents[i] = current
+		}
+		if err := computeStoredFields(computed, item, current); err != nil {
+			return err
+		}
 	}
 
-	for _, item := range req.Items {
+	// 校验数据
+	if err := validateItems(tx, meta, req.Items, true, currents); err != nil {
+		return err
+	}
+	if err := checkScopeValues(tx, scope, req.Items, true); err != nil {
+		return err
+	}
+	normalizeItems(meta, req.Items)
+
+	for i, item := range req.Items {
 		// 构建更新SQL
 		sets := make([]string, 0)
 		args := make([]interface{}, 0)
 
 		// 添加基础字段
 		if val, ok := item["updater_id"]; ok {
-			sets = append(sets, "updater_id = ?")
+			sets = append(sets, "`updater_id` = ?")
 			args = append(args, val)
 		} else {
-			sets = append(sets, "updater_id = ?")
+			sets = append(sets, "`updater_id` = ?")
 			args = append(args, updaterID)
 		}
 		if val, ok := item["updated_at"]; ok {
-			sets = append(sets, "updated_at = ?")
+			sets = append(sets, "`updated_at` = ?")
 			args = append(args, val)
 		} else {
-			sets = append(sets, "updated_at = ?")
+			sets = append(sets, "`updated_at` = ?")
 			args = append(args, time.Now())
 		}
 
-		// 添加动态字段
-		primaryKeyValues := make([]interface{}, 0)
-		for k, v := range item {
+		// 添加动态字段，按字段名排序保证SQL稳定
+		fields := make([]string, 0, len(item))
+		for k := range item {
 			if k != "updater_id" && k != "updated_at" && !utils.Contains(req.PrimaryKeyColumns, k) {
-				sets = append(sets, fmt.Sprintf("%s = ?", k))
-				args = append(args, v)
-			} else if utils.Contains(req.PrimaryKeyColumns, k) {
-				primaryKeyValues = append(primaryKeyValues, v)
+				fields = append(fields, k)
 			}
 		}
+		sort.Strings(fields)
+		for _, k := range fields {
+			sets = append(sets, model.QuoteIdentifier(k)+" = ?")
+			args = append(args, item[k])
+		}
+		if meta.Func.Versioned {
+			quoted := model.QuoteIdentifier(model.ColumnRowVersion)
+			sets = append(sets, quoted+" = "+quoted+" + 1")
+		}
 
-		// 添加WHERE条件
+		// 添加WHERE条件，主键取值按主键列顺序排列
 		whereClauses := make([]string, 0)
 		for _, pk := range req.PrimaryKeyColumns {
-			whereClauses = append(whereClauses, fmt.Sprintf("%s = ?", pk))
+			whereClauses = append(whereClauses, model.QuoteIdentifier(pk)+" = ?")
+			args = append(args, item[pk])
+		}
+		if meta.Func.SoftDelete {
+			whereClauses = append(whereClauses, model.QuoteIdentifier(model.ColumnDeletedAt)+" IS NULL")
+		}
+		if meta.Func.Versioned {
+			whereClauses = append(whereClauses, model.QuoteIdentifier(model.ColumnRowVersion)+" = ?")
+			args = append(args, versions[i])
+		}
+		if scope != nil {
+			whereClauses = append(whereClauses, scope.clause)
+			args = append(args, scope.args...)
 		}
-		args = append(args, primaryKeyValues...)
 
 		query := fmt.Sprintf("UPDATE %s SET %s WHERE %s",
-			tableName,
+			model.QuoteIdentifier(tableName),
 			strings.Join(sets, ","),
 			strings.Join(whereClauses, " AND "))
 
 		// 执行更新
-		_, err = tx.Exec(query, args...)
+		result, err := tx.Exec(query, args...)
 		if err != nil {
 			return fmt.Errorf("update table item failed: %v", err)
 		}
+
+		// 版本号不一致时记录已被修改，版本号每次递增，因此匹配的记录一定会被更新
+		if meta.Func.Versioned {
+			affected, err := result.RowsAffected()
+			if err != nil {
+				return fmt.Errorf("update table item failed: %v", err)
+			}
+			if affected == 0 {
+				return versionConflict(tx, meta, scope, policies, i, req.PrimaryKeyColumns, item)
+			}
+		}
 	}
 
 	// 提交事务
 	if err := tx.Commit(); err != nil {
 		return fmt.Errorf("commit transaction failed: %v", err)
 	}
+	InvalidateTableCache(tableID)
 
 	return nil
 }
@@ -236,24 +676,74 @@ func (s *TableService) DeleteTableItems(operatorID uint, tableID uint, req []map
 	defer tx.Rollback()
 
 	// 从配置表读取表配置
-	var tableName string
-	err = tx.Get(&tableName, "SELECT table_name FROM sys_config_tables WHERE id = ?", tableID)
+	meta, err := getTableMeta(tx, tableID)
+	if err != nil {
+		return err
+	}
+	tableName := meta.TableName
+	schema, err := getTableSchema(tx, tableName)
+	if err != nil {
+		return err
+	}
+
+	// 只能删除用户数据权限内的记录
+	scope, err := userDataScope(tx, meta, operatorID)
+	if err != nil {
+		return err
+	}
+	policies, err := userColumnPolicies(tx, meta, operatorID)
 	if err != nil {
-		return fmt.Errorf("get table name failed: %v", err)
+		return err
 	}
 
-	for _, condition := range req {
+	// 乐观锁：取出客户端提交的记录版本
+	var versions []uint64
+	if meta.Func.Versioned {
+		versions, err = takeRowVersions(req)
+		if err != nil {
+			return err
+		}
+	}
+
+	for i, condition := range req {
+		// 每条删除条件按主键或唯一键定位记录，定位字段需对用户可读，空条件不匹配任何键
+		keyColumns := make([]string, 0, len(condition))
+		for col := range condition {
+			keyColumns = append(keyColumns, col)
+		}
+		if err := checkKeyColumns(schema, keyColumns, []map[string]interface{}{condition}); err != nil {
+			return err
+		}
+		if err := policies.checkWritable([]map[string]interface{}{condition}, keyColumns); err != nil {
+			return err
+		}
+
 		// 构建WHERE条件
-		whereClauses := make([]string, 0)
-		args := make([]interface{}, 0)
-		for col, val := range condition {
-			whereClauses = append(whereClauses, fmt.Sprintf("%s = ?", col))
-			args = append(args, val)
+		where, args := buildKeyWhere(condition)
+		if meta.Func.Versioned {
+			where += " AND " + model.QuoteIdentifier(model.ColumnRowVersion) + " = ?"
+			args = append(args, versions[i])
+		}
+		if scope != nil {
+			where += " AND " + scope.clause
+			args = append(args, scope.args...)
 		}
 
-		query := fmt.Sprintf("DELETE FROM %s WHERE %s",
-			tableName,
-			strings.Join(whereClauses, " AND "))
+		var query string
+		if meta.Func.SoftDelete {
+			// 软删除：仅标记删除时间和删除人
+			bump := ""
+			if meta.Func.Versioned {
+				quoted := model.QuoteIdentifier(model.ColumnRowVersion)
+				bump = fmt.Sprintf(", %s = %s + 1", quoted, quoted)
+			}
+			deletedAt := model.QuoteIdentifier(model.ColumnDeletedAt)
+			query = fmt.Sprintf("UPDATE %s SET %s = NOW(), %s = ?%s WHERE %s AND %s IS NULL",
+				model.QuoteIdentifier(tableName), deletedAt, model.QuoteIdentifier(model.ColumnDeletedBy), bump, where, deletedAt)
+			args = append([]interface{}{operatorID}, args...)
+		} else {
+			query = fmt.Sprintf("DELETE FROM %s WHERE %s", model.QuoteIdentifier(tableName), where)
+		}
 
 		// 使用预编译语句执行删除
 		stmt, err := tx.Preparex(query)
@@ -262,16 +752,27 @@ func (s *TableService) DeleteTableItems(operatorID uint, tableID uint, req []map
 		}
 		defer stmt.Close()
 
-		_, err = stmt.Exec(args...)
+		result, err := stmt.Exec(args...)
 		if err != nil {
 			return fmt.Errorf("delete table item failed: %v", err)
 		}
+
+		if meta.Func.Versioned {
+			affected, err := result.RowsAffected()
+			if err != nil {
+				return fmt.Errorf("delete table item failed: %v", err)
+			}
+			if affected == 0 {
+				return versionConflict(tx, meta, scope, policies, i, keyColumns, condition)
+			}
+		}
 	}
 
 	// 提交事务
 	if err := tx.Commit(); err != nil {
 		return fmt.Errorf("commit transaction failed: %v", err)
 	}
+	InvalidateTableCache(tableID)
 
 	return nil
 }
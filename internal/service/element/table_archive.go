@@ -0,0 +1,332 @@
+package element
+
+import (
+	"errors"
+	"fmt"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/iiwish/lingjian/internal/model"
+	"github.com/jmoiron/sqlx"
+)
+
+var (
+	// ErrArchiveDisabled 数据表未配置归档策略
+	ErrArchiveDisabled = errors.New("archive policy is not configured for this table")
+	// ErrArchiveRunning 数据表的归档正在执行
+	ErrArchiveRunning = errors.New("archive is already running for this table")
+)
+
+// archiveStaleAfter 执行中的归档记录超过该时长视为已中断，不再阻止新的归档
+const archiveStaleAfter = 6 * time.Hour
+
+// ArchiveTableItems 按数据表的归档策略将时间字段早于期限的记录分批移入归档表，每次执行记录一条归档记录
+// operatorID为0表示由定时任务执行
+func (s *TableService) ArchiveTableItems(tableID uint, operatorID uint) (*model.TableArchiveLog, error) {
+	meta, err := getTableMeta(s.db, tableID)
+	if err != nil {
+		return nil, err
+	}
+	policy := meta.Func.Archive
+	if policy == nil {
+		return nil, ErrArchiveDisabled
+	}
+	cutoff := time.Now().AddDate(0, 0, -policy.AfterDays)
+
+	logID, err := s.startArchiveLog(tableID, cutoff, operatorID)
+	if err != nil {
+		return nil, err
+	}
+	archived, runErr := s.archiveItems(meta, cutoff)
+	if archived > 0 {
+		InvalidateTableCache(tableID)
+	}
+
+	status, message := model.ArchiveStatusSuccess, ""
+	if runErr != nil {
+		status, message = model.ArchiveStatusFailed, runErr.Error()
+	}
+	_, err = s.db.Exec(`
+		UPDATE sys_table_archive_logs SET status = ?, archived = ?, error = ?, end_time = NOW() WHERE id = ?
+	`, status, archived, message, logID)
+	if err != nil {
+		return nil, fmt.Errorf("update archive log failed: %v", err)
+	}
+
+	var log model.TableArchiveLog
+	if err := s.db.Get(&log, "SELECT id, table_id, status, archived, cutoff, IFNULL(error, '') AS error, operator_id, start_time, end_time FROM sys_table_archive_logs WHERE id = ?", logID); err != nil {
+		return nil, fmt.Errorf("get archive log failed: %v", err)
+	}
+	return &log, runErr
+}
+
+// startArchiveLog 创建执行中的归档记录，同一数据表同时只能有一个归档在执行
+func (s *TableService) startArchiveLog(tableID uint, cutoff time.Time, operatorID uint) (uint, error) {
+	tx, err := s.db.Beginx()
+	if err != nil {
+		return 0, fmt.Errorf("begin transaction failed: %v", err)
+	}
+	defer tx.Rollback()
+
+	// 锁定数据表配置，避免并发的归档同时通过检查
+	var id uint
+	if err := tx.Get(&id, "SELECT id FROM sys_config_tables WHERE id = ? FOR UPDATE", tableID); err != nil {
+		return 0, fmt.Errorf("lock table config failed: %v", err)
+	}
+	var running int
+	err = tx.Get(&running, `
+		SELECT COUNT(*) FROM sys_table_archive_logs WHERE table_id = ? AND status = ? AND start_time > ?
+	`, tableID, model.ArchiveStatusRunning, time.Now().Add(-archiveStaleAfter))
+	if err != nil {
+		return 0, fmt.Errorf("check running archive failed: %v", err)
+	}
+	if running > 0 {
+		return 0, ErrArchiveRunning
+	}
+
+	result, err := tx.Exec(`
+		INSERT INTO sys_table_archive_logs (table_id, status, cutoff, operator_id, start_time, end_time)
+		VALUES (?, ?, ?, ?, NOW(), NOW())
+	`, tableID, model.ArchiveStatusRunning, cutoff, operatorID)
+	if err != nil {
+		return 0, fmt.Errorf("insert archive log failed: %v", err)
+	}
+	logID, err := result.LastInsertId()
+	if err != nil {
+		return 0, fmt.Errorf("get last insert id failed: %v", err)
+	}
+	if err := tx.Commit(); err != nil {
+		return 0, fmt.Errorf("commit transaction failed: %v", err)
+	}
+	return uint(logID), nil
+}
+
+// archiveItems 分批移动记录，每批在一个事务中写入归档表并从数据表删除，返回移动的记录数
+func (s *TableService) archiveItems(meta *tableMeta, cutoff time.Time) (int64, error) {
+	policy := meta.Func.Archive
+	schema, err := getTableSchema(s.db, meta.TableName)
+	if err != nil {
+		return 0, err
+	}
+	if len(schema.PrimaryKey) == 0 {
+		return 0, fmt.Errorf("table %s has no primary key", meta.TableName)
+	}
+	col, ok := schema.Column(policy.Column)
+	if !ok || col.Kind() != model.KindDate {
+		return 0, fmt.Errorf("archive column %s must be a date column", policy.Column)
+	}
+	if err := ensureArchiveTable(s.db, meta.TableName, schema); err != nil {
+		return 0, err
+	}
+
+	columns := schemaColumnNames(schema)
+	quoted := make([]string, len(columns))
+	for i, name := range columns {
+		quoted[i] = model.QuoteIdentifier(name)
+	}
+	colList := strings.Join(quoted, ", ")
+	archiveTable := model.QuoteIdentifier(model.ArchiveTableName(meta.TableName))
+
+	var total int64
+	for {
+		moved, err := s.archiveBatch(meta, schema, cutoff, policy.GetBatchSize(), archiveTable, colList)
+		total += moved
+		if err != nil {
+			return total, err
+		}
+		if moved < int64(policy.GetBatchSize()) {
+			return total, nil
+		}
+	}
+}
+
+// archiveBatch 锁定一批待归档的记录，复制到归档表后从数据表删除
+func (s *TableService) archiveBatch(meta *tableMeta, schema *model.TableSchema, cutoff time.Time, batchSize int, archiveTable string, colList string) (int64, error) {
+	tx, err := s.db.Beginx()
+	if err != nil {
+		return 0, fmt.Errorf("begin transaction failed: %v", err)
+	}
+	defer tx.Rollback()
+
+	// 软删除的记录留在数据表中，由回收站彻底删除
+	query := &model.QueryCondition{}
+	query.AddScope(model.QuoteIdentifier(meta.Func.Archive.Column)+" < ?", cutoff)
+	if meta.Func.SoftDelete {
+		query.AddScope(model.ColumnDeletedAt + " IS NULL")
+	}
+	keys, err := lockMatchedKeys(tx, meta, schema, query, batchSize)
+	if err != nil || len(keys) == 0 {
+		return 0, err
+	}
+
+	where, args := keyTupleWhere(schema.PrimaryKey, keys)
+	table := model.QuoteIdentifier(meta.TableName)
+	_, err = tx.Exec(fmt.Sprintf("INSERT INTO %s (%s) SELECT %s FROM %s WHERE %s", archiveTable, colList, colList, table, where), args...)
+	if err != nil {
+		return 0, fmt.Errorf("copy items to archive table failed: %v", err)
+	}
+	result, err := tx.Exec(fmt.Sprintf("DELETE FROM %s WHERE %s", table, where), args...)
+	if err != nil {
+		return 0, fmt.Errorf("delete archived items failed: %v", err)
+	}
+	moved, err := result.RowsAffected()
+	if err != nil {
+		return 0, fmt.Errorf("get rows affected failed: %v", err)
+	}
+	if err := tx.Commit(); err != nil {
+		return 0, fmt.Errorf("commit transaction failed: %v", err)
+	}
+	return moved, nil
+}
+
+// tableExists 检查数据库中是否存在指定的表
+func tableExists(q sqlx.Queryer, tableName string) (bool, error) {
+	var count int
+	err := sqlx.Get(q, &count, "SELECT COUNT(*) FROM information_schema.tables WHERE table_schema = DATABASE() AND table_name = ?", tableName)
+	if err != nil {
+		return false, fmt.Errorf("check table exists failed: %v", err)
+	}
+	return count > 0, nil
+}
+
+// ensureArchiveTable 创建与数据表结构相同的归档表，并同步数据表新增和修改类型的字段
+// 归档表去掉唯一索引，数据表中被归档的唯一值可以再次使用
+func ensureArchiveTable(db *sqlx.DB, tableName string, schema *model.TableSchema) error {
+	archiveName := model.ArchiveTableName(tableName)
+	var count int
+	err := db.Get(&count, "SELECT COUNT(*) FROM information_schema.tables WHERE table_schema = DATABASE() AND table_name = ?", archiveName)
+	if err != nil {
+		return fmt.Errorf("check archive table failed: %v", err)
+	}
+	archiveTable := model.QuoteIdentifier(archiveName)
+
+	if count == 0 {
+		_, err := db.Exec(fmt.Sprintf("CREATE TABLE %s LIKE %s", archiveTable, model.QuoteIdentifier(tableName)))
+		if err != nil {
+			return fmt.Errorf("create archive table failed: %v", err)
+		}
+		var uniqueIndexes []string
+		err = db.Select(&uniqueIndexes, `
+			SELECT DISTINCT index_name FROM information_schema.statistics
+			WHERE table_schema = DATABASE() AND table_name = ? AND non_unique = 0 AND index_name <> 'PRIMARY'
+		`, archiveName)
+		if err != nil {
+			return fmt.Errorf("get archive table indexes failed: %v", err)
+		}
+		for _, index := range uniqueIndexes {
+			if _, err := db.Exec(fmt.Sprintf("ALTER TABLE %s DROP INDEX %s", archiveTable, model.QuoteIdentifier(index))); err != nil {
+				return fmt.Errorf("drop archive table index failed: %v", err)
+			}
+		}
+		InvalidateTableSchema(archiveName)
+		return nil
+	}
+
+	archiveSchema, err := getTableSchema(db, archiveName)
+	if err != nil {
+		return err
+	}
+	var changed bool
+	for _, name := range schemaColumnNames(schema) {
+		col := schema.Columns[name]
+		archiveCol, ok := archiveSchema.Column(name)
+		if !ok {
+			_, err := db.Exec(fmt.Sprintf("ALTER TABLE %s ADD COLUMN %s %s NULL", archiveTable, model.QuoteIdentifier(name), col.ColumnType))
+			if err != nil {
+				return fmt.Errorf("add archive table column failed: %v", err)
+			}
+			changed = true
+			continue
+		}
+		if strings.EqualFold(archiveCol.ColumnType, col.ColumnType) {
+			continue
+		}
+
+		// 字段类型修改后同步到归档表，主键字段不能为NULL
+		def := col.ColumnType + " NULL"
+		if archiveCol.PrimaryKey {
+			def = col.ColumnType + " NOT NULL"
+			if archiveCol.AutoIncrement {
+				def += " AUTO_INCREMENT"
+			}
+		}
+		_, err := db.Exec(fmt.Sprintf("ALTER TABLE %s MODIFY COLUMN %s %s", archiveTable, model.QuoteIdentifier(name), def))
+		if err != nil {
+			return fmt.Errorf("modify archive table column failed: %v", err)
+		}
+		changed = true
+	}
+	if changed {
+		InvalidateTableSchema(archiveName)
+	}
+	return nil
+}
+
+// schemaColumnNames 获取数据表的全部字段名，按字段名排序
+func schemaColumnNames(schema *model.TableSchema) []string {
+	names := make([]string, 0, len(schema.Columns))
+	for name := range schema.Columns {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}
+
+// querySource 获取查询记录的数据源，同时查询归档表时合并数据表和归档表的记录
+// 归档表缺少的字段取NULL，合并结果使用数据表名作为别名
+func (s *TableService) querySource(meta *tableMeta, includeArchived bool) (string, error) {
+	table := model.QuoteIdentifier(meta.TableName)
+	if !includeArchived || meta.Func.Archive == nil {
+		return table, nil
+	}
+	archiveName := model.ArchiveTableName(meta.TableName)
+	exists, err := tableExists(s.db, archiveName)
+	if err != nil || !exists {
+		return table, err
+	}
+
+	schema, err := getTableSchema(s.db, meta.TableName)
+	if err != nil {
+		return "", err
+	}
+	archiveSchema, err := getTableSchema(s.db, archiveName)
+	if err != nil {
+		return "", err
+	}
+	columns := schemaColumnNames(schema)
+	current := make([]string, len(columns))
+	archived := make([]string, len(columns))
+	for i, name := range columns {
+		current[i] = model.QuoteIdentifier(name)
+		if _, ok := archiveSchema.Column(name); ok {
+			archived[i] = current[i]
+		} else {
+			archived[i] = "NULL AS " + current[i]
+		}
+	}
+	return fmt.Sprintf("(SELECT %s FROM %s UNION ALL SELECT %s FROM %s) AS %s",
+		strings.Join(current, ", "), table, strings.Join(archived, ", "), model.QuoteIdentifier(archiveName), table), nil
+}
+
+// GetArchiveLogs 分页获取数据表的归档记录
+func (s *TableService) GetArchiveLogs(tableID uint, page int, pageSize int) ([]model.TableArchiveLog, int, error) {
+	var total int
+	err := s.db.Get(&total, "SELECT COUNT(*) FROM sys_table_archive_logs WHERE table_id = ?", tableID)
+	if err != nil {
+		return nil, 0, fmt.Errorf("count archive logs failed: %v", err)
+	}
+
+	logs := []model.TableArchiveLog{}
+	err = s.db.Select(&logs, `
+		SELECT id, table_id, status, archived, cutoff, IFNULL(error, '') AS error, operator_id, start_time, end_time
+		FROM sys_table_archive_logs
+		WHERE table_id = ?
+		ORDER BY id DESC
+		LIMIT ? OFFSET ?
+	`, tableID, pageSize, (page-1)*pageSize)
+	if err != nil {
+		return nil, 0, fmt.Errorf("list archive logs failed: %v", err)
+	}
+	return logs, total, nil
+}
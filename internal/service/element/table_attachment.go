@@ -0,0 +1,434 @@
+package element
+
+import (
+	"bytes"
+	"context"
+	"crypto/hmac"
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"path/filepath"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/iiwish/lingjian/internal/model"
+	"github.com/iiwish/lingjian/pkg/storage"
+	"github.com/jmoiron/sqlx"
+	"github.com/spf13/viper"
+)
+
+// defaultLinkExpire 附件下载地址的默认有效期，单位秒
+const defaultLinkExpire = 3600
+
+// defaultCleanupGraceHours 清理未被引用的附件时，上传后保留的小时数，避免清理刚上传还未保存到记录的附件
+const defaultCleanupGraceHours = 24
+
+var (
+	// ErrAttachmentNotFound 附件不存在
+	ErrAttachmentNotFound = errors.New("attachment does not exist")
+	// ErrInvalidSignature 下载地址的签名无效或已过期
+	ErrInvalidSignature = errors.New("invalid or expired download link")
+	// ErrStorageUnavailable 未初始化文件存储
+	ErrStorageUnavailable = errors.New("file storage is not initialized")
+	// ErrSignSecretMissing 未配置附件下载地址的签名密钥
+	ErrSignSecretMissing = errors.New("storage.sign_secret is not configured")
+)
+
+// AttachmentError 上传的附件不符合附件字段的限制
+type AttachmentError struct {
+	Message string
+}
+
+func (e *AttachmentError) Error() string {
+	return "invalid attachment: " + e.Message
+}
+
+// attachmentField 获取附件字段的配置，字段不是附件字段时返回错误
+func (m *tableMeta) attachmentField(field string) (*model.FieldAttachment, error) {
+	opt, ok := m.FieldOptions[field]
+	if !ok || opt.Type != model.TypeAttachment {
+		return nil, &AttachmentError{Message: fmt.Sprintf("field %s is not an attachment field", field)}
+	}
+	return opt.Attachment, nil
+}
+
+// attachmentFields 获取数据表的附件字段，按字段名排序
+func (m *tableMeta) attachmentFields() []string {
+	var fields []string
+	for name, opt := range m.FieldOptions {
+		if opt.Type == model.TypeAttachment {
+			fields = append(fields, name)
+		}
+	}
+	sort.Strings(fields)
+	return fields
+}
+
+// UploadAttachment 上传附件，按附件字段的配置检查文件大小和类型，文件类型根据文件内容识别
+// 上传的附件需要写入记录的附件字段，长期未被引用的附件由清理任务删除
+func (s *TableService) UploadAttachment(tableID uint, field string, fileName string, r io.Reader, creatorID uint) (*model.AttachmentResp, error) {
+	if storage.Default == nil {
+		return nil, ErrStorageUnavailable
+	}
+	meta, err := getTableMeta(s.db, tableID)
+	if err != nil {
+		return nil, err
+	}
+	config, err := meta.attachmentField(field)
+	if err != nil {
+		return nil, err
+	}
+	policies, err := userColumnPolicies(s.db, meta, creatorID)
+	if err != nil {
+		return nil, err
+	}
+	if err := policies.checkWritable([]map[string]interface{}{{field: nil}}, nil); err != nil {
+		return nil, err
+	}
+
+	// 根据文件开头的内容识别文件类型
+	head := make([]byte, 512)
+	n, err := io.ReadFull(r, head)
+	if err != nil && err != io.ErrUnexpectedEOF && err != io.EOF {
+		return nil, fmt.Errorf("read attachment failed: %v", err)
+	}
+	head = head[:n]
+	mimeType := http.DetectContentType(head)
+	if !config.AllowsMimeType(mimeType) {
+		return nil, &AttachmentError{Message: fmt.Sprintf("file type %s is not allowed", mimeType)}
+	}
+
+	// 多读取一个字节用于判断是否超过大小限制
+	maxSize := config.GetMaxSize()
+	key, err := attachmentKey(meta)
+	if err != nil {
+		return nil, err
+	}
+	ctx := context.Background()
+	size, err := storage.Default.Save(ctx, key, io.LimitReader(io.MultiReader(bytes.NewReader(head), r), maxSize+1))
+	if err != nil {
+		return nil, err
+	}
+	if size > maxSize {
+		storage.Default.Delete(ctx, key)
+		return nil, &AttachmentError{Message: fmt.Sprintf("file size exceeds %d bytes", maxSize)}
+	}
+
+	attachment := model.Attachment{
+		AppID:      meta.AppID,
+		TableID:    tableID,
+		FieldName:  field,
+		StorageKey: key,
+		FileName:   filepath.Base(fileName),
+		MimeType:   mimeType,
+		Size:       size,
+		CreatorID:  creatorID,
+	}
+	result, err := s.db.NamedExec(`
+		INSERT INTO sys_attachments (app_id, table_id, field_name, storage_key, file_name, mime_type, size, creator_id, created_at)
+		VALUES (:app_id, :table_id, :field_name, :storage_key, :file_name, :mime_type, :size, :creator_id, NOW())
+	`, attachment)
+	if err != nil {
+		storage.Default.Delete(ctx, key)
+		return nil, fmt.Errorf("insert sys_attachments failed: %v", err)
+	}
+	id, err := result.LastInsertId()
+	if err != nil {
+		return nil, fmt.Errorf("get last insert id failed: %v", err)
+	}
+	attachment.ID = uint(id)
+	return attachmentResp(&attachment)
+}
+
+// attachmentKey 生成附件的存储路径，不使用原始文件名
+func attachmentKey(meta *tableMeta) (string, error) {
+	random := make([]byte, 16)
+	if _, err := rand.Read(random); err != nil {
+		return "", fmt.Errorf("generate attachment key failed: %v", err)
+	}
+	return fmt.Sprintf("%d/%d/%s/%s", meta.AppID, meta.ID, time.Now().Format("200601"), hex.EncodeToString(random)), nil
+}
+
+// GetAttachment 获取附件信息和带签名的下载地址，用户需要有附件字段的读权限
+// 附件需要被用户可见的记录引用，按数据权限和软删除过滤记录
+func (s *TableService) GetAttachment(tableID uint, id uint, userID uint) (*model.AttachmentResp, error) {
+	meta, err := getTableMeta(s.db, tableID)
+	if err != nil {
+		return nil, err
+	}
+	attachment, err := getAttachment(s.db, id)
+	if err != nil {
+		return nil, err
+	}
+	if attachment.TableID != tableID {
+		return nil, ErrAttachmentNotFound
+	}
+	query := &model.QueryCondition{}
+	policies, err := s.prepareQuery(meta, query, userID)
+	if err != nil {
+		return nil, err
+	}
+	if !policies.readable(attachment.FieldName) {
+		return nil, &ColumnForbiddenError{Fields: []string{attachment.FieldName}}
+	}
+
+	// 查找引用附件的记录
+	query.AddScope("JSON_CONTAINS("+model.QuoteIdentifier(attachment.FieldName)+", CAST(? AS JSON))", id)
+	matched, err := countMatched(s.db, meta, query)
+	if err != nil {
+		return nil, err
+	}
+	if matched == 0 {
+		return nil, ErrAttachmentNotFound
+	}
+	return attachmentResp(attachment)
+}
+
+// OpenAttachment 校验下载地址的签名并打开附件
+func (s *TableService) OpenAttachment(id uint, expires int64, signature string) (*model.Attachment, io.ReadCloser, error) {
+	if storage.Default == nil {
+		return nil, nil, ErrStorageUnavailable
+	}
+	expected, err := signAttachment(id, expires)
+	if err != nil {
+		return nil, nil, err
+	}
+	if time.Now().Unix() > expires || !hmac.Equal([]byte(signature), []byte(expected)) {
+		return nil, nil, ErrInvalidSignature
+	}
+	attachment, err := getAttachment(s.db, id)
+	if err != nil {
+		return nil, nil, err
+	}
+	file, err := storage.Default.Open(context.Background(), attachment.StorageKey)
+	if errors.Is(err, storage.ErrNotFound) {
+		return nil, nil, ErrAttachmentNotFound
+	}
+	if err != nil {
+		return nil, nil, err
+	}
+	return attachment, file, nil
+}
+
+// getAttachment 读取附件记录
+func getAttachment(q sqlx.Queryer, id uint) (*model.Attachment, error) {
+	var attachment model.Attachment
+	err := sqlx.Get(q, &attachment, "SELECT * FROM sys_attachments WHERE id = ?", id)
+	if err != nil {
+		if strings.Contains(err.Error(), "no rows") {
+			return nil, ErrAttachmentNotFound
+		}
+		return nil, fmt.Errorf("get attachment failed: %v", err)
+	}
+	return &attachment, nil
+}
+
+// attachmentResp 生成附件信息响应，包含带签名的下载地址
+func attachmentResp(attachment *model.Attachment) (*model.AttachmentResp, error) {
+	expire := viper.GetInt64("storage.link_expire")
+	if expire <= 0 {
+		expire = defaultLinkExpire
+	}
+	expires := time.Now().Unix() + expire
+	signature, err := signAttachment(attachment.ID, expires)
+	if err != nil {
+		return nil, err
+	}
+	return &model.AttachmentResp{
+		ID:        attachment.ID,
+		FileName:  attachment.FileName,
+		MimeType:  attachment.MimeType,
+		Size:      attachment.Size,
+		URL:       fmt.Sprintf("/api/v1/attachments/%d/download?expires=%d&signature=%s", attachment.ID, expires, signature),
+		ExpiresAt: expires,
+	}, nil
+}
+
+// signAttachment 计算附件下载地址的签名，签名密钥storage.sign_secret必须单独配置
+func signAttachment(id uint, expires int64) (string, error) {
+	secret := viper.GetString("storage.sign_secret")
+	if secret == "" {
+		return "", ErrSignSecretMissing
+	}
+	mac := hmac.New(sha256.New, []byte(secret))
+	mac.Write([]byte(strconv.FormatUint(uint64(id), 10) + ":" + strconv.FormatInt(expires, 10)))
+	return hex.EncodeToString(mac.Sum(nil)), nil
+}
+
+// checkAttachments 检查记录中附件字段的取值，取值为附件ID数组，附件需要是上传到该字段的附件
+// 校验通过的取值转换为JSON字符串写入数据库
+func checkAttachments(q sqlx.Queryer, meta *tableMeta, items []map[string]interface{}) ([]model.FieldError, error) {
+	var errs []model.FieldError
+	for _, field := range meta.attachmentFields() {
+		config := meta.FieldOptions[field].Attachment
+		rows := make(map[uint][]int)
+		for row, item := range items {
+			value, ok := item[field]
+			if !ok || value == nil {
+				continue
+			}
+			ids, ok := attachmentIDs(value)
+			if !ok {
+				errs = append(errs, model.FieldError{Row: row, Field: field, Rule: model.RuleAttachment, Message: "必须是附件ID数组"})
+				continue
+			}
+			if len(ids) > config.GetMaxFiles() {
+				errs = append(errs, model.FieldError{Row: row, Field: field, Rule: model.RuleAttachment, Message: fmt.Sprintf("附件不能超过%d个", config.GetMaxFiles())})
+				continue
+			}
+			for _, id := range ids {
+				rows[id] = append(rows[id], row)
+			}
+			encoded, _ := json.Marshal(ids)
+			item[field] = string(encoded)
+		}
+		if len(rows) == 0 {
+			continue
+		}
+
+		ids := make([]uint, 0, len(rows))
+		for id := range rows {
+			ids = append(ids, id)
+		}
+		sort.Slice(ids, func(i, j int) bool { return ids[i] < ids[j] })
+		query, args, err := sqlx.In("SELECT id FROM sys_attachments WHERE table_id = ? AND field_name = ? AND id IN (?)", meta.ID, field, ids)
+		if err != nil {
+			return nil, fmt.Errorf("prepare attachment query failed: %v", err)
+		}
+		var found []uint
+		if err := sqlx.Select(q, &found, query, args...); err != nil {
+			return nil, fmt.Errorf("check attachments failed: %v", err)
+		}
+		exists := make(map[uint]bool, len(found))
+		for _, id := range found {
+			exists[id] = true
+		}
+		for _, id := range ids {
+			if exists[id] {
+				continue
+			}
+			for _, row := range rows[id] {
+				errs = append(errs, model.FieldError{Row: row, Field: field, Rule: model.RuleAttachment, Message: fmt.Sprintf("附件%d不存在", id)})
+			}
+		}
+	}
+	return errs, nil
+}
+
+// attachmentIDs 解析附件字段的取值，支持附件ID数组或其JSON字符串
+func attachmentIDs(value interface{}) ([]uint, bool) {
+	if s, ok := value.(string); ok {
+		if err := json.Unmarshal([]byte(s), &value); err != nil {
+			return nil, false
+		}
+	}
+	list, ok := value.([]interface{})
+	if !ok {
+		return nil, false
+	}
+	ids := make([]uint, 0, len(list))
+	for _, v := range list {
+		num, ok := v.(float64)
+		if !ok || num <= 0 || num != float64(uint(num)) {
+			return nil, false
+		}
+		ids = append(ids, uint(num))
+	}
+	return ids, true
+}
+
+// CleanupAttachments 删除数据表中未被任何记录引用的附件，包括记录删除后遗留的附件和上传后未保存的附件
+// 上传时间在graceHours小时内的附件不删除，已软删除和已归档的记录仍引用其附件，彻底删除后才清理
+func (s *TableService) CleanupAttachments(tableID uint, graceHours int) (int64, error) {
+	if storage.Default == nil {
+		return 0, ErrStorageUnavailable
+	}
+	meta, err := getTableMeta(s.db, tableID)
+	if err != nil {
+		return 0, err
+	}
+	if graceHours <= 0 {
+		graceHours = defaultCleanupGraceHours
+	}
+
+	// 归档的记录仍引用其附件
+	var archiveSchema *model.TableSchema
+	archiveName := model.ArchiveTableName(meta.TableName)
+	exists, err := tableExists(s.db, archiveName)
+	if err != nil {
+		return 0, err
+	}
+	if exists {
+		if archiveSchema, err = getTableSchema(s.db, archiveName); err != nil {
+			return 0, err
+		}
+	}
+
+	var total int64
+	ctx := context.Background()
+	for _, field := range meta.attachmentFields() {
+		column := model.QuoteIdentifier(field)
+		referenced := fmt.Sprintf("EXISTS (SELECT 1 FROM %s t WHERE JSON_CONTAINS(t.%s, CAST(a.id AS JSON)))",
+			model.QuoteIdentifier(meta.TableName), column)
+		if archiveSchema != nil {
+			if _, ok := archiveSchema.Column(field); ok {
+				referenced += fmt.Sprintf(" OR EXISTS (SELECT 1 FROM %s t WHERE JSON_CONTAINS(t.%s, CAST(a.id AS JSON)))",
+					model.QuoteIdentifier(archiveName), column)
+			}
+		}
+
+		var orphans []model.Attachment
+		err := s.db.Select(&orphans, `
+			SELECT a.* FROM sys_attachments a
+			WHERE a.table_id = ? AND a.field_name = ? AND a.created_at < NOW() - INTERVAL ? HOUR
+			AND NOT (`+referenced+`)
+		`, tableID, field, graceHours)
+		if err != nil {
+			return total, fmt.Errorf("find orphan attachments failed: %v", err)
+		}
+
+		for _, attachment := range orphans {
+			if err := storage.Default.Delete(ctx, attachment.StorageKey); err != nil {
+				return total, err
+			}
+			if _, err := s.db.Exec("DELETE FROM sys_attachments WHERE id = ?", attachment.ID); err != nil {
+				return total, fmt.Errorf("delete attachment failed: %v", err)
+			}
+			total++
+		}
+	}
+	return total, nil
+}
+
+// DeleteTableAttachments 删除数据表的全部附件文件和附件记录，彻底删除数据表时调用
+// 每个附件先删除文件再删除记录，中途失败时可以重试
+func DeleteTableAttachments(db *sqlx.DB, tableID uint) error {
+	var attachments []model.Attachment
+	if err := db.Select(&attachments, "SELECT * FROM sys_attachments WHERE table_id = ?", tableID); err != nil {
+		return fmt.Errorf("list table attachments failed: %v", err)
+	}
+	if len(attachments) == 0 {
+		return nil
+	}
+	if storage.Default == nil {
+		return ErrStorageUnavailable
+	}
+
+	ctx := context.Background()
+	for _, attachment := range attachments {
+		if err := storage.Default.Delete(ctx, attachment.StorageKey); err != nil {
+			return err
+		}
+		if _, err := db.Exec("DELETE FROM sys_attachments WHERE id = ?", attachment.ID); err != nil {
+			return fmt.Errorf("delete attachment failed: %v", err)
+		}
+	}
+	return nil
+}
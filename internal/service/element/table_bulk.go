@@ -0,0 +1,454 @@
+package element
+
+import (
+	"encoding/json"
+	"fmt"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/iiwish/lingjian/internal/model"
+	"github.com/iiwish/lingjian/pkg/utils"
+	"github.com/jmoiron/sqlx"
+)
+
+// BulkLimitError 匹配的记录数超过批量操作上限
+type BulkLimitError struct {
+	Matched int64 `json:"matched"` // 匹配条件的记录数
+	Limit   int   `json:"limit"`   // 最多影响的记录数
+}
+
+func (e *BulkLimitError) Error() string {
+	return fmt.Sprintf("%d items matched, exceeding the limit of %d", e.Matched, e.Limit)
+}
+
+// prepareBulk 读取表配置并校验批量操作的过滤条件，返回最多影响的记录数
+func prepareBulk(q sqlx.Queryer, tableID uint, req *model.TableBulkReq, userID uint) (*tableMeta, *model.TableSchema, int, error) {
+	if err := req.Validate(); err != nil {
+		return nil, nil, 0, err
+	}
+
+	meta, err := getTableMeta(q, tableID)
+	if err != nil {
+		return nil, nil, 0, err
+	}
+	schema, err := getTableSchema(q, meta.TableName)
+	if err != nil {
+		return nil, nil, 0, err
+	}
+	if len(schema.PrimaryKey) == 0 {
+		return nil, nil, 0, &model.QueryError{Message: "bulk operations require a primary key"}
+	}
+
+	// 用户不可读的字段不能用于过滤条件，没有写权限的字段不能修改
+	policies, err := userColumnPolicies(q, meta, userID)
+	if err != nil {
+		return nil, nil, 0, err
+	}
+	if err := req.Query.Validate(policies.restrict(schema)); err != nil {
+		return nil, nil, 0, err
+	}
+	if req.Patch != nil {
+		if err := policies.checkWritable([]map[string]interface{}{req.Patch}, nil); err != nil {
+			return nil, nil, 0, err
+		}
+		if err := checkSequenceWrites(meta, []map[string]interface{}{req.Patch}, nil); err != nil {
+			return nil, nil, 0, err
+		}
+	}
+
+	// 已软删除的记录不参与批量操作
+	if meta.Func.SoftDelete {
+		req.Query.AddScope(model.ColumnDeletedAt + " IS NULL")
+	}
+
+	// 只操作用户数据权限内的记录
+	if err := applyDataScope(q, meta, userID, &req.Query); err != nil {
+		return nil, nil, 0, err
+	}
+
+	limit := meta.Func.GetBulkLimit()
+	if req.MaxAffected > 0 && req.MaxAffected < limit {
+		limit = req.MaxAffected
+	}
+	return meta, schema, limit, nil
+}
+
+// checkBulkPatch 校验批量修改的字段值并按字段类型转换，修改的数据权限字段取值需在用户数据权限内
+// 涉及记录当前值的字段比较规则在锁定记录后由checkBulkCompare逐条校验
+func checkBulkPatch(q sqlx.Queryer, meta *tableMeta, schema *model.TableSchema, patch map[string]interface{}, userID uint) error {
+	if len(patch) == 0 {
+		return &model.QueryError{Message: "patch is required"}
+	}
+	if err := checkItemColumns(schema, []map[string]interface{}{patch}); err != nil {
+		return err
+	}
+	for field := range patch {
+		col, _ := schema.Column(field)
+		switch {
+		case col.PrimaryKey:
+			return &model.QueryError{Field: field, Message: "primary key cannot be patched"}
+		case meta.Func.SoftDelete && (field == model.ColumnDeletedAt || field == model.ColumnDeletedBy),
+			meta.Func.Versioned && field == model.ColumnRowVersion:
+			return &model.QueryError{Field: field, Message: "managed column cannot be patched"}
+		}
+	}
+
+	// 存储的计算字段按记录计算，不能通过批量修改更新
+	computed, err := meta.computedFields()
+	if err != nil {
+		return err
+	}
+	changed := make(map[string]bool, len(patch))
+	for field := range patch {
+		changed[field] = true
+	}
+	for _, f := range computed {
+		if changed[f.name] {
+			if !f.virtual {
+				return &model.QueryError{Field: f.name, Message: "computed field cannot be patched"}
+			}
+			continue
+		}
+		for _, ref := range f.expr.Refs() {
+			if !changed[ref] {
+				continue
+			}
+			if !f.virtual {
+				return &model.QueryError{Field: ref, Message: "field is referenced by stored computed field " + f.name}
+			}
+			changed[f.name] = true
+			break
+		}
+	}
+
+	if err := validateItems(q, meta, []map[string]interface{}{patch}, true, nil); err != nil {
+		return err
+	}
+	scope, err := userDataScope(q, meta, userID)
+	if err != nil {
+		return err
+	}
+	if err := checkScopeValues(q, scope, []map[string]interface{}{patch}, true); err != nil {
+		return err
+	}
+	normalizeItems(meta, []map[string]interface{}{patch})
+	return nil
+}
+
+// checkBulkCompare 按锁定的每条记录校验批量修改的字段比较规则，比较中未修改的字段使用记录当前值
+// 错误中的行号为记录在锁定主键中的顺序
+func checkBulkCompare(tx *sqlx.Tx, meta *tableMeta, schema *model.TableSchema, patch map[string]interface{}, keys []map[string]interface{}) error {
+	if !meta.hasCompareRules() || len(keys) == 0 {
+		return nil
+	}
+
+	cols := make([]string, len(schema.PrimaryKey))
+	for i, pk := range schema.PrimaryKey {
+		cols[i] = model.QuoteIdentifier(pk)
+	}
+	where, args := keyTupleWhere(schema.PrimaryKey, keys)
+	rows, err := tx.Queryx("SELECT * FROM "+model.QuoteIdentifier(meta.TableName)+" WHERE "+where+" ORDER BY "+strings.Join(cols, ","), args...)
+	if err != nil {
+		return fmt.Errorf("get current items failed: %v", err)
+	}
+	defer rows.Close()
+
+	var currents []map[string]interface{}
+	for rows.Next() {
+		current := make(map[string]interface{})
+		if err := rows.MapScan(current); err != nil {
+			return fmt.Errorf("get current items failed: %v", err)
+		}
+		currents = append(currents, utils.ConvertBytesToString(current).(map[string]interface{}))
+	}
+
+	// 按字段名排序，保证错误顺序稳定
+	fields := make([]string, 0, len(meta.FieldOptions))
+	for name := range meta.FieldOptions {
+		fields = append(fields, name)
+	}
+	sort.Strings(fields)
+
+	var errs []model.FieldError
+	for row, current := range currents {
+		for _, field := range fields {
+			for _, rule := range meta.FieldOptions[field].Rules {
+				if rule.Type != model.RuleCompare {
+					continue
+				}
+				if fe := compareRow(row, patch, current, field, rule); fe != nil {
+					errs = append(errs, *fe)
+				}
+			}
+		}
+	}
+	if len(errs) > 0 {
+		return &ValidationError{Errors: errs}
+	}
+	return nil
+}
+
+// PreviewBulkItems 预览按条件批量操作匹配的记录数，传入patch时同时校验修改的字段值
+func (s *TableService) PreviewBulkItems(tableID uint, req *model.TableBulkReq, userID uint) (*model.TableBulkResp, error) {
+	meta, schema, limit, err := prepareBulk(s.db, tableID, req, userID)
+	if err != nil {
+		return nil, err
+	}
+	if req.Patch != nil {
+		if err := checkBulkPatch(s.db, meta, schema, req.Patch, userID); err != nil {
+			return nil, err
+		}
+	}
+
+	matched, err := countMatched(s.db, meta, &req.Query)
+	if err != nil {
+		return nil, err
+	}
+	return &model.TableBulkResp{Matched: matched, Limit: limit}, nil
+}
+
+// BulkUpdateTableItems 按条件批量修改记录
+func (s *TableService) BulkUpdateTableItems(tableID uint, req *model.TableBulkReq, operatorID uint) (*model.TableBulkResp, error) {
+	return s.runBulk(tableID, req, operatorID, model.OperationBulkUpdate)
+}
+
+// BulkDeleteTableItems 按条件批量删除记录，启用软删除的数据表移入回收站
+func (s *TableService) BulkDeleteTableItems(tableID uint, req *model.TableBulkReq, operatorID uint) (*model.TableBulkResp, error) {
+	req.Patch = nil
+	return s.runBulk(tableID, req, operatorID, model.OperationBulkDelete)
+}
+
+// runBulk 锁定匹配的记录，按主键执行批量操作并记录操作日志
+func (s *TableService) runBulk(tableID uint, req *model.TableBulkReq, operatorID uint, operation string) (*model.TableBulkResp, error) {
+	// 开启事务
+	tx, err := s.db.Beginx()
+	if err != nil {
+		return nil, fmt.Errorf("begin transaction failed: %v", err)
+	}
+	defer tx.Rollback()
+
+	meta, schema, limit, err := prepareBulk(tx, tableID, req, operatorID)
+	if err != nil {
+		return nil, err
+	}
+	if operation == model.OperationBulkUpdate {
+		if err := checkBulkPatch(tx, meta, schema, req.Patch, operatorID); err != nil {
+			return nil, err
+		}
+	}
+
+	// 锁定匹配的记录，多查询一条用于判断是否超出上限
+	keys, err := lockMatchedKeys(tx, meta, schema, &req.Query, limit+1)
+	if err != nil {
+		return nil, err
+	}
+	if len(keys) > limit {
+		matched, err := countMatched(tx, meta, &req.Query)
+		if err != nil {
+			return nil, err
+		}
+		return nil, &BulkLimitError{Matched: matched, Limit: limit}
+	}
+	if operation == model.OperationBulkUpdate {
+		if err := checkBulkCompare(tx, meta, schema, req.Patch, keys); err != nil {
+			return nil, err
+		}
+	}
+
+	resp := &model.TableBulkResp{Matched: int64(len(keys)), Limit: limit}
+	if len(keys) > 0 {
+		query, args := buildBulkStatement(meta, schema, operation, req.Patch, operatorID, keys)
+		result, err := tx.Exec(query, args...)
+		if err != nil {
+			return nil, fmt.Errorf("%s table items failed: %v", strings.TrimPrefix(operation, "bulk_"), err)
+		}
+		if resp.Affected, err = result.RowsAffected(); err != nil {
+			return nil, fmt.Errorf("get affected rows failed: %v", err)
+		}
+	}
+
+	// 记录操作日志
+	resp.OperationID, err = saveTableOperation(tx, meta, operation, req, schema.PrimaryKey, keys, operatorID)
+	if err != nil {
+		return nil, err
+	}
+
+	// 提交事务
+	if err := tx.Commit(); err != nil {
+		return nil, fmt.Errorf("commit transaction failed: %v", err)
+	}
+	InvalidateTableCache(tableID)
+
+	return resp, nil
+}
+
+// countMatched 统计匹配条件的记录数
+func countMatched(q sqlx.Queryer, meta *tableMeta, query *model.QueryCondition) (int64, error) {
+	var matched int64
+	sql := "SELECT COUNT(*) FROM " + model.QuoteIdentifier(meta.TableName)
+	where, args := query.BuildWhere()
+	if where != "" {
+		sql += " WHERE " + where
+	}
+	if err := sqlx.Get(q, &matched, sql, args...); err != nil {
+		return 0, fmt.Errorf("count table items failed: %v", err)
+	}
+	return matched, nil
+}
+
+// lockMatchedKeys 查询并锁定匹配条件的记录主键
+func lockMatchedKeys(tx *sqlx.Tx, meta *tableMeta, schema *model.TableSchema, query *model.QueryCondition, limit int) ([]map[string]interface{}, error) {
+	cols := make([]string, len(schema.PrimaryKey))
+	for i, pk := range schema.PrimaryKey {
+		cols[i] = model.QuoteIdentifier(pk)
+	}
+	sql := "SELECT " + strings.Join(cols, ",") + " FROM " + model.QuoteIdentifier(meta.TableName)
+	where, args := query.BuildWhere()
+	if where != "" {
+		sql += " WHERE " + where
+	}
+	sql += " ORDER BY " + strings.Join(cols, ",") + " LIMIT ? FOR UPDATE"
+	args = append(args, limit)
+
+	rows, err := tx.Queryx(sql, args...)
+	if err != nil {
+		return nil, fmt.Errorf("lock table items failed: %v", err)
+	}
+	defer rows.Close()
+
+	var keys []map[string]interface{}
+	for rows.Next() {
+		key := make(map[string]interface{}, len(cols))
+		if err := rows.MapScan(key); err != nil {
+			return nil, fmt.Errorf("lock table items failed: %v", err)
+		}
+		keys = append(keys, key)
+	}
+	if keys == nil {
+		return nil, nil
+	}
+	return utils.ConvertBytesToString(keys).([]map[string]interface{}), nil
+}
+
+// buildBulkStatement 构建按主键批量修改或删除的SQL
+func buildBulkStatement(meta *tableMeta, schema *model.TableSchema, operation string, patch map[string]interface{}, operatorID uint, keys []map[string]interface{}) (string, []interface{}) {
+	var sets []string
+	var args []interface{}
+	table := model.QuoteIdentifier(meta.TableName)
+
+	if operation == model.OperationBulkUpdate {
+		fields := make([]string, 0, len(patch))
+		for field := range patch {
+			fields = append(fields, field)
+		}
+		sort.Strings(fields)
+		for _, field := range fields {
+			sets = append(sets, model.QuoteIdentifier(field)+" = ?")
+			args = append(args, patch[field])
+		}
+		if _, ok := schema.Column("updater_id"); ok && patch["updater_id"] == nil {
+			sets = append(sets, "`updater_id` = ?")
+			args = append(args, operatorID)
+		}
+		if _, ok := schema.Column("updated_at"); ok && patch["updated_at"] == nil {
+			sets = append(sets, "`updated_at` = ?")
+			args = append(args, time.Now())
+		}
+	} else if meta.Func.SoftDelete {
+		sets = append(sets, model.QuoteIdentifier(model.ColumnDeletedAt)+" = NOW()", model.QuoteIdentifier(model.ColumnDeletedBy)+" = ?")
+		args = append(args, operatorID)
+	}
+	if len(sets) > 0 && meta.Func.Versioned {
+		quoted := model.QuoteIdentifier(model.ColumnRowVersion)
+		sets = append(sets, quoted+" = "+quoted+" + 1")
+	}
+
+	// 按锁定的主键操作，保证影响的记录与操作日志一致
+	where, keyArgs := keyTupleWhere(schema.PrimaryKey, keys)
+	args = append(args, keyArgs...)
+
+	if len(sets) == 0 {
+		return "DELETE FROM " + table + " WHERE " + where, args
+	}
+	return "UPDATE " + table + " SET " + strings.Join(sets, ",") + " WHERE " + where, args
+}
+
+// keyTupleWhere 构建按主键匹配多条记录的条件
+func keyTupleWhere(primaryKey []string, keys []map[string]interface{}) (string, []interface{}) {
+	cols := make([]string, len(primaryKey))
+	for i, pk := range primaryKey {
+		cols[i] = model.QuoteIdentifier(pk)
+	}
+	tuple := "(" + strings.TrimSuffix(strings.Repeat("?,", len(cols)), ",") + ")"
+	tuples := make([]string, len(keys))
+	args := make([]interface{}, 0, len(keys)*len(cols))
+	for i, key := range keys {
+		tuples[i] = tuple
+		for _, pk := range primaryKey {
+			args = append(args, key[pk])
+		}
+	}
+	return "(" + strings.Join(cols, ",") + ") IN (" + strings.Join(tuples, ",") + ")", args
+}
+
+// saveTableOperation 保存批量操作记录，单字段主键只记录主键值
+func saveTableOperation(tx *sqlx.Tx, meta *tableMeta, operation string, req *model.TableBulkReq, primaryKey []string, keys []map[string]interface{}, operatorID uint) (uint, error) {
+	var affectedKeys interface{} = keys
+	if len(primaryKey) == 1 {
+		values := make([]interface{}, len(keys))
+		for i, key := range keys {
+			values[i] = key[primaryKey[0]]
+		}
+		affectedKeys = values
+	}
+
+	condition, err := json.Marshal(req.Query.Root)
+	if err != nil {
+		return 0, fmt.Errorf("marshal query condition failed: %v", err)
+	}
+	patch, err := json.Marshal(req.Patch)
+	if err != nil {
+		return 0, fmt.Errorf("marshal patch failed: %v", err)
+	}
+	keysJSON, err := json.Marshal(affectedKeys)
+	if err != nil {
+		return 0, fmt.Errorf("marshal affected keys failed: %v", err)
+	}
+
+	result, err := tx.Exec(`
+		INSERT INTO sys_table_operations (app_id, table_id, operation, query_condition, patch, affected, affected_keys, operator_id, created_at)
+		VALUES (?, ?, ?, ?, ?, ?, ?, ?, NOW())
+	`, meta.AppID, meta.ID, operation, string(condition), string(patch), len(keys), string(keysJSON), operatorID)
+	if err != nil {
+		return 0, fmt.Errorf("save table operation failed: %v", err)
+	}
+	id, err := result.LastInsertId()
+	if err != nil {
+		return 0, fmt.Errorf("get last insert id failed: %v", err)
+	}
+	return uint(id), nil
+}
+
+// GetTableOperations 获取数据表批量操作记录
+func (s *TableService) GetTableOperations(tableID uint, page int, pageSize int) ([]model.TableOperation, int, error) {
+	var total int
+	err := s.db.Get(&total, "SELECT COUNT(*) FROM sys_table_operations WHERE table_id = ?", tableID)
+	if err != nil {
+		return nil, 0, fmt.Errorf("count table operations failed: %v", err)
+	}
+
+	operations := []model.TableOperation{}
+	err = s.db.Select(&operations, `
+		SELECT id, app_id, table_id, operation, IFNULL(query_condition, '') AS query_condition, IFNULL(patch, '') AS patch,
+			affected, IFNULL(affected_keys, '') AS affected_keys, operator_id, created_at
+		FROM sys_table_operations
+		WHERE table_id = ?
+		ORDER BY id DESC
+		LIMIT ? OFFSET ?
+	`, tableID, pageSize, (page-1)*pageSize)
+	if err != nil {
+		return nil, 0, fmt.Errorf("list table operations failed: %v", err)
+	}
+	return operations, total, nil
+}
@@ -0,0 +1,183 @@
+package element
+
+import (
+	"bytes"
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"log"
+	"strconv"
+
+	"github.com/iiwish/lingjian/internal/model"
+	"github.com/iiwish/lingjian/pkg/redis"
+)
+
+// 查询结果缓存的Redis键前缀
+const (
+	cacheKeyPrefix       = "table_cache:"       // 查询结果，table_cache:{table_id}:{generation}:{hash}
+	cacheGenKeyPrefix    = "table_cache_gen:"   // 缓存代数，写入数据表时递增
+	cacheStatsKeyPrefix  = "table_cache_stats:" // 命中统计
+	dimCacheGenKeyPrefix = "dim_cache_gen:"     // 维度的缓存代数，修改维度明细时递增
+	permCacheGenKey      = "perm_cache_gen"     // 权限的缓存代数，修改权限、角色授权或用户角色时递增
+)
+
+// cacheEnabled 判断数据表是否启用查询结果缓存
+func cacheEnabled(meta *tableMeta) bool {
+	return meta.Func.CacheTTL > 0 && redis.Enabled()
+}
+
+// cacheGeneration 获取数据表当前的缓存代数
+func cacheGeneration(ctx context.Context, tableID uint) (int64, error) {
+	return getGeneration(ctx, cacheGenKeyPrefix+strconv.FormatUint(uint64(tableID), 10))
+}
+
+// getGeneration 读取缓存代数，未设置时为0
+func getGeneration(ctx context.Context, key string) (int64, error) {
+	value, err := redis.Get(ctx, key)
+	if errors.Is(err, redis.Nil) {
+		return 0, nil
+	}
+	if err != nil {
+		return 0, err
+	}
+	return strconv.ParseInt(value, 10, 64)
+}
+
+// queryCacheKey 生成查询结果的缓存键，由当前缓存代数和查询的摘要组成
+// 摘要包含附加了软删除和数据权限条件后生成的SQL、分页参数和用户的字段访问策略，权限不同的用户不会共用缓存
+// 数据权限按维度的上下级关系过滤记录，摘要同时包含权限和数据权限维度的缓存代数，修改授权或维度明细后不再命中旧缓存
+func queryCacheKey(ctx context.Context, meta *tableMeta, req *model.TableQueryReq, policies columnPolicies) (string, error) {
+	generation, err := cacheGeneration(ctx, meta.ID)
+	if err != nil {
+		return "", err
+	}
+	permGeneration, err := getGeneration(ctx, permCacheGenKey)
+	if err != nil {
+		return "", err
+	}
+	dimGenerations := make([]int64, len(meta.Func.DataScopes))
+	for i, ds := range meta.Func.DataScopes {
+		if dimGenerations[i], err = getGeneration(ctx, dimCacheGenKeyPrefix+strconv.FormatUint(uint64(ds.DimID), 10)); err != nil {
+			return "", err
+		}
+	}
+
+	query, args := req.Query.BuildQuery(model.QuoteIdentifier(meta.TableName))
+	payload, err := json.Marshal(struct {
+		SQL            string               `json:"sql"`
+		Args           []interface{}        `json:"args"`
+		Req            *model.TableQueryReq `json:"req"`
+		Policies       columnPolicies       `json:"policies"`
+		PermGeneration int64                `json:"perm_generation"`
+		DimGenerations []int64              `json:"dim_generations"`
+	}{query, args, req, policies, permGeneration, dimGenerations})
+	if err != nil {
+		return "", err
+	}
+	sum := sha256.Sum256(payload)
+	return fmt.Sprintf("%s%d:%d:%s", cacheKeyPrefix, meta.ID, generation, hex.EncodeToString(sum[:])), nil
+}
+
+// cachedQueryItems 优先从缓存读取查询结果，未命中时查询数据库并写入缓存，缓存不可用时直接查询数据库
+// 缓存只包含本表的记录，引用字段的名称等依赖其他数据表的内容在读取缓存后展开，不会因引用表修改而过期
+func (s *TableService) cachedQueryItems(meta *tableMeta, req *model.TableQueryReq, policies columnPolicies) (*model.TableQueryResp, error) {
+	ctx := context.Background()
+	key, err := queryCacheKey(ctx, meta, req, policies)
+	if err != nil {
+		log.Printf("查询结果缓存不可用: %v", err)
+		return s.queryItems(meta, req, policies)
+	}
+
+	data, err := redis.Get(ctx, key)
+	if err == nil {
+		// 保留数字的原始写法，避免大整数丢失精度
+		var resp model.TableQueryResp
+		decoder := json.NewDecoder(bytes.NewReader([]byte(data)))
+		decoder.UseNumber()
+		if err := decoder.Decode(&resp); err == nil {
+			recordCacheStat(ctx, meta.ID, "hits")
+			resp.Cached = true
+			return &resp, nil
+		}
+	} else if !errors.Is(err, redis.Nil) {
+		log.Printf("读取查询结果缓存失败: %v", err)
+	}
+	recordCacheStat(ctx, meta.ID, "misses")
+
+	resp, err := s.queryItems(meta, req, policies)
+	if err != nil {
+		return nil, err
+	}
+	encoded, err := json.Marshal(resp)
+	if err == nil {
+		err = redis.SetEx(ctx, key, encoded, meta.Func.CacheTTL)
+	}
+	if err != nil {
+		log.Printf("写入查询结果缓存失败: %v", err)
+	}
+	return resp, nil
+}
+
+// recordCacheStat 记录缓存命中或未命中次数
+func recordCacheStat(ctx context.Context, tableID uint, field string) {
+	key := cacheStatsKeyPrefix + strconv.FormatUint(uint64(tableID), 10)
+	if err := redis.HIncrBy(ctx, key, field, 1); err != nil {
+		log.Printf("记录查询结果缓存统计失败: %v", err)
+	}
+}
+
+// InvalidateTableCache 递增数据表的缓存代数，使已缓存的查询结果失效，旧代数的缓存到期后自动清除
+// 写入数据表记录、修改数据表配置或结构后调用
+func InvalidateTableCache(tableID uint) {
+	incrGeneration(cacheGenKeyPrefix + strconv.FormatUint(uint64(tableID), 10))
+}
+
+// InvalidateDimensionCache 递增维度的缓存代数，使按该维度过滤数据权限的查询结果缓存失效，修改维度明细后调用
+func InvalidateDimensionCache(dimID uint) {
+	incrGeneration(dimCacheGenKeyPrefix + strconv.FormatUint(uint64(dimID), 10))
+}
+
+// InvalidatePermissionCache 递增权限的缓存代数，使全部查询结果缓存失效，修改权限、角色授权或用户角色后调用
+func InvalidatePermissionCache() {
+	incrGeneration(permCacheGenKey)
+}
+
+// incrGeneration 递增缓存代数
+func incrGeneration(key string) {
+	if !redis.Enabled() {
+		return
+	}
+	if _, err := redis.Incr(context.Background(), key); err != nil {
+		log.Printf("清除查询结果缓存失败: %v", err)
+	}
+}
+
+// GetTableCacheStats 获取数据表查询结果缓存的配置和命中统计
+func (s *TableService) GetTableCacheStats(tableID uint) (*model.TableCacheStats, error) {
+	meta, err := getTableMeta(s.db, tableID)
+	if err != nil {
+		return nil, err
+	}
+	stats := &model.TableCacheStats{Enabled: cacheEnabled(meta), TTL: meta.Func.CacheTTL}
+	if !redis.Enabled() {
+		return stats, nil
+	}
+
+	ctx := context.Background()
+	if stats.Generation, err = cacheGeneration(ctx, tableID); err != nil {
+		return nil, fmt.Errorf("get cache generation failed: %v", err)
+	}
+	counts, err := redis.HGetAll(ctx, cacheStatsKeyPrefix+strconv.FormatUint(uint64(tableID), 10))
+	if err != nil {
+		return nil, fmt.Errorf("get cache stats failed: %v", err)
+	}
+	stats.Hits, _ = strconv.ParseInt(counts["hits"], 10, 64)
+	stats.Misses, _ = strconv.ParseInt(counts["misses"], 10, 64)
+	if total := stats.Hits + stats.Misses; total > 0 {
+		stats.HitRate = float64(stats.Hits) / float64(total)
+	}
+	return stats, nil
+}
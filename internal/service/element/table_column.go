@@ -0,0 +1,162 @@
+package element
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+
+	"github.com/iiwish/lingjian/internal/model"
+	"github.com/jmoiron/sqlx"
+)
+
+// ColumnForbiddenError 写入了用户没有写权限的字段
+type ColumnForbiddenError struct {
+	Fields []string `json:"fields"` // 没有写权限的字段
+}
+
+func (e *ColumnForbiddenError) Error() string {
+	return fmt.Sprintf("no permission to write fields: %s", strings.Join(e.Fields, ", "))
+}
+
+// columnPolicies 用户生效的字段访问策略，只包含受限制的字段
+type columnPolicies map[string]model.ColumnPolicy
+
+// userRoleCodes 获取用户启用的角色编码
+func userRoleCodes(q sqlx.Queryer, userID uint) (map[string]bool, error) {
+	var codes []string
+	err := sqlx.Select(q, &codes, `
+		SELECT r.code FROM sys_roles r
+		INNER JOIN sys_user_roles ur ON r.id = ur.role_id
+		WHERE ur.user_id = ?
+		AND r.status = 1
+	`, userID)
+	if err != nil {
+		return nil, fmt.Errorf("get user roles failed: %v", err)
+	}
+	roles := make(map[string]bool, len(codes))
+	for _, code := range codes {
+		roles[code] = true
+	}
+	return roles, nil
+}
+
+// userColumnPolicies 根据用户的角色计算数据表各字段生效的访问策略，没有受限制的字段时返回nil
+// 引用了不可读字段的计算字段按引用字段隐藏或脱敏
+func userColumnPolicies(q sqlx.Queryer, meta *tableMeta, userID uint) (columnPolicies, error) {
+	var roles map[string]bool
+	var policies columnPolicies
+	for name, opt := range meta.FieldOptions {
+		if len(opt.Policies) == 0 {
+			continue
+		}
+		if roles == nil {
+			var err error
+			if roles, err = userRoleCodes(q, userID); err != nil {
+				return nil, err
+			}
+		}
+		policy := model.ResolveColumnPolicy(opt.Policies, roles)
+		if policy.Access == model.AccessFull {
+			continue
+		}
+		if policies == nil {
+			policies = make(columnPolicies)
+		}
+		policies[name] = policy
+	}
+	if len(policies) == 0 {
+		return nil, nil
+	}
+
+	// 计算字段的取值由引用的字段得出，引用了不可读的字段时同样不可读，按依赖顺序处理计算字段之间的引用
+	computed, err := meta.computedFields()
+	if err != nil {
+		return nil, err
+	}
+	for _, f := range computed {
+		var restricted model.ColumnAccess
+		for _, ref := range f.expr.Refs() {
+			if policy, ok := policies[ref]; ok && !policy.Access.Readable() && restricted != model.AccessHidden {
+				restricted = policy.Access
+			}
+		}
+		if restricted == "" {
+			continue
+		}
+		if own, ok := policies[f.name]; !ok || own.Access.Readable() || restricted == model.AccessHidden {
+			policies[f.name] = model.ColumnPolicy{Access: restricted}
+		}
+	}
+	return policies, nil
+}
+
+// readable 判断字段是否可以原样读取和用于查询条件
+func (p columnPolicies) readable(field string) bool {
+	policy, ok := p[field]
+	return !ok || policy.Access.Readable()
+}
+
+// restrict 返回去掉不可读字段的数据表结构，用于校验查询条件
+func (p columnPolicies) restrict(schema *model.TableSchema) *model.TableSchema {
+	if len(p) == 0 {
+		return schema
+	}
+	restricted := *schema
+	restricted.Columns = make(map[string]model.ColumnInfo, len(schema.Columns))
+	for name, col := range schema.Columns {
+		if p.readable(name) {
+			restricted.Columns[name] = col
+		}
+	}
+	return &restricted
+}
+
+// apply 移除记录中隐藏的字段并对脱敏字段脱敏
+func (p columnPolicies) apply(rows []map[string]interface{}) {
+	if len(p) == 0 {
+		return
+	}
+	for _, row := range rows {
+		for name, policy := range p {
+			value, ok := row[name]
+			if !ok {
+				continue
+			}
+			switch policy.Access {
+			case model.AccessHidden:
+				delete(row, name)
+			case model.AccessMasked:
+				row[name] = model.MaskValue(policy.Mask, value)
+			}
+		}
+	}
+}
+
+// checkWritable 检查记录中是否有用户没有写权限的字段，keyColumns中的字段只用于定位记录，用户可读时不检查写权限
+func (p columnPolicies) checkWritable(items []map[string]interface{}, keyColumns []string) error {
+	if len(p) == 0 {
+		return nil
+	}
+	isKey := make(map[string]bool, len(keyColumns))
+	for _, col := range keyColumns {
+		isKey[col] = true
+	}
+
+	forbidden := make(map[string]bool)
+	for _, item := range items {
+		for field := range item {
+			if policy, ok := p[field]; ok && !policy.Access.Writable() && !(isKey[field] && policy.Access.Readable()) {
+				forbidden[field] = true
+			}
+		}
+	}
+	if len(forbidden) == 0 {
+		return nil
+	}
+	fields := make([]string, 0, len(forbidden))
+	for field := range forbidden {
+		fields = append(fields, field)
+	}
+	sort.Strings(fields)
+	return &ColumnForbiddenError{Fields: fields}
+}
@@ -0,0 +1,143 @@
+package element
+
+import (
+	"fmt"
+	"time"
+
+	"github.com/iiwish/lingjian/internal/model"
+	"github.com/iiwish/lingjian/pkg/formula"
+	"github.com/iiwish/lingjian/pkg/utils"
+	"github.com/jmoiron/sqlx"
+)
+
+// computedField 编译后的计算字段
+type computedField struct {
+	name    string
+	virtual bool
+	expr    *formula.Expr
+}
+
+// computedFields 按依赖顺序编译数据表的计算字段
+func (m *tableMeta) computedFields() ([]computedField, error) {
+	order, err := model.SortComputedFields(m.FieldOptions)
+	if err != nil {
+		return nil, err
+	}
+	fields := make([]computedField, 0, len(order))
+	for _, name := range order {
+		opt := m.FieldOptions[name]
+		expr, err := formula.Compile(opt.Formula)
+		if err != nil {
+			return nil, fmt.Errorf("compile formula of field %s failed: %v", name, err)
+		}
+		fields = append(fields, computedField{name: name, virtual: opt.IsVirtual(), expr: expr})
+	}
+	return fields, nil
+}
+
+// hasStoredFields 判断是否存在写入时计算的字段
+func hasStoredFields(fields []computedField) bool {
+	for _, f := range fields {
+		if !f.virtual {
+			return true
+		}
+	}
+	return false
+}
+
+// evalComputed 依次计算各计算字段，vars中包含记录的全部字段值，计算结果同样写入vars供后续字段引用
+func evalComputed(fields []computedField, vars map[string]interface{}) error {
+	for _, f := range fields {
+		value, err := f.expr.Eval(vars)
+		if err != nil {
+			return fmt.Errorf("compute field %s failed: %v", f.name, err)
+		}
+		vars[f.name] = value
+	}
+	return nil
+}
+
+// computeStoredFields 写入前计算存储的计算字段，并移除虚拟字段；
+// current为记录的当前值，新建记录时为nil
+func computeStoredFields(fields []computedField, item map[string]interface{}, current map[string]interface{}) error {
+	if !hasStoredFields(fields) {
+		for _, f := range fields {
+			delete(item, f.name)
+		}
+		return nil
+	}
+
+	vars := make(map[string]interface{}, len(current)+len(item))
+	for k, v := range current {
+		vars[k] = v
+	}
+	for k, v := range item {
+		vars[k] = v
+	}
+	// 虚拟字段也需要计算，供存储字段引用
+	if err := evalComputed(fields, vars); err != nil {
+		return err
+	}
+
+	for _, f := range fields {
+		if f.virtual {
+			delete(item, f.name)
+			continue
+		}
+		item[f.name] = vars[f.name]
+	}
+	return nil
+}
+
+// loadCurrentItem 按主键读取记录的当前值
+func loadCurrentItem(q sqlx.Queryer, tableName string, primaryKeyColumns []string, item map[string]interface{}) (map[string]interface{}, error) {
+	condition := make(map[string]interface{}, len(primaryKeyColumns))
+	for _, pk := range primaryKeyColumns {
+		condition[pk] = item[pk]
+	}
+	where, args := buildKeyWhere(condition)
+
+	rows, err := q.Queryx(fmt.Sprintf("SELECT * FROM %s WHERE %s LIMIT 1", model.QuoteIdentifier(tableName), where), args...)
+	if err != nil {
+		return nil, fmt.Errorf("get current item failed: %v", err)
+	}
+	defer rows.Close()
+
+	current := make(map[string]interface{})
+	if rows.Next() {
+		if err := rows.MapScan(current); err != nil {
+			return nil, fmt.Errorf("get current item failed: %v", err)
+		}
+	}
+	return utils.ConvertBytesToString(current).(map[string]interface{}), nil
+}
+
+// applyVirtualFields 为查询结果计算虚拟字段，计算失败的字段返回NULL
+func applyVirtualFields(fields []computedField, rows []map[string]interface{}) {
+	hasVirtual := false
+	for _, f := range fields {
+		if f.virtual {
+			hasVirtual = true
+			break
+		}
+	}
+	if !hasVirtual {
+		return
+	}
+
+	for _, row := range rows {
+		for _, f := range fields {
+			if !f.virtual {
+				continue
+			}
+			value, err := f.expr.Eval(row)
+			if err != nil {
+				value = nil
+			}
+			if t, ok := value.(time.Time); ok {
+				value = utils.CustomTime{Time: t}.Format("2006-01-02 15:04:05")
+			}
+			row[f.name] = value
+		}
+	}
+}
@@ -0,0 +1,82 @@
+package element
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/iiwish/lingjian/internal/model"
+	"github.com/jmoiron/sqlx"
+)
+
+// CopyTableItems 将源数据表中符合查询条件的记录复制到目标数据表，返回复制的记录数
+// 只复制columns中用户可以原样读取的字段，其他字段使用目标数据表的默认值；
+// 只复制用户数据权限内的记录，软删除的记录不复制，query为空时复制全部记录
+func CopyTableItems(db *sqlx.DB, srcTableID uint, dstTableName string, columns []string, query *model.QueryCondition, userID uint) (int64, error) {
+	if len(columns) == 0 {
+		return 0, nil
+	}
+	meta, err := getTableMeta(db, srcTableID)
+	if err != nil {
+		return 0, err
+	}
+	schema, err := getTableSchema(db, meta.TableName)
+	if err != nil {
+		return 0, err
+	}
+	policies, err := userColumnPolicies(db, meta, userID)
+	if err != nil {
+		return 0, err
+	}
+
+	if query == nil {
+		query = &model.QueryCondition{}
+	}
+	if len(query.Select) > 0 || len(query.GroupBy) > 0 {
+		return 0, &model.QueryError{Message: "select and group_by cannot be used when copying items"}
+	}
+	if err := query.Validate(policies.restrict(schema)); err != nil {
+		return 0, err
+	}
+	if meta.Func.SoftDelete {
+		query.AddScope(model.ColumnDeletedAt + " IS NULL")
+	}
+	if err := applyDataScope(db, meta, userID, query); err != nil {
+		return 0, err
+	}
+
+	readable := make([]string, 0, len(columns))
+	for _, col := range columns {
+		if policies.readable(col) {
+			readable = append(readable, col)
+		}
+	}
+	if len(readable) == 0 {
+		return 0, nil
+	}
+	columns = readable
+
+	quoted := make([]string, len(columns))
+	for i, col := range columns {
+		if _, ok := schema.Columns[col]; !ok {
+			return 0, fmt.Errorf("column %s does not exist in table %s", col, meta.TableName)
+		}
+		quoted[i] = model.QuoteIdentifier(col)
+	}
+	colList := strings.Join(quoted, ", ")
+	copySQL := fmt.Sprintf("INSERT INTO %s (%s) SELECT %s FROM %s",
+		model.QuoteIdentifier(dstTableName), colList, colList, model.QuoteIdentifier(meta.TableName))
+	where, args := query.BuildWhere()
+	if where != "" {
+		copySQL += " WHERE " + where
+	}
+
+	result, err := db.Exec(copySQL, args...)
+	if err != nil {
+		return 0, fmt.Errorf("copy table items failed: %v", err)
+	}
+	copied, err := result.RowsAffected()
+	if err != nil {
+		return 0, fmt.Errorf("get rows affected failed: %v", err)
+	}
+	return copied, nil
+}
@@ -0,0 +1,154 @@
+package element
+
+import (
+	"bytes"
+	"encoding/base64"
+	"encoding/json"
+	"strings"
+
+	"github.com/iiwish/lingjian/internal/model"
+)
+
+// sortKey 游标分页的排序键
+type sortKey struct {
+	field string
+	desc  bool
+}
+
+// cursorPayload 游标内容
+type cursorPayload struct {
+	Key    string        `json:"k"` // 排序键签名，防止游标用于不同排序的查询
+	Values []interface{} `json:"v"` // 上一页最后一条记录的排序键取值
+}
+
+// cursorSortKeys 计算游标分页的排序键：客户端排序字段加主键，保证排序稳定
+func cursorSortKeys(query *model.QueryCondition, schema *model.TableSchema) ([]sortKey, error) {
+	if query.IsAggregate() {
+		return nil, &model.QueryError{Message: "cursor pagination does not support aggregate queries"}
+	}
+	if len(schema.PrimaryKey) == 0 {
+		return nil, &model.QueryError{Message: "cursor pagination requires a primary key"}
+	}
+
+	keys := make([]sortKey, 0, len(query.OrderBy)+len(schema.PrimaryKey))
+	used := make(map[string]bool)
+	for _, order := range query.OrderBy {
+		col, ok := schema.Column(order.Field)
+		if !ok {
+			return nil, &model.QueryError{Field: order.Field, Message: "cursor pagination requires order by table fields"}
+		}
+		if col.Nullable {
+			return nil, &model.QueryError{Field: order.Field, Message: "cursor pagination requires non-null order by fields"}
+		}
+		if used[order.Field] {
+			continue
+		}
+		used[order.Field] = true
+		keys = append(keys, sortKey{field: order.Field, desc: order.Desc})
+	}
+	for _, pk := range schema.PrimaryKey {
+		if !used[pk] {
+			used[pk] = true
+			keys = append(keys, sortKey{field: pk})
+		}
+	}
+
+	// 指定查询字段时，排序键必须包含在查询结果中
+	if len(query.Select) > 0 {
+		selected := make(map[string]bool, len(query.Select))
+		for _, f := range query.Select {
+			if f.Bucket == "" && f.GetAlias() == f.Field {
+				selected[f.Field] = true
+			}
+		}
+		for _, key := range keys {
+			if !selected[key.field] {
+				return nil, &model.QueryError{Field: key.field, Message: "cursor pagination requires sort fields in select"}
+			}
+		}
+	}
+	return keys, nil
+}
+
+// sortKeySignature 生成排序键签名
+func sortKeySignature(keys []sortKey) string {
+	parts := make([]string, len(keys))
+	for i, key := range keys {
+		if key.desc {
+			parts[i] = key.field + " desc"
+		} else {
+			parts[i] = key.field + " asc"
+		}
+	}
+	return strings.Join(parts, ",")
+}
+
+// sortKeyOrderBy 将排序键转换为排序条件
+func sortKeyOrderBy(keys []sortKey) []model.OrderBy {
+	orderBy := make([]model.OrderBy, len(keys))
+	for i, key := range keys {
+		orderBy[i] = model.OrderBy{Field: key.field, Desc: key.desc}
+	}
+	return orderBy
+}
+
+// encodeCursor 根据记录生成游标
+func encodeCursor(keys []sortKey, row map[string]interface{}) (string, error) {
+	payload := cursorPayload{Key: sortKeySignature(keys), Values: make([]interface{}, len(keys))}
+	for i, key := range keys {
+		payload.Values[i] = row[key.field]
+	}
+	data, err := json.Marshal(payload)
+	if err != nil {
+		return "", err
+	}
+	return base64.RawURLEncoding.EncodeToString(data), nil
+}
+
+// decodeCursor 解析游标，返回排序键取值
+func decodeCursor(cursor string, keys []sortKey) ([]interface{}, error) {
+	invalid := &model.QueryError{Message: "invalid cursor"}
+	data, err := base64.RawURLEncoding.DecodeString(cursor)
+	if err != nil {
+		return nil, invalid
+	}
+
+	// 使用json.Number保留大整数精度
+	var payload cursorPayload
+	decoder := json.NewDecoder(bytes.NewReader(data))
+	decoder.UseNumber()
+	if err := decoder.Decode(&payload); err != nil {
+		return nil, invalid
+	}
+	if payload.Key != sortKeySignature(keys) || len(payload.Values) != len(keys) {
+		return nil, &model.QueryError{Message: "cursor does not match the query order"}
+	}
+	for i, v := range payload.Values {
+		if n, ok := v.(json.Number); ok {
+			payload.Values[i] = n.String()
+		}
+	}
+	return payload.Values, nil
+}
+
+// keysetClause 构建游标位置之后的过滤条件：
+// (k1 > v1) OR (k1 = v1 AND k2 > v2) OR ...，降序字段使用 <
+func keysetClause(keys []sortKey, values []interface{}) (string, []interface{}) {
+	ors := make([]string, 0, len(keys))
+	var args []interface{}
+	for i, key := range keys {
+		ands := make([]string, 0, i+1)
+		for j := 0; j < i; j++ {
+			ands = append(ands, model.QuoteIdentifier(keys[j].field)+" = ?")
+			args = append(args, values[j])
+		}
+		op := " > ?"
+		if key.desc {
+			op = " < ?"
+		}
+		ands = append(ands, model.QuoteIdentifier(key.field)+op)
+		args = append(args, values[i])
+		ors = append(ors, "("+strings.Join(ands, " AND ")+")")
+	}
+	return "(" + strings.Join(ors, " OR ") + ")", args
+}
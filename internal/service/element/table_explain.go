@@ -0,0 +1,247 @@
+package element
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"sort"
+	"strings"
+
+	"github.com/iiwish/lingjian/internal/model"
+	"github.com/jmoiron/sqlx"
+)
+
+// ErrAdminRequired 只有管理员可以使用的功能
+var ErrAdminRequired = errors.New("administrator role required")
+
+// maxIndexNameLength MySQL索引名的最大长度
+const maxIndexNameLength = 64
+
+// unindexableTypes 不能直接建立普通索引的字段类型
+var unindexableTypes = map[string]bool{
+	"tinytext": true, "text": true, "mediumtext": true, "longtext": true,
+	"tinyblob": true, "blob": true, "mediumblob": true, "longblob": true,
+	"json": true, "geometry": true,
+}
+
+// isAdmin 判断用户是否拥有管理员角色
+func isAdmin(q sqlx.Queryer, userID uint) (bool, error) {
+	roles, err := userRoleCodes(q, userID)
+	if err != nil {
+		return false, err
+	}
+	for _, code := range model.AdminRoleCodes {
+		if roles[code] {
+			return true, nil
+		}
+	}
+	return false, nil
+}
+
+// ExplainTableItems 返回按请求查询记录时生成的SQL和MySQL执行计划，标记全表扫描并根据过滤和排序字段建议索引，仅管理员可用
+// SQL与查询记录时一致，包括视图条件、归档表和分页，未指定页码和每页数量时为第一页
+func (s *TableService) ExplainTableItems(tableID uint, req *model.TableQueryReq, userID uint) (*model.TableExplainResp, error) {
+	admin, err := isAdmin(s.db, userID)
+	if err != nil {
+		return nil, err
+	}
+	if !admin {
+		return nil, ErrAdminRequired
+	}
+	if err := req.Validate(); err != nil {
+		return nil, err
+	}
+
+	meta, err := getTableMeta(s.db, tableID)
+	if err != nil {
+		return nil, err
+	}
+	if req.ViewID > 0 {
+		if _, err := s.applyTableView(tableID, req, userID); err != nil {
+			return nil, err
+		}
+	}
+	if req.Page <= 0 {
+		req.Page = 1
+	}
+	if req.PageSize <= 0 {
+		req.PageSize = defaultPageSize
+	}
+	if _, err := s.prepareQuery(meta, &req.Query, userID); err != nil {
+		return nil, err
+	}
+	schema, err := getTableSchema(s.db, meta.TableName)
+	if err != nil {
+		return nil, err
+	}
+
+	source, keys, err := s.querySourceKeys(meta, req)
+	if err != nil {
+		return nil, err
+	}
+	baseQuery, args, err := pageQuery(req, source, keys)
+	if err != nil {
+		return nil, err
+	}
+
+	var planJSON string
+	if err := s.db.Get(&planJSON, "EXPLAIN FORMAT=JSON "+baseQuery, args...); err != nil {
+		return nil, fmt.Errorf("explain table items failed: %v", err)
+	}
+	resp := &model.TableExplainResp{
+		SQL:         baseQuery,
+		Args:        args,
+		BoundSQL:    bindArgs(baseQuery, args),
+		FullScans:   []model.FullScan{},
+		Suggestions: suggestIndexes(meta.TableName, schema, &req.Query),
+	}
+	if err := json.Unmarshal([]byte(planJSON), &resp.Plan); err != nil {
+		return nil, fmt.Errorf("parse query plan failed: %v", err)
+	}
+	findFullScans(resp.Plan, &resp.FullScans)
+	return resp, nil
+}
+
+// bindArgs 将参数代入SQL中的占位符，字符串参数加引号并转义
+func bindArgs(query string, args []interface{}) string {
+	var b strings.Builder
+	i := 0
+	for _, r := range query {
+		if r == '?' && i < len(args) {
+			b.WriteString(sqlLiteral(args[i]))
+			i++
+			continue
+		}
+		b.WriteRune(r)
+	}
+	return b.String()
+}
+
+// sqlLiteral 将参数转换为SQL字面量
+func sqlLiteral(value interface{}) string {
+	switch v := value.(type) {
+	case nil:
+		return "NULL"
+	case int, int8, int16, int32, int64, uint, uint8, uint16, uint32, uint64, float32, float64:
+		return toString(v)
+	case bool:
+		if v {
+			return "1"
+		}
+		return "0"
+	default:
+		return "'" + strings.NewReplacer(`\`, `\\`, `'`, `\'`).Replace(toString(v)) + "'"
+	}
+}
+
+// findFullScans 在执行计划中查找访问方式为ALL的表
+func findFullScans(node interface{}, scans *[]model.FullScan) {
+	switch v := node.(type) {
+	case map[string]interface{}:
+		if table, ok := v["table"].(map[string]interface{}); ok && table["access_type"] == "ALL" {
+			name, _ := table["table_name"].(string)
+			rows, _ := table["rows_examined_per_scan"].(float64)
+			*scans = append(*scans, model.FullScan{Table: name, Rows: int64(rows)})
+		}
+		// 按键名遍历，保证结果顺序稳定
+		keys := make([]string, 0, len(v))
+		for key := range v {
+			keys = append(keys, key)
+		}
+		sort.Strings(keys)
+		for _, key := range keys {
+			findFullScans(v[key], scans)
+		}
+	case []interface{}:
+		for _, item := range v {
+			findFullScans(item, scans)
+		}
+	}
+}
+
+// suggestIndexes 根据过滤和排序字段建议索引，等值过滤字段在前，其后为第一个范围过滤字段或排序字段
+// 已有索引以建议的字段开头时不再建议
+func suggestIndexes(tableName string, schema *model.TableSchema, query *model.QueryCondition) []model.IndexSuggestion {
+	indexable := func(field string) bool {
+		col, ok := schema.Column(field)
+		return ok && !unindexableTypes[strings.ToLower(col.DataType)]
+	}
+
+	var eqCols, rangeCols, orderCols []string
+	seen := make(map[string]bool)
+	for _, c := range query.AndConditions() {
+		if seen[c.Field] || !indexable(c.Field) {
+			continue
+		}
+		switch c.Operator {
+		case model.OpEq, model.OpIn:
+			eqCols = append(eqCols, c.Field)
+		case model.OpGt, model.OpGte, model.OpLt, model.OpLte, model.OpBetween:
+			rangeCols = append(rangeCols, c.Field)
+		default:
+			continue
+		}
+		seen[c.Field] = true
+	}
+
+	columns := append([]string{}, eqCols...)
+	var reasons []string
+	if len(eqCols) > 0 {
+		reasons = append(reasons, "等值过滤："+strings.Join(eqCols, ", "))
+	}
+	if len(rangeCols) > 0 {
+		columns = append(columns, rangeCols[0])
+		reasons = append(reasons, "范围过滤："+rangeCols[0])
+	} else if !query.IsAggregate() {
+		// 没有范围过滤时，排序字段跟在等值过滤字段之后可以避免额外排序
+		for _, order := range query.OrderBy {
+			if !seen[order.Field] && indexable(order.Field) {
+				orderCols = append(orderCols, order.Field)
+				seen[order.Field] = true
+			}
+		}
+		if len(orderCols) > 0 {
+			columns = append(columns, orderCols...)
+			reasons = append(reasons, "排序："+strings.Join(orderCols, ", "))
+		}
+	}
+	if len(columns) == 0 || hasIndexPrefix(schema, columns) {
+		return []model.IndexSuggestion{}
+	}
+
+	name := "idx_" + strings.Join(columns, "_")
+	if len(name) > maxIndexNameLength {
+		name = name[:maxIndexNameLength]
+	}
+	quoted := make([]string, len(columns))
+	for i, col := range columns {
+		quoted[i] = model.QuoteIdentifier(col)
+	}
+	return []model.IndexSuggestion{{
+		Columns: columns,
+		Reason:  strings.Join(reasons, "；"),
+		SQL: fmt.Sprintf("CREATE INDEX %s ON %s (%s)",
+			model.QuoteIdentifier(name), model.QuoteIdentifier(tableName), strings.Join(quoted, ", ")),
+	}}
+}
+
+// hasIndexPrefix 判断是否已有以columns开头的索引
+func hasIndexPrefix(schema *model.TableSchema, columns []string) bool {
+	indexes := append(append([][]string{}, schema.UniqueKeys...), schema.Indexes...)
+	for _, index := range indexes {
+		if len(index) < len(columns) {
+			continue
+		}
+		matched := true
+		for i, col := range columns {
+			if index[i] != col {
+				matched = false
+				break
+			}
+		}
+		if matched {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,432 @@
+package element
+
+import (
+	"errors"
+	"fmt"
+	"sort"
+	"strings"
+
+	"github.com/iiwish/lingjian/internal/model"
+	"github.com/iiwish/lingjian/pkg/utils"
+	"github.com/jmoiron/sqlx"
+)
+
+// maxPlaceholders 单条SQL语句允许的最大参数个数
+const maxPlaceholders = 65535
+
+// ErrUpsertUnsupported 数据表没有主键或唯一索引，无法使用upsert
+var ErrUpsertUnsupported = errors.New("upsert requires a primary key or unique index")
+
+// ErrUpsertVersioned 启用记录版本的数据表更新记录需要提交版本号，不能使用upsert
+var ErrUpsertVersioned = errors.New("upsert is not supported on versioned tables, use update instead")
+
+// insertBatch 使用同一条INSERT语句写入的一批记录，同一批记录的字段相同
+type insertBatch struct {
+	columns []string // 写入的字段，按字段名排序
+	rows    []int    // 记录在请求中的下标
+}
+
+// duplicateRef upsert模式下与已有记录重复的记录
+type duplicateRef struct {
+	row        int  // 与同批次前面的记录重复时为该记录的下标，否则为-1
+	deleted    bool // 已有记录已软删除
+	outOfScope bool // 已有记录不在用户的数据权限内
+}
+
+// checkItemColumns 检查记录字段是否都是数据表字段
+func checkItemColumns(schema *model.TableSchema, items []map[string]interface{}) error {
+	var errs []model.FieldError
+	for row, item := range items {
+		for field := range item {
+			if _, ok := schema.Column(field); !ok {
+				errs = append(errs, model.FieldError{Row: row, Field: field, Message: field + "不是数据表字段"})
+			}
+		}
+	}
+	if len(errs) > 0 {
+		sort.SliceStable(errs, func(i, j int) bool {
+			if errs[i].Row != errs[j].Row {
+				return errs[i].Row < errs[j].Row
+			}
+			return errs[i].Field < errs[j].Field
+		})
+		return &ValidationError{Errors: errs}
+	}
+	return nil
+}
+
+// splitInsertBatches 按字段组合对记录分组，并按批次大小和参数个数上限拆分
+func splitInsertBatches(items []map[string]interface{}, batchSize int) []insertBatch {
+	var groups []*insertBatch
+	index := make(map[string]*insertBatch)
+	for row, item := range items {
+		columns := make([]string, 0, len(item))
+		for col := range item {
+			columns = append(columns, col)
+		}
+		sort.Strings(columns)
+
+		signature := strings.Join(columns, ",")
+		group, ok := index[signature]
+		if !ok {
+			group = &insertBatch{columns: columns}
+			index[signature] = group
+			groups = append(groups, group)
+		}
+		group.rows = append(group.rows, row)
+	}
+
+	var batches []insertBatch
+	for _, group := range groups {
+		size := batchSize
+		if len(group.columns) > 0 && size*len(group.columns) > maxPlaceholders {
+			size = maxPlaceholders / len(group.columns)
+		}
+		for start := 0; start < len(group.rows); start += size {
+			end := start + size
+			if end > len(group.rows) {
+				end = len(group.rows)
+			}
+			batches = append(batches, insertBatch{columns: group.columns, rows: group.rows[start:end]})
+		}
+	}
+	return batches
+}
+
+// rowKey 生成唯一键取值的比较键，存在NULL取值时返回false
+func rowKey(values []interface{}) (string, bool) {
+	parts := make([]string, len(values))
+	for i, v := range values {
+		if v == nil {
+			return "", false
+		}
+		parts[i] = fmt.Sprint(v)
+	}
+	return strings.Join(parts, "\x00"), true
+}
+
+// findDuplicates 查找批次中与已有记录或同批次前面记录的主键、唯一键重复的记录
+// 已有记录在事务中加锁，保证执行INSERT时重复情况不变；同时查询已有记录是否已软删除和是否在用户的数据权限内
+func findDuplicates(tx *sqlx.Tx, meta *tableMeta, schema *model.TableSchema, scope *dataScope, items []map[string]interface{}, batch insertBatch) (map[int]duplicateRef, error) {
+	present := make(map[string]bool, len(batch.columns))
+	for _, col := range batch.columns {
+		present[col] = true
+	}
+
+	duplicates := make(map[int]duplicateRef)
+	for _, key := range schema.UniqueKeys {
+		covered := true
+		for _, col := range key {
+			if !present[col] {
+				covered = false
+				break
+			}
+		}
+		if !covered {
+			continue
+		}
+
+		// 收集批次中的唯一键取值，同批次重复的记录视为更新前面的记录
+		seen := make(map[string]int)
+		var tuples []string
+		var keyArgs []interface{}
+		for _, row := range batch.rows {
+			values := make([]interface{}, len(key))
+			for i, col := range key {
+				values[i] = items[row][col]
+			}
+			k, ok := rowKey(values)
+			if !ok {
+				continue
+			}
+			if first, ok := seen[k]; ok {
+				if _, dup := duplicates[row]; !dup {
+					duplicates[row] = duplicateRef{row: first}
+				}
+				continue
+			}
+			seen[k] = row
+			tuples = append(tuples, "("+strings.TrimSuffix(strings.Repeat("?,", len(key)), ",")+")")
+			keyArgs = append(keyArgs, values...)
+		}
+		if len(tuples) == 0 {
+			continue
+		}
+
+		// 查询并锁定已存在的记录
+		keyCols := make([]string, len(key))
+		for i, col := range key {
+			keyCols[i] = model.QuoteIdentifier(col)
+		}
+		selectCols := append([]string{}, keyCols...)
+		var args []interface{}
+		if meta.Func.SoftDelete {
+			selectCols = append(selectCols, model.QuoteIdentifier(model.ColumnDeletedAt)+" IS NOT NULL AS `__deleted`")
+		}
+		if scope != nil {
+			selectCols = append(selectCols, "("+scope.clause+") AS `__in_scope`")
+			args = append(args, scope.args...)
+		}
+		args = append(args, keyArgs...)
+		query := fmt.Sprintf("SELECT %s FROM %s WHERE (%s) IN (%s) FOR UPDATE",
+			strings.Join(selectCols, ","), model.QuoteIdentifier(meta.TableName),
+			strings.Join(keyCols, ","), strings.Join(tuples, ","))
+		rows, err := tx.Queryx(query, args...)
+		if err != nil {
+			return nil, fmt.Errorf("find existing items failed: %v", err)
+		}
+		existing := make(map[string]duplicateRef)
+		for rows.Next() {
+			record := make(map[string]interface{})
+			if err := rows.MapScan(record); err != nil {
+				rows.Close()
+				return nil, fmt.Errorf("find existing items failed: %v", err)
+			}
+			record = utils.ConvertBytesToString(record).(map[string]interface{})
+			values := make([]interface{}, len(key))
+			for i, col := range key {
+				values[i] = record[col]
+			}
+			if k, ok := rowKey(values); ok {
+				existing[k] = duplicateRef{
+					row:        -1,
+					deleted:    toString(record["__deleted"]) == "1",
+					outOfScope: scope != nil && toString(record["__in_scope"]) != "1",
+				}
+			}
+		}
+		rows.Close()
+
+		for k, row := range seen {
+			if ref, ok := existing[k]; ok {
+				if prev, dup := duplicates[row]; !dup || prev.row >= 0 {
+					duplicates[row] = ref
+				}
+			}
+		}
+	}
+	return duplicates, nil
+}
+
+// checkDuplicates 检查upsert将要更新的已有记录，数据权限外的记录不能更新，已软删除的记录只在指定恢复时更新
+func checkDuplicates(duplicates map[int]duplicateRef, restore bool) error {
+	var errs []model.FieldError
+	for row, ref := range duplicates {
+		switch {
+		case ref.outOfScope:
+			errs = append(errs, model.FieldError{Row: row, Message: "与数据权限外的记录重复"})
+		case ref.deleted && !restore:
+			errs = append(errs, model.FieldError{Row: row, Message: "与回收站中的记录重复"})
+		}
+	}
+	if len(errs) > 0 {
+		sort.Slice(errs, func(i, j int) bool { return errs[i].Row < errs[j].Row })
+		return &ValidationError{Errors: errs}
+	}
+	return nil
+}
+
+// insertItems 执行一批记录的INSERT语句，upsert模式下重复的记录更新已有记录
+func insertItems(tx *sqlx.Tx, meta *tableMeta, schema *model.TableSchema, scope *dataScope, items []map[string]interface{}, batch insertBatch, opts model.CreateTableItemsOptions, resp *model.CreateTableItemsResp) error {
+	rows := batch.rows
+	var duplicates map[int]duplicateRef
+	if opts.Mode == model.InsertModeUpsert {
+		var err error
+		duplicates, err = findDuplicates(tx, meta, schema, scope, items, batch)
+		if err != nil {
+			return err
+		}
+		if err := checkDuplicates(duplicates, opts.Restore); err != nil {
+			return err
+		}
+
+		// 新记录排在前面，保证自增主键连续分配给新记录
+		rows = make([]int, 0, len(batch.rows))
+		for _, row := range batch.rows {
+			if _, ok := duplicates[row]; !ok {
+				rows = append(rows, row)
+			}
+		}
+		for _, row := range batch.rows {
+			if _, ok := duplicates[row]; ok {
+				rows = append(rows, row)
+			}
+		}
+	}
+
+	// 构建多行INSERT语句
+	columns := make([]string, len(batch.columns))
+	for i, col := range batch.columns {
+		columns[i] = model.QuoteIdentifier(col)
+	}
+	placeholders := "(" + strings.TrimSuffix(strings.Repeat("?,", len(columns)), ",") + ")"
+	values := make([]string, len(rows))
+	args := make([]interface{}, 0, len(rows)*len(columns))
+	for i, row := range rows {
+		values[i] = placeholders
+		for _, col := range batch.columns {
+			args = append(args, items[row][col])
+		}
+	}
+	query := fmt.Sprintf("INSERT INTO %s (%s) VALUES %s",
+		model.QuoteIdentifier(meta.TableName), strings.Join(columns, ","), strings.Join(values, ","))
+
+	if opts.Mode == model.InsertModeUpsert {
+		query += " ON DUPLICATE KEY UPDATE " + strings.Join(upsertAssignments(meta, schema, batch.columns, opts.Restore), ",")
+	}
+
+	result, err := tx.Exec(query, args...)
+	if err != nil {
+		return fmt.Errorf("insert table items failed: %v", err)
+	}
+
+	// 统计插入和更新的记录数，重复的已有记录已加锁，与执行时一致
+	updated := len(duplicates)
+	resp.Inserted += len(rows) - updated
+	resp.Updated += updated
+
+	if resp.IDs == nil {
+		return nil
+	}
+
+	// 记录主键：请求中的取值，或按唯一键查询写入后的主键
+	idKey := schema.PrimaryKey[0]
+	var pending []int
+	for _, row := range rows {
+		if id := items[row][idKey]; id != nil {
+			resp.IDs[row] = id
+		} else {
+			pending = append(pending, row)
+		}
+	}
+	if len(pending) == 0 {
+		return nil
+	}
+	if key := lookupKey(schema, idKey, items, batch.columns, pending); key != nil {
+		return selectItemIDs(tx, meta.TableName, idKey, key, items, pending, resp.IDs)
+	}
+
+	// 没有可用于查询的唯一键时只会插入新记录，多行INSERT的自增主键连续分配
+	if schema.AutoIncrementKey() == "" {
+		return nil
+	}
+	nextID, err := result.LastInsertId()
+	if err != nil {
+		return fmt.Errorf("get last insert id failed: %v", err)
+	}
+	for _, row := range pending {
+		resp.IDs[row] = nextID
+		nextID++
+	}
+	return nil
+}
+
+// lookupKey 选择用于查询写入记录主键的唯一键，唯一键需为批次写入的字段且各记录取值不为NULL
+func lookupKey(schema *model.TableSchema, idKey string, items []map[string]interface{}, columns []string, rows []int) []string {
+	present := make(map[string]bool, len(columns))
+	for _, col := range columns {
+		present[col] = true
+	}
+	for _, key := range schema.UniqueKeys {
+		usable := !(len(key) == 1 && key[0] == idKey)
+		for _, col := range key {
+			if !usable {
+				break
+			}
+			if !present[col] {
+				usable = false
+				break
+			}
+			for _, row := range rows {
+				if items[row][col] == nil {
+					usable = false
+					break
+				}
+			}
+		}
+		if usable {
+			return key
+		}
+	}
+	return nil
+}
+
+// selectItemIDs 按唯一键取值查询记录的主键
+func selectItemIDs(tx *sqlx.Tx, tableName string, idKey string, key []string, items []map[string]interface{}, rows []int, ids []interface{}) error {
+	keyCols := make([]string, len(key))
+	for i, col := range key {
+		keyCols[i] = model.QuoteIdentifier(col)
+	}
+	tuple := "(" + strings.TrimSuffix(strings.Repeat("?,", len(key)), ",") + ")"
+	tuples := make([]string, len(rows))
+	args := make([]interface{}, 0, len(rows)*len(key))
+	for i, row := range rows {
+		tuples[i] = tuple
+		for _, col := range key {
+			args = append(args, items[row][col])
+		}
+	}
+	query := fmt.Sprintf("SELECT %s, %s AS `__id` FROM %s WHERE (%s) IN (%s)",
+		strings.Join(keyCols, ","), model.QuoteIdentifier(idKey), model.QuoteIdentifier(tableName),
+		strings.Join(keyCols, ","), strings.Join(tuples, ","))
+	records, err := tx.Queryx(query, args...)
+	if err != nil {
+		return fmt.Errorf("get inserted ids failed: %v", err)
+	}
+	defer records.Close()
+
+	found := make(map[string]interface{}, len(rows))
+	for records.Next() {
+		record := make(map[string]interface{})
+		if err := records.MapScan(record); err != nil {
+			return fmt.Errorf("get inserted ids failed: %v", err)
+		}
+		record = utils.ConvertBytesToString(record).(map[string]interface{})
+		values := make([]interface{}, len(key))
+		for i, col := range key {
+			values[i] = record[col]
+		}
+		if k, ok := rowKey(values); ok {
+			found[k] = record["__id"]
+		}
+	}
+
+	for _, row := range rows {
+		values := make([]interface{}, len(key))
+		for i, col := range key {
+			values[i] = items[row][col]
+		}
+		if k, ok := rowKey(values); ok {
+			ids[row] = found[k]
+		}
+	}
+	return nil
+}
+
+// upsertAssignments 构建ON DUPLICATE KEY UPDATE的赋值列表，主键和编号不更新，restore为true时同时恢复软删除的记录
+func upsertAssignments(meta *tableMeta, schema *model.TableSchema, columns []string, restore bool) []string {
+	isPrimaryKey := make(map[string]bool, len(schema.PrimaryKey))
+	for _, pk := range schema.PrimaryKey {
+		isPrimaryKey[pk] = true
+	}
+
+	assignments := make([]string, 0, len(columns)+2)
+	for _, col := range columns {
+		if isPrimaryKey[col] || meta.FieldOptions[col].Type == model.TypeSequence {
+			continue
+		}
+		quoted := model.QuoteIdentifier(col)
+		assignments = append(assignments, quoted+" = VALUES("+quoted+")")
+	}
+	if meta.Func.SoftDelete && restore {
+		assignments = append(assignments,
+			model.QuoteIdentifier(model.ColumnDeletedAt)+" = NULL",
+			model.QuoteIdentifier(model.ColumnDeletedBy)+" = 0")
+	}
+	if len(assignments) == 0 {
+		// 只写入主键时保持记录不变
+		quoted := model.QuoteIdentifier(schema.UniqueKeys[0][0])
+		assignments = append(assignments, quoted+" = "+quoted)
+	}
+	return assignments
+}
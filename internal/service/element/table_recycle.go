@@ -0,0 +1,177 @@
+package element
+
+import (
+	"fmt"
+
+	"github.com/iiwish/lingjian/internal/model"
+)
+
+// purgeBatchSize 回收站清理每批删除的记录数
+const purgeBatchSize = 1000
+
+// GetRecycleItems 获取回收站记录列表
+func (s *TableService) GetRecycleItems(tableID uint, page int, pageSize int, userID uint) ([]map[string]interface{}, int, error) {
+	// 从配置表读取表配置
+	meta, err := getTableMeta(s.db, tableID)
+	if err != nil {
+		return nil, 0, err
+	}
+	if !meta.Func.SoftDelete {
+		return nil, 0, ErrSoftDeleteDisabled
+	}
+
+	req := &model.TableQueryReq{
+		Page:     page,
+		PageSize: pageSize,
+		Query: model.QueryCondition{
+			Root:    model.ConditionGroup{Logic: model.LogicAnd},
+			OrderBy: []model.OrderBy{{Field: model.ColumnDeletedAt, Desc: true}},
+		},
+	}
+	req.Query.AddScope(model.ColumnDeletedAt + " IS NOT NULL")
+	if err := applyDataScope(s.db, meta, userID, &req.Query); err != nil {
+		return nil, 0, err
+	}
+	policies, err := userColumnPolicies(s.db, meta, userID)
+	if err != nil {
+		return nil, 0, err
+	}
+
+	resp, err := s.queryItems(meta, req, policies)
+	if err != nil {
+		return nil, 0, err
+	}
+	return resp.Items, int(resp.Total), nil
+}
+
+// RestoreTableItems 从回收站恢复数据表记录
+func (s *TableService) RestoreTableItems(operatorID uint, tableID uint, req []map[string]interface{}) error {
+	// 开启事务
+	tx, err := s.db.Beginx()
+	if err != nil {
+		return fmt.Errorf("begin transaction failed: %v", err)
+	}
+	defer tx.Rollback()
+
+	// 从配置表读取表配置
+	meta, err := getTableMeta(tx, tableID)
+	if err != nil {
+		return err
+	}
+	if !meta.Func.SoftDelete {
+		return ErrSoftDeleteDisabled
+	}
+	scope, err := userDataScope(tx, meta, operatorID)
+	if err != nil {
+		return err
+	}
+
+	for _, condition := range req {
+		where, args := buildKeyWhere(condition)
+		if scope != nil {
+			where += " AND " + scope.clause
+			args = append(args, scope.args...)
+		}
+		bump := ""
+		if meta.Func.Versioned {
+			bump = fmt.Sprintf(", %s = %s + 1", model.ColumnRowVersion, model.ColumnRowVersion)
+		}
+		query := fmt.Sprintf("UPDATE %s SET %s = NULL, %s = 0%s WHERE %s AND %s IS NOT NULL",
+			meta.TableName, model.ColumnDeletedAt, model.ColumnDeletedBy, bump, where, model.ColumnDeletedAt)
+
+		_, err = tx.Exec(query, args...)
+		if err != nil {
+			return fmt.Errorf("restore table item failed: %v", err)
+		}
+	}
+
+	// 提交事务
+	if err := tx.Commit(); err != nil {
+		return fmt.Errorf("commit transaction failed: %v", err)
+	}
+	InvalidateTableCache(tableID)
+
+	return nil
+}
+
+// PurgeTableItems 从回收站彻底删除数据表记录
+func (s *TableService) PurgeTableItems(operatorID uint, tableID uint, req []map[string]interface{}) error {
+	// 开启事务
+	tx, err := s.db.Beginx()
+	if err != nil {
+		return fmt.Errorf("begin transaction failed: %v", err)
+	}
+	defer tx.Rollback()
+
+	// 从配置表读取表配置
+	meta, err := getTableMeta(tx, tableID)
+	if err != nil {
+		return err
+	}
+	if !meta.Func.SoftDelete {
+		return ErrSoftDeleteDisabled
+	}
+	scope, err := userDataScope(tx, meta, operatorID)
+	if err != nil {
+		return err
+	}
+
+	for _, condition := range req {
+		where, args := buildKeyWhere(condition)
+		if scope != nil {
+			where += " AND " + scope.clause
+			args = append(args, scope.args...)
+		}
+		query := fmt.Sprintf("DELETE FROM %s WHERE %s AND %s IS NOT NULL",
+			meta.TableName, where, model.ColumnDeletedAt)
+
+		_, err = tx.Exec(query, args...)
+		if err != nil {
+			return fmt.Errorf("purge table item failed: %v", err)
+		}
+	}
+
+	// 提交事务
+	if err := tx.Commit(); err != nil {
+		return fmt.Errorf("commit transaction failed: %v", err)
+	}
+	InvalidateTableCache(tableID)
+
+	return nil
+}
+
+// PurgeExpiredItems 彻底删除超过保留期的回收站记录，retentionDays为0时使用表配置
+func (s *TableService) PurgeExpiredItems(tableID uint, retentionDays int) (int64, error) {
+	// 从配置表读取表配置
+	meta, err := getTableMeta(s.db, tableID)
+	if err != nil {
+		return 0, err
+	}
+	if !meta.Func.SoftDelete {
+		return 0, ErrSoftDeleteDisabled
+	}
+	if retentionDays <= 0 {
+		retentionDays = meta.Func.GetRetentionDays()
+	}
+
+	// 分批删除，避免长时间锁表
+	query := fmt.Sprintf("DELETE FROM %s WHERE %s < NOW() - INTERVAL ? DAY LIMIT ?",
+		meta.TableName, model.ColumnDeletedAt)
+	var total int64
+	for {
+		result, err := s.db.Exec(query, retentionDays, purgeBatchSize)
+		if err != nil {
+			return total, fmt.Errorf("purge expired items failed: %v", err)
+		}
+		affected, err := result.RowsAffected()
+		if err != nil {
+			return total, fmt.Errorf("get rows affected failed: %v", err)
+		}
+		total += affected
+		if affected < purgeBatchSize {
+			break
+		}
+	}
+
+	return total, nil
+}
@@ -0,0 +1,300 @@
+package element
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+
+	"github.com/iiwish/lingjian/internal/model"
+	"github.com/jmoiron/sqlx"
+)
+
+// referenceTarget 引用字段指向的表和匹配列
+type referenceTarget struct {
+	table     string // 目标表名
+	key       string // 匹配列
+	label     string // 展开时显示的列
+	scope     string // 目标记录的过滤条件，排除已软删除的记录
+	args      []interface{}
+	dimension bool           // 是否为维度
+	meta      *tableMeta     // 引用数据表时为目标表配置
+	policies  columnPolicies // 展开时目标表对用户生效的字段访问策略
+}
+
+// refRecord 引用的目标记录
+type refRecord struct {
+	Key    string `db:"ref_key"`
+	ID     uint   `db:"id"`
+	Name   string `db:"name"`
+	Code   string `db:"code"`
+	NodeID string `db:"node_id"`
+}
+
+// resolveReference 解析引用字段指向的表和匹配列
+func resolveReference(q sqlx.Queryer, ref *model.FieldReference) (*referenceTarget, error) {
+	if ref.Type == model.RefDimension {
+		var dimTable string
+		err := sqlx.Get(q, &dimTable, "SELECT table_name FROM sys_config_dimensions WHERE id = ?", ref.DimID)
+		if err != nil {
+			return nil, fmt.Errorf("get dimension table name failed: %v", err)
+		}
+		key := ref.Key
+		if key == "" {
+			key = "id"
+		}
+		return &referenceTarget{table: dimTable, key: key, dimension: true}, nil
+	}
+
+	meta, err := getTableMeta(q, ref.TableID)
+	if err != nil {
+		return nil, err
+	}
+	target := &referenceTarget{table: meta.TableName, key: ref.Key, label: ref.Label, meta: meta}
+	if target.key == "" {
+		schema, err := getTableSchema(q, meta.TableName)
+		if err != nil {
+			return nil, err
+		}
+		if len(schema.PrimaryKey) != 1 {
+			return nil, fmt.Errorf("referenced table %s requires a key column", meta.TableName)
+		}
+		target.key = schema.PrimaryKey[0]
+	}
+	if meta.Func.SoftDelete {
+		target.scope = model.ColumnDeletedAt + " IS NULL"
+	}
+	return target, nil
+}
+
+// restrict 按用户在目标表的数据权限过滤引用的记录，显示列按目标表的字段访问策略隐藏或脱敏
+func (t *referenceTarget) restrict(q sqlx.Queryer, userID uint) error {
+	if t.meta == nil {
+		return nil
+	}
+	policies, err := userColumnPolicies(q, t.meta, userID)
+	if err != nil {
+		return err
+	}
+	t.policies = policies
+
+	ds, err := userDataScope(q, t.meta, userID)
+	if err != nil {
+		return err
+	}
+	if ds != nil {
+		if t.scope != "" {
+			t.scope += " AND "
+		}
+		t.scope += ds.clause
+		t.args = append(t.args, ds.args...)
+	}
+	return nil
+}
+
+// loadReferences 按匹配列的取值读取引用的目标记录
+func loadReferences(q sqlx.Queryer, target *referenceTarget, values []string) (map[string]refRecord, error) {
+	key := model.QuoteIdentifier(target.key)
+	var columns string
+	if target.dimension {
+		columns = "id, name, code, node_id"
+	} else if target.label != "" {
+		columns = fmt.Sprintf("0 AS id, IFNULL(CAST(%s AS CHAR), '') AS name, '' AS code, '' AS node_id", model.QuoteIdentifier(target.label))
+	} else {
+		columns = "0 AS id, '' AS name, '' AS code, '' AS node_id"
+	}
+	query := fmt.Sprintf("SELECT CAST(%s AS CHAR) AS ref_key, %s FROM %s WHERE %s IN (?)",
+		key, columns, model.QuoteIdentifier(target.table), key)
+	if target.scope != "" {
+		query += " AND " + target.scope
+	}
+
+	query, args, err := sqlx.In(query, append([]interface{}{values}, target.args...)...)
+	if err != nil {
+		return nil, fmt.Errorf("prepare reference query failed: %v", err)
+	}
+	var records []refRecord
+	if err := sqlx.Select(q, &records, query, args...); err != nil {
+		return nil, fmt.Errorf("load references failed: %v", err)
+	}
+
+	found := make(map[string]refRecord, len(records))
+	for _, r := range records {
+		found[r.Key] = r
+	}
+	return found, nil
+}
+
+// referenceFields 获取数据表的引用字段，按字段名排序
+func (m *tableMeta) referenceFields() []string {
+	var fields []string
+	for name, opt := range m.FieldOptions {
+		if opt.Reference != nil {
+			fields = append(fields, name)
+		}
+	}
+	sort.Strings(fields)
+	return fields
+}
+
+// checkReferences 检查记录中引用字段的取值是否存在，NULL和空值不检查
+func checkReferences(q sqlx.Queryer, meta *tableMeta, items []map[string]interface{}) ([]model.FieldError, error) {
+	var errs []model.FieldError
+	for _, field := range meta.referenceFields() {
+		rows := make(map[string][]int)
+		for row, item := range items {
+			if value, ok := item[field]; ok && !isEmptyValue(value) {
+				key := toString(value)
+				rows[key] = append(rows[key], row)
+			}
+		}
+		if len(rows) == 0 {
+			continue
+		}
+
+		target, err := resolveReference(q, meta.FieldOptions[field].Reference)
+		if err != nil {
+			return nil, err
+		}
+		values := make([]string, 0, len(rows))
+		for key := range rows {
+			values = append(values, key)
+		}
+		sort.Strings(values)
+		found, err := loadReferences(q, target, values)
+		if err != nil {
+			return nil, err
+		}
+		for _, key := range values {
+			if _, ok := found[key]; ok {
+				continue
+			}
+			for _, row := range rows[key] {
+				errs = append(errs, model.FieldError{Row: row, Field: field, Rule: model.RuleReference, Message: fmt.Sprintf("引用的记录%s不存在", key)})
+			}
+		}
+	}
+	return errs, nil
+}
+
+// expandReferences 将记录中引用字段的取值展开为名称、编码和维度路径，保存在记录的_refs中
+// 用户不可读的字段不展开，引用数据表时只展开用户在目标表数据权限内的记录
+func expandReferences(q sqlx.Queryer, meta *tableMeta, policies columnPolicies, userID uint, rows []map[string]interface{}) error {
+	fields := meta.referenceFields()
+	if len(fields) == 0 || len(rows) == 0 {
+		return nil
+	}
+
+	refs := make([]map[string]model.RefItem, len(rows))
+	for i := range refs {
+		refs[i] = make(map[string]model.RefItem)
+	}
+	for _, field := range fields {
+		if !policies.readable(field) {
+			continue
+		}
+		seen := make(map[string]bool)
+		var values []string
+		for _, row := range rows {
+			if value, ok := row[field]; ok && value != nil {
+				key := toString(value)
+				if !seen[key] {
+					seen[key] = true
+					values = append(values, key)
+				}
+			}
+		}
+		if len(values) == 0 {
+			continue
+		}
+
+		target, err := resolveReference(q, meta.FieldOptions[field].Reference)
+		if err != nil {
+			return err
+		}
+		if err := target.restrict(q, userID); err != nil {
+			return err
+		}
+		found, err := loadReferences(q, target, values)
+		if err != nil {
+			return err
+		}
+		if target.label != "" && len(target.policies) > 0 {
+			for key, r := range found {
+				label := map[string]interface{}{target.label: r.Name}
+				target.policies.apply([]map[string]interface{}{label})
+				r.Name = toString(label[target.label])
+				found[key] = r
+			}
+		}
+		var paths map[string]string
+		if target.dimension {
+			if paths, err = dimensionPaths(q, target.table, found); err != nil {
+				return err
+			}
+		}
+
+		for i, row := range rows {
+			value, ok := row[field]
+			if !ok || value == nil {
+				continue
+			}
+			item := model.RefItem{Key: value}
+			if r, ok := found[toString(value)]; ok {
+				item.Found = true
+				item.Name = r.Name
+				item.Code = r.Code
+				item.Path = paths[r.Key]
+			}
+			refs[i][field] = item
+		}
+	}
+
+	for i, row := range rows {
+		row["_refs"] = refs[i]
+	}
+	return nil
+}
+
+// dimensionPaths 根据维度明细的node_id生成从根节点开始的名称路径
+func dimensionPaths(q sqlx.Queryer, dimTable string, records map[string]refRecord) (map[string]string, error) {
+	idSet := make(map[string]bool)
+	for _, r := range records {
+		for _, id := range strings.Split(r.NodeID, "_") {
+			if id != "" {
+				idSet[id] = true
+			}
+		}
+	}
+	if len(idSet) == 0 {
+		return nil, nil
+	}
+	ids := make([]string, 0, len(idSet))
+	for id := range idSet {
+		ids = append(ids, id)
+	}
+
+	query, args, err := sqlx.In(fmt.Sprintf("SELECT CAST(id AS CHAR) AS ref_key, name FROM %s WHERE id IN (?)", model.QuoteIdentifier(dimTable)), ids)
+	if err != nil {
+		return nil, fmt.Errorf("prepare dimension path query failed: %v", err)
+	}
+	var nodes []refRecord
+	if err := sqlx.Select(q, &nodes, query, args...); err != nil {
+		return nil, fmt.Errorf("load dimension path failed: %v", err)
+	}
+	names := make(map[string]string, len(nodes))
+	for _, n := range nodes {
+		names[n.Key] = n.Name
+	}
+
+	paths := make(map[string]string, len(records))
+	for key, r := range records {
+		var parts []string
+		for _, id := range strings.Split(r.NodeID, "_") {
+			if name, ok := names[id]; ok {
+				parts = append(parts, name)
+			}
+		}
+		paths[key] = strings.Join(parts, "/")
+	}
+	return paths, nil
+}
@@ -0,0 +1,103 @@
+package element
+
+import (
+	"fmt"
+	"sync"
+	"time"
+
+	"github.com/iiwish/lingjian/internal/model"
+	"github.com/jmoiron/sqlx"
+)
+
+// schemaCacheTTL 数据表结构缓存有效期，防止表结构在平台外被修改后长期不一致
+const schemaCacheTTL = 5 * time.Minute
+
+// cachedSchema 缓存的数据表结构
+type cachedSchema struct {
+	schema   *model.TableSchema
+	loadedAt time.Time
+}
+
+// schemaCache 数据表结构缓存，以物理表名为键
+var schemaCache = struct {
+	sync.RWMutex
+	items map[string]cachedSchema
+}{items: make(map[string]cachedSchema)}
+
+// getTableSchema 获取数据表结构，优先从缓存读取
+func getTableSchema(q sqlx.Queryer, tableName string) (*model.TableSchema, error) {
+	schemaCache.RLock()
+	cached, ok := schemaCache.items[tableName]
+	schemaCache.RUnlock()
+	if ok && time.Since(cached.loadedAt) < schemaCacheTTL {
+		return cached.schema, nil
+	}
+
+	var columns []model.ColumnInfo
+	err := sqlx.Select(q, &columns, `
+		SELECT COLUMN_NAME AS name, DATA_TYPE AS data_type, COLUMN_TYPE AS column_type,
+			IS_NULLABLE = 'YES' AS nullable, COLUMN_KEY = 'PRI' AS primary_key,
+			EXTRA LIKE '%auto_increment%' AS auto_increment
+		FROM information_schema.columns
+		WHERE table_schema = DATABASE() AND table_name = ?
+		ORDER BY ORDINAL_POSITION
+	`, tableName)
+	if err != nil {
+		return nil, fmt.Errorf("get table schema failed: %v", err)
+	}
+	if len(columns) == 0 {
+		return nil, fmt.Errorf("table %s does not exist", tableName)
+	}
+
+	schema := model.NewTableSchema(columns)
+
+	// 读取索引，全文索引用于校验全文检索条件，唯一索引用于写入时识别重复记录，普通索引用于索引建议
+	var indexColumns []struct {
+		IndexName  string `db:"index_name"`
+		ColumnName string `db:"column_name"`
+		IndexType  string `db:"index_type"`
+		NonUnique  bool   `db:"non_unique"`
+	}
+	err = sqlx.Select(q, &indexColumns, `
+		SELECT INDEX_NAME AS index_name, COLUMN_NAME AS column_name, INDEX_TYPE AS index_type, NON_UNIQUE AS non_unique
+		FROM information_schema.statistics
+		WHERE table_schema = DATABASE() AND table_name = ? AND INDEX_NAME != 'PRIMARY'
+		ORDER BY INDEX_NAME, SEQ_IN_INDEX
+	`, tableName)
+	if err != nil {
+		return nil, fmt.Errorf("get table schema failed: %v", err)
+	}
+	if len(schema.PrimaryKey) > 0 {
+		schema.UniqueKeys = append(schema.UniqueKeys, schema.PrimaryKey)
+	}
+	for i, col := range indexColumns {
+		var indexes *[][]string
+		switch {
+		case col.IndexType == model.IndexTypeFulltext:
+			indexes = &schema.FulltextIndexes
+		case !col.NonUnique:
+			indexes = &schema.UniqueKeys
+		default:
+			indexes = &schema.Indexes
+		}
+		if i == 0 || col.IndexName != indexColumns[i-1].IndexName {
+			*indexes = append(*indexes, nil)
+		}
+		last := len(*indexes) - 1
+		(*indexes)[last] = append((*indexes)[last], col.ColumnName)
+	}
+
+	schemaCache.Lock()
+	schemaCache.items[tableName] = cachedSchema{schema: schema, loadedAt: time.Now()}
+	schemaCache.Unlock()
+	return schema, nil
+}
+
+// InvalidateTableSchema 清除数据表结构缓存，数据表结构变更后调用
+func InvalidateTableSchema(tableNames ...string) {
+	schemaCache.Lock()
+	defer schemaCache.Unlock()
+	for _, name := range tableNames {
+		delete(schemaCache.items, name)
+	}
+}
@@ -0,0 +1,199 @@
+package element
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+
+	"github.com/iiwish/lingjian/internal/model"
+	"github.com/jmoiron/sqlx"
+)
+
+// dataScope 用户数据权限的过滤条件
+type dataScope struct {
+	clause string
+	args   []interface{}
+	fields []scopeField // 受限的数据权限字段
+}
+
+// scopeField 单个数据权限字段的过滤条件
+type scopeField struct {
+	name   string
+	clause string
+	args   []interface{}
+}
+
+// permittedItemIDs 获取用户通过角色获得的维度明细权限，包含0时表示拥有全部明细的权限
+func permittedItemIDs(q sqlx.Queryer, userID uint, dimID uint) ([]uint, error) {
+	var itemIDs []uint
+	err := sqlx.Select(q, &itemIDs, `
+		SELECT DISTINCT p.item_id FROM sys_permissions p
+		INNER JOIN sys_role_permissions rp ON p.id = rp.permission_id
+		INNER JOIN sys_user_roles ur ON rp.role_id = ur.role_id
+		WHERE ur.user_id = ?
+		AND p.dim_id = ?
+		AND p.status = 1
+	`, userID, dimID)
+	if err != nil {
+		return nil, fmt.Errorf("get user permissions failed: %v", err)
+	}
+	return itemIDs, nil
+}
+
+// userDataScope 根据用户的维度权限构建数据权限过滤条件，数据表未配置数据权限或用户拥有全部权限时返回nil
+func userDataScope(q sqlx.Queryer, meta *tableMeta, userID uint) (*dataScope, error) {
+	var clauses []string
+	var args []interface{}
+	var fields []scopeField
+	for _, ds := range meta.Func.DataScopes {
+		itemIDs, err := permittedItemIDs(q, userID, ds.DimID)
+		if err != nil {
+			return nil, err
+		}
+		// 没有该维度的权限时不能访问任何记录
+		if len(itemIDs) == 0 {
+			return &dataScope{clause: "1 = 0", fields: []scopeField{{name: ds.Field, clause: "1 = 0"}}}, nil
+		}
+
+		fullAccess := false
+		for _, id := range itemIDs {
+			if id == 0 {
+				fullAccess = true
+				break
+			}
+		}
+		if fullAccess {
+			continue
+		}
+
+		var dimTable string
+		err = sqlx.Get(q, &dimTable, "SELECT table_name FROM sys_config_dimensions WHERE id = ?", ds.DimID)
+		if err != nil {
+			return nil, fmt.Errorf("get dimension table name failed: %v", err)
+		}
+
+		// 有权限的维度节点及其全部下级节点
+		key := "id"
+		if ds.DimKey == "code" {
+			key = "code"
+		}
+		dim := model.QuoteIdentifier(dimTable)
+		placeholders := strings.TrimSuffix(strings.Repeat("?,", len(itemIDs)), ",")
+		field := scopeField{name: ds.Field, clause: fmt.Sprintf(
+			`%s IN (SELECT d.%s FROM %s d INNER JOIN %s p ON d.id = p.id OR d.node_id LIKE CONCAT(p.node_id, '\_%%') WHERE p.id IN (%s))`,
+			model.QuoteIdentifier(ds.Field), key, dim, dim, placeholders)}
+		for _, id := range itemIDs {
+			field.args = append(field.args, id)
+		}
+		clauses = append(clauses, field.clause)
+		args = append(args, field.args...)
+		fields = append(fields, field)
+	}
+
+	if len(clauses) == 0 {
+		return nil, nil
+	}
+	return &dataScope{clause: strings.Join(clauses, " AND "), args: args, fields: fields}, nil
+}
+
+// applyDataScope 为查询条件追加用户的数据权限过滤条件
+func applyDataScope(q sqlx.Queryer, meta *tableMeta, userID uint, query *model.QueryCondition) error {
+	ds, err := userDataScope(q, meta, userID)
+	if err != nil {
+		return err
+	}
+	if ds != nil {
+		query.AddScope(ds.clause, ds.args...)
+	}
+	return nil
+}
+
+// inDataScope 判断记录是否在用户的数据权限内
+func inDataScope(q sqlx.Queryer, meta *tableMeta, ds *dataScope, keyColumns []string, item map[string]interface{}) (bool, error) {
+	if ds == nil {
+		return true, nil
+	}
+	condition := make(map[string]interface{}, len(keyColumns))
+	for _, col := range keyColumns {
+		condition[col] = item[col]
+	}
+	where, args := buildKeyWhere(condition)
+	args = append(args, ds.args...)
+
+	var count int
+	query := fmt.Sprintf("SELECT COUNT(*) FROM %s WHERE %s AND %s", model.QuoteIdentifier(meta.TableName), where, ds.clause)
+	if err := sqlx.Get(q, &count, query, args...); err != nil {
+		return false, fmt.Errorf("check data scope failed: %v", err)
+	}
+	return count > 0, nil
+}
+
+// checkScopeValues 检查写入的数据权限字段取值是否在用户的数据权限内
+// partial为true时表示局部更新，只检查提交的字段；否则未提交的数据权限字段按NULL检查
+func checkScopeValues(q sqlx.Queryer, ds *dataScope, items []map[string]interface{}, partial bool) error {
+	if ds == nil {
+		return nil
+	}
+
+	var errs []model.FieldError
+	for _, field := range ds.fields {
+		// 收集各取值对应的行号
+		rows := make(map[string][]int)
+		var values []interface{}
+		for row, item := range items {
+			value, present := item[field.name]
+			if !present && partial {
+				continue
+			}
+			key := toString(value)
+			if value == nil {
+				key = "\x00"
+			}
+			if _, ok := rows[key]; !ok {
+				values = append(values, value)
+			}
+			rows[key] = append(rows[key], row)
+		}
+		if len(values) == 0 {
+			continue
+		}
+
+		// 将取值构造为派生表，按数据权限条件过滤
+		quoted := model.QuoteIdentifier(field.name)
+		selects := make([]string, len(values))
+		for i := range values {
+			selects[i] = "SELECT ? AS " + quoted
+		}
+		query := fmt.Sprintf("SELECT CAST(%s AS CHAR) FROM (%s) t WHERE %s",
+			quoted, strings.Join(selects, " UNION ALL "), field.clause)
+		args := append(append([]interface{}{}, values...), field.args...)
+		var permitted []string
+		if err := sqlx.Select(q, &permitted, query, args...); err != nil {
+			return fmt.Errorf("check data scope values failed: %v", err)
+		}
+
+		allowed := make(map[string]bool, len(permitted))
+		for _, v := range permitted {
+			allowed[v] = true
+		}
+		for key, keyRows := range rows {
+			if allowed[key] {
+				continue
+			}
+			for _, row := range keyRows {
+				errs = append(errs, model.FieldError{Row: row, Field: field.name, Message: "不在数据权限范围内"})
+			}
+		}
+	}
+
+	if len(errs) > 0 {
+		sort.SliceStable(errs, func(i, j int) bool {
+			if errs[i].Row != errs[j].Row {
+				return errs[i].Row < errs[j].Row
+			}
+			return errs[i].Field < errs[j].Field
+		})
+		return &ValidationError{Errors: errs}
+	}
+	return nil
+}
@@ -0,0 +1,99 @@
+package element
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+	"unicode/utf8"
+
+	"github.com/iiwish/lingjian/internal/model"
+)
+
+// maxSearchKeywordLength 快速搜索关键字最大长度
+const maxSearchKeywordLength = 100
+
+// ErrSearchDisabled 数据表未配置快速搜索字段
+var ErrSearchDisabled = errors.New("table does not configure search columns")
+
+// likeEscaper 转义LIKE通配符
+var likeEscaper = strings.NewReplacer(`\`, `\\`, `%`, `\%`, `_`, `\_`)
+
+// SearchTableItems 在数据表配置的搜索字段中快速搜索记录，结果按相关度排序
+func (s *TableService) SearchTableItems(tableID uint, keyword string, req *model.TableQueryReq, userID uint) (*model.TableQueryResp, error) {
+	keyword = strings.TrimSpace(keyword)
+	if keyword == "" {
+		return nil, &model.QueryError{Message: "keyword is required"}
+	}
+	if utf8.RuneCountInString(keyword) > maxSearchKeywordLength {
+		return nil, &model.QueryError{Message: "keyword is too long"}
+	}
+
+	// 从配置表读取表配置
+	meta, err := getTableMeta(s.db, tableID)
+	if err != nil {
+		return nil, err
+	}
+
+	// 用户不可读的字段不参与搜索
+	policies, err := userColumnPolicies(s.db, meta, userID)
+	if err != nil {
+		return nil, err
+	}
+	var cols []string
+	for _, col := range meta.Func.SearchCols {
+		if policies.readable(col) {
+			cols = append(cols, col)
+		}
+	}
+	if len(cols) == 0 {
+		return nil, ErrSearchDisabled
+	}
+
+	schema, err := getTableSchema(s.db, meta.TableName)
+	if err != nil {
+		return nil, err
+	}
+
+	// 搜索字段共用一个全文索引时直接检索，否则逐个字段检索，没有全文索引的字段使用LIKE
+	var clauses []string
+	var args []interface{}
+	if schema.HasFulltextIndex(cols...) {
+		clauses = append(clauses, model.MatchClause(cols...))
+		args = append(args, keyword)
+		req.Query.AddRelevance(model.MatchClause(cols...), keyword)
+	} else {
+		for _, col := range cols {
+			if _, ok := schema.Column(col); !ok {
+				return nil, fmt.Errorf("search column %s does not exist", col)
+			}
+			if schema.HasFulltextIndex(col) {
+				clauses = append(clauses, model.MatchClause(col))
+				args = append(args, keyword)
+				req.Query.AddRelevance(model.MatchClause(col), keyword)
+				continue
+			}
+			clauses = append(clauses, model.QuoteIdentifier(col)+" LIKE ?")
+			args = append(args, "%"+likeEscaper.Replace(keyword)+"%")
+		}
+	}
+	req.Query.AddScope("("+strings.Join(clauses, " OR ")+")", args...)
+
+	// 软删除的记录不出现在搜索结果中
+	if meta.Func.SoftDelete {
+		req.Query.AddScope(model.ColumnDeletedAt + " IS NULL")
+	}
+	if err := applyDataScope(s.db, meta, userID, &req.Query); err != nil {
+		return nil, err
+	}
+
+	resp, err := s.queryItems(meta, req, policies)
+	if err != nil {
+		return nil, err
+	}
+	if req.ExpandRefs {
+		if err := expandReferences(s.db, meta, policies, userID, resp.Items); err != nil {
+			return nil, err
+		}
+	}
+	return resp, nil
+}
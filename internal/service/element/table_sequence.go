@@ -0,0 +1,84 @@
+package element
+
+import (
+	"fmt"
+	"sort"
+	"time"
+
+	"github.com/iiwish/lingjian/internal/model"
+	"github.com/jmoiron/sqlx"
+)
+
+// sequenceFields 获取数据表的编号字段，按字段名排序
+func (m *tableMeta) sequenceFields() []string {
+	var fields []string
+	for name, opt := range m.FieldOptions {
+		if opt.Type == model.TypeSequence && opt.Sequence != nil {
+			fields = append(fields, name)
+		}
+	}
+	sort.Strings(fields)
+	return fields
+}
+
+// nextSequence 为编号字段分配count个连续的计数，返回其中第一个
+// 计数器行在事务提交前保持锁定，并发写入按顺序分配；事务回滚时计数一并回滚，已提交的计数不会再次分配
+func nextSequence(tx *sqlx.Tx, tableID uint, field string, period string, count int) (int64, error) {
+	_, err := tx.Exec(`
+		INSERT INTO sys_table_sequences (table_id, field_name, period, value)
+		VALUES (?, ?, ?, LAST_INSERT_ID(?))
+		ON DUPLICATE KEY UPDATE value = LAST_INSERT_ID(value + ?)
+	`, tableID, field, period, count, count)
+	if err != nil {
+		return 0, fmt.Errorf("allocate sequence failed: %v", err)
+	}
+	var last int64
+	if err := tx.Get(&last, "SELECT LAST_INSERT_ID()"); err != nil {
+		return 0, fmt.Errorf("get sequence value failed: %v", err)
+	}
+	return last - int64(count) + 1, nil
+}
+
+// assignSequences 为新增的记录生成编号字段的取值
+func assignSequences(tx *sqlx.Tx, meta *tableMeta, items []map[string]interface{}) error {
+	if len(items) == 0 {
+		return nil
+	}
+	now := time.Now()
+	for _, field := range meta.sequenceFields() {
+		seq := meta.FieldOptions[field].Sequence
+		first, err := nextSequence(tx, meta.ID, field, seq.Period(now), len(items))
+		if err != nil {
+			return err
+		}
+		for i, item := range items {
+			item[field] = seq.Format(now, first+int64(i))
+		}
+	}
+	return nil
+}
+
+// checkSequenceWrites 检查是否修改了编号字段，keyColumns中的字段只用于定位记录，不检查
+func checkSequenceWrites(meta *tableMeta, items []map[string]interface{}, keyColumns []string) error {
+	fields := meta.sequenceFields()
+	if len(fields) == 0 {
+		return nil
+	}
+	isKey := make(map[string]bool, len(keyColumns))
+	for _, col := range keyColumns {
+		isKey[col] = true
+	}
+
+	var errs []model.FieldError
+	for row, item := range items {
+		for _, field := range fields {
+			if _, ok := item[field]; ok && !isKey[field] {
+				errs = append(errs, model.FieldError{Row: row, Field: field, Rule: model.RuleSequence, Message: "编号由系统生成，不能修改"})
+			}
+		}
+	}
+	if len(errs) > 0 {
+		return &ValidationError{Errors: errs}
+	}
+	return nil
+}
@@ -0,0 +1,383 @@
+package element
+
+import (
+	"fmt"
+	"regexp"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+	"unicode/utf8"
+
+	"github.com/iiwish/lingjian/internal/model"
+	"github.com/jmoiron/sqlx"
+)
+
+// ValidationError 数据表记录校验错误
+type ValidationError struct {
+	Errors []model.FieldError
+}
+
+func (e *ValidationError) Error() string {
+	msgs := make([]string, 0, len(e.Errors))
+	for _, fe := range e.Errors {
+		msgs = append(msgs, fmt.Sprintf("row %d field %s: %s", fe.Row, fe.Field, fe.Message))
+	}
+	return "validation failed: " + strings.Join(msgs, "; ")
+}
+
+// dimensionCheck 待批量校验的维度取值
+type dimensionCheck struct {
+	field string
+	rule  model.FieldRule
+	rows  map[string][]int // 取值 -> 行号
+}
+
+// hasCompareRules 判断数据表是否配置了字段比较规则
+func (m *tableMeta) hasCompareRules() bool {
+	for _, opt := range m.FieldOptions {
+		for _, rule := range opt.Rules {
+			if rule.Type == model.RuleCompare {
+				return true
+			}
+		}
+	}
+	return false
+}
+
+// validateItems 按字段类型和校验规则校验数据表记录，partial为true时表示局部更新，未提交的字段不做必填校验
+// currents为局部更新时各记录的当前值，比较规则中未提交的字段使用当前值
+func validateItems(q sqlx.Queryer, meta *tableMeta, items []map[string]interface{}, partial bool, currents []map[string]interface{}) error {
+	if len(meta.FieldOptions) == 0 {
+		return nil
+	}
+
+	// 按字段名排序，保证错误顺序稳定
+	fields := make([]string, 0, len(meta.FieldOptions))
+	for name := range meta.FieldOptions {
+		fields = append(fields, name)
+	}
+	sort.Strings(fields)
+
+	var errs []model.FieldError
+	regexps := make(map[string]*regexp.Regexp)
+	var dimChecks []*dimensionCheck
+
+	for _, field := range fields {
+		// 逻辑字段类型的隐含规则先于配置的校验规则
+		opt := meta.FieldOptions[field]
+		for _, rule := range append(opt.TypeRules(), opt.Rules...) {
+			var dimCheck *dimensionCheck
+			if rule.Type == model.RuleDimension {
+				dimCheck = &dimensionCheck{field: field, rule: rule, rows: make(map[string][]int)}
+				dimChecks = append(dimChecks, dimCheck)
+			}
+
+			for row, item := range items {
+				value, present := item[field]
+				if rule.Type == model.RuleRequired {
+					if (!partial || present) && isEmptyValue(value) {
+						errs = append(errs, newFieldError(row, field, rule, "不能为空"))
+					}
+					continue
+				}
+				if rule.Type == model.RuleCompare {
+					if err := compareRow(row, item, currentAt(currents, row), field, rule); err != nil {
+						errs = append(errs, *err)
+					}
+					continue
+				}
+				// 其余规则只校验有值的字段
+				if !present || isEmptyValue(value) {
+					continue
+				}
+
+				switch rule.Type {
+				case model.RuleMin, model.RuleMax:
+					num, ok := toFloat(value)
+					if !ok {
+						errs = append(errs, newFieldError(row, field, rule, "必须是数字"))
+						continue
+					}
+					limit := rule.Value.(float64)
+					if rule.Type == model.RuleMin && num < limit {
+						errs = append(errs, newFieldError(row, field, rule, fmt.Sprintf("不能小于%v", limit)))
+					}
+					if rule.Type == model.RuleMax && num > limit {
+						errs = append(errs, newFieldError(row, field, rule, fmt.Sprintf("不能大于%v", limit)))
+					}
+				case model.RuleMinLength, model.RuleMaxLength:
+					length := utf8.RuneCountInString(toString(value))
+					limit := int(rule.Value.(float64))
+					if rule.Type == model.RuleMinLength && length < limit {
+						errs = append(errs, newFieldError(row, field, rule, fmt.Sprintf("长度不能小于%d", limit)))
+					}
+					if rule.Type == model.RuleMaxLength && length > limit {
+						errs = append(errs, newFieldError(row, field, rule, fmt.Sprintf("长度不能大于%d", limit)))
+					}
+				case model.RuleRegex:
+					pattern := rule.Value.(string)
+					re, ok := regexps[pattern]
+					if !ok {
+						re = regexp.MustCompile(pattern)
+						regexps[pattern] = re
+					}
+					if !re.MatchString(toString(value)) {
+						errs = append(errs, newFieldError(row, field, rule, "格式不正确"))
+					}
+				case model.RuleEnum:
+					matched := false
+					for _, option := range rule.Value.([]interface{}) {
+						if toString(option) == toString(value) {
+							matched = true
+							break
+						}
+					}
+					if !matched {
+						errs = append(errs, newFieldError(row, field, rule, "不在可选值范围内"))
+					}
+				case model.RuleDimension:
+					key := toString(value)
+					dimCheck.rows[key] = append(dimCheck.rows[key], row)
+				}
+			}
+		}
+	}
+
+	// 批量校验维度取值
+	for _, check := range dimChecks {
+		missing, err := findMissingDimensionValues(q, check.rule, check.rows)
+		if err != nil {
+			return err
+		}
+		for _, key := range missing {
+			for _, row := range check.rows[key] {
+				errs = append(errs, newFieldError(row, check.field, check.rule, fmt.Sprintf("维度中不存在值%s", key)))
+			}
+		}
+	}
+
+	// 批量校验引用字段的取值
+	refErrs, err := checkReferences(q, meta, items)
+	if err != nil {
+		return err
+	}
+	errs = append(errs, refErrs...)
+
+	// 校验附件字段的取值
+	attachErrs, err := checkAttachments(q, meta, items)
+	if err != nil {
+		return err
+	}
+	errs = append(errs, attachErrs...)
+
+	if len(errs) > 0 {
+		sort.SliceStable(errs, func(i, j int) bool { return errs[i].Row < errs[j].Row })
+		return &ValidationError{Errors: errs}
+	}
+	return nil
+}
+
+// normalizeItems 按逻辑字段类型转换记录的取值，在校验通过后、写入前调用
+func normalizeItems(meta *tableMeta, items []map[string]interface{}) {
+	for field, opt := range meta.FieldOptions {
+		if opt.Type != model.TypeBoolean {
+			continue
+		}
+		for _, item := range items {
+			if value, ok := item[field]; ok && value != nil {
+				item[field] = opt.NormalizeValue(value)
+			}
+		}
+	}
+}
+
+// currentAt 获取第row条记录的当前值，没有当前值时返回nil
+func currentAt(currents []map[string]interface{}, row int) map[string]interface{} {
+	if row < len(currents) {
+		return currents[row]
+	}
+	return nil
+}
+
+// compareRow 校验记录的字段比较规则，比较的两个字段都未提交时不校验，只提交其中一个时另一个使用记录当前值
+func compareRow(row int, item map[string]interface{}, current map[string]interface{}, field string, rule model.FieldRule) *model.FieldError {
+	value, present := item[field]
+	other, otherPresent := item[rule.Field]
+	if !present && !otherPresent {
+		return nil
+	}
+	if !present {
+		value = current[field]
+	}
+	if !otherPresent {
+		other = current[rule.Field]
+	}
+	if isEmptyValue(value) || isEmptyValue(other) {
+		return nil
+	}
+	if !compareValues(value, other, rule.Operator) {
+		fe := newFieldError(row, field, rule, fmt.Sprintf("与字段%s的比较不成立", rule.Field))
+		return &fe
+	}
+	return nil
+}
+
+// findMissingDimensionValues 查找维度中不存在的取值
+func findMissingDimensionValues(q sqlx.Queryer, rule model.FieldRule, rows map[string][]int) ([]string, error) {
+	if len(rows) == 0 {
+		return nil, nil
+	}
+
+	var dimTable string
+	err := sqlx.Get(q, &dimTable, "SELECT table_name FROM sys_config_dimensions WHERE id = ?", rule.DimID)
+	if err != nil {
+		return nil, fmt.Errorf("get dimension table name failed: %v", err)
+	}
+
+	values := make([]string, 0, len(rows))
+	for key := range rows {
+		values = append(values, key)
+	}
+	sort.Strings(values)
+
+	dimKey := model.QuoteIdentifier(rule.GetDimKey())
+	query, args, err := sqlx.In(fmt.Sprintf("SELECT CAST(%s AS CHAR) FROM %s WHERE %s IN (?)", dimKey, model.QuoteIdentifier(dimTable), dimKey), values)
+	if err != nil {
+		return nil, fmt.Errorf("prepare dimension query failed: %v", err)
+	}
+	var existing []string
+	if err := sqlx.Select(q, &existing, query, args...); err != nil {
+		return nil, fmt.Errorf("check dimension values failed: %v", err)
+	}
+
+	found := make(map[string]bool, len(existing))
+	for _, v := range existing {
+		found[v] = true
+	}
+	var missing []string
+	for _, v := range values {
+		if !found[v] {
+			missing = append(missing, v)
+		}
+	}
+	return missing, nil
+}
+
+// newFieldError 创建字段校验错误，优先使用规则中的自定义提示
+func newFieldError(row int, field string, rule model.FieldRule, message string) model.FieldError {
+	if rule.Message != "" {
+		message = rule.Message
+	}
+	return model.FieldError{Row: row, Field: field, Rule: rule.Type, Message: message}
+}
+
+// isEmptyValue 判断字段值是否为空
+func isEmptyValue(value interface{}) bool {
+	if value == nil {
+		return true
+	}
+	if s, ok := value.(string); ok {
+		return strings.TrimSpace(s) == ""
+	}
+	return false
+}
+
+// toFloat 将字段值转换为浮点数
+func toFloat(value interface{}) (float64, bool) {
+	switch v := value.(type) {
+	case float64:
+		return v, true
+	case float32:
+		return float64(v), true
+	case int:
+		return float64(v), true
+	case int64:
+		return float64(v), true
+	case uint:
+		return float64(v), true
+	case uint64:
+		return float64(v), true
+	case bool:
+		if v {
+			return 1, true
+		}
+		return 0, true
+	case string:
+		f, err := strconv.ParseFloat(strings.TrimSpace(v), 64)
+		return f, err == nil
+	case []byte:
+		f, err := strconv.ParseFloat(strings.TrimSpace(string(v)), 64)
+		return f, err == nil
+	}
+	return 0, false
+}
+
+// toString 将字段值转换为字符串
+func toString(value interface{}) string {
+	switch v := value.(type) {
+	case nil:
+		return ""
+	case string:
+		return v
+	case []byte:
+		return string(v)
+	case float64:
+		return strconv.FormatFloat(v, 'f', -1, 64)
+	case time.Time:
+		return v.Format("2006-01-02 15:04:05")
+	default:
+		return fmt.Sprintf("%v", v)
+	}
+}
+
+// toTime 将日期时间字段值转换为时间，支持日期和日期时间格式
+func toTime(value interface{}) (time.Time, bool) {
+	if t, ok := value.(time.Time); ok {
+		return t, true
+	}
+	s := strings.TrimSpace(toString(value))
+	for _, layout := range []string{"2006-01-02 15:04:05", "2006-01-02"} {
+		if t, err := time.ParseInLocation(layout, s, time.Local); err == nil {
+			return t, true
+		}
+	}
+	return time.Time{}, false
+}
+
+// compareValues 比较两个字段值，均为数字时按数值比较，均为日期时按时间比较，否则按字符串比较
+func compareValues(a, b interface{}, op model.Operator) bool {
+	var cmp int
+	fa, okA := toFloat(a)
+	fb, okB := toFloat(b)
+	ta, okTimeA := toTime(a)
+	tb, okTimeB := toTime(b)
+	if okA && okB {
+		switch {
+		case fa < fb:
+			cmp = -1
+		case fa > fb:
+			cmp = 1
+		}
+	} else if okTimeA && okTimeB {
+		cmp = ta.Compare(tb)
+	} else {
+		cmp = strings.Compare(toString(a), toString(b))
+	}
+
+	switch op {
+	case model.OpEq:
+		return cmp == 0
+	case model.OpNe:
+		return cmp != 0
+	case model.OpGt:
+		return cmp > 0
+	case model.OpGte:
+		return cmp >= 0
+	case model.OpLt:
+		return cmp < 0
+	case model.OpLte:
+		return cmp <= 0
+	}
+	return false
+}
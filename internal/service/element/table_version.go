@@ -0,0 +1,79 @@
+package element
+
+import (
+	"errors"
+	"fmt"
+	"math"
+	"time"
+
+	"github.com/iiwish/lingjian/internal/model"
+	"github.com/iiwish/lingjian/pkg/utils"
+	"github.com/jmoiron/sqlx"
+)
+
+// ErrItemNotFound 记录不存在或已删除
+var ErrItemNotFound = errors.New("table item does not exist")
+
+// ConflictError 记录版本冲突，记录已被其他用户修改
+type ConflictError struct {
+	Row     int                    `json:"row"`     // 冲突记录在请求中的下标
+	Current map[string]interface{} `json:"current"` // 记录的当前值
+}
+
+func (e *ConflictError) Error() string {
+	return fmt.Sprintf("row %d has been modified, current version is %v", e.Row, e.Current[model.ColumnRowVersion])
+}
+
+// takeRowVersions 取出并移除记录中的版本号，启用乐观锁的数据表修改和删除记录时必须提供
+func takeRowVersions(items []map[string]interface{}) ([]uint64, error) {
+	versions := make([]uint64, len(items))
+	var errs []model.FieldError
+	for row, item := range items {
+		value, ok := item[model.ColumnRowVersion]
+		delete(item, model.ColumnRowVersion)
+		if !ok || value == nil {
+			errs = append(errs, model.FieldError{Row: row, Field: model.ColumnRowVersion, Message: "缺少记录版本"})
+			continue
+		}
+		f, ok := toFloat(value)
+		if !ok || f < 1 || f != math.Trunc(f) {
+			errs = append(errs, model.FieldError{Row: row, Field: model.ColumnRowVersion, Message: "记录版本无效"})
+			continue
+		}
+		versions[row] = uint64(f)
+	}
+	if len(errs) > 0 {
+		return nil, &ValidationError{Errors: errs}
+	}
+	return versions, nil
+}
+
+// versionConflict 版本校验失败时读取记录当前值，记录不存在、已删除或不在数据权限内时返回 ErrItemNotFound
+func versionConflict(q sqlx.Queryer, meta *tableMeta, scope *dataScope, policies columnPolicies, row int, keyColumns []string, item map[string]interface{}) error {
+	visible, err := inDataScope(q, meta, scope, keyColumns, item)
+	if err != nil {
+		return err
+	}
+	if !visible {
+		return ErrItemNotFound
+	}
+
+	current, err := loadCurrentItem(q, meta.TableName, keyColumns, item)
+	if err != nil {
+		return err
+	}
+	if len(current) == 0 || (meta.Func.SoftDelete && current[model.ColumnDeletedAt] != nil) {
+		return ErrItemNotFound
+	}
+
+	for key, value := range current {
+		if t, ok := value.(time.Time); ok {
+			current[key] = utils.CustomTime{Time: t}.Format("2006-01-02 15:04:05")
+		}
+	}
+	if computed, err := meta.computedFields(); err == nil {
+		applyVirtualFields(computed, []map[string]interface{}{current})
+	}
+	policies.apply([]map[string]interface{}{current})
+	return &ConflictError{Row: row, Current: current}
+}
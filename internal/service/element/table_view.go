@@ -0,0 +1,354 @@
+package element
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"strings"
+
+	"github.com/iiwish/lingjian/internal/model"
+	"github.com/jmoiron/sqlx"
+)
+
+// defaultPageSize 查询接口默认的每页数量，按视图查询且视图未配置每页数量时使用
+const defaultPageSize = 10
+
+var (
+	// ErrViewNotFound 视图不存在或当前用户不可见
+	ErrViewNotFound = errors.New("table view does not exist")
+	// ErrViewForbidden 只有视图创建人和管理员可以修改视图
+	ErrViewForbidden = errors.New("only the creator or an administrator can modify the view")
+	// ErrViewNameExists 同一用户在同一数据表下的视图名称重复
+	ErrViewNameExists = errors.New("view name already exists")
+)
+
+// viewAccess 当前用户访问视图的上下文
+type viewAccess struct {
+	userID uint
+	admin  bool
+	roles  map[string]bool
+}
+
+// newViewAccess 读取用户的角色，用于判断共享视图是否可见
+func newViewAccess(q sqlx.Queryer, userID uint) (*viewAccess, error) {
+	roles, err := userRoleCodes(q, userID)
+	if err != nil {
+		return nil, err
+	}
+	access := &viewAccess{userID: userID, roles: roles}
+	for _, code := range model.AdminRoleCodes {
+		if roles[code] {
+			access.admin = true
+		}
+	}
+	return access, nil
+}
+
+// visible 判断视图对用户是否可见，创建人可见自己的视图，共享视图对指定角色或所有用户可见
+func (a *viewAccess) visible(view *model.TableViewResp) bool {
+	if view.CreatorID == a.userID {
+		return true
+	}
+	if !view.Shared {
+		return false
+	}
+	if len(view.Roles) == 0 {
+		return true
+	}
+	for _, role := range view.Roles {
+		if a.roles[role] {
+			return true
+		}
+	}
+	return false
+}
+
+// editable 判断用户是否可以修改和删除视图
+func (a *viewAccess) editable(view *model.TableViewResp) bool {
+	return view.CreatorID == a.userID || a.admin
+}
+
+// parseTableView 解析视图的共享角色和配置
+func parseTableView(view *model.TableView) (*model.TableViewResp, error) {
+	resp := &model.TableViewResp{
+		ID:        view.ID,
+		TableID:   view.TableID,
+		Name:      view.Name,
+		Shared:    view.Shared,
+		Roles:     []string{},
+		CreatorID: view.CreatorID,
+		CreatedAt: view.CreatedAt,
+		UpdatedAt: view.UpdatedAt,
+	}
+	if view.Roles != "" {
+		if err := json.Unmarshal([]byte(view.Roles), &resp.Roles); err != nil {
+			return nil, fmt.Errorf("parse view roles failed: %v", err)
+		}
+	}
+	if view.Config != "" {
+		if err := json.Unmarshal([]byte(view.Config), &resp.TableViewConfig); err != nil {
+			return nil, fmt.Errorf("parse view config failed: %v", err)
+		}
+	}
+	return resp, nil
+}
+
+// getTableView 读取数据表的视图，视图不存在或对用户不可见时返回ErrViewNotFound
+func getTableView(q sqlx.Queryer, tableID uint, viewID uint, access *viewAccess) (*model.TableViewResp, error) {
+	var view model.TableView
+	err := sqlx.Get(q, &view, `
+		SELECT id, app_id, table_id, name, shared, IFNULL(roles, '') AS roles, IFNULL(config, '') AS config,
+			created_at, creator_id, updated_at, updater_id
+		FROM sys_table_views
+		WHERE id = ? AND table_id = ?
+	`, viewID, tableID)
+	if err != nil {
+		if strings.Contains(err.Error(), "no rows") {
+			return nil, ErrViewNotFound
+		}
+		return nil, fmt.Errorf("get table view failed: %v", err)
+	}
+	resp, err := parseTableView(&view)
+	if err != nil {
+		return nil, err
+	}
+	if !access.visible(resp) {
+		return nil, ErrViewNotFound
+	}
+	resp.Editable = access.editable(resp)
+	return resp, nil
+}
+
+// ListTableViews 获取用户可见的视图，包括自己创建的视图和共享给自己的视图
+func (s *TableService) ListTableViews(tableID uint, userID uint) ([]*model.TableViewResp, error) {
+	access, err := newViewAccess(s.db, userID)
+	if err != nil {
+		return nil, err
+	}
+	var views []model.TableView
+	err = s.db.Select(&views, `
+		SELECT id, app_id, table_id, name, shared, IFNULL(roles, '') AS roles, IFNULL(config, '') AS config,
+			created_at, creator_id, updated_at, updater_id
+		FROM sys_table_views
+		WHERE table_id = ? AND (creator_id = ? OR shared = 1)
+		ORDER BY id
+	`, tableID, userID)
+	if err != nil {
+		return nil, fmt.Errorf("list table views failed: %v", err)
+	}
+
+	resp := make([]*model.TableViewResp, 0, len(views))
+	for i := range views {
+		view, err := parseTableView(&views[i])
+		if err != nil {
+			return nil, err
+		}
+		if access.visible(view) {
+			view.Editable = access.editable(view)
+			resp = append(resp, view)
+		}
+	}
+	return resp, nil
+}
+
+// GetTableView 获取视图详情
+func (s *TableService) GetTableView(tableID uint, viewID uint, userID uint) (*model.TableViewResp, error) {
+	access, err := newViewAccess(s.db, userID)
+	if err != nil {
+		return nil, err
+	}
+	return getTableView(s.db, tableID, viewID, access)
+}
+
+// CreateTableView 创建视图
+func (s *TableService) CreateTableView(tableID uint, req *model.TableViewReq, userID uint) (*model.TableViewResp, error) {
+	meta, err := getTableMeta(s.db, tableID)
+	if err != nil {
+		return nil, err
+	}
+	roles, config, err := s.validateTableView(meta, req, userID)
+	if err != nil {
+		return nil, err
+	}
+	if err := s.checkViewName(tableID, userID, req.Name, 0); err != nil {
+		return nil, err
+	}
+
+	result, err := s.db.Exec(`
+		INSERT INTO sys_table_views (app_id, table_id, name, shared, roles, config, created_at, creator_id, updated_at, updater_id)
+		VALUES (?, ?, ?, ?, ?, ?, NOW(), ?, NOW(), ?)
+	`, meta.AppID, tableID, req.Name, req.Shared, roles, config, userID, userID)
+	if err != nil {
+		return nil, fmt.Errorf("insert sys_table_views failed: %v", err)
+	}
+	id, err := result.LastInsertId()
+	if err != nil {
+		return nil, fmt.Errorf("get last insert id failed: %v", err)
+	}
+	return s.GetTableView(tableID, uint(id), userID)
+}
+
+// UpdateTableView 更新视图，只有创建人和管理员可以修改
+func (s *TableService) UpdateTableView(tableID uint, viewID uint, req *model.TableViewReq, userID uint) (*model.TableViewResp, error) {
+	access, err := newViewAccess(s.db, userID)
+	if err != nil {
+		return nil, err
+	}
+	view, err := getTableView(s.db, tableID, viewID, access)
+	if err != nil {
+		return nil, err
+	}
+	if !view.Editable {
+		return nil, ErrViewForbidden
+	}
+
+	meta, err := getTableMeta(s.db, tableID)
+	if err != nil {
+		return nil, err
+	}
+	roles, config, err := s.validateTableView(meta, req, userID)
+	if err != nil {
+		return nil, err
+	}
+	if err := s.checkViewName(tableID, view.CreatorID, req.Name, viewID); err != nil {
+		return nil, err
+	}
+
+	_, err = s.db.Exec(`
+		UPDATE sys_table_views SET name = ?, shared = ?, roles = ?, config = ?, updated_at = NOW(), updater_id = ?
+		WHERE id = ?
+	`, req.Name, req.Shared, roles, config, userID, viewID)
+	if err != nil {
+		return nil, fmt.Errorf("update sys_table_views failed: %v", err)
+	}
+	return getTableView(s.db, tableID, viewID, access)
+}
+
+// DeleteTableView 删除视图，只有创建人和管理员可以删除
+func (s *TableService) DeleteTableView(tableID uint, viewID uint, userID uint) error {
+	access, err := newViewAccess(s.db, userID)
+	if err != nil {
+		return err
+	}
+	view, err := getTableView(s.db, tableID, viewID, access)
+	if err != nil {
+		return err
+	}
+	if !view.Editable {
+		return ErrViewForbidden
+	}
+	if _, err := s.db.Exec("DELETE FROM sys_table_views WHERE id = ?", viewID); err != nil {
+		return fmt.Errorf("delete table view failed: %v", err)
+	}
+	return nil
+}
+
+// checkViewName 检查视图名称在创建人的视图中是否重复
+func (s *TableService) checkViewName(tableID uint, creatorID uint, name string, excludeID uint) error {
+	var count int
+	err := s.db.Get(&count, `
+		SELECT COUNT(*) FROM sys_table_views WHERE table_id = ? AND creator_id = ? AND name = ? AND id <> ?
+	`, tableID, creatorID, name, excludeID)
+	if err != nil {
+		return fmt.Errorf("check view name failed: %v", err)
+	}
+	if count > 0 {
+		return ErrViewNameExists
+	}
+	return nil
+}
+
+// validateTableView 根据表结构和用户的字段访问策略校验视图配置，返回共享角色和配置的JSON
+func (s *TableService) validateTableView(meta *tableMeta, req *model.TableViewReq, userID uint) (string, string, error) {
+	if err := req.Validate(); err != nil {
+		return "", "", err
+	}
+	if req.Roles == nil {
+		req.Roles = []string{}
+	}
+	roles, err := json.Marshal(req.Roles)
+	if err != nil {
+		return "", "", fmt.Errorf("marshal view roles failed: %v", err)
+	}
+	// 保存客户端提交的配置，校验时查询条件会被规范化
+	config, err := json.Marshal(req.TableViewConfig)
+	if err != nil {
+		return "", "", fmt.Errorf("marshal view config failed: %v", err)
+	}
+
+	schema, err := getTableSchema(s.db, meta.TableName)
+	if err != nil {
+		return "", "", err
+	}
+	policies, err := userColumnPolicies(s.db, meta, userID)
+	if err != nil {
+		return "", "", err
+	}
+	query := req.Query
+	if len(req.Sort) > 0 {
+		query.OrderBy = req.Sort
+	}
+	if err := query.Validate(policies.restrict(schema)); err != nil {
+		return "", "", err
+	}
+
+	for _, col := range append(append([]string{}, req.HiddenColumns...), req.ColumnOrder...) {
+		if _, ok := schema.Columns[col]; ok {
+			continue
+		}
+		if opt, ok := meta.FieldOptions[col]; ok && opt.IsVirtual() {
+			continue
+		}
+		return "", "", &model.QueryError{Message: fmt.Sprintf("column %s does not exist", col)}
+	}
+	return string(roles), string(config), nil
+}
+
+// applyTableView 将视图的配置合并到查询请求中，视图的过滤条件与请求的过滤条件同时生效，
+// 请求未指定的查询字段、分组、排序和每页数量使用视图的配置
+func (s *TableService) applyTableView(tableID uint, req *model.TableQueryReq, userID uint) (*model.TableViewResp, error) {
+	view, err := s.GetTableView(tableID, req.ViewID, userID)
+	if err != nil {
+		return nil, err
+	}
+
+	query := &req.Query
+	viewQuery := view.Query
+	switch {
+	case len(viewQuery.Root.Conditions) == 0:
+	case len(query.Root.Conditions) == 0:
+		query.Root = viewQuery.Root
+	default:
+		query.Root = model.ConditionGroup{Logic: model.LogicAnd, Conditions: []interface{}{viewQuery.Root, query.Root}}
+	}
+	if len(query.Select) == 0 && len(query.GroupBy) == 0 {
+		query.Select = viewQuery.Select
+		query.GroupBy = viewQuery.GroupBy
+		query.Having = viewQuery.Having
+	}
+	if len(query.OrderBy) == 0 {
+		query.OrderBy = viewQuery.OrderBy
+		if len(view.Sort) > 0 {
+			query.OrderBy = view.Sort
+		}
+	}
+	if req.PageSize <= 0 {
+		req.PageSize = view.PageSize
+	}
+	if req.PageSize <= 0 {
+		req.PageSize = defaultPageSize
+	}
+	return view, nil
+}
+
+// hideColumns 从查询结果中移除视图隐藏的字段
+func hideColumns(items []map[string]interface{}, columns []string) {
+	if len(columns) == 0 {
+		return
+	}
+	for _, item := range items {
+		for _, col := range columns {
+			delete(item, col)
+		}
+	}
+}
@@ -4,6 +4,7 @@ import (
 	"errors"
 
 	"github.com/iiwish/lingjian/internal/model"
+	"github.com/iiwish/lingjian/internal/service/element"
 )
 
 // PermissionService 权限服务
@@ -72,6 +73,9 @@ func (s *PermissionService) UpdatePermission(operatorID uint, permissionID uint,
 		return errors.New("权限代码已存在")
 	}
 
+	// 授权变更后数据表查询结果缓存失效
+	defer element.InvalidatePermissionCache()
+
 	// 更新权限
 	_, err = model.DB.Exec(`
 		UPDATE sys_permissions 
@@ -102,6 +106,9 @@ func (s *PermissionService) DeletePermission(operatorID uint, permissionID uint)
 		return errors.New("权限不存在")
 	}
 
+	// 授权变更后数据表查询结果缓存失效
+	defer element.InvalidatePermissionCache()
+
 	// 开始事务
 	tx, err := model.DB.Begin()
 	if err != nil {
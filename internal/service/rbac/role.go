@@ -5,6 +5,7 @@ import (
 	"time"
 
 	"github.com/iiwish/lingjian/internal/model"
+	"github.com/iiwish/lingjian/internal/service/element"
 )
 
 // RoleService 角色服务
@@ -72,6 +73,9 @@ func (s *RoleService) UpdateRole(operatorID uint, roleID uint, req *model.Role)
 		return errors.New("角色代码已存在")
 	}
 
+	// 授权变更后数据表查询结果缓存失效
+	defer element.InvalidatePermissionCache()
+
 	// 更新角色
 	_, err = model.DB.Exec(`
 		UPDATE sys_roles 
@@ -103,6 +107,9 @@ func (s *RoleService) DeleteRole(operatorID uint, roleID uint) error {
 		return errors.New("请先删除子角色")
 	}
 
+	// 授权变更后数据表查询结果缓存失效
+	defer element.InvalidatePermissionCache()
+
 	// 开始事务
 	tx, err := model.DB.Begin()
 	if err != nil {
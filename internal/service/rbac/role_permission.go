@@ -5,6 +5,7 @@ import (
 	"time"
 
 	"github.com/iiwish/lingjian/internal/model"
+	"github.com/iiwish/lingjian/internal/service/element"
 )
 
 // RolePermissionService 角色权限关联服务
@@ -44,6 +45,9 @@ func (s *RolePermissionService) AddPermissionsToRole(operatorID uint, roleID str
 		return errors.New("角色不存在")
 	}
 
+	// 授权变更后数据表查询结果缓存失效
+	defer element.InvalidatePermissionCache()
+
 	// 开始事务
 	tx, err := model.DB.Begin()
 	if err != nil {
@@ -100,6 +104,9 @@ func (s *RolePermissionService) RemovePermissionsFromRole(userID uint, roleID st
 		return errors.New("角色不存在")
 	}
 
+	// 授权变更后数据表查询结果缓存失效
+	defer element.InvalidatePermissionCache()
+
 	// 开始事务
 	tx, err := model.DB.Begin()
 	if err != nil {
@@ -5,6 +5,7 @@ import (
 	"time"
 
 	"github.com/iiwish/lingjian/internal/model"
+	"github.com/iiwish/lingjian/internal/service/element"
 )
 
 // UserRoleService 用户角色关联服务
@@ -36,6 +37,9 @@ func (s *UserRoleService) AddRoleToUser(operatorID uint, userID uint, roleID []u
 		return errors.New("用户不存在")
 	}
 
+	// 授权变更后数据表查询结果缓存失效
+	defer element.InvalidatePermissionCache()
+
 	// 开始事务
 	tx, err := model.DB.Begin()
 	if err != nil {
@@ -80,6 +84,9 @@ func (s *UserRoleService) RemoveRolesFromUser(operatorID, userID uint, roleID []
 		return errors.New("角色代码列表为空")
 	}
 
+	// 授权变更后数据表查询结果缓存失效
+	defer element.InvalidatePermissionCache()
+
 	// 开始事务
 	tx, err := model.DB.Begin()
 	if err != nil {
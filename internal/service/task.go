@@ -13,12 +13,18 @@ import (
 	"time"
 
 	"github.com/iiwish/lingjian/internal/model"
+	"github.com/iiwish/lingjian/internal/service/config"
+	"github.com/iiwish/lingjian/internal/service/element"
 )
 
 // 任务类型常量
 const (
-	TaskTypeSQL  = "sql"
-	TaskTypeHTTP = "http"
+	TaskTypeSQL               = "sql"
+	TaskTypeHTTP              = "http"
+	TaskTypeTablePurge        = "table_purge"        // 清理数据表回收站中超过保留期的记录
+	TaskTypeAttachmentCleanup = "attachment_cleanup" // 清理数据表中未被记录引用的附件
+	TaskTypeTableArchive      = "table_archive"      // 按数据表的归档策略将过期记录移入归档表
+	TaskTypeTableTrashPurge   = "table_trash_purge"  // 彻底删除回收站中超过保留期的数据表
 )
 
 // 任务状态常量
@@ -42,7 +48,7 @@ type TaskService struct {
 // CreateScheduledTask 创建定时任务
 func (s *TaskService) CreateScheduledTask(appID uint, name, typ, cron string, content map[string]interface{}, timeout, retryTimes int) error {
 	// 检查任务类型
-	if typ != TaskTypeSQL && typ != TaskTypeHTTP {
+	if typ != TaskTypeSQL && typ != TaskTypeHTTP && typ != TaskTypeTablePurge && typ != TaskTypeAttachmentCleanup && typ != TaskTypeTableArchive && typ != TaskTypeTableTrashPurge {
 		return fmt.Errorf("不支持的任务类型: %s", typ)
 	}
 
@@ -67,7 +73,7 @@ func (s *TaskService) CreateScheduledTask(appID uint, name, typ, cron string, co
 	}
 
 	// 验证任务内容
-	if err := s.validateTaskContent(typ, content); err != nil {
+	if err := s.validateTaskContent(appID, typ, content); err != nil {
 		return fmt.Errorf("验证任务内容失败: %v", err)
 	}
 
@@ -103,10 +109,11 @@ func (s *TaskService) CreateScheduledTask(appID uint, name, typ, cron string, co
 func (s *TaskService) UpdateScheduledTask(taskID uint, name, cron string, content map[string]interface{}, timeout, retryTimes int) error {
 	// 检查任务是否存在
 	var task struct {
+		AppID  uint `db:"app_id"`
 		Type   string
 		Status int
 	}
-	err := model.DB.Get(&task, "SELECT type, status FROM sys_scheduled_tasks WHERE id = ?", taskID)
+	err := model.DB.Get(&task, "SELECT app_id, type, status FROM sys_scheduled_tasks WHERE id = ?", taskID)
 	if err != nil {
 		if err == sql.ErrNoRows {
 			return errors.New("任务不存在")
@@ -120,7 +127,7 @@ func (s *TaskService) UpdateScheduledTask(taskID uint, name, cron string, conten
 	}
 
 	// 验证任务内容
-	if err := s.validateTaskContent(task.Type, content); err != nil {
+	if err := s.validateTaskContent(task.AppID, task.Type, content); err != nil {
 		return err
 	}
 
@@ -191,13 +198,14 @@ func (s *TaskService) ExecuteTask(taskID uint) error {
 	// 检查任务是否存在且启用
 	var task struct {
 		ID         uint
+		AppID      uint `db:"app_id"`
 		Type       string
 		Content    string
 		Status     int
 		Timeout    int
-		RetryTimes int
+		RetryTimes int `db:"retry_times"`
 	}
-	err := model.DB.Get(&task, "SELECT id, type, content, status, timeout, retry_times FROM sys_scheduled_tasks WHERE id = ?", taskID)
+	err := model.DB.Get(&task, "SELECT id, app_id, type, content, status, timeout, retry_times FROM sys_scheduled_tasks WHERE id = ?", taskID)
 	if err != nil {
 		return errors.New("任务不存在")
 	}
@@ -247,6 +255,14 @@ RetryLoop:
 				result, execErr = s.executeSQL(content)
 			case TaskTypeHTTP:
 				result, execErr = s.executeHTTP(content)
+			case TaskTypeTablePurge:
+				result, execErr = s.executeTablePurge(task.AppID, content)
+			case TaskTypeAttachmentCleanup:
+				result, execErr = s.executeAttachmentCleanup(task.AppID, content)
+			case TaskTypeTableArchive:
+				result, execErr = s.executeTableArchive(task.AppID, content)
+			case TaskTypeTableTrashPurge:
+				result, execErr = s.executeTableTrashPurge(task.AppID, content)
 			default:
 				execErr = errors.New("不支持的任务类型")
 			}
@@ -354,8 +370,91 @@ func (s *TaskService) executeHTTP(content map[string]interface{}) (string, error
 	return string(body), nil
 }
 
+// executeTablePurge 执行回收站清理任务
+func (s *TaskService) executeTablePurge(appID uint, content map[string]interface{}) (string, error) {
+	tableID, err := taskTableID(appID, content)
+	if err != nil {
+		return "", err
+	}
+	retentionDays, _ := content["retention_days"].(float64)
+
+	tableService := element.NewTableService(model.DB)
+	affected, err := tableService.PurgeExpiredItems(tableID, int(retentionDays))
+	if err != nil {
+		return "", err
+	}
+
+	return fmt.Sprintf("清理成功，删除 %d 行", affected), nil
+}
+
+// executeAttachmentCleanup 执行附件清理任务
+func (s *TaskService) executeAttachmentCleanup(appID uint, content map[string]interface{}) (string, error) {
+	tableID, err := taskTableID(appID, content)
+	if err != nil {
+		return "", err
+	}
+	graceHours, _ := content["grace_hours"].(float64)
+
+	tableService := element.NewTableService(model.DB)
+	affected, err := tableService.CleanupAttachments(tableID, int(graceHours))
+	if err != nil {
+		return "", err
+	}
+
+	return fmt.Sprintf("清理成功，删除 %d 个附件", affected), nil
+}
+
+// executeTableArchive 执行数据归档任务
+func (s *TaskService) executeTableArchive(appID uint, content map[string]interface{}) (string, error) {
+	tableID, err := taskTableID(appID, content)
+	if err != nil {
+		return "", err
+	}
+
+	tableService := element.NewTableService(model.DB)
+	archiveLog, err := tableService.ArchiveTableItems(tableID, 0)
+	if err != nil {
+		return "", err
+	}
+
+	return fmt.Sprintf("归档成功，移动 %d 行", archiveLog.Archived), nil
+}
+
+// executeTableTrashPurge 执行数据表回收站清理任务，只清理任务所在应用的数据表
+func (s *TaskService) executeTableTrashPurge(appID uint, content map[string]interface{}) (string, error) {
+	retentionDays, _ := content["retention_days"].(float64)
+
+	tableService := config.NewTableService(model.DB)
+	purged, err := tableService.PurgeTrashTables(appID, int(retentionDays))
+	if err != nil {
+		return "", err
+	}
+
+	return fmt.Sprintf("清理成功，删除 %d 个数据表", purged), nil
+}
+
+// taskTableID 获取任务内容中的数据表ID，数据表必须属于任务所在的应用
+func taskTableID(appID uint, content map[string]interface{}) (uint, error) {
+	tableID, ok := content["table_id"].(float64)
+	if !ok || tableID <= 0 || tableID != float64(uint(tableID)) {
+		return 0, errors.New("无效的数据表ID")
+	}
+	var tableAppID uint
+	err := model.DB.Get(&tableAppID, "SELECT app_id FROM sys_config_tables WHERE id = ?", uint(tableID))
+	if err != nil {
+		if err == sql.ErrNoRows {
+			return 0, errors.New("数据表不存在")
+		}
+		return 0, fmt.Errorf("获取数据表失败: %v", err)
+	}
+	if tableAppID != appID {
+		return 0, errors.New("数据表不属于当前应用")
+	}
+	return uint(tableID), nil
+}
+
 // validateTaskContent 验证任务内容
-func (s *TaskService) validateTaskContent(typ string, content map[string]interface{}) error {
+func (s *TaskService) validateTaskContent(appID uint, typ string, content map[string]interface{}) error {
 	switch typ {
 	case TaskTypeSQL:
 		sql, ok := content["sql"].(string)
@@ -374,6 +473,51 @@ func (s *TaskService) validateTaskContent(typ string, content map[string]interfa
 		}
 		return nil
 
+	case TaskTypeTablePurge:
+		if _, ok := content["table_id"].(float64); !ok {
+			return errors.New("回收站清理任务必须包含table_id字段")
+		}
+		if _, err := taskTableID(appID, content); err != nil {
+			return err
+		}
+		if days, ok := content["retention_days"]; ok {
+			if d, ok := days.(float64); !ok || d < 0 {
+				return errors.New("无效的retention_days字段")
+			}
+		}
+		return nil
+
+	case TaskTypeAttachmentCleanup:
+		if _, ok := content["table_id"].(float64); !ok {
+			return errors.New("附件清理任务必须包含table_id字段")
+		}
+		if _, err := taskTableID(appID, content); err != nil {
+			return err
+		}
+		if hours, ok := content["grace_hours"]; ok {
+			if h, ok := hours.(float64); !ok || h < 0 {
+				return errors.New("无效的grace_hours字段")
+			}
+		}
+		return nil
+
+	case TaskTypeTableArchive:
+		if _, ok := content["table_id"].(float64); !ok {
+			return errors.New("数据归档任务必须包含table_id字段")
+		}
+		if _, err := taskTableID(appID, content); err != nil {
+			return err
+		}
+		return nil
+
+	case TaskTypeTableTrashPurge:
+		if days, ok := content["retention_days"]; ok {
+			if d, ok := days.(float64); !ok || d < 0 {
+				return errors.New("无效的retention_days字段")
+			}
+		}
+		return nil
+
 	default:
 		return errors.New("不支持的任务类型")
 	}
@@ -419,7 +563,7 @@ func (s *TaskService) CreateElementTrigger(appID uint, elementType string, eleme
 	}
 
 	// 验证触发器内容
-	if err := s.validateTaskContent(typ, content); err != nil {
+	if err := s.validateTaskContent(appID, typ, content); err != nil {
 		return err
 	}
 
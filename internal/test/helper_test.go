@@ -15,6 +15,7 @@ import (
 	"github.com/gin-gonic/gin"
 	v1 "github.com/iiwish/lingjian/api/v1"
 	"github.com/iiwish/lingjian/api/v1/config"
+	"github.com/iiwish/lingjian/api/v1/element"
 	"github.com/iiwish/lingjian/internal/middleware"
 	"github.com/iiwish/lingjian/internal/model"
 	"github.com/iiwish/lingjian/pkg/queue"
@@ -71,11 +72,17 @@ func cleanTestData() error {
 		"sys_element_triggers",
 		"sys_task_logs",
 		"sys_scheduled_tasks",
-		"sys_config_menu",
-		"sys_config_form",
-		"sys_config_model",
-		"sys_config_dimension",
-		"sys_config_table",
+		"sys_table_operations",
+		"sys_table_sequences",
+		"sys_attachments",
+		"sys_table_views",
+		"sys_table_archive_logs",
+		"sys_menu_system",
+		"sys_config_menus",
+		"sys_config_forms",
+		"sys_config_models",
+		"sys_config_dimensions",
+		"sys_config_tables",
 		"sys_permissions",
 		"sys_roles",
 		"sys_apps",
@@ -120,18 +127,30 @@ func initTestData() error {
 	// 创建测试应用
 	log.Println("创建测试应用...")
 	_, err = model.DB.Exec(`
-		INSERT INTO sys_apps (name, code, description, status, created_at, updated_at)
-		VALUES (?, ?, ?, ?, ?, ?),
-		(?, ?, ?, ?, ?, ?)
+		INSERT INTO sys_apps (id, name, code, description, status, created_at, updated_at)
+		VALUES (?, ?, ?, ?, ?, ?, ?),
+		(?, ?, ?, ?, ?, ?, ?)
 	`,
-		"测试应用1", "test_app1", "用于测试的应用1", 1, now, now,
-		"测试应用2", "test_app2", "用于测试的应用2", 1, now, now,
+		1, "测试应用1", "test_app1", "用于测试的应用1", 1, now, now,
+		2, "测试应用2", "test_app2", "用于测试的应用2", 1, now, now,
 	)
 	if err != nil {
 		log.Printf("创建测试应用失败: %v", err)
 		return fmt.Errorf("failed to create test apps: %v", err)
 	}
 
+	// 创建应用1的系统菜单维度，创建数据表时会在其中添加菜单项
+	log.Println("创建系统菜单...")
+	_, err = model.DB.Exec(`
+		INSERT INTO sys_config_dimensions (app_id, table_name, display_name, description, dimension_type, status, custom_columns, created_at, updated_at)
+		VALUES (?, ?, ?, ?, ?, ?, ?, ?, ?)
+	`, 1, "sys_menu_system", "系统", "系统菜单", "menu", 1,
+		`[{"name":"source_id","length":30,"comment":"数据源id"},{"name":"menu_type","length":1,"comment":"菜单类型"},{"name":"icon_path","length":100,"comment":"图标路径"}]`, now, now)
+	if err != nil {
+		log.Printf("创建系统菜单失败: %v", err)
+		return fmt.Errorf("failed to create system menu: %v", err)
+	}
+
 	// 创建测试角色
 	log.Println("创建测试角色...")
 	_, err = model.DB.Exec(`
@@ -238,6 +257,9 @@ func setupTestRouter() *gin.Engine {
 				auth.POST("/refresh", v1.RefreshToken)
 			}
 
+			// 附件下载路由（通过签名校验）
+			element.RegisterPublicRoutes(v1Group)
+
 			// 需要认证的路由
 			authorized := v1Group.Group("/")
 			authorized.Use(middleware.AuthMiddleware())
@@ -258,6 +280,8 @@ func setupTestRouter() *gin.Engine {
 					v1.RegisterAppRoutes(rbacProtected)
 					// 注册配置相关路由
 					config.RegisterConfigRoutes(rbacProtected)
+					// 注册元素相关路由
+					element.RegisterElementRoutes(rbacProtected)
 					// 注册任务相关路由
 					v1.RegisterTaskRoutes(rbacProtected)
 				}
@@ -43,6 +43,8 @@ CREATE TABLE IF NOT EXISTS sys_permissions (
     type VARCHAR(20) NOT NULL COMMENT 'menu:菜单 api:接口',
     path VARCHAR(255),
     method VARCHAR(10),
+    dim_id BIGINT UNSIGNED NOT NULL DEFAULT 0 COMMENT '维度ID',
+    item_id BIGINT UNSIGNED NOT NULL DEFAULT 0 COMMENT '维度明细ID 0:全部明细',
     description TEXT,
     status TINYINT NOT NULL DEFAULT 1 COMMENT '0:禁用 1:启用',
     created_at TIMESTAMP NOT NULL DEFAULT CURRENT_TIMESTAMP,
@@ -109,90 +111,195 @@ CREATE TABLE IF NOT EXISTS sys_app_templates (
     FOREIGN KEY (creator_id) REFERENCES sys_users(id) ON DELETE CASCADE
 ) ENGINE=InnoDB DEFAULT CHARSET=utf8mb4 COLLATE=utf8mb4_general_ci;
 
--- 配置相关表
-CREATE TABLE IF NOT EXISTS sys_config_table (
-    id BIGINT UNSIGNED AUTO_INCREMENT PRIMARY KEY,
-    app_id BIGINT UNSIGNED NOT NULL COMMENT '应用ID',
-    table_name VARCHAR(64) NOT NULL COMMENT '表名',
-    display_name VARCHAR(64) NOT NULL COMMENT '显示名称',
-    description TEXT COMMENT '描述',
-    filters JSON COMMENT '筛选配置项，包含列名和类型(time/other)',
-    created_at TIMESTAMP NOT NULL DEFAULT CURRENT_TIMESTAMP,
-    updated_at TIMESTAMP NOT NULL DEFAULT CURRENT_TIMESTAMP ON UPDATE CURRENT_TIMESTAMP,
-    deleted_at TIMESTAMP NULL,
-    UNIQUE KEY uk_app_table (app_id, table_name),
-    FOREIGN KEY (app_id) REFERENCES sys_apps(id) ON DELETE CASCADE
+-- 配置相关表，与internal/model/db/config.sql保持一致
+CREATE TABLE IF NOT EXISTS sys_config_tables (
+    id BIGINT UNSIGNED AUTO_INCREMENT PRIMARY KEY COMMENT '主键ID',
+    app_id BIGINT UNSIGNED NOT NULL DEFAULT 0 COMMENT '应用ID',
+    table_name VARCHAR(64) NOT NULL DEFAULT '' COMMENT '数据库表名，按应用命名为app{应用ID}_{逻辑表名}',
+    logical_name VARCHAR(64) NOT NULL DEFAULT '' COMMENT '逻辑表名，用户看到的表名',
+    display_name VARCHAR(64) NOT NULL DEFAULT '' COMMENT '显示名称',
+    description VARCHAR(200) NOT NULL DEFAULT '' COMMENT '描述',
+    func JSON COMMENT '表功能配置、筛选字段、搜索字段等',
+    field_options JSON COMMENT '字段扩展配置，如校验规则等',
+    status TINYINT NOT NULL DEFAULT 1 COMMENT '状态 0:禁用 1:启用',
+    created_at DATETIME NOT NULL DEFAULT '1901-01-01 00:00:00' COMMENT '创建时间',
+    creator_id BIGINT UNSIGNED NOT NULL DEFAULT 0 COMMENT '创建人ID',
+    updated_at DATETIME NOT NULL DEFAULT CURRENT_TIMESTAMP ON UPDATE CURRENT_TIMESTAMP COMMENT '更新时间',
+    updater_id BIGINT UNSIGNED NOT NULL DEFAULT 0 COMMENT '更新人ID',
+    original_name VARCHAR(64) NOT NULL DEFAULT '' COMMENT '删除前的表名，数据表在回收站中时table_name为回收站表名',
+    deleted_at DATETIME NULL DEFAULT NULL COMMENT '删除时间，不为空表示数据表在回收站中',
+    deleter_id BIGINT UNSIGNED NOT NULL DEFAULT 0 COMMENT '删除人ID',
+    UNIQUE KEY uk_app_table (app_id, table_name) COMMENT '应用ID和表名唯一索引',
+    KEY idx_app_logical (app_id, logical_name) COMMENT '应用ID和逻辑表名索引',
+    KEY idx_deleted_at (deleted_at) COMMENT '删除时间索引'
 ) ENGINE=InnoDB DEFAULT CHARSET=utf8mb4 COMMENT='数据表配置' COLLATE=utf8mb4_general_ci;
 
 -- 维度配置
-CREATE TABLE IF NOT EXISTS sys_config_dimension (
-    id BIGINT UNSIGNED AUTO_INCREMENT PRIMARY KEY,
-    app_id BIGINT UNSIGNED NOT NULL COMMENT '应用ID',
-    table_name VARCHAR(64) NOT NULL COMMENT '维度表名',
-    display_name VARCHAR(64) NOT NULL COMMENT '显示名称',
-    description TEXT COMMENT '描述',
-    created_at TIMESTAMP NOT NULL DEFAULT CURRENT_TIMESTAMP,
-    updated_at TIMESTAMP NOT NULL DEFAULT CURRENT_TIMESTAMP ON UPDATE CURRENT_TIMESTAMP,
-    deleted_at TIMESTAMP NULL,
-    UNIQUE KEY uk_app_dimension (app_id, table_name),
-    FOREIGN KEY (app_id) REFERENCES sys_apps(id) ON DELETE CASCADE
+CREATE TABLE IF NOT EXISTS sys_config_dimensions (
+    id BIGINT UNSIGNED AUTO_INCREMENT PRIMARY KEY COMMENT '主键ID',
+    app_id BIGINT UNSIGNED NOT NULL DEFAULT 0 COMMENT '应用ID',
+    table_name VARCHAR(64) NOT NULL DEFAULT '' COMMENT '数据库中的维度表名，按应用命名为app{应用ID}_{逻辑表名}',
+    logical_name VARCHAR(64) NOT NULL DEFAULT '' COMMENT '逻辑表名，用户看到的维度表名',
+    display_name VARCHAR(64) NOT NULL DEFAULT '' COMMENT '显示名称',
+    description VARCHAR(200) DEFAULT '' COMMENT '描述',
+    dimension_type VARCHAR(20) NOT NULL DEFAULT '' COMMENT '维度类型：general, menu',
+    status TINYINT NOT NULL DEFAULT 1 COMMENT '状态 0:禁用 1:启用',
+    custom_columns JSON COMMENT '自定义字段',
+    created_at DATETIME NOT NULL DEFAULT '1901-01-01 00:00:00' COMMENT '创建时间',
+    creator_id BIGINT UNSIGNED NOT NULL DEFAULT 0 COMMENT '创建人ID',
+    updated_at DATETIME NOT NULL DEFAULT CURRENT_TIMESTAMP ON UPDATE CURRENT_TIMESTAMP COMMENT '更新时间',
+    updater_id BIGINT UNSIGNED NOT NULL DEFAULT 0 COMMENT '更新人ID',
+    UNIQUE KEY uk_app_dimension (app_id, table_name) COMMENT '应用ID和维度表名唯一索引',
+    KEY idx_app_logical (app_id, logical_name) COMMENT '应用ID和逻辑表名索引'
 ) ENGINE=InnoDB DEFAULT CHARSET=utf8mb4 COMMENT='维度配置' COLLATE=utf8mb4_general_ci;
 
 -- 数据模型配置
-CREATE TABLE IF NOT EXISTS sys_config_model (
-    id BIGINT UNSIGNED AUTO_INCREMENT PRIMARY KEY,
-    app_id BIGINT UNSIGNED NOT NULL COMMENT '应用ID',
-    model_name VARCHAR(64) NOT NULL COMMENT '模型名称',
-    display_name VARCHAR(64) NOT NULL COMMENT '显示名称',
-    description TEXT COMMENT '描述',
+CREATE TABLE IF NOT EXISTS sys_config_models (
+    id BIGINT UNSIGNED AUTO_INCREMENT PRIMARY KEY COMMENT '主键ID',
+    app_id BIGINT UNSIGNED NOT NULL DEFAULT 0 COMMENT '应用ID',
+    model_code VARCHAR(64) NOT NULL DEFAULT '' COMMENT '模型名称',
+    display_name VARCHAR(64) NOT NULL DEFAULT '' COMMENT '显示名称',
+    description VARCHAR(200) DEFAULT '' COMMENT '描述',
     configuration JSON COMMENT '模型配置，包含表关系、关联字段等',
-    created_at TIMESTAMP NOT NULL DEFAULT CURRENT_TIMESTAMP,
-    updated_at TIMESTAMP NOT NULL DEFAULT CURRENT_TIMESTAMP ON UPDATE CURRENT_TIMESTAMP,
-    deleted_at TIMESTAMP NULL,
-    UNIQUE KEY uk_app_model (app_id, model_name),
-    FOREIGN KEY (app_id) REFERENCES sys_apps(id) ON DELETE CASCADE
+    status TINYINT NOT NULL DEFAULT 1 COMMENT '状态 0:禁用 1:启用',
+    created_at DATETIME NOT NULL DEFAULT '1901-01-01 00:00:00' COMMENT '创建时间',
+    creator_id BIGINT UNSIGNED NOT NULL DEFAULT 0 COMMENT '创建人ID',
+    updated_at DATETIME NOT NULL DEFAULT CURRENT_TIMESTAMP ON UPDATE CURRENT_TIMESTAMP COMMENT '更新时间',
+    updater_id BIGINT UNSIGNED NOT NULL DEFAULT 0 COMMENT '更新人ID',
+    UNIQUE KEY uk_app_model (app_id, model_code) COMMENT '应用ID和模型名称唯一索引'
 ) ENGINE=InnoDB DEFAULT CHARSET=utf8mb4 COMMENT='数据模型配置' COLLATE=utf8mb4_general_ci;
 
 -- 表单配置
-CREATE TABLE IF NOT EXISTS sys_config_form (
-    id BIGINT UNSIGNED AUTO_INCREMENT PRIMARY KEY,
-    app_id BIGINT UNSIGNED NOT NULL COMMENT '应用ID',
-    model_id BIGINT UNSIGNED NOT NULL COMMENT '关联的数据模型ID',
-    form_name VARCHAR(64) NOT NULL COMMENT '表单名称',
-    display_name VARCHAR(64) NOT NULL COMMENT '显示名称',
-    description TEXT COMMENT '描述',
+CREATE TABLE IF NOT EXISTS sys_config_forms (
+    id BIGINT UNSIGNED AUTO_INCREMENT PRIMARY KEY COMMENT '主键ID',
+    app_id BIGINT UNSIGNED NOT NULL DEFAULT 0 COMMENT '应用ID',
+    model_id BIGINT UNSIGNED NOT NULL DEFAULT 0 COMMENT '关联的数据模型ID',
+    form_name VARCHAR(64) NOT NULL DEFAULT '' COMMENT '表单名称',
+    form_type VARCHAR(20) NOT NULL DEFAULT '' COMMENT '表单类型：form/table/chart/dashboard',
+    display_name VARCHAR(64) NOT NULL DEFAULT '' COMMENT '显示名称',
+    description VARCHAR(200) DEFAULT '' COMMENT '描述',
     configuration JSON COMMENT '表单配置，包含元素类型、布局等',
-    created_at TIMESTAMP NOT NULL DEFAULT CURRENT_TIMESTAMP,
-    updated_at TIMESTAMP NOT NULL DEFAULT CURRENT_TIMESTAMP ON UPDATE CURRENT_TIMESTAMP,
-    deleted_at TIMESTAMP NULL,
-    UNIQUE KEY uk_app_form (app_id, form_name),
-    FOREIGN KEY (app_id) REFERENCES sys_apps(id) ON DELETE CASCADE,
-    FOREIGN KEY (model_id) REFERENCES sys_config_model(id) ON DELETE CASCADE
+    status TINYINT NOT NULL DEFAULT 1 COMMENT '状态 0:禁用 1:启用',
+    created_at DATETIME NOT NULL DEFAULT '1901-01-01 00:00:00' COMMENT '创建时间',
+    creator_id BIGINT UNSIGNED NOT NULL DEFAULT 0 COMMENT '创建人ID',
+    updated_at DATETIME NOT NULL DEFAULT CURRENT_TIMESTAMP ON UPDATE CURRENT_TIMESTAMP COMMENT '更新时间',
+    updater_id BIGINT UNSIGNED NOT NULL DEFAULT 0 COMMENT '更新人ID',
+    -- deleted_at DATETIME NULL DEFAULT '1901-01-01 00:00:00' COMMENT '删除时间',
+    UNIQUE KEY uk_app_form (app_id, form_name) COMMENT '应用ID和表单名称唯一索引'
 ) ENGINE=InnoDB DEFAULT CHARSET=utf8mb4 COMMENT='表单配置' COLLATE=utf8mb4_general_ci;
 
 -- 菜单配置
-CREATE TABLE IF NOT EXISTS sys_config_menu (
-    id BIGINT UNSIGNED AUTO_INCREMENT PRIMARY KEY,
-    app_id BIGINT UNSIGNED NOT NULL COMMENT '应用ID',
-    node_id VARCHAR(32) NOT NULL COMMENT '节点ID',
-    parent_id VARCHAR(32) NOT NULL DEFAULT '0' COMMENT '父节点ID',
-    menu_name VARCHAR(64) NOT NULL COMMENT '菜单名称',
-    menu_code VARCHAR(64) NOT NULL COMMENT '菜单编码',
-    menu_type TINYINT NOT NULL COMMENT '菜单类型：1目录 2菜单 3按钮',
+CREATE TABLE IF NOT EXISTS sys_config_menus (
+    id BIGINT UNSIGNED AUTO_INCREMENT PRIMARY KEY COMMENT '主键ID',
+    app_id BIGINT UNSIGNED NOT NULL DEFAULT 0 COMMENT '应用ID',
+    node_id VARCHAR(64) NOT NULL DEFAULT '' COMMENT '节点ID',
+    parent_id BIGINT UNSIGNED NOT NULL DEFAULT 0 COMMENT '父节点ID',
+    menu_name VARCHAR(64) NOT NULL DEFAULT '' COMMENT '菜单名称',
+    menu_code VARCHAR(64) NOT NULL DEFAULT '' COMMENT '菜单编码',
+    menu_type TINYINT NOT NULL DEFAULT 1 COMMENT '菜单类型：1目录 2table 3dimension 4menu 5model 6form',
     level INT NOT NULL DEFAULT 1 COMMENT '层级',
     sort INT NOT NULL DEFAULT 0 COMMENT '排序',
-    icon VARCHAR(64) COMMENT '图标',
-    path VARCHAR(255) COMMENT '路由路径',
-    component VARCHAR(255) COMMENT '前端组件',
-    permission VARCHAR(255) COMMENT '权限标识',
-    visible TINYINT(1) NOT NULL DEFAULT 1 COMMENT '是否可见',
-    created_at TIMESTAMP NOT NULL DEFAULT CURRENT_TIMESTAMP,
-    updated_at TIMESTAMP NOT NULL DEFAULT CURRENT_TIMESTAMP ON UPDATE CURRENT_TIMESTAMP,
-    deleted_at TIMESTAMP NULL,
-    UNIQUE KEY uk_app_menu (app_id, node_id),
-    FOREIGN KEY (app_id) REFERENCES sys_apps(id) ON DELETE CASCADE
+    icon VARCHAR(64) NOT NULL DEFAULT '' COMMENT '图标',
+    source_id BIGINT UNSIGNED NOT NULL DEFAULT 0 COMMENT '数据源中的ID',
+    status TINYINT NOT NULL DEFAULT 1 COMMENT '是否可见',
+    created_at DATETIME NOT NULL DEFAULT '1901-01-01 00:00:00' COMMENT '创建时间',
+    creator_id BIGINT UNSIGNED NOT NULL DEFAULT 0 COMMENT '创建人ID',
+    updated_at DATETIME NOT NULL DEFAULT CURRENT_TIMESTAMP ON UPDATE CURRENT_TIMESTAMP COMMENT '更新时间',
+    updater_id BIGINT UNSIGNED NOT NULL DEFAULT 0 COMMENT '更新人ID',
+    UNIQUE KEY uk_app_menu (app_id, node_id) COMMENT '应用ID和节点ID唯一索引'
 ) ENGINE=InnoDB DEFAULT CHARSET=utf8mb4 COMMENT='菜单配置' COLLATE=utf8mb4_general_ci;
 
+-- 数据表批量操作记录
+CREATE TABLE IF NOT EXISTS sys_table_operations (
+    id BIGINT UNSIGNED AUTO_INCREMENT PRIMARY KEY COMMENT '主键ID',
+    app_id BIGINT UNSIGNED NOT NULL DEFAULT 0 COMMENT '应用ID',
+    table_id BIGINT UNSIGNED NOT NULL DEFAULT 0 COMMENT '数据表ID',
+    operation VARCHAR(20) NOT NULL DEFAULT '' COMMENT '操作类型：bulk_update, bulk_delete, clone',
+    query_condition JSON COMMENT '过滤条件',
+    patch JSON COMMENT '更新的字段值，克隆时为源数据表和复制参数',
+    affected INT NOT NULL DEFAULT 0 COMMENT '影响的记录数',
+    affected_keys JSON COMMENT '影响的记录主键',
+    operator_id BIGINT UNSIGNED NOT NULL DEFAULT 0 COMMENT '操作人ID',
+    created_at DATETIME NOT NULL DEFAULT CURRENT_TIMESTAMP COMMENT '操作时间',
+    KEY idx_table_created (table_id, created_at) COMMENT '数据表ID和操作时间索引'
+) ENGINE=InnoDB DEFAULT CHARSET=utf8mb4 COMMENT='数据表批量操作记录' COLLATE=utf8mb4_general_ci;
+
+-- 数据表编号字段计数器
+CREATE TABLE IF NOT EXISTS sys_table_sequences (
+    table_id BIGINT UNSIGNED NOT NULL COMMENT '数据表ID',
+    field_name VARCHAR(64) NOT NULL COMMENT '编号字段名',
+    period VARCHAR(8) NOT NULL DEFAULT '' COMMENT '计数周期，如2026、202610，不重置时为空',
+    value BIGINT UNSIGNED NOT NULL DEFAULT 0 COMMENT '已分配的最大计数',
+    updated_at DATETIME NOT NULL DEFAULT CURRENT_TIMESTAMP ON UPDATE CURRENT_TIMESTAMP COMMENT '更新时间',
+    PRIMARY KEY (table_id, field_name, period)
+) ENGINE=InnoDB DEFAULT CHARSET=utf8mb4 COMMENT='数据表编号字段计数器' COLLATE=utf8mb4_general_ci;
+
+-- 数据表附件
+CREATE TABLE IF NOT EXISTS sys_attachments (
+    id BIGINT UNSIGNED AUTO_INCREMENT PRIMARY KEY COMMENT '主键ID',
+    app_id BIGINT UNSIGNED NOT NULL DEFAULT 0 COMMENT '应用ID',
+    table_id BIGINT UNSIGNED NOT NULL DEFAULT 0 COMMENT '数据表ID',
+    field_name VARCHAR(64) NOT NULL DEFAULT '' COMMENT '附件字段名',
+    storage_key VARCHAR(255) NOT NULL DEFAULT '' COMMENT '存储路径',
+    file_name VARCHAR(255) NOT NULL DEFAULT '' COMMENT '原始文件名',
+    mime_type VARCHAR(100) NOT NULL DEFAULT '' COMMENT '文件类型',
+    size BIGINT NOT NULL DEFAULT 0 COMMENT '文件大小',
+    creator_id BIGINT UNSIGNED NOT NULL DEFAULT 0 COMMENT '上传人ID',
+    created_at DATETIME NOT NULL DEFAULT CURRENT_TIMESTAMP COMMENT '上传时间',
+    KEY idx_table_field (table_id, field_name, created_at) COMMENT '数据表ID、字段名和上传时间索引'
+) ENGINE=InnoDB DEFAULT CHARSET=utf8mb4 COMMENT='数据表附件' COLLATE=utf8mb4_general_ci;
+
+-- 数据表视图
+CREATE TABLE IF NOT EXISTS sys_table_views (
+    id BIGINT UNSIGNED AUTO_INCREMENT PRIMARY KEY COMMENT '主键ID',
+    app_id BIGINT UNSIGNED NOT NULL DEFAULT 0 COMMENT '应用ID',
+    table_id BIGINT UNSIGNED NOT NULL DEFAULT 0 COMMENT '数据表ID',
+    name VARCHAR(64) NOT NULL DEFAULT '' COMMENT '视图名称',
+    shared TINYINT NOT NULL DEFAULT 0 COMMENT '是否共享 0:仅创建人可见 1:共享',
+    roles JSON COMMENT '共享的角色编码，为空时共享给应用内所有用户',
+    config JSON COMMENT '查询条件、排序、每页数量、隐藏字段和字段顺序',
+    created_at DATETIME NOT NULL DEFAULT '1901-01-01 00:00:00' COMMENT '创建时间',
+    creator_id BIGINT UNSIGNED NOT NULL DEFAULT 0 COMMENT '创建人ID',
+    updated_at DATETIME NOT NULL DEFAULT CURRENT_TIMESTAMP ON UPDATE CURRENT_TIMESTAMP COMMENT '更新时间',
+    updater_id BIGINT UNSIGNED NOT NULL DEFAULT 0 COMMENT '更新人ID',
+    UNIQUE KEY uk_table_creator_name (table_id, creator_id, name) COMMENT '数据表ID、创建人和视图名称唯一索引'
+) ENGINE=InnoDB DEFAULT CHARSET=utf8mb4 COMMENT='数据表视图' COLLATE=utf8mb4_general_ci;
+
+-- 数据表归档记录
+CREATE TABLE IF NOT EXISTS sys_table_archive_logs (
+    id BIGINT UNSIGNED AUTO_INCREMENT PRIMARY KEY COMMENT '主键ID',
+    table_id BIGINT UNSIGNED NOT NULL DEFAULT 0 COMMENT '数据表ID',
+    status TINYINT NOT NULL DEFAULT 0 COMMENT '执行状态：0失败/1成功/2执行中',
+    archived BIGINT NOT NULL DEFAULT 0 COMMENT '归档的记录数',
+    cutoff DATETIME NOT NULL DEFAULT CURRENT_TIMESTAMP COMMENT '归档截止时间，早于该时间的记录归档',
+    error TEXT COMMENT '错误信息',
+    operator_id BIGINT UNSIGNED NOT NULL DEFAULT 0 COMMENT '操作人ID，定时任务执行时为0',
+    start_time DATETIME NOT NULL DEFAULT CURRENT_TIMESTAMP COMMENT '开始时间',
+    end_time DATETIME NOT NULL DEFAULT CURRENT_TIMESTAMP COMMENT '结束时间',
+    KEY idx_table_start (table_id, start_time) COMMENT '数据表ID和开始时间索引'
+) ENGINE=InnoDB DEFAULT CHARSET=utf8mb4 COMMENT='数据表归档记录' COLLATE=utf8mb4_general_ci;
+
+-- 系统菜单维度表，对应sys_config_dimensions中table_name为sys_menu_system的维度
+CREATE TABLE IF NOT EXISTS sys_menu_system (
+    id BIGINT UNSIGNED AUTO_INCREMENT PRIMARY KEY COMMENT '主键ID',
+    node_id VARCHAR(100) NOT NULL DEFAULT '' COMMENT '节点ID',
+    parent_id BIGINT UNSIGNED NOT NULL DEFAULT 0 COMMENT '父节点ID',
+    name VARCHAR(100) NOT NULL DEFAULT '' COMMENT '名称',
+    code VARCHAR(100) NOT NULL DEFAULT '' COMMENT '编码',
+    description VARCHAR(200) NOT NULL DEFAULT '' COMMENT '描述',
+    level INT NOT NULL DEFAULT 0 COMMENT '层级',
+    sort INT NOT NULL DEFAULT 0 COMMENT '排序',
+    status TINYINT NOT NULL DEFAULT 1 COMMENT '状态',
+    source_id VARCHAR(30) NOT NULL DEFAULT '' COMMENT '数据源id',
+    menu_type VARCHAR(1) NOT NULL DEFAULT '' COMMENT '菜单类型',
+    icon_path VARCHAR(100) NOT NULL DEFAULT '' COMMENT '图标路径',
+    created_at DATETIME NOT NULL DEFAULT '1901-01-01 00:00:00' COMMENT '创建时间',
+    creator_id INT NOT NULL DEFAULT 0 COMMENT '创建者ID',
+    updated_at DATETIME NOT NULL DEFAULT CURRENT_TIMESTAMP ON UPDATE CURRENT_TIMESTAMP COMMENT '更新时间',
+    updater_id INT NOT NULL DEFAULT 0 COMMENT '更新者ID',
+    UNIQUE KEY uk_code (code)
+) ENGINE=InnoDB DEFAULT CHARSET=utf8mb4 COMMENT='系统菜单' COLLATE=utf8mb4_general_ci;
+
 -- 定时任务表
 CREATE TABLE IF NOT EXISTS sys_scheduled_tasks (
     id BIGINT UNSIGNED AUTO_INCREMENT PRIMARY KEY,
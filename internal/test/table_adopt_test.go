@@ -0,0 +1,57 @@
+package test
+
+import (
+	"testing"
+
+	"github.com/iiwish/lingjian/internal/model"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestTableAdopt(t *testing.T) {
+	helper := NewTestHelper(t)
+
+	_, err := model.DB.Exec("DROP TABLE IF EXISTS legacy_orders")
+	assert.NoError(t, err)
+	_, err = model.DB.Exec(`
+		CREATE TABLE legacy_orders (
+			id BIGINT UNSIGNED AUTO_INCREMENT PRIMARY KEY,
+			order_no VARCHAR(32) NOT NULL,
+			token VARBINARY(16),
+			UNIQUE KEY uk_order_no (order_no)
+		)
+	`)
+	assert.NoError(t, err)
+	_, err = model.DB.Exec("INSERT INTO legacy_orders (order_no) VALUES ('A001'), ('A002')")
+	assert.NoError(t, err)
+
+	var tableID uint
+	t.Run("纳管已有数据表", func(t *testing.T) {
+		body := map[string]interface{}{"table_name": "legacy_orders", "display_name": "历史订单", "func": `{"soft_delete": true}`}
+		w := helper.MakeRequest(t, "POST", "/api/v1/config/tables/adopt", body, appHeader)
+		data := helper.AssertSuccess(t, w)["data"].(map[string]interface{})
+		tableID = uint(data["id"].(float64))
+
+		assert.Len(t, data["fields"], 3)
+		unsupported := data["unsupported"].([]interface{})
+		if assert.Len(t, unsupported, 1) {
+			assert.Equal(t, "token", unsupported[0].(map[string]interface{})["name"])
+		}
+	})
+
+	t.Run("不能重复纳管", func(t *testing.T) {
+		body := map[string]interface{}{"table_name": "legacy_orders"}
+		w := helper.MakeRequest(t, "POST", "/api/v1/config/tables/adopt", body, appHeader)
+		helper.AssertError(t, w, 500)
+	})
+
+	t.Run("系统表不能纳管", func(t *testing.T) {
+		body := map[string]interface{}{"table_name": "sys_users"}
+		w := helper.MakeRequest(t, "POST", "/api/v1/config/tables/adopt", body, appHeader)
+		helper.AssertError(t, w, 400)
+	})
+
+	t.Run("查询纳管的数据表", func(t *testing.T) {
+		items := queryItems(t, helper, tableID, map[string]interface{}{})
+		assert.Len(t, items, 2)
+	})
+}
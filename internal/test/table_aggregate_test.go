@@ -0,0 +1,76 @@
+package test
+
+import (
+	"net/http"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestTableAggregateQuery(t *testing.T) {
+	helper := NewTestHelper(t)
+
+	tableID := newTestTable("test_aggregate", "聚合查询测试表", []map[string]interface{}{
+		{"name": "category", "column_type": "varchar(20)"},
+		{"name": "amount", "column_type": "int"},
+		{"name": "order_date", "column_type": "date"},
+	}).create(t, helper)
+	basePath := tablePath(tableID)
+
+	items := []map[string]interface{}{
+		{"category": "A", "amount": 10, "order_date": "2024-01-05"},
+		{"category": "A", "amount": 20, "order_date": "2024-02-10"},
+		{"category": "B", "amount": 5, "order_date": "2024-04-01"},
+	}
+	w := helper.MakeRequest(t, "POST", basePath, items, appHeader)
+	helper.AssertSuccess(t, w)
+
+	t.Run("分组聚合", func(t *testing.T) {
+		rows := queryItems(t, helper, tableID, map[string]interface{}{
+			"page": 1, "page_size": 10,
+			"query": map[string]interface{}{
+				"select": []map[string]interface{}{
+					{"field": "category"},
+					{"field": "amount", "func": "sum", "alias": "total"},
+					{"func": "count"},
+				},
+				"having":   map[string]interface{}{"logic": "AND", "conditions": []map[string]interface{}{{"field": "total", "operator": "gt", "value": 10}}},
+				"order_by": []map[string]interface{}{{"field": "total", "desc": true}},
+			},
+		})
+		if assert.Len(t, rows, 1) {
+			row := rows[0].(map[string]interface{})
+			assert.Equal(t, "A", row["category"])
+			assert.Equal(t, "30", row["total"])
+			assert.Equal(t, float64(2), row["count"])
+		}
+	})
+
+	t.Run("按季度分桶", func(t *testing.T) {
+		rows := queryItems(t, helper, tableID, map[string]interface{}{
+			"page": 1, "page_size": 10,
+			"query": map[string]interface{}{
+				"select": []map[string]interface{}{
+					{"field": "order_date", "bucket": "quarter", "alias": "quarter"},
+					{"field": "amount", "func": "sum", "alias": "total"},
+				},
+				"order_by": []map[string]interface{}{{"field": "quarter"}},
+			},
+		})
+		if assert.Len(t, rows, 2) {
+			assert.Equal(t, "2024-Q1", rows[0].(map[string]interface{})["quarter"])
+			assert.Equal(t, "2024-Q2", rows[1].(map[string]interface{})["quarter"])
+		}
+	})
+
+	t.Run("非法聚合函数", func(t *testing.T) {
+		body := map[string]interface{}{
+			"page": 1, "page_size": 10,
+			"query": map[string]interface{}{
+				"select": []map[string]interface{}{{"field": "amount", "func": "median"}},
+			},
+		}
+		w := helper.MakeRequest(t, "POST", basePath+"/query", body, appHeader)
+		helper.AssertError(t, w, http.StatusBadRequest)
+	})
+}
@@ -0,0 +1,117 @@
+package test
+
+import (
+	"testing"
+	"time"
+
+	"github.com/iiwish/lingjian/internal/model"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestTableArchive(t *testing.T) {
+	helper := NewTestHelper(t)
+
+	tableID := newTestTable("test_archival", "归档测试表", []map[string]interface{}{
+		{"name": "code", "type": "text", "length": 50},
+		{"name": "occurred_at", "type": "datetime"},
+	}).withFunc(`{"archive": {"column": "occurred_at", "after_days": 30, "batch_size": 2}}`).withIndexes([]map[string]interface{}{
+		{"name": "uk_code", "type": "UNIQUE", "fields": []string{"code"}},
+	}).create(t, helper)
+	basePath := tablePath(tableID)
+	old := time.Now().AddDate(0, 0, -60).Format("2006-01-02 15:04:05")
+	recent := time.Now().Format("2006-01-02 15:04:05")
+	items := []map[string]interface{}{
+		{"code": "a", "occurred_at": old}, {"code": "b", "occurred_at": old},
+		{"code": "c", "occurred_at": old}, {"code": "d", "occurred_at": recent},
+	}
+	helper.AssertSuccess(t, helper.MakeRequest(t, "POST", basePath, items, appHeader))
+
+	t.Run("归档过期记录", func(t *testing.T) {
+		w := helper.MakeRequest(t, "POST", basePath+"/archive", nil, appHeader)
+		data := helper.AssertSuccess(t, w)["data"].(map[string]interface{})
+		assert.Equal(t, float64(3), data["archived"])
+		assert.Equal(t, float64(model.ArchiveStatusSuccess), data["status"])
+
+		var count int
+		err := model.DB.Get(&count, "SELECT COUNT(*) FROM app1_test_archival")
+		assert.NoError(t, err)
+		assert.Equal(t, 1, count)
+		err = model.DB.Get(&count, "SELECT COUNT(*) FROM app1_test_archival_archive")
+		assert.NoError(t, err)
+		assert.Equal(t, 3, count)
+	})
+
+	t.Run("查询包含归档记录", func(t *testing.T) {
+		body := map[string]interface{}{"page": 1, "page_size": 10, "count_mode": "exact"}
+		w := helper.MakeRequest(t, "POST", basePath+"/query", body, appHeader)
+		data := helper.AssertSuccess(t, w)["data"].(map[string]interface{})
+		assert.Equal(t, float64(1), data["total"])
+
+		body["include_archived"] = true
+		w = helper.MakeRequest(t, "POST", basePath+"/query", body, appHeader)
+		data = helper.AssertSuccess(t, w)["data"].(map[string]interface{})
+		assert.Equal(t, float64(4), data["total"])
+	})
+
+	t.Run("执行计划包含归档表", func(t *testing.T) {
+		body := map[string]interface{}{"include_archived": true}
+		w := helper.MakeRequest(t, "POST", basePath+"/explain", body, appHeader)
+		data := helper.AssertSuccess(t, w)["data"].(map[string]interface{})
+		assert.Contains(t, data["sql"], "UNION ALL")
+		assert.Contains(t, data["sql"], "`app1_test_archival_archive`")
+
+		w = helper.MakeRequest(t, "POST", basePath+"/explain", map[string]interface{}{}, appHeader)
+		data = helper.AssertSuccess(t, w)["data"].(map[string]interface{})
+		assert.NotContains(t, data["sql"], "app1_test_archival_archive")
+	})
+
+	t.Run("归档后唯一值可以再次使用", func(t *testing.T) {
+		w := helper.MakeRequest(t, "POST", basePath, []map[string]interface{}{{"code": "a", "occurred_at": old}}, appHeader)
+		helper.AssertSuccess(t, w)
+		w = helper.MakeRequest(t, "POST", basePath+"/archive", nil, appHeader)
+		data := helper.AssertSuccess(t, w)["data"].(map[string]interface{})
+		assert.Equal(t, float64(1), data["archived"])
+	})
+
+	t.Run("获取归档记录", func(t *testing.T) {
+		w := helper.MakeRequest(t, "GET", basePath+"/archive/logs", nil, appHeader)
+		data := helper.AssertSuccess(t, w)["data"].(map[string]interface{})
+		assert.Equal(t, float64(2), data["total"])
+	})
+
+	t.Run("软删除的记录不归档", func(t *testing.T) {
+		softID := newTestTable("test_archive_soft", "软删除归档", []map[string]interface{}{
+			{"name": "occurred_at", "type": "datetime"},
+		}).withFunc(`{"soft_delete": true, "archive": {"column": "occurred_at", "after_days": 30}}`).create(t, helper)
+		softPath := tablePath(softID)
+		items := []map[string]interface{}{{"occurred_at": old}, {"occurred_at": old}}
+		helper.AssertSuccess(t, helper.MakeRequest(t, "POST", softPath, items, appHeader))
+		_, err := model.DB.Exec("UPDATE app1_test_archive_soft SET deleted_at = NOW() ORDER BY id LIMIT 1")
+		assert.NoError(t, err)
+
+		w := helper.MakeRequest(t, "POST", softPath+"/archive", nil, appHeader)
+		data := helper.AssertSuccess(t, w)["data"].(map[string]interface{})
+		assert.Equal(t, float64(1), data["archived"])
+
+		var count int
+		err = model.DB.Get(&count, "SELECT COUNT(*) FROM app1_test_archive_soft WHERE deleted_at IS NOT NULL")
+		assert.NoError(t, err)
+		assert.Equal(t, 1, count)
+	})
+
+	t.Run("更新时校验归档字段", func(t *testing.T) {
+		body := map[string]interface{}{
+			"table_name":   "test_archival",
+			"display_name": "归档测试表",
+			"func":         `{"archive": {"column": "missing_at", "after_days": 30}}`,
+		}
+		w := helper.MakeRequest(t, "PUT", configTablePath(tableID), body, appHeader)
+		helper.AssertError(t, w, 500)
+	})
+
+	t.Run("未配置归档策略", func(t *testing.T) {
+		otherID := newTestTable("test_archive_none", "未配置归档", []map[string]interface{}{}).create(t, helper)
+		w := helper.MakeRequest(t, "POST", tablePath(otherID)+"/archive", nil, appHeader)
+		helper.AssertError(t, w, 400)
+	})
+}
@@ -0,0 +1,143 @@
+package test
+
+import (
+	"bytes"
+	"mime/multipart"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"strconv"
+	"testing"
+
+	"github.com/iiwish/lingjian/internal/model"
+	"github.com/iiwish/lingjian/internal/service/element"
+	"github.com/iiwish/lingjian/pkg/storage"
+	"github.com/spf13/viper"
+	"github.com/stretchr/testify/assert"
+)
+
+// uploadAttachment 上传附件到数据表的附件字段
+func uploadAttachment(t *testing.T, helper *TestHelper, tableID uint, field, fileName string, content []byte) *httptest.ResponseRecorder {
+	var body bytes.Buffer
+	writer := multipart.NewWriter(&body)
+	assert.NoError(t, writer.WriteField("field", field))
+	part, err := writer.CreateFormFile("file", fileName)
+	assert.NoError(t, err)
+	part.Write(content)
+	assert.NoError(t, writer.Close())
+
+	path := tablePath(tableID) + "/attachments"
+	req := httptest.NewRequest("POST", path, &body)
+	req.Header.Set("Content-Type", writer.FormDataContentType())
+	req.Header.Set("Authorization", "Bearer "+helper.Token)
+	req.Header.Set("App-ID", appHeader["App-ID"])
+	w := httptest.NewRecorder()
+	helper.Router.ServeHTTP(w, req)
+	return w
+}
+
+func TestTableAttachments(t *testing.T) {
+	helper := NewTestHelper(t)
+	storage.Default = storage.NewLocalStorage(t.TempDir())
+	viper.Set("storage.sign_secret", "test_sign_secret")
+
+	tableID := newTestTable("test_attachment", "附件字段测试表", []map[string]interface{}{
+		{"name": "files", "type": "attachment", "attachment": map[string]interface{}{
+			"max_size": 1024, "max_files": 2, "mime_types": []string{"text/plain"},
+		}},
+	}).create(t, helper)
+	basePath := tablePath(tableID)
+	content := []byte("hello attachment")
+
+	var attachmentID float64
+	var downloadURL string
+	t.Run("上传附件", func(t *testing.T) {
+		w := uploadAttachment(t, helper, tableID, "files", "hello.txt", content)
+		resp := helper.AssertSuccess(t, w)
+		data := resp["data"].(map[string]interface{})
+		assert.Equal(t, "hello.txt", data["file_name"])
+		assert.Equal(t, "text/plain; charset=utf-8", data["mime_type"])
+		attachmentID = data["id"].(float64)
+		downloadURL = data["url"].(string)
+	})
+
+	t.Run("文件类型和大小受限", func(t *testing.T) {
+		png := []byte("\x89PNG\r\n\x1a\n0000")
+		helper.AssertError(t, uploadAttachment(t, helper, tableID, "files", "a.png", png), 400)
+		large := bytes.Repeat([]byte("a"), 2048)
+		helper.AssertError(t, uploadAttachment(t, helper, tableID, "files", "large.txt", large), 400)
+	})
+
+	t.Run("下载附件", func(t *testing.T) {
+		w := helper.MakeRequest(t, "GET", downloadURL, nil)
+		assert.Equal(t, http.StatusOK, w.Code)
+		assert.Equal(t, content, w.Body.Bytes())
+		assert.Equal(t, `attachment; filename="hello.txt"; filename*=UTF-8''hello.txt`, w.Header().Get("Content-Disposition"))
+
+		u, _ := url.Parse(downloadURL)
+		q := u.Query()
+		q.Set("signature", "invalid")
+		u.RawQuery = q.Encode()
+		w = helper.MakeRequest(t, "GET", u.String(), nil)
+		assert.Equal(t, http.StatusForbidden, w.Code)
+	})
+
+	t.Run("记录引用附件", func(t *testing.T) {
+		items := []map[string]interface{}{{"files": []interface{}{attachmentID}}}
+		w := helper.MakeRequest(t, "POST", basePath, items, appHeader)
+		helper.AssertSuccess(t, w)
+
+		items = []map[string]interface{}{{"files": []interface{}{attachmentID + 1000}}}
+		w = helper.MakeRequest(t, "POST", basePath, items, appHeader)
+		helper.AssertError(t, w, 400)
+	})
+
+	t.Run("获取被记录引用的附件", func(t *testing.T) {
+		w := helper.MakeRequest(t, "GET", basePath+"/attachments/"+strconv.Itoa(int(attachmentID)), nil, appHeader)
+		data := helper.AssertSuccess(t, w)["data"].(map[string]interface{})
+		assert.Equal(t, "hello.txt", data["file_name"])
+
+		w = uploadAttachment(t, helper, tableID, "files", "unsaved.txt", content)
+		unsavedID := helper.AssertSuccess(t, w)["data"].(map[string]interface{})["id"].(float64)
+		w = helper.MakeRequest(t, "GET", basePath+"/attachments/"+strconv.Itoa(int(unsavedID)), nil, appHeader)
+		helper.AssertError(t, w, 404)
+		_, err := model.DB.Exec("DELETE FROM sys_attachments WHERE id = ?", unsavedID)
+		assert.NoError(t, err)
+	})
+
+	t.Run("清理未引用的附件", func(t *testing.T) {
+		w := uploadAttachment(t, helper, tableID, "files", "orphan.txt", content)
+		resp := helper.AssertSuccess(t, w)
+		orphanID := resp["data"].(map[string]interface{})["id"].(float64)
+		_, err := model.DB.Exec("UPDATE sys_attachments SET created_at = NOW() - INTERVAL 2 DAY WHERE table_id = ?", tableID)
+		assert.NoError(t, err)
+
+		deleted, err := element.NewTableService(model.DB).CleanupAttachments(tableID, 24)
+		assert.NoError(t, err)
+		assert.Equal(t, int64(1), deleted)
+
+		var count int
+		err = model.DB.Get(&count, "SELECT COUNT(*) FROM sys_attachments WHERE id = ?", orphanID)
+		assert.NoError(t, err)
+		assert.Equal(t, 0, count)
+	})
+
+	t.Run("归档记录引用的附件不清理", func(t *testing.T) {
+		_, err := model.DB.Exec("CREATE TABLE app1_test_attachment_archive LIKE app1_test_attachment")
+		assert.NoError(t, err)
+		defer model.DB.Exec("DROP TABLE IF EXISTS app1_test_attachment_archive")
+		_, err = model.DB.Exec("INSERT INTO app1_test_attachment_archive SELECT * FROM app1_test_attachment")
+		assert.NoError(t, err)
+		_, err = model.DB.Exec("DELETE FROM app1_test_attachment")
+		assert.NoError(t, err)
+
+		deleted, err := element.NewTableService(model.DB).CleanupAttachments(tableID, 24)
+		assert.NoError(t, err)
+		assert.Equal(t, int64(0), deleted)
+
+		var count int
+		err = model.DB.Get(&count, "SELECT COUNT(*) FROM sys_attachments WHERE id = ?", attachmentID)
+		assert.NoError(t, err)
+		assert.Equal(t, 1, count)
+	})
+}
@@ -0,0 +1,86 @@
+package test
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestTableBulkOperations(t *testing.T) {
+	helper := NewTestHelper(t)
+
+	tableID := newTestTable("test_bulk", "批量操作测试表", []map[string]interface{}{
+		{"name": "status", "column_type": "int", "not_null": true},
+		{"name": "category", "column_type": "varchar(20)"},
+	}).withFunc(`{"soft_delete": true, "bulk_limit": 5}`).create(t, helper)
+	basePath := tablePath(tableID)
+
+	items := make([]map[string]interface{}, 0, 8)
+	for i := 0; i < 8; i++ {
+		category := "a"
+		if i >= 3 {
+			category = "b"
+		}
+		items = append(items, map[string]interface{}{"status": 0, "category": category})
+	}
+	w := helper.MakeRequest(t, "POST", basePath, items, appHeader)
+	helper.AssertSuccess(t, w)
+
+	conditionOf := func(category string) map[string]interface{} {
+		return map[string]interface{}{
+			"root": map[string]interface{}{
+				"conditions": []map[string]interface{}{{"field": "category", "operator": "eq", "value": category}},
+			},
+		}
+	}
+
+	t.Run("预览", func(t *testing.T) {
+		body := map[string]interface{}{"query": conditionOf("b"), "patch": map[string]interface{}{"status": 1}}
+		w := helper.MakeRequest(t, "POST", basePath+"/bulk/preview", body, appHeader)
+		data := helper.AssertSuccess(t, w)["data"].(map[string]interface{})
+		assert.Equal(t, float64(5), data["matched"])
+		assert.Equal(t, float64(5), data["limit"])
+	})
+
+	t.Run("超出上限不执行", func(t *testing.T) {
+		body := map[string]interface{}{"query": conditionOf("b"), "patch": map[string]interface{}{"status": 1}, "max_affected": 3}
+		w := helper.MakeRequest(t, "PUT", basePath+"/bulk", body, appHeader)
+		resp := helper.AssertError(t, w, 400)
+		assert.Equal(t, float64(5), resp["data"].(map[string]interface{})["matched"])
+	})
+
+	t.Run("缺少条件", func(t *testing.T) {
+		body := map[string]interface{}{"patch": map[string]interface{}{"status": 1}}
+		w := helper.MakeRequest(t, "PUT", basePath+"/bulk", body, appHeader)
+		helper.AssertError(t, w, 400)
+	})
+
+	t.Run("批量修改", func(t *testing.T) {
+		body := map[string]interface{}{"query": conditionOf("b"), "patch": map[string]interface{}{"status": 1}}
+		w := helper.MakeRequest(t, "PUT", basePath+"/bulk", body, appHeader)
+		data := helper.AssertSuccess(t, w)["data"].(map[string]interface{})
+		assert.Equal(t, float64(5), data["matched"])
+		assert.Equal(t, float64(5), data["affected"])
+	})
+
+	t.Run("批量删除", func(t *testing.T) {
+		body := map[string]interface{}{"query": conditionOf("a")}
+		w := helper.MakeRequest(t, "DELETE", basePath+"/bulk", body, appHeader)
+		data := helper.AssertSuccess(t, w)["data"].(map[string]interface{})
+		assert.Equal(t, float64(3), data["affected"])
+
+		remaining := queryItems(t, helper, tableID, map[string]interface{}{})
+		assert.Len(t, remaining, 5)
+	})
+
+	t.Run("操作记录", func(t *testing.T) {
+		w := helper.MakeRequest(t, "GET", basePath+"/operations", nil, appHeader)
+		data := helper.AssertSuccess(t, w)["data"].(map[string]interface{})
+		operations := data["items"].([]interface{})
+		if assert.Len(t, operations, 2) {
+			latest := operations[0].(map[string]interface{})
+			assert.Equal(t, "bulk_delete", latest["operation"])
+			assert.JSONEq(t, "[1,2,3]", latest["affected_keys"].(string))
+		}
+	})
+}
@@ -0,0 +1,40 @@
+package test
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestTableQueryCache(t *testing.T) {
+	helper := NewTestHelper(t)
+
+	tableID := newTestTable("test_query_cache", "查询缓存测试表", []map[string]interface{}{
+		{"name": "name", "column_type": "varchar(50)"},
+	}).withFunc(`{"cache_ttl": 60}`).create(t, helper)
+	basePath := tablePath(tableID)
+
+	t.Run("未连接Redis时直接查询", func(t *testing.T) {
+		w := helper.MakeRequest(t, "POST", basePath, []map[string]interface{}{{"name": "张三"}}, appHeader)
+		helper.AssertSuccess(t, w)
+		assert.Len(t, queryItems(t, helper, tableID, map[string]interface{}{}), 1)
+
+		w = helper.MakeRequest(t, "GET", basePath+"/cache", nil, appHeader)
+		stats := helper.AssertSuccess(t, w)["data"].(map[string]interface{})
+		assert.Equal(t, false, stats["enabled"])
+		assert.Equal(t, float64(60), stats["ttl"])
+	})
+
+	t.Run("非法的缓存有效期", func(t *testing.T) {
+		body := map[string]interface{}{
+			"table_name":   "test_query_cache_bad",
+			"display_name": "非法缓存配置",
+			"func":         `{"cache_ttl": -1}`,
+			"fields": []map[string]interface{}{
+				{"name": "id", "column_type": "bigint", "primary_key": true},
+			},
+		}
+		w := helper.MakeRequest(t, "POST", "/api/v1/config/tables", body, appHeader)
+		helper.AssertError(t, w, 500)
+	})
+}
@@ -0,0 +1,100 @@
+package test
+
+import (
+	"testing"
+
+	"github.com/iiwish/lingjian/internal/model"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestTableClone(t *testing.T) {
+	helper := NewTestHelper(t)
+
+	tableID := newTestTable("test_clone_src", "克隆源表", []map[string]interface{}{
+		{"name": "name", "type": "text", "length": 50},
+		{"name": "status", "column_type": "int"},
+	}).withFunc(`{"soft_delete": true}`).withIndexes([]map[string]interface{}{
+		{"name": "idx_status", "type": "INDEX", "fields": []string{"status"}},
+	}).create(t, helper)
+	basePath := tablePath(tableID)
+	items := []map[string]interface{}{
+		{"name": "a", "status": 1}, {"name": "b", "status": 1}, {"name": "c", "status": 0},
+	}
+	helper.AssertSuccess(t, helper.MakeRequest(t, "POST", basePath, items, appHeader))
+	clonePath := configTablePath(tableID) + "/clone"
+
+	t.Run("克隆结构和符合条件的记录", func(t *testing.T) {
+		body := map[string]interface{}{
+			"table_name": "test_clone_dst",
+			"copy_data":  true,
+			"query": map[string]interface{}{
+				"root": map[string]interface{}{
+					"logic":      "AND",
+					"conditions": []map[string]interface{}{{"field": "status", "operator": "eq", "value": 1}},
+				},
+			},
+		}
+		w := helper.MakeRequest(t, "POST", clonePath, body, appHeader)
+		data := helper.AssertSuccess(t, w)["data"].(map[string]interface{})
+		assert.Equal(t, float64(2), data["copied"])
+		newID := uint(data["id"].(float64))
+
+		w = helper.MakeRequest(t, "GET", configTablePath(newID), nil, appHeader)
+		table := helper.AssertSuccess(t, w)["data"].(map[string]interface{})
+		assert.Equal(t, "克隆源表", table["display_name"])
+		assert.Contains(t, table["func"], "soft_delete")
+
+		var count int
+		err := model.DB.Get(&count, "SELECT COUNT(*) FROM app1_test_clone_dst WHERE deleted_at IS NULL")
+		assert.NoError(t, err)
+		assert.Equal(t, 2, count)
+
+		var operation string
+		err = model.DB.Get(&operation, "SELECT operation FROM sys_table_operations WHERE table_id = ?", newID)
+		assert.NoError(t, err)
+		assert.Equal(t, "clone", operation)
+	})
+
+	t.Run("不复制记录", func(t *testing.T) {
+		body := map[string]interface{}{"table_name": "test_clone_empty"}
+		w := helper.MakeRequest(t, "POST", clonePath, body, appHeader)
+		data := helper.AssertSuccess(t, w)["data"].(map[string]interface{})
+		assert.Equal(t, float64(0), data["copied"])
+	})
+
+	t.Run("不复制用户不能原样读取的字段", func(t *testing.T) {
+		srcID := newTestTable("test_clone_policy", "克隆字段权限", []map[string]interface{}{
+			{"name": "name", "column_type": "varchar(50)"},
+			{"name": "phone", "column_type": "varchar(20)", "policies": []map[string]interface{}{
+				{"access": "masked", "mask": "phone"},
+			}},
+			{"name": "salary", "column_type": "int", "policies": []map[string]interface{}{
+				{"access": "hidden"},
+			}},
+		}).create(t, helper)
+		_, err := model.DB.Exec("INSERT INTO app1_test_clone_policy (name, phone, salary) VALUES ('张三', '13812345678', 10000)")
+		assert.NoError(t, err)
+
+		body := map[string]interface{}{"table_name": "test_clone_policy_dst", "copy_data": true}
+		w := helper.MakeRequest(t, "POST", configTablePath(srcID)+"/clone", body, appHeader)
+		data := helper.AssertSuccess(t, w)["data"].(map[string]interface{})
+		assert.Equal(t, float64(1), data["copied"])
+
+		var row struct {
+			Name   string  `db:"name"`
+			Phone  *string `db:"phone"`
+			Salary *int    `db:"salary"`
+		}
+		err = model.DB.Get(&row, "SELECT name, phone, salary FROM app1_test_clone_policy_dst")
+		assert.NoError(t, err)
+		assert.Equal(t, "张三", row.Name)
+		assert.Nil(t, row.Phone)
+		assert.Nil(t, row.Salary)
+	})
+
+	t.Run("表名不能重复", func(t *testing.T) {
+		body := map[string]interface{}{"table_name": "test_clone_src"}
+		w := helper.MakeRequest(t, "POST", clonePath, body, appHeader)
+		helper.AssertError(t, w, 500)
+	})
+}
@@ -0,0 +1,126 @@
+package test
+
+import (
+	"testing"
+
+	"github.com/iiwish/lingjian/internal/model"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestTableColumnPolicies(t *testing.T) {
+	helper := NewTestHelper(t)
+
+	tableID := newTestTable("test_column_policy", "字段权限测试表", []map[string]interface{}{
+		{"name": "name", "column_type": "varchar(50)"},
+		{"name": "phone", "column_type": "varchar(20)", "policies": []map[string]interface{}{
+			{"access": "masked", "mask": "phone"},
+		}},
+		{"name": "salary", "column_type": "int", "policies": []map[string]interface{}{
+			{"access": "hidden"},
+			{"roles": []string{"hr"}, "access": "full"},
+		}},
+		{"name": "level", "column_type": "int", "default": "1", "policies": []map[string]interface{}{
+			{"roles": []string{"app_admin"}, "access": "readonly"},
+		}},
+		{"name": "annual_salary", "computed": "virtual", "formula": "salary * 12"},
+		{"name": "next_level", "computed": "virtual", "formula": "level + 1"},
+	}).create(t, helper)
+	basePath := tablePath(tableID)
+
+	t.Run("不能写入受限字段", func(t *testing.T) {
+		items := []map[string]interface{}{{"name": "张三", "phone": "13812345678", "salary": 10000}}
+		w := helper.MakeRequest(t, "POST", basePath, items, appHeader)
+		resp := helper.AssertError(t, w, 403)
+		fields := resp["data"].(map[string]interface{})["fields"].([]interface{})
+		assert.ElementsMatch(t, []interface{}{"phone", "salary"}, fields)
+	})
+
+	t.Run("读取时隐藏和脱敏", func(t *testing.T) {
+		_, err := model.DB.Exec("INSERT INTO app1_test_column_policy (name, phone, salary) VALUES ('张三', '13812345678', 10000)")
+		assert.NoError(t, err)
+
+		items := queryItems(t, helper, tableID, map[string]interface{}{})
+		if assert.Len(t, items, 1) {
+			item := items[0].(map[string]interface{})
+			assert.Equal(t, "138****5678", item["phone"])
+			assert.NotContains(t, item, "salary")
+			assert.Equal(t, float64(1), item["level"])
+			// 引用隐藏字段的计算字段同样隐藏，引用可读字段的计算字段正常返回
+			assert.NotContains(t, item, "annual_salary")
+			assert.Contains(t, item, "next_level")
+		}
+	})
+
+	t.Run("不能按隐藏字段过滤", func(t *testing.T) {
+		body := map[string]interface{}{
+			"query": map[string]interface{}{
+				"root": map[string]interface{}{
+					"conditions": []map[string]interface{}{{"field": "salary", "operator": "gt", "value": 5000}},
+				},
+			},
+		}
+		w := helper.MakeRequest(t, "POST", basePath+"/query", body, appHeader)
+		helper.AssertError(t, w, 400)
+	})
+
+	t.Run("只读字段不能修改", func(t *testing.T) {
+		body := map[string]interface{}{
+			"primary_key_columns": []string{"id"},
+			"items":               []map[string]interface{}{{"id": 1, "level": 2}},
+		}
+		w := helper.MakeRequest(t, "PUT", basePath, body, appHeader)
+		helper.AssertError(t, w, 403)
+
+		body["items"] = []map[string]interface{}{{"id": 1, "name": "李四"}}
+		w = helper.MakeRequest(t, "PUT", basePath, body, appHeader)
+		helper.AssertSuccess(t, w)
+	})
+	t.Run("修改时不能绕过字段权限", func(t *testing.T) {
+		cases := []struct {
+			name string
+			body map[string]interface{}
+			code int
+		}{
+			{"转义的字段名", map[string]interface{}{
+				"primary_key_columns": []string{"id"},
+				"items":               []map[string]interface{}{{"id": 1, "`salary`": 0}},
+			}, 400},
+			{"字段名中拼接赋值", map[string]interface{}{
+				"primary_key_columns": []string{"id"},
+				"items":               []map[string]interface{}{{"id": 1, "name = name, salary": 0}},
+			}, 400},
+			{"按隐藏字段定位记录", map[string]interface{}{
+				"primary_key_columns": []string{"salary"},
+				"items":               []map[string]interface{}{{"salary": 10000, "name": "王五"}},
+			}, 400},
+			{"修改软删除字段", map[string]interface{}{
+				"primary_key_columns": []string{"id"},
+				"items":               []map[string]interface{}{{"id": 1, "deleted_at": "2024-01-01 00:00:00"}},
+			}, 400},
+		}
+		for _, c := range cases {
+			t.Run(c.name, func(t *testing.T) {
+				w := helper.MakeRequest(t, "PUT", basePath, c.body, appHeader)
+				helper.AssertError(t, w, c.code)
+			})
+		}
+	})
+	t.Run("删除时不能绕过字段权限", func(t *testing.T) {
+		cases := []struct {
+			name       string
+			conditions []map[string]interface{}
+		}{
+			{"空条件", []map[string]interface{}{{}}},
+			{"按非唯一字段删除", []map[string]interface{}{{"name": "李四"}}},
+			{"按隐藏字段删除", []map[string]interface{}{{"salary": 10000}}},
+			{"字段名中拼接条件", []map[string]interface{}{{"id = id OR 1": 1}}},
+		}
+		for _, c := range cases {
+			t.Run(c.name, func(t *testing.T) {
+				w := helper.MakeRequest(t, "DELETE", basePath, c.conditions, appHeader)
+				helper.AssertError(t, w, 400)
+			})
+		}
+		assert.Len(t, queryItems(t, helper, tableID, map[string]interface{}{}), 1)
+	})
+}
@@ -0,0 +1,62 @@
+package test
+
+import (
+	"net/http"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestTableComputedFields(t *testing.T) {
+	helper := NewTestHelper(t)
+
+	tableID := newTestTable("test_computed", "计算字段测试表", []map[string]interface{}{
+		{"name": "qty", "column_type": "int"},
+		{"name": "price", "column_type": "decimal(10,2)"},
+		{"name": "amount", "column_type": "decimal(12,2)", "computed": "stored", "formula": "qty * price"},
+		{"name": "level", "computed": "virtual", "formula": "IF(amount >= 100, '大额', '小额')"},
+	}).create(t, helper)
+	basePath := tablePath(tableID)
+
+	t.Run("写入时计算存储字段", func(t *testing.T) {
+		items := []map[string]interface{}{{"qty": 3, "price": 20}}
+		w := helper.MakeRequest(t, "POST", basePath, items, appHeader)
+		helper.AssertSuccess(t, w)
+
+		rows := queryItems(t, helper, tableID, map[string]interface{}{"page": 1, "page_size": 10})
+		if assert.Len(t, rows, 1) {
+			row := rows[0].(map[string]interface{})
+			assert.Equal(t, "60.00", row["amount"])
+			assert.Equal(t, "小额", row["level"])
+		}
+	})
+
+	t.Run("更新时结合当前值重新计算", func(t *testing.T) {
+		req := map[string]interface{}{
+			"primary_key_columns": []string{"id"},
+			"items":               []map[string]interface{}{{"id": 1, "qty": 10}},
+		}
+		w := helper.MakeRequest(t, "PUT", basePath, req, appHeader)
+		helper.AssertSuccess(t, w)
+
+		rows := queryItems(t, helper, tableID, map[string]interface{}{"page": 1, "page_size": 10})
+		if assert.Len(t, rows, 1) {
+			row := rows[0].(map[string]interface{})
+			assert.Equal(t, "200.00", row["amount"])
+			assert.Equal(t, "大额", row["level"])
+		}
+	})
+
+	t.Run("公式引用不存在的字段", func(t *testing.T) {
+		tableData := map[string]interface{}{
+			"table_name":   "test_computed_invalid",
+			"display_name": "无效计算字段",
+			"fields": []map[string]interface{}{
+				{"name": "id", "column_type": "bigint", "primary_key": true, "auto_increment": true},
+				{"name": "total", "column_type": "int", "computed": "stored", "formula": "missing * 2"},
+			},
+		}
+		w := helper.MakeRequest(t, "POST", "/api/v1/config/tables", tableData, appHeader)
+		assert.NotEqual(t, http.StatusOK, w.Code)
+	})
+}
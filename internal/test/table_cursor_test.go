@@ -0,0 +1,71 @@
+package test
+
+import (
+	"net/http"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestTableCursorPagination(t *testing.T) {
+	helper := NewTestHelper(t)
+
+	tableID := newTestTable("test_cursor", "游标分页测试表", []map[string]interface{}{
+		{"name": "score", "column_type": "int", "not_null": true},
+	}).create(t, helper)
+	basePath := tablePath(tableID)
+
+	// 分数有重复，验证主键作为排序补充键
+	items := make([]map[string]interface{}, 0, 7)
+	for _, score := range []int{50, 80, 80, 60, 90, 80, 70} {
+		items = append(items, map[string]interface{}{"score": score})
+	}
+	w := helper.MakeRequest(t, "POST", basePath, items, appHeader)
+	helper.AssertSuccess(t, w)
+
+	t.Run("游标翻页", func(t *testing.T) {
+		var ids []float64
+		cursor := ""
+		for i := 0; i < 10; i++ {
+			body := map[string]interface{}{
+				"page_size":  3,
+				"pagination": "cursor",
+				"cursor":     cursor,
+				"query": map[string]interface{}{
+					"order_by": []map[string]interface{}{{"field": "score", "desc": true}},
+				},
+			}
+			w := helper.MakeRequest(t, "POST", basePath+"/query", body, appHeader)
+			resp := helper.AssertSuccess(t, w)
+			data := resp["data"].(map[string]interface{})
+			assert.Equal(t, float64(-1), data["total"])
+			for _, item := range data["items"].([]interface{}) {
+				ids = append(ids, item.(map[string]interface{})["id"].(float64))
+			}
+			if data["has_more"] != true {
+				break
+			}
+			cursor = data["next_cursor"].(string)
+		}
+		assert.Equal(t, []float64{5, 2, 3, 6, 7, 4, 1}, ids)
+	})
+
+	t.Run("统计方式", func(t *testing.T) {
+		body := map[string]interface{}{"page": 1, "page_size": 2, "count_mode": "exact"}
+		w := helper.MakeRequest(t, "POST", basePath+"/query", body, appHeader)
+		data := helper.AssertSuccess(t, w)["data"].(map[string]interface{})
+		assert.Equal(t, float64(7), data["total"])
+		assert.Equal(t, true, data["has_more"])
+
+		body["count_mode"] = "none"
+		w = helper.MakeRequest(t, "POST", basePath+"/query", body, appHeader)
+		data = helper.AssertSuccess(t, w)["data"].(map[string]interface{})
+		assert.Equal(t, float64(-1), data["total"])
+	})
+
+	t.Run("无效游标", func(t *testing.T) {
+		body := map[string]interface{}{"page_size": 3, "cursor": "not-a-cursor"}
+		w := helper.MakeRequest(t, "POST", basePath+"/query", body, appHeader)
+		helper.AssertError(t, w, http.StatusBadRequest)
+	})
+}
@@ -0,0 +1,119 @@
+package test
+
+import (
+	"testing"
+
+	"github.com/iiwish/lingjian/internal/model"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestTableDDL(t *testing.T) {
+	helper := NewTestHelper(t)
+
+	tableID := newTestTable("test_ddl_src", "建表语句测试表", []map[string]interface{}{
+		{"name": "code", "type": "text", "length": 20, "comment": "编码"},
+		{"name": "amount", "column_type": "decimal(10,2)", "default": "0"},
+	}).withFunc(`{"soft_delete": true}`).withIndexes([]map[string]interface{}{
+		{"name": "uk_code", "type": "UNIQUE", "fields": []string{"code"}},
+	}).create(t, helper)
+	exportPath := configTablePath(tableID) + "/export"
+
+	var ddl string
+	t.Run("导出建表语句", func(t *testing.T) {
+		w := helper.MakeRequest(t, "GET", exportPath, nil, appHeader)
+		data := helper.AssertSuccess(t, w)["data"].(map[string]interface{})
+		ddl = data["ddl"].(string)
+		assert.Contains(t, ddl, "CREATE TABLE `test_ddl_src`")
+		assert.NotContains(t, ddl, "app1_")
+	})
+
+	t.Run("导出平台数据表定义", func(t *testing.T) {
+		w := helper.MakeRequest(t, "GET", exportPath+"?format=json", nil, appHeader)
+		table := helper.AssertSuccess(t, w)["data"].(map[string]interface{})["table"].(map[string]interface{})
+		assert.Equal(t, "test_ddl_src", table["table_name"])
+		// 软删除托管字段不导出
+		assert.Len(t, table["fields"], 3)
+
+		helper.AssertError(t, helper.MakeRequest(t, "GET", exportPath+"?format=xml", nil, appHeader), 400)
+	})
+
+	t.Run("试导入建表语句", func(t *testing.T) {
+		body := map[string]interface{}{
+			"ddl": ddl + `;
+				-- 导出后追加的注释
+			`,
+			"table_name": "test_ddl_dst",
+			"func":       `{"soft_delete": true}`,
+			"dry_run":    true,
+		}
+		w := helper.MakeRequest(t, "POST", "/api/v1/config/tables/import", body, appHeader)
+		data := helper.AssertSuccess(t, w)["data"].(map[string]interface{})
+		assert.Nil(t, data["id"])
+		table := data["table"].(map[string]interface{})
+		assert.Len(t, table["fields"], 3)
+		assert.Len(t, table["indexes"], 1)
+		assert.Nil(t, data["unsupported"])
+
+		var count int
+		err := model.DB.Get(&count, "SELECT COUNT(*) FROM sys_config_tables WHERE logical_name = 'test_ddl_dst'")
+		assert.NoError(t, err)
+		assert.Equal(t, 0, count)
+	})
+
+	t.Run("导入建表语句并说明不支持的子句", func(t *testing.T) {
+		body := map[string]interface{}{
+			"ddl": "CREATE TABLE `test_ddl_orders` (" +
+				"`order_id` bigint NOT NULL, `line_no` int NOT NULL, `sku` varchar(32) NOT NULL COMMENT '商品', " +
+				"`created_at` datetime DEFAULT CURRENT_TIMESTAMP, " +
+				"PRIMARY KEY (`order_id`, `line_no`), KEY `idx_sku` (`sku`), " +
+				"CONSTRAINT `fk_sku` FOREIGN KEY (`sku`) REFERENCES `skus` (`code`)" +
+				") ENGINE=InnoDB DEFAULT CHARSET=utf8mb4 COMMENT='订单明细'",
+		}
+		w := helper.MakeRequest(t, "POST", "/api/v1/config/tables/import", body, appHeader)
+		data := helper.AssertSuccess(t, w)["data"].(map[string]interface{})
+		assert.NotNil(t, data["id"])
+		assert.Len(t, data["unsupported"], 2)
+
+		var keys []string
+		err := model.DB.Select(&keys, `
+			SELECT column_name FROM information_schema.key_column_usage
+			WHERE table_schema = DATABASE() AND table_name = 'app1_test_ddl_orders' AND constraint_name = 'PRIMARY'
+			ORDER BY ordinal_position
+		`)
+		assert.NoError(t, err)
+		assert.Equal(t, []string{"order_id", "line_no"}, keys)
+	})
+
+	t.Run("导入的默认值和注释按字符串转义", func(t *testing.T) {
+		body := map[string]interface{}{
+			"ddl": "CREATE TABLE `test_ddl_quote` (`id` bigint NOT NULL AUTO_INCREMENT, " +
+				"`note` varchar(50) DEFAULT 'it''s' COMMENT 'O\\'Brien', PRIMARY KEY (`id`))",
+		}
+		w := helper.MakeRequest(t, "POST", "/api/v1/config/tables/import", body, appHeader)
+		helper.AssertSuccess(t, w)
+
+		var column struct {
+			Default string `db:"column_default"`
+			Comment string `db:"column_comment"`
+		}
+		err := model.DB.Get(&column, `
+			SELECT column_default, column_comment FROM information_schema.columns
+			WHERE table_schema = DATABASE() AND table_name = 'app1_test_ddl_quote' AND column_name = 'note'
+		`)
+		assert.NoError(t, err)
+		assert.Equal(t, "it's", column.Default)
+		assert.Equal(t, "O'Brien", column.Comment)
+	})
+
+	t.Run("字段名不合法", func(t *testing.T) {
+		body := map[string]interface{}{"ddl": "CREATE TABLE `test_ddl_bad_name` (`a b` int)", "dry_run": true}
+		w := helper.MakeRequest(t, "POST", "/api/v1/config/tables/import", body, appHeader)
+		helper.AssertError(t, w, 400)
+	})
+
+	t.Run("无法解析的语句", func(t *testing.T) {
+		body := map[string]interface{}{"ddl": "CREATE TABLE test_ddl_bad LIKE test_ddl_src"}
+		w := helper.MakeRequest(t, "POST", "/api/v1/config/tables/import", body, appHeader)
+		helper.AssertError(t, w, 400)
+	})
+}
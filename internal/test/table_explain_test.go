@@ -0,0 +1,59 @@
+package test
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestTableExplain(t *testing.T) {
+	helper := NewTestHelper(t)
+
+	tableID := newTestTable("test_explain", "执行计划测试表", []map[string]interface{}{
+		{"name": "status", "column_type": "varchar(20)"},
+		{"name": "created", "column_type": "date"},
+	}).create(t, helper)
+	basePath := tablePath(tableID)
+
+	t.Run("全表扫描和索引建议", func(t *testing.T) {
+		body := map[string]interface{}{"query": map[string]interface{}{
+			"root": map[string]interface{}{
+				"conditions": []map[string]interface{}{{"field": "status", "operator": "eq", "value": "done"}},
+			},
+			"order_by": []map[string]interface{}{{"field": "created", "desc": true}},
+		}}
+		w := helper.MakeRequest(t, "POST", basePath+"/explain", body, appHeader)
+		data := helper.AssertSuccess(t, w)["data"].(map[string]interface{})
+
+		assert.Contains(t, data["bound_sql"], "`status` = 'done'")
+		assert.Contains(t, data["bound_sql"], "LIMIT 11 OFFSET 0")
+		assert.NotEmpty(t, data["plan"])
+		assert.NotEmpty(t, data["full_scans"])
+		suggestions := data["suggestions"].([]interface{})
+		if assert.Len(t, suggestions, 1) {
+			columns := suggestions[0].(map[string]interface{})["columns"]
+			assert.Equal(t, []interface{}{"status", "created"}, columns)
+		}
+	})
+
+	t.Run("主键过滤不建议索引", func(t *testing.T) {
+		body := map[string]interface{}{"query": map[string]interface{}{
+			"root": map[string]interface{}{
+				"conditions": []map[string]interface{}{{"field": "id", "operator": "eq", "value": 1}},
+			},
+		}}
+		w := helper.MakeRequest(t, "POST", basePath+"/explain", body, appHeader)
+		data := helper.AssertSuccess(t, w)["data"].(map[string]interface{})
+		assert.Empty(t, data["suggestions"])
+	})
+
+	t.Run("未知字段", func(t *testing.T) {
+		body := map[string]interface{}{"query": map[string]interface{}{
+			"root": map[string]interface{}{
+				"conditions": []map[string]interface{}{{"field": "missing", "operator": "eq", "value": 1}},
+			},
+		}}
+		w := helper.MakeRequest(t, "POST", basePath+"/explain", body, appHeader)
+		helper.AssertError(t, w, 400)
+	})
+}
@@ -0,0 +1,68 @@
+package test
+
+import (
+	"strconv"
+	"testing"
+)
+
+var appHeader = map[string]string{"App-ID": "1"}
+
+// testTable 测试数据表定义，字段以自增主键id开头
+type testTable map[string]interface{}
+
+// newTestTable 创建测试数据表定义，fields为主键id之外的字段
+func newTestTable(tableName string, displayName string, fields []map[string]interface{}) testTable {
+	idField := map[string]interface{}{"name": "id", "column_type": "bigint", "primary_key": true, "auto_increment": true}
+	return testTable{
+		"table_name":   tableName,
+		"display_name": displayName,
+		"fields":       append([]map[string]interface{}{idField}, fields...),
+	}
+}
+
+// withFunc 设置数据表功能配置
+func (tt testTable) withFunc(tableFunc string) testTable {
+	tt["func"] = tableFunc
+	return tt
+}
+
+// withIndexes 设置数据表索引
+func (tt testTable) withIndexes(indexes []map[string]interface{}) testTable {
+	tt["indexes"] = indexes
+	return tt
+}
+
+// create 创建数据表并返回表ID
+func (tt testTable) create(t *testing.T, helper *TestHelper) uint {
+	return createTestTable(t, helper, tt)
+}
+
+// createTestTable 创建测试数据表并返回表ID
+func createTestTable(t *testing.T, helper *TestHelper, tableData map[string]interface{}) uint {
+	w := helper.MakeRequest(t, "POST", "/api/v1/config/tables", tableData, appHeader)
+	resp := helper.AssertSuccess(t, w)
+	data, ok := resp["data"].(map[string]interface{})
+	if !ok {
+		t.Fatal("创建数据表响应格式不正确")
+	}
+	return uint(data["id"].(float64))
+}
+
+// tablePath 数据表记录接口路径
+func tablePath(tableID uint) string {
+	return "/api/v1/table/" + strconv.FormatUint(uint64(tableID), 10)
+}
+
+// configTablePath 数据表配置接口路径
+func configTablePath(tableID uint) string {
+	return "/api/v1/config/tables/" + strconv.FormatUint(uint64(tableID), 10)
+}
+
+// queryItems 查询数据表记录
+func queryItems(t *testing.T, helper *TestHelper, tableID uint, body map[string]interface{}) []interface{} {
+	w := helper.MakeRequest(t, "POST", tablePath(tableID)+"/query", body, appHeader)
+	resp := helper.AssertSuccess(t, w)
+	data := resp["data"].(map[string]interface{})
+	items, _ := data["items"].([]interface{})
+	return items
+}
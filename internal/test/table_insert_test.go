@@ -0,0 +1,107 @@
+package test
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestTableBatchInsertAndUpsert(t *testing.T) {
+	helper := NewTestHelper(t)
+
+	tableID := newTestTable("test_upsert", "批量写入测试表", []map[string]interface{}{
+		{"name": "code", "column_type": "varchar(50)", "not_null": true},
+		{"name": "name", "column_type": "varchar(50)"},
+	}).withIndexes([]map[string]interface{}{
+		{"name": "uk_code", "type": "UNIQUE", "fields": []string{"code"}},
+	}).create(t, helper)
+	basePath := tablePath(tableID)
+
+	t.Run("分批插入", func(t *testing.T) {
+		items := []map[string]interface{}{
+			{"code": "A1", "name": "一"},
+			{"code": "A2", "name": "二"},
+			{"code": "A3"},
+		}
+		w := helper.MakeRequest(t, "POST", basePath+"?batch_size=1", items, appHeader)
+		data := helper.AssertSuccess(t, w)["data"].(map[string]interface{})
+		assert.Equal(t, float64(3), data["inserted"])
+		assert.Equal(t, float64(0), data["updated"])
+		assert.Equal(t, []interface{}{float64(1), float64(2), float64(3)}, data["ids"])
+	})
+
+	t.Run("重复记录插入失败", func(t *testing.T) {
+		w := helper.MakeRequest(t, "POST", basePath, []map[string]interface{}{{"code": "A1"}}, appHeader)
+		helper.AssertError(t, w, 500)
+	})
+
+	t.Run("upsert", func(t *testing.T) {
+		items := []map[string]interface{}{
+			{"code": "A4", "name": "四"},
+			{"code": "A1", "name": "壹"},
+		}
+		w := helper.MakeRequest(t, "POST", basePath+"?mode=upsert", items, appHeader)
+		data := helper.AssertSuccess(t, w)["data"].(map[string]interface{})
+		assert.Equal(t, float64(1), data["inserted"])
+		assert.Equal(t, float64(1), data["updated"])
+		assert.Equal(t, []interface{}{float64(4), float64(1)}, data["ids"])
+
+		rows := queryItems(t, helper, tableID, map[string]interface{}{
+			"query": map[string]interface{}{
+				"root": map[string]interface{}{
+					"conditions": []map[string]interface{}{{"field": "code", "operator": "eq", "value": "A1"}},
+				},
+			},
+		})
+		if assert.Len(t, rows, 1) {
+			assert.Equal(t, "壹", rows[0].(map[string]interface{})["name"])
+		}
+	})
+
+	t.Run("未知字段", func(t *testing.T) {
+		w := helper.MakeRequest(t, "POST", basePath, []map[string]interface{}{{"code": "A5", "unknown": 1}}, appHeader)
+		helper.AssertError(t, w, 400)
+
+		w = helper.MakeRequest(t, "POST", basePath+"?mode=replace", []map[string]interface{}{{"code": "A5"}}, appHeader)
+		helper.AssertError(t, w, 400)
+	})
+}
+
+func TestTableUpsertSoftDeletedAndVersioned(t *testing.T) {
+	helper := NewTestHelper(t)
+
+	tableID := newTestTable("test_upsert_deleted", "upsert回收站测试表", []map[string]interface{}{
+		{"name": "code", "column_type": "varchar(50)", "not_null": true},
+		{"name": "name", "column_type": "varchar(50)"},
+	}).withFunc(`{"soft_delete": true}`).withIndexes([]map[string]interface{}{
+		{"name": "uk_code", "type": "UNIQUE", "fields": []string{"code"}},
+	}).create(t, helper)
+	basePath := tablePath(tableID)
+
+	helper.AssertSuccess(t, helper.MakeRequest(t, "POST", basePath, []map[string]interface{}{{"code": "A1", "name": "一"}}, appHeader))
+	helper.AssertSuccess(t, helper.MakeRequest(t, "DELETE", basePath, []map[string]interface{}{{"id": 1}}, appHeader))
+
+	t.Run("与回收站中的记录重复时报错", func(t *testing.T) {
+		items := []map[string]interface{}{{"code": "A1", "name": "壹"}}
+		w := helper.MakeRequest(t, "POST", basePath+"?mode=upsert", items, appHeader)
+		helper.AssertError(t, w, 400)
+		assert.Empty(t, queryItems(t, helper, tableID, map[string]interface{}{}))
+	})
+
+	t.Run("指定恢复时更新并恢复记录", func(t *testing.T) {
+		items := []map[string]interface{}{{"code": "A1", "name": "壹"}}
+		w := helper.MakeRequest(t, "POST", basePath+"?mode=upsert&restore=true", items, appHeader)
+		data := helper.AssertSuccess(t, w)["data"].(map[string]interface{})
+		assert.Equal(t, float64(1), data["updated"])
+		assert.Equal(t, []interface{}{float64(1)}, data["ids"])
+		assert.Len(t, queryItems(t, helper, tableID, map[string]interface{}{}), 1)
+	})
+
+	t.Run("启用记录版本的数据表不支持upsert", func(t *testing.T) {
+		versionedID := newTestTable("test_upsert_versioned", "upsert版本测试表", []map[string]interface{}{
+			{"name": "code", "column_type": "varchar(50)"},
+		}).withFunc(`{"versioned": true}`).create(t, helper)
+		w := helper.MakeRequest(t, "POST", tablePath(versionedID)+"?mode=upsert", []map[string]interface{}{{"id": 1, "code": "A1"}}, appHeader)
+		helper.AssertError(t, w, 400)
+	})
+}
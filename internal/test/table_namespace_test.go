@@ -0,0 +1,93 @@
+package test
+
+import (
+	"strconv"
+	"testing"
+
+	"github.com/iiwish/lingjian/internal/model"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestTableNamespace(t *testing.T) {
+	helper := NewTestHelper(t)
+
+	tableID := newTestTable("test_namespace", "命名空间测试表", []map[string]interface{}{}).create(t, helper)
+	configPath := configTablePath(tableID)
+
+	t.Run("数据表创建在应用命名空间中", func(t *testing.T) {
+		w := helper.MakeRequest(t, "GET", configPath, nil, appHeader)
+		data := helper.AssertSuccess(t, w)["data"].(map[string]interface{})
+		assert.Equal(t, "test_namespace", data["table_name"])
+		assert.Equal(t, "app1_test_namespace", data["physical_name"])
+
+		var count int
+		err := model.DB.Get(&count, "SELECT COUNT(*) FROM information_schema.tables WHERE table_schema = DATABASE() AND table_name = ?", "app1_test_namespace")
+		assert.NoError(t, err)
+		assert.Equal(t, 1, count)
+	})
+
+	t.Run("保留的表名前缀和后缀", func(t *testing.T) {
+		for _, name := range []string{"sys_namespace", "app2_namespace", "namespace_archive"} {
+			body := map[string]interface{}{
+				"table_name":   name,
+				"display_name": name,
+				"fields": []map[string]interface{}{
+					{"name": "id", "column_type": "bigint", "primary_key": true, "auto_increment": true},
+				},
+			}
+			w := helper.MakeRequest(t, "POST", "/api/v1/config/tables", body, appHeader)
+			helper.AssertError(t, w, 500)
+		}
+	})
+
+	t.Run("修改逻辑表名同时修改数据库表名", func(t *testing.T) {
+		body := map[string]interface{}{"table_name": "test_namespace_renamed", "display_name": "命名空间测试表"}
+		helper.AssertSuccess(t, helper.MakeRequest(t, "PUT", configPath, body, appHeader))
+
+		var count int
+		err := model.DB.Get(&count, "SELECT COUNT(*) FROM information_schema.tables WHERE table_schema = DATABASE() AND table_name = ?", "app1_test_namespace_renamed")
+		assert.NoError(t, err)
+		assert.Equal(t, 1, count)
+	})
+
+	t.Run("迁移旧数据表到应用命名空间", func(t *testing.T) {
+		_, err := model.DB.Exec("DROP TABLE IF EXISTS legacy_namespace")
+		assert.NoError(t, err)
+		_, err = model.DB.Exec("CREATE TABLE legacy_namespace (id BIGINT UNSIGNED AUTO_INCREMENT PRIMARY KEY)")
+		assert.NoError(t, err)
+		w := helper.MakeRequest(t, "POST", "/api/v1/config/tables/adopt", map[string]interface{}{"table_name": "legacy_namespace"}, appHeader)
+		legacyID := uint(helper.AssertSuccess(t, w)["data"].(map[string]interface{})["id"].(float64))
+
+		w = helper.MakeRequest(t, "POST", configTablePath(legacyID)+"/namespace", nil, appHeader)
+		data := helper.AssertSuccess(t, w)["data"].(map[string]interface{})
+		assert.Equal(t, "app1_legacy_namespace", data["physical_name"])
+		assert.Len(t, queryItems(t, helper, legacyID, map[string]interface{}{}), 0)
+	})
+	t.Run("迁移旧维度到应用命名空间", func(t *testing.T) {
+		_, err := model.DB.Exec("DROP TABLE IF EXISTS legacy_dim_namespace, app1_legacy_dim_namespace")
+		assert.NoError(t, err)
+		_, err = model.DB.Exec("CREATE TABLE legacy_dim_namespace (id BIGINT UNSIGNED AUTO_INCREMENT PRIMARY KEY)")
+		assert.NoError(t, err)
+		result, err := model.DB.Exec(`
+			INSERT INTO sys_config_dimensions (app_id, table_name, logical_name, display_name, dimension_type)
+			VALUES (1, 'legacy_dim_namespace', 'legacy_dim_namespace', '旧维度', 'general')
+		`)
+		assert.NoError(t, err)
+		dimID, _ := result.LastInsertId()
+		dimPath := "/api/v1/config/dimensions/" + strconv.FormatInt(dimID, 10) + "/namespace"
+
+		w := helper.MakeRequest(t, "POST", dimPath, nil, appHeader)
+		data := helper.AssertSuccess(t, w)["data"].(map[string]interface{})
+		assert.Equal(t, "app1_legacy_dim_namespace", data["physical_name"])
+
+		var tableName string
+		err = model.DB.Get(&tableName, "SELECT table_name FROM sys_config_dimensions WHERE id = ?", dimID)
+		assert.NoError(t, err)
+		assert.Equal(t, "app1_legacy_dim_namespace", tableName)
+
+		// 再次迁移不做处理
+		w = helper.MakeRequest(t, "POST", dimPath, nil, appHeader)
+		data = helper.AssertSuccess(t, w)["data"].(map[string]interface{})
+		assert.Equal(t, "app1_legacy_dim_namespace", data["physical_name"])
+	})
+}
@@ -0,0 +1,83 @@
+package test
+
+import (
+	"net/http"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestTableQueryValidation(t *testing.T) {
+	helper := NewTestHelper(t)
+
+	tableID := newTestTable("test_query_validation", "查询校验测试表", []map[string]interface{}{
+		{"name": "name", "column_type": "varchar(50)"},
+		{"name": "age", "column_type": "int"},
+		{"name": "birthday", "column_type": "date"},
+	}).create(t, helper)
+	basePath := tablePath(tableID)
+
+	items := []map[string]interface{}{
+		{"name": "张三", "age": 20, "birthday": "2004-05-01"},
+		{"name": "李四", "age": 30, "birthday": "1994-08-15"},
+	}
+	w := helper.MakeRequest(t, "POST", basePath, items, appHeader)
+	helper.AssertSuccess(t, w)
+
+	t.Run("嵌套条件组与类型转换", func(t *testing.T) {
+		rows := queryItems(t, helper, tableID, map[string]interface{}{
+			"page": 1, "page_size": 10,
+			"query": map[string]interface{}{
+				"root": map[string]interface{}{
+					"logic": "AND",
+					"conditions": []interface{}{
+						map[string]interface{}{"field": "age", "operator": "gte", "value": "25"},
+						map[string]interface{}{
+							"logic": "OR",
+							"conditions": []interface{}{
+								map[string]interface{}{"field": "birthday", "operator": "lt", "value": "2000-01-01"},
+								map[string]interface{}{"field": "name", "operator": "eq", "value": "张三"},
+							},
+						},
+					},
+				},
+			},
+		})
+		if assert.Len(t, rows, 1) {
+			assert.Equal(t, "李四", rows[0].(map[string]interface{})["name"])
+		}
+	})
+
+	invalid := map[string]map[string]interface{}{
+		"未知字段": {
+			"root": map[string]interface{}{"conditions": []interface{}{
+				map[string]interface{}{"field": "unknown", "operator": "eq", "value": 1},
+			}},
+		},
+		"字段注入": {
+			"root": map[string]interface{}{"conditions": []interface{}{
+				map[string]interface{}{"field": "age = 1 OR 1", "operator": "eq", "value": 1},
+			}},
+		},
+		"条件格式错误": {
+			"root": map[string]interface{}{"conditions": []interface{}{
+				map[string]interface{}{"field": 123, "operator": "eq"},
+			}},
+		},
+		"取值类型错误": {
+			"root": map[string]interface{}{"conditions": []interface{}{
+				map[string]interface{}{"field": "age", "operator": "gt", "value": "abc"},
+			}},
+		},
+		"非法排序字段": {
+			"order_by": []map[string]interface{}{{"field": "age; DROP TABLE sys_users"}},
+		},
+	}
+	for name, query := range invalid {
+		t.Run(name, func(t *testing.T) {
+			body := map[string]interface{}{"page": 1, "page_size": 10, "query": query}
+			w := helper.MakeRequest(t, "POST", basePath+"/query", body, appHeader)
+			helper.AssertError(t, w, http.StatusBadRequest)
+		})
+	}
+}
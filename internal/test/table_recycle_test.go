@@ -0,0 +1,59 @@
+package test
+
+import (
+	"net/http"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestTableSoftDeleteFlow(t *testing.T) {
+	helper := NewTestHelper(t)
+
+	tableID := newTestTable("test_soft_delete", "软删除测试表", []map[string]interface{}{
+		{"name": "name", "column_type": "varchar(50)", "not_null": true},
+	}).withFunc(`{"soft_delete": true, "retention_days": 7}`).create(t, helper)
+	basePath := tablePath(tableID)
+
+	t.Run("创建记录", func(t *testing.T) {
+		items := []map[string]interface{}{{"id": 1, "name": "张三"}, {"id": 2, "name": "李四"}}
+		w := helper.MakeRequest(t, "POST", basePath, items, appHeader)
+		helper.AssertSuccess(t, w)
+	})
+
+	t.Run("软删除记录", func(t *testing.T) {
+		w := helper.MakeRequest(t, "DELETE", basePath, []map[string]interface{}{{"id": 1}}, appHeader)
+		assert.Equal(t, http.StatusNoContent, w.Code)
+
+		items := queryItems(t, helper, tableID, map[string]interface{}{"page": 1, "page_size": 10})
+		assert.Len(t, items, 1)
+	})
+
+	t.Run("回收站列表", func(t *testing.T) {
+		w := helper.MakeRequest(t, "GET", basePath+"/recycle", nil, appHeader)
+		resp := helper.AssertSuccess(t, w)
+		data := resp["data"].(map[string]interface{})
+		assert.Equal(t, float64(1), data["total"])
+	})
+
+	t.Run("恢复记录", func(t *testing.T) {
+		w := helper.MakeRequest(t, "PUT", basePath+"/recycle", []map[string]interface{}{{"id": 1}}, appHeader)
+		helper.AssertSuccess(t, w)
+
+		items := queryItems(t, helper, tableID, map[string]interface{}{"page": 1, "page_size": 10})
+		assert.Len(t, items, 2)
+	})
+
+	t.Run("彻底删除记录", func(t *testing.T) {
+		w := helper.MakeRequest(t, "DELETE", basePath, []map[string]interface{}{{"id": 2}}, appHeader)
+		assert.Equal(t, http.StatusNoContent, w.Code)
+
+		w = helper.MakeRequest(t, "DELETE", basePath+"/recycle", []map[string]interface{}{{"id": 2}}, appHeader)
+		assert.Equal(t, http.StatusNoContent, w.Code)
+
+		w = helper.MakeRequest(t, "GET", basePath+"/recycle", nil, appHeader)
+		resp := helper.AssertSuccess(t, w)
+		data := resp["data"].(map[string]interface{})
+		assert.Equal(t, float64(0), data["total"])
+	})
+}
@@ -0,0 +1,102 @@
+package test
+
+import (
+	"strconv"
+	"testing"
+
+	"github.com/iiwish/lingjian/internal/model"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestTableReferenceFields(t *testing.T) {
+	helper := NewTestHelper(t)
+
+	// 创建地区维度：华东/上海
+	w := helper.MakeRequest(t, "POST", "/api/v1/config/dimensions", map[string]interface{}{
+		"table_name":     "test_ref_region",
+		"display_name":   "地区",
+		"dimension_type": "normal",
+	}, appHeader)
+	dimID := uint(helper.AssertSuccess(t, w)["data"].(map[string]interface{})["ID"].(float64))
+	dimPath := "/api/v1/dimension/" + strconv.FormatUint(uint64(dimID), 10)
+
+	w = helper.MakeRequest(t, "POST", dimPath, map[string]interface{}{"name": "华东", "code": "east", "status": 1}, appHeader)
+	east := helper.AssertSuccess(t, w)["data"].(map[string]interface{})["id"].(float64)
+	w = helper.MakeRequest(t, "POST", dimPath, map[string]interface{}{"name": "上海", "code": "shanghai", "status": 1, "parent_id": east}, appHeader)
+	helper.AssertSuccess(t, w)
+
+	tableID := newTestTable("test_reference", "引用字段测试表", []map[string]interface{}{
+		{"name": "region", "column_type": "varchar(50)", "reference": map[string]interface{}{
+			"type": "dimension", "dim_id": dimID, "key": "code",
+		}},
+	}).create(t, helper)
+	basePath := tablePath(tableID)
+
+	t.Run("引用的记录不存在", func(t *testing.T) {
+		w := helper.MakeRequest(t, "POST", basePath, []map[string]interface{}{{"region": "beijing"}}, appHeader)
+		resp := helper.AssertError(t, w, 400)
+		errs := resp["data"].([]interface{})
+		if assert.Len(t, errs, 1) {
+			assert.Equal(t, "reference", errs[0].(map[string]interface{})["rule"])
+		}
+	})
+
+	t.Run("展开维度路径", func(t *testing.T) {
+		w := helper.MakeRequest(t, "POST", basePath, []map[string]interface{}{{"region": "shanghai"}}, appHeader)
+		helper.AssertSuccess(t, w)
+
+		items := queryItems(t, helper, tableID, map[string]interface{}{"expand_refs": true})
+		if assert.Len(t, items, 1) {
+			refs := items[0].(map[string]interface{})["_refs"].(map[string]interface{})
+			region := refs["region"].(map[string]interface{})
+			assert.Equal(t, "上海", region["name"])
+			assert.Equal(t, "华东/上海", region["path"])
+			assert.Equal(t, true, region["found"])
+		}
+	})
+
+	t.Run("不能引用其他应用的维度", func(t *testing.T) {
+		w := helper.MakeRequest(t, "POST", "/api/v1/config/dimensions", map[string]interface{}{
+			"table_name":     "test_ref_region_other",
+			"display_name":   "其他应用地区",
+			"dimension_type": "normal",
+		}, map[string]string{"App-ID": "2"})
+		otherDimID := helper.AssertSuccess(t, w)["data"].(map[string]interface{})["ID"].(float64)
+
+		table := newTestTable("test_reference_other", "跨应用引用", []map[string]interface{}{
+			{"name": "region", "column_type": "varchar(50)", "reference": map[string]interface{}{
+				"type": "dimension", "dim_id": otherDimID, "key": "code",
+			}},
+		})
+		w = helper.MakeRequest(t, "POST", "/api/v1/config/tables", table, appHeader)
+		helper.AssertError(t, w, 500)
+	})
+}
+
+func TestTableReferenceTargetPolicies(t *testing.T) {
+	helper := NewTestHelper(t)
+
+	customerID := newTestTable("test_ref_customer", "引用目标测试表", []map[string]interface{}{
+		{"name": "name", "column_type": "varchar(50)", "policies": []map[string]interface{}{
+			{"access": "hidden"},
+		}},
+	}).create(t, helper)
+	helper.AssertSuccess(t, helper.MakeRequest(t, "POST", tablePath(customerID), []map[string]interface{}{{"id": 1}}, appHeader))
+	_, err := model.DB.Exec("UPDATE app1_test_ref_customer SET name = '张三' WHERE id = 1")
+	assert.NoError(t, err)
+
+	tableID := newTestTable("test_ref_order", "引用来源测试表", []map[string]interface{}{
+		{"name": "customer_id", "column_type": "bigint", "reference": map[string]interface{}{
+			"type": "table", "table_id": customerID, "label": "name",
+		}},
+	}).create(t, helper)
+	helper.AssertSuccess(t, helper.MakeRequest(t, "POST", tablePath(tableID), []map[string]interface{}{{"customer_id": 1}}, appHeader))
+
+	items := queryItems(t, helper, tableID, map[string]interface{}{"expand_refs": true})
+	if assert.Len(t, items, 1) {
+		refs := items[0].(map[string]interface{})["_refs"].(map[string]interface{})
+		customer := refs["customer_id"].(map[string]interface{})
+		assert.Equal(t, true, customer["found"])
+		assert.NotContains(t, customer, "name")
+	}
+}
@@ -0,0 +1,129 @@
+package test
+
+import (
+	"net/http"
+	"strconv"
+	"testing"
+
+	"github.com/iiwish/lingjian/internal/model"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestTableDataScopes(t *testing.T) {
+	helper := NewTestHelper(t)
+
+	// 创建地区维度：华东/上海、华北
+	w := helper.MakeRequest(t, "POST", "/api/v1/config/dimensions", map[string]interface{}{
+		"table_name":     "test_region",
+		"display_name":   "地区",
+		"dimension_type": "normal",
+	}, appHeader)
+	dimID := uint(helper.AssertSuccess(t, w)["data"].(map[string]interface{})["ID"].(float64))
+	dimPath := "/api/v1/dimension/" + strconv.FormatUint(uint64(dimID), 10)
+
+	createRegion := func(name, code string, parentID float64) float64 {
+		body := map[string]interface{}{"name": name, "code": code, "status": 1, "parent_id": parentID}
+		w := helper.MakeRequest(t, "POST", dimPath, body, appHeader)
+		return helper.AssertSuccess(t, w)["data"].(map[string]interface{})["id"].(float64)
+	}
+	east := createRegion("华东", "east", 0)
+	shanghai := createRegion("上海", "shanghai", east)
+	north := createRegion("华北", "north", 0)
+
+	tableID := newTestTable("test_data_scope", "数据权限测试表", []map[string]interface{}{
+		{"name": "region_id", "column_type": "bigint"},
+		{"name": "amount", "column_type": "int"},
+	}).withFunc(`{"data_scopes": [{"field": "region_id", "dim_id": `+strconv.FormatUint(uint64(dimID), 10)+`}]}`).create(t, helper)
+	basePath := tablePath(tableID)
+
+	// 授予当前用户指定维度明细的权限
+	grant := func(itemID float64) {
+		_, err := model.DB.Exec(`DELETE FROM sys_permissions WHERE code = 'test_region_scope'`)
+		assert.NoError(t, err)
+		_, err = model.DB.Exec(`
+			INSERT INTO sys_permissions (name, code, app_code, type, dim_id, item_id, status)
+			VALUES ('地区数据权限', 'test_region_scope', 'test_app1', 'dim', ?, ?, 1)
+		`, dimID, itemID)
+		assert.NoError(t, err)
+		_, err = model.DB.Exec(`
+			INSERT INTO sys_role_permissions (role_id, permission_id)
+			SELECT r.id, p.id FROM sys_roles r, sys_permissions p
+			WHERE r.code = 'app_admin' AND p.code = 'test_region_scope'
+		`)
+		assert.NoError(t, err)
+	}
+
+	grant(0)
+	items := []map[string]interface{}{
+		{"region_id": east, "amount": 1},
+		{"region_id": shanghai, "amount": 2},
+		{"region_id": north, "amount": 3},
+	}
+	helper.AssertSuccess(t, helper.MakeRequest(t, "POST", basePath, items, appHeader))
+
+	t.Run("没有权限", func(t *testing.T) {
+		_, err := model.DB.Exec(`DELETE FROM sys_permissions WHERE code = 'test_region_scope'`)
+		assert.NoError(t, err)
+		assert.Len(t, queryItems(t, helper, tableID, map[string]interface{}{}), 0)
+
+		w := helper.MakeRequest(t, "POST", basePath, []map[string]interface{}{{"region_id": east, "amount": 4}}, appHeader)
+		helper.AssertError(t, w, http.StatusBadRequest)
+	})
+
+	t.Run("包含下级节点", func(t *testing.T) {
+		grant(east)
+		items := queryItems(t, helper, tableID, map[string]interface{}{})
+		assert.Len(t, items, 2)
+	})
+
+	t.Run("写入的取值需在权限内", func(t *testing.T) {
+		w := helper.MakeRequest(t, "POST", basePath, []map[string]interface{}{{"region_id": north, "amount": 4}}, appHeader)
+		helper.AssertError(t, w, http.StatusBadRequest)
+		w = helper.MakeRequest(t, "POST", basePath, []map[string]interface{}{{"amount": 4}}, appHeader)
+		helper.AssertError(t, w, http.StatusBadRequest)
+
+		body := map[string]interface{}{
+			"primary_key_columns": []string{"id"},
+			"items":               []map[string]interface{}{{"id": 2, "region_id": north}},
+		}
+		w = helper.MakeRequest(t, "PUT", basePath, body, appHeader)
+		helper.AssertError(t, w, http.StatusBadRequest)
+
+		body["items"] = []map[string]interface{}{{"id": 2, "region_id": east}}
+		w = helper.MakeRequest(t, "PUT", basePath, body, appHeader)
+		helper.AssertSuccess(t, w)
+	})
+
+	t.Run("不能修改权限外的记录", func(t *testing.T) {
+		body := map[string]interface{}{
+			"primary_key_columns": []string{"id"},
+			"items":               []map[string]interface{}{{"id": 3, "amount": 30}},
+		}
+		w := helper.MakeRequest(t, "PUT", basePath, body, appHeader)
+		helper.AssertSuccess(t, w)
+
+		w = helper.MakeRequest(t, "DELETE", basePath, []map[string]interface{}{{"id": 3}}, appHeader)
+		assert.Equal(t, http.StatusNoContent, w.Code)
+
+		grant(0)
+		items := queryItems(t, helper, tableID, map[string]interface{}{})
+		if assert.Len(t, items, 3) {
+			assert.Equal(t, float64(3), items[2].(map[string]interface{})["amount"])
+		}
+	})
+
+	t.Run("不能使用其他应用的维度", func(t *testing.T) {
+		w := helper.MakeRequest(t, "POST", "/api/v1/config/dimensions", map[string]interface{}{
+			"table_name":     "test_region_other",
+			"display_name":   "其他应用地区",
+			"dimension_type": "normal",
+		}, map[string]string{"App-ID": "2"})
+		otherDimID := uint(helper.AssertSuccess(t, w)["data"].(map[string]interface{})["ID"].(float64))
+
+		table := newTestTable("test_data_scope_other", "跨应用数据权限", []map[string]interface{}{
+			{"name": "region_id", "column_type": "bigint"},
+		}).withFunc(`{"data_scopes": [{"field": "region_id", "dim_id": ` + strconv.FormatUint(uint64(otherDimID), 10) + `}]}`)
+		w = helper.MakeRequest(t, "POST", "/api/v1/config/tables", table, appHeader)
+		helper.AssertError(t, w, 500)
+	})
+}
@@ -0,0 +1,72 @@
+package test
+
+import (
+	"net/url"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestTableFulltextSearch(t *testing.T) {
+	helper := NewTestHelper(t)
+
+	tableID := newTestTable("test_fulltext", "全文检索测试表", []map[string]interface{}{
+		{"name": "title", "column_type": "varchar(100)"},
+		{"name": "content", "column_type": "text"},
+		{"name": "code", "column_type": "varchar(50)"},
+	}).withFunc(`{"search_cols": ["title", "content"]}`).withIndexes([]map[string]interface{}{
+		{"name": "ft_title_content", "type": "FULLTEXT", "parser": "ngram", "fields": []string{"title", "content"}},
+		{"name": "ft_title", "type": "FULLTEXT", "parser": "ngram", "fields": []string{"title"}},
+		{"name": "uk_code", "type": "UNIQUE", "fields": []string{"code"}},
+	}).create(t, helper)
+	basePath := tablePath(tableID)
+
+	items := []map[string]interface{}{
+		{"title": "数据仓库建设", "content": "维度建模与数据治理", "code": "A1"},
+		{"title": "报表开发", "content": "基于数据仓库的报表", "code": "A2"},
+		{"title": "权限管理", "content": "角色与菜单", "code": "A3"},
+	}
+	w := helper.MakeRequest(t, "POST", basePath, items, appHeader)
+	helper.AssertSuccess(t, w)
+
+	t.Run("唯一索引", func(t *testing.T) {
+		w := helper.MakeRequest(t, "POST", basePath, []map[string]interface{}{{"title": "重复", "code": "A1"}}, appHeader)
+		helper.AssertError(t, w, 500)
+	})
+
+	t.Run("match操作符", func(t *testing.T) {
+		items := queryItems(t, helper, tableID, map[string]interface{}{
+			"query": map[string]interface{}{
+				"root": map[string]interface{}{
+					"conditions": []map[string]interface{}{{"field": "title", "operator": "match", "value": "数据仓库"}},
+				},
+			},
+		})
+		assert.Len(t, items, 1)
+	})
+
+	t.Run("字段没有全文索引", func(t *testing.T) {
+		body := map[string]interface{}{
+			"query": map[string]interface{}{
+				"root": map[string]interface{}{
+					"conditions": []map[string]interface{}{{"field": "code", "operator": "match", "value": "A1"}},
+				},
+			},
+		}
+		w := helper.MakeRequest(t, "POST", basePath+"/query", body, appHeader)
+		helper.AssertError(t, w, 400)
+	})
+
+	t.Run("快速搜索", func(t *testing.T) {
+		w := helper.MakeRequest(t, "GET", basePath+"/search?q="+url.QueryEscape("数据仓库"), nil, appHeader)
+		data := helper.AssertSuccess(t, w)["data"].(map[string]interface{})
+		items := data["items"].([]interface{})
+		if assert.Len(t, items, 2) {
+			// 标题和内容都匹配的记录相关度更高
+			assert.Equal(t, "A1", items[0].(map[string]interface{})["code"])
+		}
+
+		w = helper.MakeRequest(t, "GET", basePath+"/search?q=", nil, appHeader)
+		helper.AssertError(t, w, 400)
+	})
+}
@@ -0,0 +1,69 @@
+package test
+
+import (
+	"strconv"
+	"testing"
+	"time"
+
+	"github.com/iiwish/lingjian/internal/model"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestTableSequenceFields(t *testing.T) {
+	helper := NewTestHelper(t)
+
+	tableID := newTestTable("test_sequence", "编号字段测试表", []map[string]interface{}{
+		{"name": "order_no", "type": "sequence", "sequence": map[string]interface{}{
+			"pattern": "PO-{yyyy}-{seq:6}", "reset": "year",
+		}},
+		{"name": "name", "column_type": "varchar(50)"},
+	}).create(t, helper)
+	basePath := tablePath(tableID)
+	year := strconv.Itoa(time.Now().Year())
+
+	t.Run("新增记录时生成编号", func(t *testing.T) {
+		items := []map[string]interface{}{{"name": "a", "order_no": "manual"}, {"name": "b"}}
+		w := helper.MakeRequest(t, "POST", basePath, items, appHeader)
+		helper.AssertSuccess(t, w)
+
+		var numbers []string
+		err := model.DB.Select(&numbers, "SELECT order_no FROM app1_test_sequence ORDER BY id")
+		assert.NoError(t, err)
+		assert.Equal(t, []string{"PO-" + year + "-000001", "PO-" + year + "-000002"}, numbers)
+	})
+
+	t.Run("删除后编号不复用", func(t *testing.T) {
+		_, err := model.DB.Exec("DELETE FROM app1_test_sequence")
+		assert.NoError(t, err)
+
+		w := helper.MakeRequest(t, "POST", basePath, []map[string]interface{}{{"name": "c"}}, appHeader)
+		helper.AssertSuccess(t, w)
+
+		var number string
+		err = model.DB.Get(&number, "SELECT order_no FROM app1_test_sequence")
+		assert.NoError(t, err)
+		assert.Equal(t, "PO-"+year+"-000003", number)
+	})
+
+	t.Run("不能修改编号", func(t *testing.T) {
+		body := map[string]interface{}{
+			"primary_key_columns": []string{"id"},
+			"items":               []map[string]interface{}{{"id": 3, "order_no": "PO-1"}},
+		}
+		w := helper.MakeRequest(t, "PUT", basePath, body, appHeader)
+		helper.AssertError(t, w, 400)
+	})
+
+	t.Run("重置周期需要对应的日期", func(t *testing.T) {
+		body := map[string]interface{}{
+			"table_name":   "test_sequence_bad",
+			"display_name": "非法编号配置",
+			"fields": []map[string]interface{}{
+				{"name": "id", "column_type": "bigint", "primary_key": true},
+				{"name": "no", "type": "sequence", "sequence": map[string]interface{}{"pattern": "NO-{seq}", "reset": "month"}},
+			},
+		}
+		w := helper.MakeRequest(t, "POST", "/api/v1/config/tables", body, appHeader)
+		helper.AssertError(t, w, 500)
+	})
+}
@@ -0,0 +1,96 @@
+package test
+
+import (
+	"strconv"
+	"testing"
+
+	"github.com/iiwish/lingjian/internal/model"
+	"github.com/iiwish/lingjian/internal/service/config"
+	"github.com/iiwish/lingjian/pkg/storage"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestTableTrash(t *testing.T) {
+	helper := NewTestHelper(t)
+
+	tableID := newTestTable("test_trash", "回收站测试表", []map[string]interface{}{
+		{"name": "name", "type": "text", "length": 50},
+	}).create(t, helper)
+	configPath := configTablePath(tableID)
+	helper.AssertSuccess(t, helper.MakeRequest(t, "POST", tablePath(tableID), []map[string]interface{}{{"name": "a"}}, appHeader))
+	trashName := model.TrashTableName(tableID)
+
+	t.Run("删除数据表移入回收站", func(t *testing.T) {
+		helper.AssertSuccess(t, helper.MakeRequest(t, "DELETE", configPath, nil, appHeader))
+
+		var count int
+		err := model.DB.Get(&count, "SELECT COUNT(*) FROM "+trashName)
+		assert.NoError(t, err)
+		assert.Equal(t, 1, count)
+
+		helper.AssertError(t, helper.MakeRequest(t, "GET", configPath, nil, appHeader), 500)
+		helper.AssertError(t, helper.MakeRequest(t, "POST", tablePath(tableID)+"/query", map[string]interface{}{}, appHeader), 500)
+
+		w := helper.MakeRequest(t, "GET", "/api/v1/config/tables/trash", nil, appHeader)
+		tables := helper.AssertSuccess(t, w)["data"].([]interface{})
+		var found bool
+		for _, table := range tables {
+			item := table.(map[string]interface{})
+			if uint(item["id"].(float64)) == tableID {
+				found = true
+				assert.Equal(t, "test_trash", item["table_name"])
+			}
+		}
+		assert.True(t, found)
+	})
+
+	t.Run("恢复数据表", func(t *testing.T) {
+		helper.AssertSuccess(t, helper.MakeRequest(t, "PUT", configPath+"/restore", nil, appHeader))
+		items := queryItems(t, helper, tableID, map[string]interface{}{"page": 1, "page_size": 10})
+		assert.Len(t, items, 1)
+
+		helper.AssertError(t, helper.MakeRequest(t, "PUT", configPath+"/restore", nil, appHeader), 404)
+	})
+
+	t.Run("超过保留期不能恢复并被清理", func(t *testing.T) {
+		helper.AssertSuccess(t, helper.MakeRequest(t, "DELETE", configPath, nil, appHeader))
+		_, err := model.DB.Exec("UPDATE sys_config_tables SET deleted_at = DATE_SUB(NOW(), INTERVAL 400 DAY) WHERE id = ?", tableID)
+		assert.NoError(t, err)
+		helper.AssertError(t, helper.MakeRequest(t, "PUT", configPath+"/restore", nil, appHeader), 400)
+
+		// 回收站数据表的归档表、附件、编号计数器和操作记录一起清理
+		storage.Default = storage.NewLocalStorage(t.TempDir())
+		_, err = model.DB.Exec("CREATE TABLE " + model.ArchiveTableName(trashName) + " LIKE " + trashName)
+		assert.NoError(t, err)
+		_, err = model.DB.Exec("INSERT INTO sys_attachments (app_id, table_id, field_name, storage_key) VALUES (1, ?, 'files', 'missing.txt')", tableID)
+		assert.NoError(t, err)
+		_, err = model.DB.Exec("INSERT INTO sys_table_sequences (table_id, field_name, value) VALUES (?, 'code', 1)", tableID)
+		assert.NoError(t, err)
+		_, err = model.DB.Exec("INSERT INTO sys_table_operations (app_id, table_id, operation) VALUES (1, ?, 'bulk_update')", tableID)
+		assert.NoError(t, err)
+
+		purged, err := config.NewTableService(model.DB).PurgeTrashTables(1, 0)
+		assert.NoError(t, err)
+		assert.GreaterOrEqual(t, purged, 1)
+
+		var count int
+		err = model.DB.Get(&count, "SELECT COUNT(*) FROM information_schema.tables WHERE table_schema = DATABASE() AND table_name = ?", trashName)
+		assert.NoError(t, err)
+		assert.Equal(t, 0, count)
+		err = model.DB.Get(&count, "SELECT COUNT(*) FROM information_schema.tables WHERE table_schema = DATABASE() AND table_name = ?", model.ArchiveTableName(trashName))
+		assert.NoError(t, err)
+		assert.Equal(t, 0, count)
+		for _, table := range []string{"sys_config_tables", "sys_attachments", "sys_table_sequences", "sys_table_operations"} {
+			column := "table_id"
+			if table == "sys_config_tables" {
+				column = "id"
+			}
+			err = model.DB.Get(&count, "SELECT COUNT(*) FROM "+table+" WHERE "+column+" = ?", tableID)
+			assert.NoError(t, err)
+			assert.Equal(t, 0, count, table)
+		}
+		err = model.DB.Get(&count, "SELECT COUNT(*) FROM sys_menu_system WHERE menu_type = '2' AND source_id = ?", strconv.FormatUint(uint64(tableID), 10))
+		assert.NoError(t, err)
+		assert.Equal(t, 0, count)
+	})
+}
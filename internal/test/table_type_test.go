@@ -0,0 +1,114 @@
+package test
+
+import (
+	"testing"
+
+	"github.com/iiwish/lingjian/internal/model"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestTableLogicalFieldTypes(t *testing.T) {
+	helper := NewTestHelper(t)
+
+	tableID := createTestTable(t, helper, map[string]interface{}{
+		"table_name":   "test_field_type",
+		"display_name": "逻辑字段类型测试表",
+		"fields": []map[string]interface{}{
+			{"name": "id", "type": "integer", "primary_key": true, "auto_increment": true},
+			{"name": "title", "type": "text", "length": 20},
+			{"name": "amount", "type": "money", "precision": 12},
+			{"name": "active", "type": "boolean", "default": "true"},
+			{"name": "status", "type": "enum", "options": []string{"draft", "done"}, "default": "draft"},
+			{"name": "email", "type": "email"},
+			{"name": "birthday", "type": "date"},
+		},
+	})
+	basePath := tablePath(tableID)
+
+	t.Run("映射为数据库字段类型", func(t *testing.T) {
+		var columns []struct {
+			Name       string `db:"column_name"`
+			ColumnType string `db:"column_type"`
+		}
+		err := model.DB.Select(&columns, `
+			SELECT column_name, column_type FROM information_schema.columns
+			WHERE table_schema = DATABASE() AND table_name = 'app1_test_field_type'
+		`)
+		assert.NoError(t, err)
+		types := make(map[string]string)
+		for _, col := range columns {
+			types[col.Name] = col.ColumnType
+		}
+		assert.Equal(t, "bigint", types["id"])
+		assert.Equal(t, "varchar(20)", types["title"])
+		assert.Equal(t, "decimal(12,2)", types["amount"])
+		assert.Equal(t, "tinyint(1)", types["active"])
+		assert.Equal(t, "varchar(50)", types["status"])
+	})
+
+	t.Run("按字段类型校验取值", func(t *testing.T) {
+		items := []map[string]interface{}{{"title": "a", "status": "deleted", "email": "abc", "birthday": "2024/01/01"}}
+		w := helper.MakeRequest(t, "POST", basePath, items, appHeader)
+		resp := helper.AssertError(t, w, 400)
+		assert.Len(t, resp["data"], 3)
+
+		items = []map[string]interface{}{{"title": "a", "amount": 12.5, "status": "done", "email": "a@b.com", "birthday": "2024-01-01"}}
+		w = helper.MakeRequest(t, "POST", basePath, items, appHeader)
+		helper.AssertSuccess(t, w)
+	})
+
+	t.Run("布尔取值转换为0和1", func(t *testing.T) {
+		items := []map[string]interface{}{{"title": "b", "active": "false"}, {"title": "c", "active": true}}
+		w := helper.MakeRequest(t, "POST", basePath, items, appHeader)
+		helper.AssertSuccess(t, w)
+
+		var values []int
+		err := model.DB.Select(&values, "SELECT active FROM app1_test_field_type WHERE title IN ('b', 'c') ORDER BY title")
+		assert.NoError(t, err)
+		assert.Equal(t, []int{0, 1}, values)
+	})
+
+	t.Run("非法的默认值和字段类型", func(t *testing.T) {
+		body := map[string]interface{}{
+			"table_name":   "test_field_type_bad",
+			"display_name": "非法字段类型",
+			"fields": []map[string]interface{}{
+				{"name": "id", "type": "integer", "primary_key": true},
+				{"name": "data", "type": "json", "default": "{}"},
+			},
+		}
+		w := helper.MakeRequest(t, "POST", "/api/v1/config/tables", body, appHeader)
+		helper.AssertError(t, w, 500)
+
+		body["fields"] = []map[string]interface{}{
+			{"name": "id", "column_type": "int; DROP TABLE sys_users", "primary_key": true},
+		}
+		w = helper.MakeRequest(t, "POST", "/api/v1/config/tables", body, appHeader)
+		helper.AssertError(t, w, 500)
+
+		body["fields"] = []map[string]interface{}{
+			{"name": "id int primary key, x", "column_type": "int", "primary_key": true},
+		}
+		w = helper.MakeRequest(t, "POST", "/api/v1/config/tables", body, appHeader)
+		helper.AssertError(t, w, 500)
+	})
+
+	t.Run("默认值和注释按字符串转义", func(t *testing.T) {
+		tableID := newTestTable("test_field_type_quote", "默认值转义", []map[string]interface{}{
+			{"name": "note", "column_type": "varchar(50)", "default": `it's \ ok`, "comment": "O'Brien"},
+		}).create(t, helper)
+
+		var column struct {
+			Default string `db:"column_default"`
+			Comment string `db:"column_comment"`
+		}
+		err := model.DB.Get(&column, `
+			SELECT column_default, column_comment FROM information_schema.columns
+			WHERE table_schema = DATABASE() AND table_name = 'app1_test_field_type_quote' AND column_name = 'note'
+		`)
+		assert.NoError(t, err)
+		assert.Equal(t, `it's \ ok`, column.Default)
+		assert.Equal(t, "O'Brien", column.Comment)
+		assert.NotZero(t, tableID)
+	})
+}
@@ -0,0 +1,112 @@
+package test
+
+import (
+	"net/http"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestTableValidationRules(t *testing.T) {
+	helper := NewTestHelper(t)
+
+	tableID := newTestTable("test_validation", "校验规则测试表", []map[string]interface{}{
+		{"name": "name", "column_type": "varchar(50)", "rules": []map[string]interface{}{
+			{"type": "required"},
+			{"type": "max_length", "value": 10},
+		}},
+		{"name": "age", "column_type": "int", "rules": []map[string]interface{}{
+			{"type": "min", "value": 0},
+			{"type": "max", "value": 150, "message": "年龄不合法"},
+		}},
+		{"name": "status", "column_type": "varchar(20)", "rules": []map[string]interface{}{
+			{"type": "enum", "value": []string{"active", "inactive"}},
+		}},
+	}).create(t, helper)
+	basePath := tablePath(tableID)
+
+	t.Run("校验通过", func(t *testing.T) {
+		items := []map[string]interface{}{{"name": "张三", "age": 20, "status": "active"}}
+		w := helper.MakeRequest(t, "POST", basePath, items, appHeader)
+		helper.AssertSuccess(t, w)
+	})
+
+	t.Run("校验失败返回字段错误", func(t *testing.T) {
+		items := []map[string]interface{}{
+			{"name": "", "age": 20},
+			{"name": "李四", "age": 200, "status": "unknown"},
+		}
+		w := helper.MakeRequest(t, "POST", basePath, items, appHeader)
+		resp := helper.AssertError(t, w, http.StatusBadRequest)
+		errs, ok := resp["data"].([]interface{})
+		if !ok {
+			t.Fatal("校验错误响应格式不正确")
+		}
+		assert.Len(t, errs, 3)
+		first := errs[0].(map[string]interface{})
+		assert.Equal(t, float64(0), first["row"])
+		assert.Equal(t, "name", first["field"])
+	})
+
+	t.Run("局部更新不校验未提交的必填字段", func(t *testing.T) {
+		req := map[string]interface{}{
+			"primary_key_columns": []string{"id"},
+			"items":               []map[string]interface{}{{"id": 1, "age": 30}},
+		}
+		w := helper.MakeRequest(t, "PUT", basePath, req, appHeader)
+		helper.AssertSuccess(t, w)
+	})
+}
+
+func TestTableCompareRuleOnUpdate(t *testing.T) {
+	helper := NewTestHelper(t)
+
+	tableID := newTestTable("test_compare_rule", "比较规则测试表", []map[string]interface{}{
+		{"name": "start_date", "column_type": "date"},
+		{"name": "end_date", "column_type": "date", "rules": []map[string]interface{}{
+			{"type": "compare", "field": "start_date", "operator": "gte"},
+		}},
+	}).create(t, helper)
+	basePath := tablePath(tableID)
+
+	items := []map[string]interface{}{{"start_date": "2024-01-01", "end_date": "2024-01-31"}}
+	helper.AssertSuccess(t, helper.MakeRequest(t, "POST", basePath, items, appHeader))
+
+	t.Run("只提交比较字段之一时使用记录当前值", func(t *testing.T) {
+		req := map[string]interface{}{
+			"primary_key_columns": []string{"id"},
+			"items":               []map[string]interface{}{{"id": 1, "start_date": "2024-02-01"}},
+		}
+		w := helper.MakeRequest(t, "PUT", basePath, req, appHeader)
+		helper.AssertError(t, w, http.StatusBadRequest)
+
+		req["items"] = []map[string]interface{}{{"id": 1, "end_date": "2023-12-31"}}
+		w = helper.MakeRequest(t, "PUT", basePath, req, appHeader)
+		helper.AssertError(t, w, http.StatusBadRequest)
+	})
+
+	t.Run("比较成立时更新成功", func(t *testing.T) {
+		req := map[string]interface{}{
+			"primary_key_columns": []string{"id"},
+			"items":               []map[string]interface{}{{"id": 1, "start_date": "2024-01-15"}},
+		}
+		w := helper.MakeRequest(t, "PUT", basePath, req, appHeader)
+		helper.AssertSuccess(t, w)
+	})
+	t.Run("批量修改按每条记录的当前值校验", func(t *testing.T) {
+		body := map[string]interface{}{
+			"query": map[string]interface{}{
+				"root": map[string]interface{}{
+					"conditions": []map[string]interface{}{{"field": "id", "operator": "eq", "value": 1}},
+				},
+			},
+			"patch": map[string]interface{}{"start_date": "2024-03-01"},
+		}
+		w := helper.MakeRequest(t, "PUT", basePath+"/bulk", body, appHeader)
+		helper.AssertError(t, w, http.StatusBadRequest)
+
+		body["patch"] = map[string]interface{}{"start_date": "2024-01-20"}
+		w = helper.MakeRequest(t, "PUT", basePath+"/bulk", body, appHeader)
+		helper.AssertSuccess(t, w)
+	})
+}
@@ -0,0 +1,64 @@
+package test
+
+import (
+	"net/http"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestTableOptimisticLocking(t *testing.T) {
+	helper := NewTestHelper(t)
+
+	tableID := newTestTable("test_row_version", "乐观锁测试表", []map[string]interface{}{
+		{"name": "name", "column_type": "varchar(50)"},
+	}).withFunc(`{"versioned": true}`).create(t, helper)
+	basePath := tablePath(tableID)
+
+	w := helper.MakeRequest(t, "POST", basePath, []map[string]interface{}{{"name": "张三"}}, appHeader)
+	helper.AssertSuccess(t, w)
+
+	t.Run("缺少版本", func(t *testing.T) {
+		body := map[string]interface{}{
+			"primary_key_columns": []string{"id"},
+			"items":               []map[string]interface{}{{"id": 1, "name": "李四"}},
+		}
+		w := helper.MakeRequest(t, "PUT", basePath, body, appHeader)
+		helper.AssertError(t, w, 400)
+	})
+
+	t.Run("更新递增版本", func(t *testing.T) {
+		body := map[string]interface{}{
+			"primary_key_columns": []string{"id"},
+			"items":               []map[string]interface{}{{"id": 1, "name": "李四", "row_version": 1}},
+		}
+		w := helper.MakeRequest(t, "PUT", basePath, body, appHeader)
+		helper.AssertSuccess(t, w)
+
+		items := queryItems(t, helper, tableID, map[string]interface{}{})
+		if assert.Len(t, items, 1) {
+			assert.Equal(t, float64(2), items[0].(map[string]interface{})["row_version"])
+		}
+	})
+
+	t.Run("过期版本冲突", func(t *testing.T) {
+		body := map[string]interface{}{
+			"primary_key_columns": []string{"id"},
+			"items":               []map[string]interface{}{{"id": 1, "name": "王五", "row_version": 1}},
+		}
+		w := helper.MakeRequest(t, "PUT", basePath, body, appHeader)
+		resp := helper.AssertError(t, w, 409)
+		current := resp["data"].(map[string]interface{})["current"].(map[string]interface{})
+		assert.Equal(t, "李四", current["name"])
+	})
+
+	t.Run("If-Match删除", func(t *testing.T) {
+		headers := map[string]string{"App-ID": "1", "If-Match": `"1"`}
+		w := helper.MakeRequest(t, "DELETE", basePath, []map[string]interface{}{{"id": 1}}, headers)
+		helper.AssertError(t, w, 409)
+
+		headers["If-Match"] = `"2"`
+		w = helper.MakeRequest(t, "DELETE", basePath, []map[string]interface{}{{"id": 1}}, headers)
+		assert.Equal(t, http.StatusNoContent, w.Code)
+	})
+}
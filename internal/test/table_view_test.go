@@ -0,0 +1,95 @@
+package test
+
+import (
+	"strconv"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestTableViews(t *testing.T) {
+	helper := NewTestHelper(t)
+
+	tableID := newTestTable("test_view", "视图测试表", []map[string]interface{}{
+		{"name": "name", "column_type": "varchar(50)"},
+		{"name": "status", "column_type": "int"},
+	}).create(t, helper)
+	basePath := tablePath(tableID)
+	items := []map[string]interface{}{
+		{"name": "a", "status": 1}, {"name": "b", "status": 1}, {"name": "c", "status": 0},
+	}
+	helper.AssertSuccess(t, helper.MakeRequest(t, "POST", basePath, items, appHeader))
+
+	var viewID float64
+	t.Run("创建视图", func(t *testing.T) {
+		body := map[string]interface{}{
+			"name": "启用的记录",
+			"query": map[string]interface{}{
+				"root": map[string]interface{}{
+					"logic":      "AND",
+					"conditions": []map[string]interface{}{{"field": "status", "operator": "eq", "value": 1}},
+				},
+			},
+			"sort":           []map[string]interface{}{{"field": "name", "desc": true}},
+			"page_size":      1,
+			"hidden_columns": []string{"status"},
+			"column_order":   []string{"name", "id"},
+		}
+		w := helper.MakeRequest(t, "POST", basePath+"/views", body, appHeader)
+		data := helper.AssertSuccess(t, w)["data"].(map[string]interface{})
+		assert.Equal(t, true, data["editable"])
+		viewID = data["id"].(float64)
+
+		w = helper.MakeRequest(t, "POST", basePath+"/views", body, appHeader)
+		helper.AssertError(t, w, 409)
+	})
+
+	t.Run("按视图查询", func(t *testing.T) {
+		body := map[string]interface{}{"view_id": viewID}
+		w := helper.MakeRequest(t, "POST", basePath+"/query", body, appHeader)
+		data := helper.AssertSuccess(t, w)["data"].(map[string]interface{})
+		assert.Equal(t, float64(2), data["total"])
+		assert.Equal(t, float64(1), data["pageSize"])
+		rows := data["items"].([]interface{})
+		assert.Len(t, rows, 1)
+		row := rows[0].(map[string]interface{})
+		assert.Equal(t, "b", row["name"])
+		assert.NotContains(t, row, "status")
+	})
+
+	t.Run("视图条件与请求条件同时生效", func(t *testing.T) {
+		body := map[string]interface{}{
+			"view_id":   viewID,
+			"page_size": 10,
+			"query": map[string]interface{}{
+				"root": map[string]interface{}{
+					"logic":      "AND",
+					"conditions": []map[string]interface{}{{"field": "name", "operator": "eq", "value": "a"}},
+				},
+			},
+		}
+		w := helper.MakeRequest(t, "POST", basePath+"/query", body, appHeader)
+		data := helper.AssertSuccess(t, w)["data"].(map[string]interface{})
+		assert.Equal(t, float64(1), data["total"])
+	})
+
+	t.Run("视图字段必须存在", func(t *testing.T) {
+		body := map[string]interface{}{"name": "非法视图", "hidden_columns": []string{"missing"}}
+		w := helper.MakeRequest(t, "POST", basePath+"/views", body, appHeader)
+		helper.AssertError(t, w, 400)
+	})
+
+	t.Run("更新和删除视图", func(t *testing.T) {
+		viewPath := basePath + "/views/" + strconv.Itoa(int(viewID))
+		body := map[string]interface{}{"name": "共享视图", "shared": true, "roles": []string{"app_admin"}}
+		w := helper.MakeRequest(t, "PUT", viewPath, body, appHeader)
+		data := helper.AssertSuccess(t, w)["data"].(map[string]interface{})
+		assert.Equal(t, true, data["shared"])
+
+		w = helper.MakeRequest(t, "GET", basePath+"/views", nil, appHeader)
+		assert.Len(t, helper.AssertSuccess(t, w)["data"].([]interface{}), 1)
+
+		helper.AssertSuccess(t, helper.MakeRequest(t, "DELETE", viewPath, nil, appHeader))
+		helper.AssertError(t, helper.MakeRequest(t, "GET", viewPath, nil, appHeader), 404)
+	})
+}
@@ -0,0 +1,336 @@
+package formula
+
+import (
+	"fmt"
+	"math"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// DateTimeLayout 日期时间的默认格式
+const DateTimeLayout = "2006-01-02 15:04:05"
+
+// dateLayouts 支持解析的日期格式
+var dateLayouts = []string{
+	DateTimeLayout,
+	"2006-01-02",
+	time.RFC3339,
+	"2006-01-02T15:04:05",
+	"2006/01/02 15:04:05",
+	"2006/01/02",
+}
+
+// env 表达式求值环境
+type env struct {
+	vars map[string]interface{}
+	now  time.Time
+}
+
+func (n *literalNode) eval(e *env) (interface{}, error) {
+	return n.value, nil
+}
+
+func (n *fieldNode) eval(e *env) (interface{}, error) {
+	return normalize(e.vars[n.name]), nil
+}
+
+func (n *unaryNode) eval(e *env) (interface{}, error) {
+	v, err := n.operand.eval(e)
+	if err != nil {
+		return nil, err
+	}
+	switch n.op {
+	case "NOT":
+		if v == nil {
+			return nil, nil
+		}
+		return !toBool(v), nil
+	case "-":
+		if v == nil {
+			return nil, nil
+		}
+		num, ok := toNumber(v)
+		if !ok {
+			return nil, fmt.Errorf("operator - requires a number, got %v", v)
+		}
+		return -num, nil
+	}
+	return nil, fmt.Errorf("unknown operator %s", n.op)
+}
+
+func (n *binaryNode) eval(e *env) (interface{}, error) {
+	left, err := n.left.eval(e)
+	if err != nil {
+		return nil, err
+	}
+
+	// 逻辑运算短路求值
+	switch n.op {
+	case "AND":
+		if left != nil && !toBool(left) {
+			return false, nil
+		}
+		right, err := n.right.eval(e)
+		if err != nil {
+			return nil, err
+		}
+		if right != nil && !toBool(right) {
+			return false, nil
+		}
+		if left == nil || right == nil {
+			return nil, nil
+		}
+		return true, nil
+	case "OR":
+		if left != nil && toBool(left) {
+			return true, nil
+		}
+		right, err := n.right.eval(e)
+		if err != nil {
+			return nil, err
+		}
+		if right != nil && toBool(right) {
+			return true, nil
+		}
+		if left == nil || right == nil {
+			return nil, nil
+		}
+		return false, nil
+	}
+
+	right, err := n.right.eval(e)
+	if err != nil {
+		return nil, err
+	}
+
+	switch n.op {
+	case "&":
+		return toString(left) + toString(right), nil
+	case "=", "!=", "<", "<=", ">", ">=":
+		if left == nil || right == nil {
+			return nil, nil
+		}
+		cmp := compare(left, right)
+		switch n.op {
+		case "=":
+			return cmp == 0, nil
+		case "!=":
+			return cmp != 0, nil
+		case "<":
+			return cmp < 0, nil
+		case "<=":
+			return cmp <= 0, nil
+		case ">":
+			return cmp > 0, nil
+		default:
+			return cmp >= 0, nil
+		}
+	}
+
+	// 算术运算，NULL参与运算结果为NULL
+	if left == nil || right == nil {
+		return nil, nil
+	}
+	a, ok := toNumber(left)
+	if !ok {
+		return nil, fmt.Errorf("operator %s requires numbers, got %v", n.op, left)
+	}
+	b, ok := toNumber(right)
+	if !ok {
+		return nil, fmt.Errorf("operator %s requires numbers, got %v", n.op, right)
+	}
+	switch n.op {
+	case "+":
+		return a + b, nil
+	case "-":
+		return a - b, nil
+	case "*":
+		return a * b, nil
+	case "/":
+		// 与SQL一致，除数为0时结果为NULL
+		if b == 0 {
+			return nil, nil
+		}
+		return a / b, nil
+	case "%":
+		if b == 0 {
+			return nil, nil
+		}
+		return math.Mod(a, b), nil
+	}
+	return nil, fmt.Errorf("unknown operator %s", n.op)
+}
+
+func (n *caseNode) eval(e *env) (interface{}, error) {
+	for _, when := range n.whens {
+		cond, err := when.cond.eval(e)
+		if err != nil {
+			return nil, err
+		}
+		if toBool(cond) {
+			return when.result.eval(e)
+		}
+	}
+	if n.elseNode != nil {
+		return n.elseNode.eval(e)
+	}
+	return nil, nil
+}
+
+func (n *callNode) eval(e *env) (interface{}, error) {
+	// 惰性求值的函数只计算需要的参数
+	if n.fn.lazy != nil {
+		return n.fn.lazy(e, n.args)
+	}
+
+	args := make([]interface{}, len(n.args))
+	for i, arg := range n.args {
+		v, err := arg.eval(e)
+		if err != nil {
+			return nil, err
+		}
+		if v == nil && n.fn.nullable {
+			return nil, nil
+		}
+		args[i] = v
+	}
+	v, err := n.fn.call(e, args)
+	if err != nil {
+		return nil, fmt.Errorf("%s: %v", n.name, err)
+	}
+	return v, nil
+}
+
+// normalize 统一数据库及JSON中的取值类型
+func normalize(v interface{}) interface{} {
+	switch val := v.(type) {
+	case []byte:
+		return string(val)
+	case int:
+		return float64(val)
+	case int8:
+		return float64(val)
+	case int16:
+		return float64(val)
+	case int32:
+		return float64(val)
+	case int64:
+		return float64(val)
+	case uint:
+		return float64(val)
+	case uint8:
+		return float64(val)
+	case uint16:
+		return float64(val)
+	case uint32:
+		return float64(val)
+	case uint64:
+		return float64(val)
+	case float32:
+		return float64(val)
+	}
+	return v
+}
+
+// toNumber 转换为数字
+func toNumber(v interface{}) (float64, bool) {
+	switch val := normalize(v).(type) {
+	case float64:
+		return val, true
+	case bool:
+		if val {
+			return 1, true
+		}
+		return 0, true
+	case string:
+		f, err := strconv.ParseFloat(strings.TrimSpace(val), 64)
+		return f, err == nil
+	}
+	return 0, false
+}
+
+// toString 转换为字符串，NULL转换为空字符串
+func toString(v interface{}) string {
+	switch val := normalize(v).(type) {
+	case nil:
+		return ""
+	case string:
+		return val
+	case float64:
+		return strconv.FormatFloat(val, 'f', -1, 64)
+	case bool:
+		if val {
+			return "true"
+		}
+		return "false"
+	case time.Time:
+		return val.Format(DateTimeLayout)
+	}
+	return fmt.Sprintf("%v", v)
+}
+
+// toBool 转换为布尔值，NULL视为false
+func toBool(v interface{}) bool {
+	switch val := normalize(v).(type) {
+	case nil:
+		return false
+	case bool:
+		return val
+	case float64:
+		return val != 0
+	case string:
+		s := strings.TrimSpace(strings.ToLower(val))
+		return s != "" && s != "0" && s != "false"
+	case time.Time:
+		return !val.IsZero()
+	}
+	return true
+}
+
+// toTime 转换为时间
+func toTime(v interface{}) (time.Time, bool) {
+	switch val := normalize(v).(type) {
+	case time.Time:
+		return val, true
+	case string:
+		s := strings.TrimSpace(val)
+		for _, layout := range dateLayouts {
+			if t, err := time.ParseInLocation(layout, s, time.Local); err == nil {
+				return t, true
+			}
+		}
+	}
+	return time.Time{}, false
+}
+
+// compare 比较两个非NULL取值：均为数字时按数值比较，任一为时间时按时间比较，否则按字符串比较
+func compare(a, b interface{}) int {
+	if fa, ok := toNumber(a); ok {
+		if fb, ok := toNumber(b); ok {
+			switch {
+			case fa < fb:
+				return -1
+			case fa > fb:
+				return 1
+			}
+			return 0
+		}
+	}
+	_, aIsTime := a.(time.Time)
+	_, bIsTime := b.(time.Time)
+	if aIsTime || bIsTime {
+		ta, okA := toTime(a)
+		tb, okB := toTime(b)
+		if okA && okB {
+			switch {
+			case ta.Before(tb):
+				return -1
+			case ta.After(tb):
+				return 1
+			}
+			return 0
+		}
+	}
+	return strings.Compare(toString(a), toString(b))
+}
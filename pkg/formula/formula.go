@@ -0,0 +1,60 @@
+// Package formula 实现数据表计算字段使用的表达式语言。
+//
+// 支持算术运算(+ - * / %)、比较运算(= != <> < <= > >=)、逻辑运算(AND OR NOT)、
+// 字符串拼接(&)、CASE WHEN 表达式以及内置函数，标识符表示对其他字段的引用，
+// 包含特殊字符的字段名可以使用反引号包裹。表达式只能读取传入的字段值，不能访问外部资源。
+package formula
+
+import (
+	"fmt"
+	"sort"
+	"time"
+)
+
+// MaxLength 表达式最大长度
+const MaxLength = 4096
+
+// Expr 编译后的表达式
+type Expr struct {
+	src  string
+	root node
+	refs []string
+}
+
+// Compile 编译表达式
+func Compile(src string) (*Expr, error) {
+	if len(src) > MaxLength {
+		return nil, fmt.Errorf("expression exceeds %d characters", MaxLength)
+	}
+	tokens, err := lex(src)
+	if err != nil {
+		return nil, err
+	}
+	root, refSet, err := parse(tokens)
+	if err != nil {
+		return nil, err
+	}
+
+	refs := make([]string, 0, len(refSet))
+	for name := range refSet {
+		refs = append(refs, name)
+	}
+	sort.Strings(refs)
+
+	return &Expr{src: src, root: root, refs: refs}, nil
+}
+
+// String 返回表达式原文
+func (e *Expr) String() string {
+	return e.src
+}
+
+// Refs 返回表达式引用的字段名
+func (e *Expr) Refs() []string {
+	return e.refs
+}
+
+// Eval 使用字段值计算表达式，结果类型为 nil、float64、string、bool 或 time.Time
+func (e *Expr) Eval(vars map[string]interface{}) (interface{}, error) {
+	return e.root.eval(&env{vars: vars, now: time.Now()})
+}
@@ -0,0 +1,72 @@
+package formula_test
+
+import (
+	"testing"
+
+	"github.com/iiwish/lingjian/pkg/formula"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestFormulaEval(t *testing.T) {
+	vars := map[string]interface{}{
+		"qty":        3,
+		"price":      "2.5",
+		"name":       "lingjian",
+		"status":     1,
+		"start_date": "2024-01-15",
+		"end_date":   "2024-03-01 10:00:00",
+		"remark":     nil,
+	}
+
+	cases := []struct {
+		name   string
+		expr   string
+		expect interface{}
+	}{
+		{"算术运算", "qty * price + 1", 8.5},
+		{"运算优先级", "(qty + 1) * 2 - 10 / 5", 6.0},
+		{"除数为0", "qty / 0", nil},
+		{"NULL参与运算", "remark + 1", nil},
+		{"比较运算", "qty >= 3 AND price < 3", true},
+		{"字符串拼接", "UPPER(name) & '-' & qty", "LINGJIAN-3"},
+		{"IF函数", "IF(status = 1, '启用', '停用')", "启用"},
+		{"CASE表达式", "CASE WHEN qty > 5 THEN 'L' WHEN qty > 2 THEN 'M' ELSE 'S' END", "M"},
+		{"IFNULL函数", "IFNULL(remark, '无')", "无"},
+		{"ROUND函数", "ROUND(10 / 3, 2)", 3.33},
+		{"字符串截取", "SUBSTR(name, 5, 4)", "jian"},
+		{"日期差", "DATE_DIFF(end_date, start_date)", 46.0},
+		{"日期格式化", "DATE_FORMAT(DATE_ADD(start_date, 1, 'month'), 'YYYY/MM/DD')", "2024/02/15"},
+		{"日期部分", "YEAR(start_date) * 100 + MONTH(start_date)", 202401.0},
+	}
+
+	for _, c := range cases {
+		t.Run(c.name, func(t *testing.T) {
+			expr, err := formula.Compile(c.expr)
+			if !assert.NoError(t, err) {
+				return
+			}
+			result, err := expr.Eval(vars)
+			assert.NoError(t, err)
+			assert.Equal(t, c.expect, result)
+		})
+	}
+}
+
+func TestFormulaCompile(t *testing.T) {
+	expr, err := formula.Compile("IF(qty > 0, qty * `unit price`, 0)")
+	assert.NoError(t, err)
+	assert.Equal(t, []string{"qty", "unit price"}, expr.Refs())
+
+	invalid := []string{
+		"qty *",
+		"UNKNOWN_FUNC(qty)",
+		"IF(qty)",
+		"CASE qty END",
+		"'unterminated",
+		"qty # 2",
+	}
+	for _, src := range invalid {
+		_, err := formula.Compile(src)
+		assert.Error(t, err, src)
+	}
+}
@@ -0,0 +1,421 @@
+package formula
+
+import (
+	"fmt"
+	"math"
+	"strings"
+	"time"
+	"unicode/utf8"
+)
+
+// function 内置函数定义
+type function struct {
+	minArgs  int                                                   // 最少参数个数
+	maxArgs  int                                                   // 最多参数个数，-1表示不限
+	nullable bool                                                  // 任一参数为NULL时直接返回NULL
+	call     func(e *env, args []interface{}) (interface{}, error) // 普通函数
+	lazy     func(e *env, args []node) (interface{}, error)        // 惰性求值函数
+}
+
+// functions 内置函数表
+var functions map[string]*function
+
+func init() {
+	functions = map[string]*function{
+		// 逻辑函数
+		"IF":       {minArgs: 2, maxArgs: 3, lazy: fnIf},
+		"IFNULL":   {minArgs: 2, maxArgs: 2, lazy: fnCoalesce},
+		"COALESCE": {minArgs: 1, maxArgs: -1, lazy: fnCoalesce},
+		"ISNULL":   {minArgs: 1, maxArgs: 1, call: fnIsNull},
+
+		// 数学函数
+		"ABS":   {minArgs: 1, maxArgs: 1, nullable: true, call: mathFunc(math.Abs)},
+		"FLOOR": {minArgs: 1, maxArgs: 1, nullable: true, call: mathFunc(math.Floor)},
+		"CEIL":  {minArgs: 1, maxArgs: 1, nullable: true, call: mathFunc(math.Ceil)},
+		"SQRT":  {minArgs: 1, maxArgs: 1, nullable: true, call: fnSqrt},
+		"ROUND": {minArgs: 1, maxArgs: 2, nullable: true, call: fnRound},
+		"POWER": {minArgs: 2, maxArgs: 2, nullable: true, call: fnPower},
+		"MOD":   {minArgs: 2, maxArgs: 2, nullable: true, call: fnMod},
+		"MIN":   {minArgs: 1, maxArgs: -1, call: fnMin},
+		"MAX":   {minArgs: 1, maxArgs: -1, call: fnMax},
+
+		// 字符串函数
+		"CONCAT":   {minArgs: 1, maxArgs: -1, call: fnConcat},
+		"UPPER":    {minArgs: 1, maxArgs: 1, nullable: true, call: strFunc(strings.ToUpper)},
+		"LOWER":    {minArgs: 1, maxArgs: 1, nullable: true, call: strFunc(strings.ToLower)},
+		"TRIM":     {minArgs: 1, maxArgs: 1, nullable: true, call: strFunc(strings.TrimSpace)},
+		"LENGTH":   {minArgs: 1, maxArgs: 1, nullable: true, call: fnLength},
+		"LEFT":     {minArgs: 2, maxArgs: 2, nullable: true, call: fnLeft},
+		"RIGHT":    {minArgs: 2, maxArgs: 2, nullable: true, call: fnRight},
+		"SUBSTR":   {minArgs: 2, maxArgs: 3, nullable: true, call: fnSubstr},
+		"REPLACE":  {minArgs: 3, maxArgs: 3, nullable: true, call: fnReplace},
+		"CONTAINS": {minArgs: 2, maxArgs: 2, nullable: true, call: fnContains},
+		"TEXT":     {minArgs: 1, maxArgs: 1, nullable: true, call: fnText},
+		"VALUE":    {minArgs: 1, maxArgs: 1, nullable: true, call: fnValue},
+
+		// 日期函数
+		"NOW":         {minArgs: 0, maxArgs: 0, call: fnNow},
+		"TODAY":       {minArgs: 0, maxArgs: 0, call: fnToday},
+		"YEAR":        {minArgs: 1, maxArgs: 1, nullable: true, call: datePart(func(t time.Time) int { return t.Year() })},
+		"MONTH":       {minArgs: 1, maxArgs: 1, nullable: true, call: datePart(func(t time.Time) int { return int(t.Month()) })},
+		"DAY":         {minArgs: 1, maxArgs: 1, nullable: true, call: datePart(func(t time.Time) int { return t.Day() })},
+		"DATE_ADD":    {minArgs: 2, maxArgs: 3, nullable: true, call: fnDateAdd},
+		"DATE_DIFF":   {minArgs: 2, maxArgs: 3, nullable: true, call: fnDateDiff},
+		"DATE_FORMAT": {minArgs: 2, maxArgs: 2, nullable: true, call: fnDateFormat},
+	}
+}
+
+func fnIf(e *env, args []node) (interface{}, error) {
+	cond, err := args[0].eval(e)
+	if err != nil {
+		return nil, err
+	}
+	if toBool(cond) {
+		return args[1].eval(e)
+	}
+	if len(args) > 2 {
+		return args[2].eval(e)
+	}
+	return nil, nil
+}
+
+func fnCoalesce(e *env, args []node) (interface{}, error) {
+	for _, arg := range args {
+		v, err := arg.eval(e)
+		if err != nil {
+			return nil, err
+		}
+		if v != nil {
+			return v, nil
+		}
+	}
+	return nil, nil
+}
+
+func fnIsNull(e *env, args []interface{}) (interface{}, error) {
+	return args[0] == nil, nil
+}
+
+// number 读取数字参数
+func number(v interface{}) (float64, error) {
+	f, ok := toNumber(v)
+	if !ok {
+		return 0, fmt.Errorf("expected a number, got %v", v)
+	}
+	return f, nil
+}
+
+// integer 读取整数参数
+func integer(v interface{}) (int, error) {
+	f, err := number(v)
+	if err != nil {
+		return 0, err
+	}
+	return int(f), nil
+}
+
+// date 读取日期参数
+func date(v interface{}) (time.Time, error) {
+	t, ok := toTime(v)
+	if !ok {
+		return time.Time{}, fmt.Errorf("expected a date, got %v", v)
+	}
+	return t, nil
+}
+
+func mathFunc(fn func(float64) float64) func(e *env, args []interface{}) (interface{}, error) {
+	return func(e *env, args []interface{}) (interface{}, error) {
+		x, err := number(args[0])
+		if err != nil {
+			return nil, err
+		}
+		return fn(x), nil
+	}
+}
+
+func fnSqrt(e *env, args []interface{}) (interface{}, error) {
+	x, err := number(args[0])
+	if err != nil {
+		return nil, err
+	}
+	if x < 0 {
+		return nil, nil
+	}
+	return math.Sqrt(x), nil
+}
+
+func fnRound(e *env, args []interface{}) (interface{}, error) {
+	x, err := number(args[0])
+	if err != nil {
+		return nil, err
+	}
+	digits := 0
+	if len(args) > 1 {
+		if digits, err = integer(args[1]); err != nil {
+			return nil, err
+		}
+	}
+	pow := math.Pow(10, float64(digits))
+	return math.Round(x*pow) / pow, nil
+}
+
+func fnPower(e *env, args []interface{}) (interface{}, error) {
+	x, err := number(args[0])
+	if err != nil {
+		return nil, err
+	}
+	y, err := number(args[1])
+	if err != nil {
+		return nil, err
+	}
+	result := math.Pow(x, y)
+	if math.IsNaN(result) || math.IsInf(result, 0) {
+		return nil, nil
+	}
+	return result, nil
+}
+
+func fnMod(e *env, args []interface{}) (interface{}, error) {
+	x, err := number(args[0])
+	if err != nil {
+		return nil, err
+	}
+	y, err := number(args[1])
+	if err != nil {
+		return nil, err
+	}
+	if y == 0 {
+		return nil, nil
+	}
+	return math.Mod(x, y), nil
+}
+
+// extreme 返回参数中的最小或最大值，忽略NULL
+func extreme(args []interface{}, less bool) interface{} {
+	var result interface{}
+	for _, arg := range args {
+		if arg == nil {
+			continue
+		}
+		if result == nil {
+			result = arg
+			continue
+		}
+		cmp := compare(arg, result)
+		if (less && cmp < 0) || (!less && cmp > 0) {
+			result = arg
+		}
+	}
+	return result
+}
+
+func fnMin(e *env, args []interface{}) (interface{}, error) {
+	return extreme(args, true), nil
+}
+
+func fnMax(e *env, args []interface{}) (interface{}, error) {
+	return extreme(args, false), nil
+}
+
+func fnConcat(e *env, args []interface{}) (interface{}, error) {
+	var sb strings.Builder
+	for _, arg := range args {
+		sb.WriteString(toString(arg))
+	}
+	return sb.String(), nil
+}
+
+func strFunc(fn func(string) string) func(e *env, args []interface{}) (interface{}, error) {
+	return func(e *env, args []interface{}) (interface{}, error) {
+		return fn(toString(args[0])), nil
+	}
+}
+
+func fnLength(e *env, args []interface{}) (interface{}, error) {
+	return float64(utf8.RuneCountInString(toString(args[0]))), nil
+}
+
+func fnLeft(e *env, args []interface{}) (interface{}, error) {
+	s := []rune(toString(args[0]))
+	n, err := integer(args[1])
+	if err != nil {
+		return nil, err
+	}
+	n = clamp(n, 0, len(s))
+	return string(s[:n]), nil
+}
+
+func fnRight(e *env, args []interface{}) (interface{}, error) {
+	s := []rune(toString(args[0]))
+	n, err := integer(args[1])
+	if err != nil {
+		return nil, err
+	}
+	n = clamp(n, 0, len(s))
+	return string(s[len(s)-n:]), nil
+}
+
+// fnSubstr 截取子串，起始位置从1开始
+func fnSubstr(e *env, args []interface{}) (interface{}, error) {
+	s := []rune(toString(args[0]))
+	start, err := integer(args[1])
+	if err != nil {
+		return nil, err
+	}
+	start = clamp(start-1, 0, len(s))
+	end := len(s)
+	if len(args) > 2 {
+		length, err := integer(args[2])
+		if err != nil {
+			return nil, err
+		}
+		end = clamp(start+length, start, len(s))
+	}
+	return string(s[start:end]), nil
+}
+
+func fnReplace(e *env, args []interface{}) (interface{}, error) {
+	return strings.ReplaceAll(toString(args[0]), toString(args[1]), toString(args[2])), nil
+}
+
+func fnContains(e *env, args []interface{}) (interface{}, error) {
+	return strings.Contains(toString(args[0]), toString(args[1])), nil
+}
+
+func fnText(e *env, args []interface{}) (interface{}, error) {
+	return toString(args[0]), nil
+}
+
+func fnValue(e *env, args []interface{}) (interface{}, error) {
+	f, ok := toNumber(args[0])
+	if !ok {
+		return nil, nil
+	}
+	return f, nil
+}
+
+func clamp(v, lo, hi int) int {
+	if v < lo {
+		return lo
+	}
+	if v > hi {
+		return hi
+	}
+	return v
+}
+
+func fnNow(e *env, args []interface{}) (interface{}, error) {
+	return e.now, nil
+}
+
+func fnToday(e *env, args []interface{}) (interface{}, error) {
+	y, m, d := e.now.Date()
+	return time.Date(y, m, d, 0, 0, 0, 0, e.now.Location()), nil
+}
+
+func datePart(fn func(time.Time) int) func(e *env, args []interface{}) (interface{}, error) {
+	return func(e *env, args []interface{}) (interface{}, error) {
+		t, err := date(args[0])
+		if err != nil {
+			return nil, err
+		}
+		return float64(fn(t)), nil
+	}
+}
+
+// dateUnit 读取时间单位参数，默认为天
+func dateUnit(args []interface{}, index int) (string, error) {
+	if len(args) <= index {
+		return "day", nil
+	}
+	unit := strings.ToLower(toString(args[index]))
+	switch unit {
+	case "year", "month", "day", "hour", "minute", "second":
+		return unit, nil
+	}
+	return "", fmt.Errorf("unknown unit %s", unit)
+}
+
+// fnDateAdd 日期加减：DATE_ADD(date, n[, unit])
+func fnDateAdd(e *env, args []interface{}) (interface{}, error) {
+	t, err := date(args[0])
+	if err != nil {
+		return nil, err
+	}
+	n, err := integer(args[1])
+	if err != nil {
+		return nil, err
+	}
+	unit, err := dateUnit(args, 2)
+	if err != nil {
+		return nil, err
+	}
+	switch unit {
+	case "year":
+		return t.AddDate(n, 0, 0), nil
+	case "month":
+		return t.AddDate(0, n, 0), nil
+	case "day":
+		return t.AddDate(0, 0, n), nil
+	case "hour":
+		return t.Add(time.Duration(n) * time.Hour), nil
+	case "minute":
+		return t.Add(time.Duration(n) * time.Minute), nil
+	default:
+		return t.Add(time.Duration(n) * time.Second), nil
+	}
+}
+
+// fnDateDiff 日期差值：DATE_DIFF(end, start[, unit])
+func fnDateDiff(e *env, args []interface{}) (interface{}, error) {
+	end, err := date(args[0])
+	if err != nil {
+		return nil, err
+	}
+	start, err := date(args[1])
+	if err != nil {
+		return nil, err
+	}
+	unit, err := dateUnit(args, 2)
+	if err != nil {
+		return nil, err
+	}
+	switch unit {
+	case "year":
+		return float64(end.Year() - start.Year()), nil
+	case "month":
+		return float64((end.Year()-start.Year())*12 + int(end.Month()) - int(start.Month())), nil
+	case "day":
+		// 按自然日计算，忽略时分秒
+		ey, em, ed := end.Date()
+		sy, sm, sd := start.Date()
+		endDay := time.Date(ey, em, ed, 0, 0, 0, 0, time.UTC)
+		startDay := time.Date(sy, sm, sd, 0, 0, 0, 0, time.UTC)
+		return math.Round(endDay.Sub(startDay).Hours() / 24), nil
+	case "hour":
+		return math.Trunc(end.Sub(start).Hours()), nil
+	case "minute":
+		return math.Trunc(end.Sub(start).Minutes()), nil
+	default:
+		return math.Trunc(end.Sub(start).Seconds()), nil
+	}
+}
+
+// dateFormatReplacer 将 YYYY-MM-DD HH:mm:ss 风格的格式转换为Go格式
+var dateFormatReplacer = strings.NewReplacer(
+	"YYYY", "2006",
+	"YY", "06",
+	"MM", "01",
+	"DD", "02",
+	"HH", "15",
+	"mm", "04",
+	"ss", "05",
+)
+
+func fnDateFormat(e *env, args []interface{}) (interface{}, error) {
+	t, err := date(args[0])
+	if err != nil {
+		return nil, err
+	}
+	return t.Format(dateFormatReplacer.Replace(toString(args[1]))), nil
+}
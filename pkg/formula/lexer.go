@@ -0,0 +1,129 @@
+package formula
+
+import (
+	"fmt"
+	"strings"
+	"unicode"
+)
+
+// tokenType 词法单元类型
+type tokenType int
+
+const (
+	tokEOF tokenType = iota
+	tokNumber
+	tokString
+	tokIdent
+	tokOp
+	tokLParen
+	tokRParen
+	tokComma
+)
+
+// token 词法单元
+type token struct {
+	typ tokenType
+	val string
+	pos int
+}
+
+// lex 将表达式拆分为词法单元
+func lex(src string) ([]token, error) {
+	var tokens []token
+	runes := []rune(src)
+	i := 0
+	for i < len(runes) {
+		r := runes[i]
+		switch {
+		case unicode.IsSpace(r):
+			i++
+		case unicode.IsDigit(r) || (r == '.' && i+1 < len(runes) && unicode.IsDigit(runes[i+1])):
+			start := i
+			seenDot := false
+			for i < len(runes) && (unicode.IsDigit(runes[i]) || (runes[i] == '.' && !seenDot)) {
+				if runes[i] == '.' {
+					seenDot = true
+				}
+				i++
+			}
+			tokens = append(tokens, token{typ: tokNumber, val: string(runes[start:i]), pos: start})
+		case r == '\'' || r == '"':
+			start := i
+			quote := r
+			i++
+			var sb strings.Builder
+			closed := false
+			for i < len(runes) {
+				if runes[i] == '\\' && i+1 < len(runes) {
+					sb.WriteRune(runes[i+1])
+					i += 2
+					continue
+				}
+				if runes[i] == quote {
+					// 连续两个引号表示引号本身
+					if i+1 < len(runes) && runes[i+1] == quote {
+						sb.WriteRune(quote)
+						i += 2
+						continue
+					}
+					closed = true
+					i++
+					break
+				}
+				sb.WriteRune(runes[i])
+				i++
+			}
+			if !closed {
+				return nil, fmt.Errorf("unterminated string at position %d", start)
+			}
+			tokens = append(tokens, token{typ: tokString, val: sb.String(), pos: start})
+		case r == '`':
+			// 反引号包裹的字段名
+			start := i
+			i++
+			for i < len(runes) && runes[i] != '`' {
+				i++
+			}
+			if i >= len(runes) || i == start+1 {
+				return nil, fmt.Errorf("invalid quoted identifier at position %d", start)
+			}
+			tokens = append(tokens, token{typ: tokIdent, val: string(runes[start+1 : i]), pos: start})
+			i++
+		case unicode.IsLetter(r) || r == '_':
+			start := i
+			for i < len(runes) && (unicode.IsLetter(runes[i]) || unicode.IsDigit(runes[i]) || runes[i] == '_') {
+				i++
+			}
+			tokens = append(tokens, token{typ: tokIdent, val: string(runes[start:i]), pos: start})
+		case r == '(':
+			tokens = append(tokens, token{typ: tokLParen, val: "(", pos: i})
+			i++
+		case r == ')':
+			tokens = append(tokens, token{typ: tokRParen, val: ")", pos: i})
+			i++
+		case r == ',':
+			tokens = append(tokens, token{typ: tokComma, val: ",", pos: i})
+			i++
+		default:
+			// 优先匹配双字符运算符
+			if i+1 < len(runes) {
+				two := string(runes[i : i+2])
+				switch two {
+				case "==", "!=", "<>", "<=", ">=", "&&", "||":
+					tokens = append(tokens, token{typ: tokOp, val: two, pos: i})
+					i += 2
+					continue
+				}
+			}
+			switch r {
+			case '+', '-', '*', '/', '%', '=', '<', '>', '!', '&':
+				tokens = append(tokens, token{typ: tokOp, val: string(r), pos: i})
+				i++
+			default:
+				return nil, fmt.Errorf("unexpected character %q at position %d", r, i)
+			}
+		}
+	}
+	tokens = append(tokens, token{typ: tokEOF, pos: len(runes)})
+	return tokens, nil
+}
@@ -0,0 +1,379 @@
+package formula
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+)
+
+// maxDepth 表达式最大嵌套层数
+const maxDepth = 64
+
+// node 语法树节点
+type node interface {
+	eval(env *env) (interface{}, error)
+}
+
+// literalNode 常量
+type literalNode struct {
+	value interface{}
+}
+
+// fieldNode 字段引用
+type fieldNode struct {
+	name string
+}
+
+// unaryNode 一元运算
+type unaryNode struct {
+	op      string
+	operand node
+}
+
+// binaryNode 二元运算
+type binaryNode struct {
+	op          string
+	left, right node
+}
+
+// whenClause CASE表达式的WHEN分支
+type whenClause struct {
+	cond, result node
+}
+
+// caseNode CASE WHEN表达式
+type caseNode struct {
+	whens    []whenClause
+	elseNode node
+}
+
+// callNode 函数调用
+type callNode struct {
+	name string
+	fn   *function
+	args []node
+}
+
+// parser 语法分析器
+type parser struct {
+	tokens []token
+	pos    int
+	depth  int
+	refs   map[string]bool
+}
+
+// parse 将词法单元解析为语法树
+func parse(tokens []token) (node, map[string]bool, error) {
+	p := &parser{tokens: tokens, refs: make(map[string]bool)}
+	n, err := p.parseExpr()
+	if err != nil {
+		return nil, nil, err
+	}
+	if tok := p.peek(); tok.typ != tokEOF {
+		return nil, nil, fmt.Errorf("unexpected %q at position %d", tok.val, tok.pos)
+	}
+	return n, p.refs, nil
+}
+
+func (p *parser) peek() token {
+	return p.tokens[p.pos]
+}
+
+func (p *parser) next() token {
+	tok := p.tokens[p.pos]
+	if tok.typ != tokEOF {
+		p.pos++
+	}
+	return tok
+}
+
+// isKeyword 判断当前词法单元是否为指定关键字
+func (p *parser) isKeyword(keyword string) bool {
+	tok := p.peek()
+	return tok.typ == tokIdent && strings.EqualFold(tok.val, keyword)
+}
+
+// isOp 判断当前词法单元是否为指定运算符之一
+func (p *parser) isOp(ops ...string) bool {
+	tok := p.peek()
+	if tok.typ != tokOp {
+		return false
+	}
+	for _, op := range ops {
+		if tok.val == op {
+			return true
+		}
+	}
+	return false
+}
+
+// expectKeyword 读取指定关键字
+func (p *parser) expectKeyword(keyword string) error {
+	if !p.isKeyword(keyword) {
+		tok := p.peek()
+		return fmt.Errorf("expected %s at position %d", keyword, tok.pos)
+	}
+	p.next()
+	return nil
+}
+
+func (p *parser) parseExpr() (node, error) {
+	p.depth++
+	defer func() { p.depth-- }()
+	if p.depth > maxDepth {
+		return nil, fmt.Errorf("expression is nested too deeply")
+	}
+	return p.parseOr()
+}
+
+func (p *parser) parseOr() (node, error) {
+	left, err := p.parseAnd()
+	if err != nil {
+		return nil, err
+	}
+	for p.isKeyword("OR") || p.isOp("||") {
+		p.next()
+		right, err := p.parseAnd()
+		if err != nil {
+			return nil, err
+		}
+		left = &binaryNode{op: "OR", left: left, right: right}
+	}
+	return left, nil
+}
+
+func (p *parser) parseAnd() (node, error) {
+	left, err := p.parseNot()
+	if err != nil {
+		return nil, err
+	}
+	for p.isKeyword("AND") || p.isOp("&&") {
+		p.next()
+		right, err := p.parseNot()
+		if err != nil {
+			return nil, err
+		}
+		left = &binaryNode{op: "AND", left: left, right: right}
+	}
+	return left, nil
+}
+
+func (p *parser) parseNot() (node, error) {
+	if p.isKeyword("NOT") || p.isOp("!") {
+		p.next()
+		p.depth++
+		defer func() { p.depth-- }()
+		if p.depth > maxDepth {
+			return nil, fmt.Errorf("expression is nested too deeply")
+		}
+		operand, err := p.parseNot()
+		if err != nil {
+			return nil, err
+		}
+		return &unaryNode{op: "NOT", operand: operand}, nil
+	}
+	return p.parseCompare()
+}
+
+func (p *parser) parseCompare() (node, error) {
+	left, err := p.parseConcat()
+	if err != nil {
+		return nil, err
+	}
+	if p.isOp("=", "==", "!=", "<>", "<", "<=", ">", ">=") {
+		op := p.next().val
+		switch op {
+		case "==":
+			op = "="
+		case "<>":
+			op = "!="
+		}
+		right, err := p.parseConcat()
+		if err != nil {
+			return nil, err
+		}
+		return &binaryNode{op: op, left: left, right: right}, nil
+	}
+	return left, nil
+}
+
+func (p *parser) parseConcat() (node, error) {
+	left, err := p.parseAdd()
+	if err != nil {
+		return nil, err
+	}
+	for p.isOp("&") {
+		p.next()
+		right, err := p.parseAdd()
+		if err != nil {
+			return nil, err
+		}
+		left = &binaryNode{op: "&", left: left, right: right}
+	}
+	return left, nil
+}
+
+func (p *parser) parseAdd() (node, error) {
+	left, err := p.parseMul()
+	if err != nil {
+		return nil, err
+	}
+	for p.isOp("+", "-") {
+		op := p.next().val
+		right, err := p.parseMul()
+		if err != nil {
+			return nil, err
+		}
+		left = &binaryNode{op: op, left: left, right: right}
+	}
+	return left, nil
+}
+
+func (p *parser) parseMul() (node, error) {
+	left, err := p.parseUnary()
+	if err != nil {
+		return nil, err
+	}
+	for p.isOp("*", "/", "%") {
+		op := p.next().val
+		right, err := p.parseUnary()
+		if err != nil {
+			return nil, err
+		}
+		left = &binaryNode{op: op, left: left, right: right}
+	}
+	return left, nil
+}
+
+func (p *parser) parseUnary() (node, error) {
+	if p.isOp("-", "+") {
+		op := p.next().val
+		p.depth++
+		defer func() { p.depth-- }()
+		if p.depth > maxDepth {
+			return nil, fmt.Errorf("expression is nested too deeply")
+		}
+		operand, err := p.parseUnary()
+		if err != nil {
+			return nil, err
+		}
+		if op == "+" {
+			return operand, nil
+		}
+		return &unaryNode{op: "-", operand: operand}, nil
+	}
+	return p.parsePrimary()
+}
+
+func (p *parser) parsePrimary() (node, error) {
+	tok := p.next()
+	switch tok.typ {
+	case tokNumber:
+		v, err := strconv.ParseFloat(tok.val, 64)
+		if err != nil {
+			return nil, fmt.Errorf("invalid number %q at position %d", tok.val, tok.pos)
+		}
+		return &literalNode{value: v}, nil
+	case tokString:
+		return &literalNode{value: tok.val}, nil
+	case tokLParen:
+		n, err := p.parseExpr()
+		if err != nil {
+			return nil, err
+		}
+		if p.peek().typ != tokRParen {
+			return nil, fmt.Errorf("expected ) at position %d", p.peek().pos)
+		}
+		p.next()
+		return n, nil
+	case tokIdent:
+		switch strings.ToUpper(tok.val) {
+		case "TRUE":
+			return &literalNode{value: true}, nil
+		case "FALSE":
+			return &literalNode{value: false}, nil
+		case "NULL":
+			return &literalNode{value: nil}, nil
+		case "CASE":
+			return p.parseCase()
+		}
+		if p.peek().typ == tokLParen {
+			return p.parseCall(tok)
+		}
+		p.refs[tok.val] = true
+		return &fieldNode{name: tok.val}, nil
+	case tokEOF:
+		return nil, fmt.Errorf("unexpected end of expression")
+	}
+	return nil, fmt.Errorf("unexpected %q at position %d", tok.val, tok.pos)
+}
+
+// parseCase 解析 CASE WHEN ... THEN ... [ELSE ...] END
+func (p *parser) parseCase() (node, error) {
+	n := &caseNode{}
+	for p.isKeyword("WHEN") {
+		p.next()
+		cond, err := p.parseExpr()
+		if err != nil {
+			return nil, err
+		}
+		if err := p.expectKeyword("THEN"); err != nil {
+			return nil, err
+		}
+		result, err := p.parseExpr()
+		if err != nil {
+			return nil, err
+		}
+		n.whens = append(n.whens, whenClause{cond: cond, result: result})
+	}
+	if len(n.whens) == 0 {
+		return nil, fmt.Errorf("CASE requires at least one WHEN at position %d", p.peek().pos)
+	}
+	if p.isKeyword("ELSE") {
+		p.next()
+		elseNode, err := p.parseExpr()
+		if err != nil {
+			return nil, err
+		}
+		n.elseNode = elseNode
+	}
+	if err := p.expectKeyword("END"); err != nil {
+		return nil, err
+	}
+	return n, nil
+}
+
+// parseCall 解析函数调用，并检查函数名与参数个数
+func (p *parser) parseCall(name token) (node, error) {
+	fnName := strings.ToUpper(name.val)
+	fn, ok := functions[fnName]
+	if !ok {
+		return nil, fmt.Errorf("unknown function %s at position %d", name.val, name.pos)
+	}
+	p.next() // (
+
+	var args []node
+	if p.peek().typ != tokRParen {
+		for {
+			arg, err := p.parseExpr()
+			if err != nil {
+				return nil, err
+			}
+			args = append(args, arg)
+			if p.peek().typ == tokComma {
+				p.next()
+				continue
+			}
+			break
+		}
+	}
+	if p.peek().typ != tokRParen {
+		return nil, fmt.Errorf("expected ) at position %d", p.peek().pos)
+	}
+	p.next()
+
+	if len(args) < fn.minArgs || (fn.maxArgs >= 0 && len(args) > fn.maxArgs) {
+		return nil, fmt.Errorf("function %s: wrong number of arguments", fnName)
+	}
+	return &callNode{name: fnName, fn: fn, args: args}, nil
+}
@@ -4,6 +4,7 @@ import (
 	"context"
 	"fmt"
 	"log"
+	"time"
 
 	"github.com/redis/go-redis/v9"
 	"github.com/spf13/viper"
@@ -11,6 +12,9 @@ import (
 
 var RDB *redis.Client
 
+// Nil 键不存在时Get返回的错误
+const Nil = redis.Nil
+
 func InitRedis() {
 	host := viper.GetString("redis.host")
 	port := viper.GetString("redis.port")
@@ -41,11 +45,21 @@ func CloseRedis() {
 	}
 }
 
+// Enabled 判断是否已连接Redis
+func Enabled() bool {
+	return RDB != nil
+}
+
 // Set 设置键值对
 func Set(ctx context.Context, key string, value interface{}, ttl int) error {
 	return RDB.Set(ctx, key, value, 0).Err()
 }
 
+// SetEx 设置键值对，ttl为过期时间，单位秒
+func SetEx(ctx context.Context, key string, value interface{}, ttl int) error {
+	return RDB.Set(ctx, key, value, time.Duration(ttl)*time.Second).Err()
+}
+
 // Get 获取值
 func Get(ctx context.Context, key string) (string, error) {
 	return RDB.Get(ctx, key).Result()
@@ -61,3 +75,18 @@ func Exists(ctx context.Context, key string) (bool, error) {
 	result, err := RDB.Exists(ctx, key).Result()
 	return result > 0, err
 }
+
+// Incr 将键的值加1并返回新值
+func Incr(ctx context.Context, key string) (int64, error) {
+	return RDB.Incr(ctx, key).Result()
+}
+
+// HIncrBy 将哈希字段的值增加increment
+func HIncrBy(ctx context.Context, key, field string, increment int64) error {
+	return RDB.HIncrBy(ctx, key, field, increment).Err()
+}
+
+// HGetAll 获取哈希的全部字段
+func HGetAll(ctx context.Context, key string) (map[string]string, error) {
+	return RDB.HGetAll(ctx, key).Result()
+}
@@ -0,0 +1,41 @@
+package storage
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+
+	"github.com/spf13/viper"
+)
+
+// ErrNotFound 文件不存在
+var ErrNotFound = errors.New("file not found")
+
+// Storage 文件存储接口，key为存储路径，由调用方生成，以/分隔
+type Storage interface {
+	// Save 保存文件，返回写入的字节数
+	Save(ctx context.Context, key string, r io.Reader) (int64, error)
+	// Open 打开文件，文件不存在时返回ErrNotFound
+	Open(ctx context.Context, key string) (io.ReadCloser, error)
+	// Delete 删除文件，文件不存在时不报错
+	Delete(ctx context.Context, key string) error
+}
+
+// Default 默认文件存储
+var Default Storage
+
+// InitStorage 根据配置初始化默认文件存储，目前支持local
+func InitStorage() error {
+	switch typ := viper.GetString("storage.type"); typ {
+	case "", "local":
+		root := viper.GetString("storage.local.root")
+		if root == "" {
+			root = "data/attachments"
+		}
+		Default = NewLocalStorage(root)
+	default:
+		return fmt.Errorf("unsupported storage type: %s", typ)
+	}
+	return nil
+}
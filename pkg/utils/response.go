@@ -72,6 +72,8 @@ func Error(c *gin.Context, code int, message string) {
 		httpStatus = http.StatusForbidden
 	case 404:
 		httpStatus = http.StatusNotFound
+	case 409:
+		httpStatus = http.StatusConflict
 	default:
 		httpStatus = http.StatusInternalServerError
 	}
@@ -95,6 +97,8 @@ func ErrorWithData(c *gin.Context, code int, message string, data interface{}) {
 		httpStatus = http.StatusForbidden
 	case 404:
 		httpStatus = http.StatusNotFound
+	case 409:
+		httpStatus = http.StatusConflict
 	default:
 		httpStatus = http.StatusInternalServerError
 	}